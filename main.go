@@ -4,10 +4,13 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"net/url"
 	"os"
 	"regexp"
 	"strings"
+	"time"
 
+	"github.com/openshift/sippy/pkg/alerting"
 	"github.com/openshift/sippy/pkg/buganalysis"
 	"github.com/openshift/sippy/pkg/sippyserver"
 	"github.com/openshift/sippy/pkg/testgridanalysis/testgridconversion"
@@ -24,6 +27,10 @@ type Options struct {
 	Dashboards        []string
 	// TODO perhaps this could drive the synthetic tests too
 	Variants                []string
+	VariantConfig           string
+	SyntheticTestConfig     string
+	IdentificationConfig    string
+	TestOwnershipConfig     string
 	StartDay                int
 	endDay                  int
 	NumDays                 int
@@ -33,7 +40,29 @@ type Options struct {
 	Output                  string
 	FailureClusterThreshold int
 	FetchData               string
+	FetchWorkers            int
+	FetchRetries            int
+	FetchResume             bool
+	TestGridURL             string
+	ProwURL                 string
+	CISearchURL             string
+	BugSource               string
+	JiraURL                 string
+	JiraQuery               string
+	JiraTokenFile           string
+	BugFile                 string
+	BugCacheFile            string
+	BugCacheTTL             time.Duration
+	BugLookupWorkers        int
+	BugLookupRetries        int
+	BugLookupQPS            float64
 	ListenAddr              string
+	RefreshInterval         time.Duration
+	RefreshJitter           time.Duration
+	SnapshotDir             string
+	SnapshotMaxCount        int
+	SnapshotMaxAge          time.Duration
+	AlertConfig             string
 	Server                  bool
 	SkipBugLookup           bool
 }
@@ -48,6 +77,18 @@ func main() {
 		FailureClusterThreshold: 10,
 		StartDay:                0,
 		ListenAddr:              ":8080",
+		RefreshJitter:           5 * time.Minute,
+		SnapshotMaxAge:          30 * 24 * time.Hour,
+		FetchWorkers:            4,
+		FetchRetries:            3,
+		TestGridURL:             testgridhelpers.DefaultTestGridURL,
+		ProwURL:                 testgridconversion.DefaultProwURL,
+		CISearchURL:             buganalysis.DefaultCISearchURL,
+		BugSource:               "ci-search",
+		BugCacheTTL:             buganalysis.DefaultTTL,
+		BugLookupWorkers:        4,
+		BugLookupRetries:        3,
+		BugLookupQPS:            5,
 	}
 
 	klog.InitFlags(nil)
@@ -69,8 +110,12 @@ func main() {
 	flags := cmd.Flags()
 	flags.StringVar(&opt.LocalData, "local-data", opt.LocalData, "Path to testgrid data from local disk")
 	flags.StringArrayVar(&opt.OpenshiftReleases, "release", opt.OpenshiftReleases, "Which releases to analyze (one per arg instance)")
-	flags.StringArrayVar(&opt.Dashboards, "dashboard", opt.Dashboards, "<display-name>=<comma-separated-list-of-dashboards>=<openshift-version>")
-	flags.StringArrayVar(&opt.Variants, "variant", opt.Variants, "{ocp,kube,none}")
+	flags.StringArrayVar(&opt.Dashboards, "dashboard", opt.Dashboards, "<display-name>=<comma-separated-list-of-dashboards>=<openshift-version>[=<comma-separated-list-of-variant-schemes>]")
+	flags.StringArrayVar(&opt.Variants, "variant", opt.Variants, "{ocp,kube,none} (one per arg instance), variants from more than one scheme are prefixed with the scheme name")
+	flags.StringVar(&opt.VariantConfig, "variant-config", opt.VariantConfig, "Path to a JSON file that defines variants, used as the \"config\" variant scheme")
+	flags.StringVar(&opt.IdentificationConfig, "test-identification-config", opt.IdentificationConfig, "Path to a JSON file that holds the setup containers, curated tests, and bugzilla components.  In server mode it is reloaded on every refresh")
+	flags.StringVar(&opt.TestOwnershipConfig, "test-ownership-config", opt.TestOwnershipConfig, "Path to a JSON file that assigns owners to tests whose sig or operator does not identify them, and teams to components")
+	flags.StringVar(&opt.SyntheticTestConfig, "synthetic-test-config", opt.SyntheticTestConfig, "Path to a JSON file that declares the synthetic tests, used instead of the built-in synthetic tests")
 	flags.IntVar(&opt.StartDay, "start-day", opt.StartDay, "Analyze data starting from this day")
 	// TODO convert this to be an offset so that we can go backwards from "data we have"
 	flags.IntVar(&opt.endDay, "end-day", opt.endDay, "Look at job runs going back to this day")
@@ -78,11 +123,33 @@ func main() {
 	flags.Float64Var(&opt.TestSuccessThreshold, "test-success-threshold", opt.TestSuccessThreshold, "Filter results for tests that are more than this percent successful")
 	flags.StringVar(&opt.JobFilter, "job-filter", opt.JobFilter, "Only analyze jobs that match this regex")
 	flags.StringVar(&opt.FetchData, "fetch-data", opt.FetchData, "Download testgrid data to directory specified for future use with --local-data")
+	flags.IntVar(&opt.FetchWorkers, "fetch-workers", opt.FetchWorkers, "Number of concurrent requests to make to testgrid when using --fetch-data")
+	flags.IntVar(&opt.FetchRetries, "fetch-retries", opt.FetchRetries, "Number of times to retry a failed testgrid request when using --fetch-data")
+	flags.BoolVar(&opt.FetchResume, "fetch-resume", opt.FetchResume, "Skip dashboards and jobs already fetched by an interrupted or partly failed --fetch-data run into the same directory")
+	flags.StringVar(&opt.TestGridURL, "testgrid-url", opt.TestGridURL, "Base URL of the testgrid instance to fetch from and link to, for instance a mirror of testgrid.k8s.io")
+	flags.StringVar(&opt.ProwURL, "prow-url", opt.ProwURL, "Base URL of the prow instance used to link to job runs")
+	flags.StringVar(&opt.CISearchURL, "ci-search-url", opt.CISearchURL, "Base URL of the ci-search instance used to find bugs that match test/job failures and to link to the failures of a test")
+	flags.StringVar(&opt.BugSource, "bug-source", opt.BugSource, "Where to find bugs that match test/job failures: ci-search, jira, or file")
+	flags.StringVar(&opt.JiraURL, "jira-url", opt.JiraURL, "Base URL of the jira instance searched for bugs with --bug-source=jira")
+	flags.StringVar(&opt.JiraQuery, "jira-query", opt.JiraQuery, "JQL that restricts the issues searched with --bug-source=jira, for instance \"project = OCPBUGS\"")
+	flags.StringVar(&opt.JiraTokenFile, "jira-token-file", opt.JiraTokenFile, "Path to a file holding the token used to authenticate to jira with --bug-source=jira")
+	flags.StringVar(&opt.BugFile, "bug-file", opt.BugFile, "Path to a JSON file that maps test names and job keys to their bugs, used with --bug-source=file")
+	flags.StringVar(&opt.BugCacheFile, "bug-cache-file", opt.BugCacheFile, "Path to a file that the bugs found for test/job failures are persisted in, so that they survive restarts")
+	flags.DurationVar(&opt.BugCacheTTL, "bug-cache-ttl", opt.BugCacheTTL, "How long the bugs found for a test/job failure are used before they are looked up again")
+	flags.IntVar(&opt.BugLookupWorkers, "bug-lookup-workers", opt.BugLookupWorkers, "Number of concurrent requests to make to the bug source")
+	flags.IntVar(&opt.BugLookupRetries, "bug-lookup-retries", opt.BugLookupRetries, "Number of times to retry a failed request to the bug source")
+	flags.Float64Var(&opt.BugLookupQPS, "bug-lookup-qps", opt.BugLookupQPS, "Maximum number of requests per second to make to the bug source, 0 for no limit")
 	flags.IntVar(&opt.MinTestRuns, "min-test-runs", opt.MinTestRuns, "Ignore tests with less than this number of runs")
 	flags.IntVar(&opt.FailureClusterThreshold, "failure-cluster-threshold", opt.FailureClusterThreshold, "Include separate report on job runs with more than N test failures, -1 to disable")
 	flags.StringVarP(&opt.Output, "output", "o", opt.Output, "Output format for report: json, text")
 	flag.StringVar(&opt.ListenAddr, "listen", opt.ListenAddr, "The address to serve analysis reports on")
 	flags.BoolVar(&opt.Server, "server", opt.Server, "Run in web server mode (serve reports over http)")
+	flags.DurationVar(&opt.RefreshInterval, "refresh-interval", opt.RefreshInterval, "In server mode, fetch testgrid data into --local-data and rebuild the reports this often. 0 disables periodic refresh")
+	flags.DurationVar(&opt.RefreshJitter, "refresh-jitter", opt.RefreshJitter, "Maximum random delay added to every --refresh-interval")
+	flags.StringVar(&opt.SnapshotDir, "snapshot-dir", opt.SnapshotDir, "In server mode, store a snapshot of every computed report in this directory so past reports can be viewed")
+	flags.IntVar(&opt.SnapshotMaxCount, "snapshot-max-count", opt.SnapshotMaxCount, "Number of snapshots of each release to keep in --snapshot-dir. 0 keeps any number")
+	flags.DurationVar(&opt.SnapshotMaxAge, "snapshot-max-age", opt.SnapshotMaxAge, "How long to keep the snapshots in --snapshot-dir, counted from the newest one. 0 keeps them forever")
+	flags.StringVar(&opt.AlertConfig, "alert-config", opt.AlertConfig, "In server mode, path to a JSON file of alerting rules that are evaluated after every refresh, and where to send the alerts")
 	flags.BoolVar(&opt.SkipBugLookup, "skip-bug-lookup", opt.SkipBugLookup, "Do not attempt to find bugs that match test/job failures")
 
 	flags.AddGoFlag(flag.CommandLine.Lookup("v"))
@@ -109,18 +176,25 @@ func (o *Options) ToTestGridDashboardCoordinates() []sippyserver.TestGridDashboa
 	dashboards := []sippyserver.TestGridDashboardCoordinates{}
 	for _, dashboard := range o.Dashboards {
 		tokens := strings.Split(dashboard, "=")
-		if len(tokens) != 3 {
+		if len(tokens) != 3 && len(tokens) != 4 {
 			// launch error
-			panic(fmt.Sprintf("must have three tokens: %q", dashboard))
+			panic(fmt.Sprintf("must have three or four tokens: %q", dashboard))
 		}
 
-		dashboards = append(dashboards,
-			sippyserver.TestGridDashboardCoordinates{
-				ReportName:             tokens[0],
-				TestGridDashboardNames: strings.Split(tokens[1], ","),
-				BugzillaRelease:        tokens[2],
-			},
-		)
+		coordinates := sippyserver.TestGridDashboardCoordinates{
+			ReportName:             tokens[0],
+			TestGridDashboardNames: strings.Split(tokens[1], ","),
+			BugzillaRelease:        tokens[2],
+		}
+		if len(tokens) == 4 {
+			variantManager, err := o.variantManagerForSchemes(strings.Split(tokens[3], ","))
+			if err != nil {
+				// launch error, the schemes are checked in Validate
+				panic(fmt.Sprintf("invalid variant schemes %q: %v", dashboard, err))
+			}
+			coordinates.VariantManager = variantManager
+		}
+		dashboards = append(dashboards, coordinates)
 	}
 
 	return dashboards
@@ -146,30 +220,152 @@ func (o *Options) Validate() error {
 
 	for _, dashboard := range o.Dashboards {
 		tokens := strings.Split(dashboard, "=")
-		if len(tokens) != 3 {
-			return fmt.Errorf("must have three tokens: %q", dashboard)
+		if len(tokens) != 3 && len(tokens) != 4 {
+			return fmt.Errorf("must have three or four tokens: %q", dashboard)
+		}
+		if len(tokens) == 4 {
+			if err := o.validateVariantSchemes(strings.Split(tokens[3], ",")); err != nil {
+				return fmt.Errorf("%q: %v", dashboard, err)
+			}
+		}
+	}
+
+	if o.FetchWorkers < 1 {
+		return fmt.Errorf("--fetch-workers must be at least 1")
+	}
+	if o.FetchRetries < 0 {
+		return fmt.Errorf("--fetch-retries must not be negative")
+	}
+
+	if o.RefreshInterval < 0 {
+		return fmt.Errorf("--refresh-interval must not be negative")
+	}
+	if o.RefreshJitter < 0 {
+		return fmt.Errorf("--refresh-jitter must not be negative")
+	}
+	if o.RefreshInterval > 0 {
+		if !o.Server {
+			return fmt.Errorf("--refresh-interval is only valid with --server")
+		}
+		if len(o.LocalData) == 0 {
+			return fmt.Errorf("--refresh-interval requires --local-data, it is where the fetched data is written")
+		}
+	}
+
+	if len(o.SnapshotDir) > 0 && !o.Server {
+		return fmt.Errorf("--snapshot-dir is only valid with --server")
+	}
+	if o.SnapshotMaxCount < 0 {
+		return fmt.Errorf("--snapshot-max-count must not be negative")
+	}
+	if o.SnapshotMaxAge < 0 {
+		return fmt.Errorf("--snapshot-max-age must not be negative")
+	}
+	if len(o.AlertConfig) > 0 {
+		if !o.Server {
+			return fmt.Errorf("--alert-config is only valid with --server")
+		}
+		if _, err := o.getAlertManager(); err != nil {
+			return fmt.Errorf("--alert-config: %v", err)
 		}
 	}
 
-	if len(o.Variants) > 1 {
-		return fmt.Errorf("only one --variant allowed for now")
-	} else if len(o.Variants) == 1 {
-		if !sets.NewString("ocp", "kube", "none").Has(o.Variants[0]) {
-			return fmt.Errorf("only ocp, kube, or none is allowed")
+	if _, err := testgridhelpers.NewEndpoint(o.TestGridURL); err != nil {
+		return fmt.Errorf("--testgrid-url: %v", err)
+	}
+	if err := validateBaseURL(o.ProwURL); err != nil {
+		return fmt.Errorf("--prow-url: %v", err)
+	}
+	if err := validateBaseURL(o.CISearchURL); err != nil {
+		return fmt.Errorf("--ci-search-url: %v", err)
+	}
+	if o.BugCacheTTL <= 0 {
+		return fmt.Errorf("--bug-cache-ttl must be positive")
+	}
+	if o.BugLookupWorkers < 1 {
+		return fmt.Errorf("--bug-lookup-workers must be at least 1")
+	}
+	if o.BugLookupRetries < 0 {
+		return fmt.Errorf("--bug-lookup-retries must not be negative")
+	}
+	if o.BugLookupQPS < 0 {
+		return fmt.Errorf("--bug-lookup-qps must not be negative")
+	}
+	if !sets.NewString("ci-search", "jira", "file").Has(o.BugSource) {
+		return fmt.Errorf("only ci-search, jira, or file is allowed for --bug-source")
+	}
+	// the bug source reads its file or token, which need not exist when bugs are not looked up
+	if !o.SkipBugLookup {
+		if o.BugSource == "jira" {
+			if err := validateBaseURL(o.JiraURL); err != nil {
+				return fmt.Errorf("--jira-url: %v", err)
+			}
+		}
+		if _, err := o.getBugSource(); err != nil {
+			return fmt.Errorf("--bug-source=%s: %v", o.BugSource, err)
 		}
 	}
 
+	for _, variant := range o.Variants {
+		if !sets.NewString("ocp", "kube", "none").Has(variant) {
+			return fmt.Errorf("only ocp, kube, or none is allowed for --variant")
+		}
+	}
+	if len(o.VariantConfig) > 0 {
+		// load the config now so that a bad config fails immediately instead of after fetching data
+		if _, err := o.variantManagerForSchemes([]string{"config"}); err != nil {
+			return fmt.Errorf("--variant-config: %v", err)
+		}
+	}
+	if len(o.IdentificationConfig) > 0 {
+		if _, err := testidentification.LoadIdentificationConfig(o.IdentificationConfig); err != nil {
+			return fmt.Errorf("--test-identification-config: %v", err)
+		}
+	}
+	if len(o.TestOwnershipConfig) > 0 {
+		// the components of the owners are checked against the identification config once it is in use
+		if _, err := testidentification.LoadOwnershipConfig(o.TestOwnershipConfig); err != nil {
+			return fmt.Errorf("--test-ownership-config: %v", err)
+		}
+	}
+	if len(o.SyntheticTestConfig) > 0 {
+		if _, err := o.getSynthenticTestManager(); err != nil {
+			return fmt.Errorf("--synthetic-test-config: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func validateBaseURL(baseURL string) error {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return err
+	}
+	if len(parsed.Scheme) == 0 || len(parsed.Host) == 0 {
+		return fmt.Errorf("%q must include a scheme and a host", baseURL)
+	}
 	return nil
 }
 
 func (o *Options) Run() error {
+	if len(o.IdentificationConfig) > 0 {
+		config, err := testidentification.LoadIdentificationConfig(o.IdentificationConfig)
+		if err != nil {
+			return err
+		}
+		if err := testidentification.SetIdentificationConfig(config); err != nil {
+			return err
+		}
+	}
+
 	if len(o.FetchData) != 0 {
 		dashboards := []string{}
 		for _, dashboardCoordinate := range o.ToTestGridDashboardCoordinates() {
 			dashboards = append(dashboards, dashboardCoordinate.TestGridDashboardNames...)
 		}
-		testgridhelpers.DownloadData(dashboards, o.JobFilter, o.FetchData)
-		return nil
+		_, err := testgridhelpers.NewFetcher(o.toFetchOptions()).Fetch(dashboards)
+		return err
 	}
 
 	if !o.Server {
@@ -184,15 +380,55 @@ func (o *Options) Run() error {
 }
 
 func (o *Options) runServerMode() error {
+	variantManager, err := o.getVariantManager()
+	if err != nil {
+		return err
+	}
+	syntheticTestManager, err := o.getSynthenticTestManager()
+	if err != nil {
+		return err
+	}
+	ownershipConfig, err := o.getOwnershipConfig()
+	if err != nil {
+		return err
+	}
+	testOwnership, err := getTestOwnership(ownershipConfig)
+	if err != nil {
+		return err
+	}
+
+	var snapshotStore *sippyserver.SnapshotStore
+	if len(o.SnapshotDir) > 0 {
+		snapshotStore, err = sippyserver.NewSnapshotStore(o.SnapshotDir, sippyserver.SnapshotRetention{
+			MaxCount: o.SnapshotMaxCount,
+			MaxAge:   o.SnapshotMaxAge,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	alertManager, err := o.getAlertManager()
+	if err != nil {
+		return err
+	}
+	bugCache, err := o.getBugCache()
+	if err != nil {
+		return err
+	}
+
 	server := sippyserver.NewServer(
 		o.toTestGridLoadingConfig(),
 		o.toRawJobResultsAnalysisConfig(),
-		o.toDisplayDataConfig(),
+		o.toDisplayDataConfig(testOwnership),
 		o.ToTestGridDashboardCoordinates(),
 		o.ListenAddr,
-		o.getSynthenticTestManager(),
-		o.getVariantManager(),
-		o.getBugCache(),
+		syntheticTestManager,
+		variantManager,
+		bugCache,
+		o.toRefreshConfig(ownershipConfig),
+		snapshotStore,
+		alertManager,
 	)
 	server.RefreshData() // force a data refresh once before serving.
 	server.Serve()
@@ -200,13 +436,35 @@ func (o *Options) runServerMode() error {
 }
 
 func (o *Options) runCLIReportMode() error {
+	variantManager, err := o.getVariantManager()
+	if err != nil {
+		return err
+	}
+	syntheticTestManager, err := o.getSynthenticTestManager()
+	if err != nil {
+		return err
+	}
+	ownershipConfig, err := o.getOwnershipConfig()
+	if err != nil {
+		return err
+	}
+	testOwnership, err := getTestOwnership(ownershipConfig)
+	if err != nil {
+		return err
+	}
+
 	analyzer := sippyserver.TestReportGeneratorConfig{
 		TestGridLoadingConfig:       o.toTestGridLoadingConfig(),
 		RawJobResultsAnalysisConfig: o.toRawJobResultsAnalysisConfig(),
-		DisplayDataConfig:           o.toDisplayDataConfig(),
+		DisplayDataConfig:           o.toDisplayDataConfig(testOwnership),
 	}
 
-	testReport := analyzer.PrepareTestReport(o.ToTestGridDashboardCoordinates()[0], o.getSynthenticTestManager(), o.getVariantManager(), o.getBugCache())
+	bugCache, err := o.getBugCache()
+	if err != nil {
+		return err
+	}
+
+	testReport := analyzer.PrepareTestReport(o.ToTestGridDashboardCoordinates()[0], syntheticTestManager, variantManager, bugCache)
 
 	enc := json.NewEncoder(os.Stdout)
 	enc.Encode(testReport.ByTest)
@@ -224,41 +482,167 @@ func (o *Options) hasOCPDashboard() bool {
 	return false
 }
 
-func (o *Options) getBugCache() buganalysis.BugCache {
+func (o *Options) getBugCache() (buganalysis.BugCache, error) {
 	if o.SkipBugLookup || len(o.OpenshiftReleases) == 0 {
-		return buganalysis.NewNoOpBugCache()
-	} else {
-		return buganalysis.NewBugCache()
+		return buganalysis.NewNoOpBugCache(), nil
+	}
+	source, err := o.getBugSource()
+	if err != nil {
+		return nil, err
+	}
+	return buganalysis.NewBugCache(source, o.toBugCacheOptions()), nil
+}
+
+func (o *Options) toBugCacheOptions() buganalysis.CacheOptions {
+	var requestInterval time.Duration
+	if o.BugLookupQPS > 0 {
+		requestInterval = time.Duration(float64(time.Second) / o.BugLookupQPS)
+	}
+	return buganalysis.CacheOptions{
+		Filename:        o.BugCacheFile,
+		TTL:             o.BugCacheTTL,
+		Workers:         o.BugLookupWorkers,
+		RequestInterval: requestInterval,
+		MaxRetries:      o.BugLookupRetries,
+		RetryBackoff:    2 * time.Second,
+	}
+}
+
+func (o *Options) getBugSource() (buganalysis.BugSource, error) {
+	switch o.BugSource {
+	case "jira":
+		return buganalysis.NewJiraSource(o.JiraURL, o.JiraQuery, o.JiraTokenFile)
+	case "file":
+		if len(o.BugFile) == 0 {
+			return nil, fmt.Errorf("--bug-file is required")
+		}
+		return buganalysis.NewFileSource(o.BugFile)
+	default:
+		return buganalysis.NewCISearchSource(o.CISearchURL), nil
 	}
 }
 
-func (o *Options) getVariantManager() testidentification.VariantManager {
-	if len(o.Variants) == 0 {
+// getOwnershipConfig returns the --test-ownership-config, or nil without one.
+func (o *Options) getOwnershipConfig() (*testidentification.OwnershipConfig, error) {
+	if len(o.TestOwnershipConfig) == 0 {
+		return nil, nil
+	}
+	config, err := testidentification.LoadOwnershipConfig(o.TestOwnershipConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// getTestOwnership returns the ownership of the config, or resolves owners from the sig and operator of the tests
+// without one.  It must be called after the identification config is in use, because the owners must be its components.
+func getTestOwnership(config *testidentification.OwnershipConfig) (testidentification.TestOwnership, error) {
+	if config == nil {
+		return testidentification.DefaultTestOwnership(), nil
+	}
+	testOwnership, err := testidentification.NewTestOwnership(*config)
+	if err != nil {
+		return nil, fmt.Errorf("--test-ownership-config: %v", err)
+	}
+	return testOwnership, nil
+}
+
+// getVariantManager returns the variant manager for dashboards that do not choose their own variant schemes.
+func (o *Options) getVariantManager() (testidentification.VariantManager, error) {
+	schemes := append([]string{}, o.Variants...)
+	if len(o.VariantConfig) > 0 {
+		schemes = append(schemes, "config")
+	}
+	if len(schemes) == 0 {
 		if o.hasOCPDashboard() {
-			return testidentification.NewOpenshiftVariantManager()
+			return testidentification.NewOpenshiftVariantManager(), nil
 		}
-		return testidentification.NewEmptyVariantManager()
+		return testidentification.NewEmptyVariantManager(), nil
 	}
+	return o.variantManagerForSchemes(schemes)
+}
 
-	// TODO allow more than one with a union
-	switch o.Variants[0] {
-	case "ocp":
-		return testidentification.NewOpenshiftVariantManager()
-	case "kube":
-		return testidentification.NewKubeVariantManager()
-	case "none":
-		return testidentification.NewEmptyVariantManager()
-	default:
-		panic("only ocp, kube, or none is allowed")
+func (o *Options) validateVariantSchemes(schemes []string) error {
+	for _, scheme := range schemes {
+		switch scheme {
+		case "ocp", "kube", "none":
+		case "config":
+			if len(o.VariantConfig) == 0 {
+				return fmt.Errorf("the config variant scheme requires --variant-config")
+			}
+		default:
+			return fmt.Errorf("only ocp, kube, none, or config variant schemes are allowed, not %q", scheme)
+		}
+	}
+	if len(sets.NewString(schemes...)) != len(schemes) {
+		return fmt.Errorf("variant schemes must not be repeated")
+	}
+	return nil
+}
+
+// variantManagerForSchemes returns the variant manager for a list of variant schemes.  When there is more than one,
+// each variant is prefixed with the name of its scheme so that, for instance, the ocp and kube upgrade variants stay
+// separate.
+func (o *Options) variantManagerForSchemes(schemes []string) (testidentification.VariantManager, error) {
+	if err := o.validateVariantSchemes(schemes); err != nil {
+		return nil, err
+	}
+
+	managers := []testidentification.NamespacedVariantManager{}
+	for _, scheme := range schemes {
+		var variantManager testidentification.VariantManager
+		switch scheme {
+		case "ocp":
+			variantManager = testidentification.NewOpenshiftVariantManager()
+		case "kube":
+			variantManager = testidentification.NewKubeVariantManager()
+		case "none":
+			variantManager = testidentification.NewEmptyVariantManager()
+		case "config":
+			config, err := testidentification.LoadVariantConfig(o.VariantConfig)
+			if err != nil {
+				return nil, err
+			}
+			variantManager, err = testidentification.NewConfigVariantManager(config)
+			if err != nil {
+				return nil, err
+			}
+		}
+		managers = append(managers, testidentification.NamespacedVariantManager{Namespace: scheme, VariantManager: variantManager})
 	}
+
+	if len(managers) == 1 {
+		return managers[0].VariantManager, nil
+	}
+	return testidentification.NewCompositeVariantManager(managers...), nil
+}
+
+// getAlertManager returns nil if alerting is disabled.
+func (o *Options) getAlertManager() (*alerting.Manager, error) {
+	if len(o.AlertConfig) == 0 {
+		return nil, nil
+	}
+	config, err := alerting.LoadConfig(o.AlertConfig)
+	if err != nil {
+		return nil, err
+	}
+	return alerting.NewManager(config)
 }
 
-func (o *Options) getSynthenticTestManager() testgridconversion.SythenticTestManager {
+func (o *Options) getSynthenticTestManager() (testgridconversion.SythenticTestManager, error) {
+	if len(o.SyntheticTestConfig) > 0 {
+		config, err := testgridconversion.LoadSyntheticTestConfig(o.SyntheticTestConfig)
+		if err != nil {
+			return nil, err
+		}
+		return testgridconversion.NewConfigSyntheticTestManager(config)
+	}
+
 	if o.hasOCPDashboard() {
-		return testgridconversion.NewOpenshiftSythenticTestManager()
+		return testgridconversion.NewConfigSyntheticTestManager(testgridconversion.OpenshiftSyntheticTestConfig())
 	}
 
-	return testgridconversion.NewEmptySythenticTestManager()
+	return testgridconversion.NewEmptySythenticTestManager(), nil
 }
 
 func (o *Options) toTestGridLoadingConfig() sippyserver.TestGridLoadingConfig {
@@ -268,8 +652,44 @@ func (o *Options) toTestGridLoadingConfig() sippyserver.TestGridLoadingConfig {
 	}
 
 	return sippyserver.TestGridLoadingConfig{
-		LocalData: o.LocalData,
-		JobFilter: jobFilter,
+		LocalData:        o.LocalData,
+		JobFilter:        jobFilter,
+		TestGridEndpoint: o.getTestGridEndpoint(),
+		ProwURL:          o.ProwURL,
+	}
+}
+
+func (o *Options) getTestGridEndpoint() testgridhelpers.Endpoint {
+	// the url is checked in Validate
+	endpoint, _ := testgridhelpers.NewEndpoint(o.TestGridURL)
+	return endpoint
+}
+
+func (o *Options) toFetchOptions() testgridhelpers.FetchOptions {
+	var jobFilter *regexp.Regexp
+	if len(o.JobFilter) > 0 {
+		jobFilter = regexp.MustCompile(o.JobFilter)
+	}
+
+	return testgridhelpers.FetchOptions{
+		StoragePath:  o.FetchData,
+		JobFilter:    jobFilter,
+		Workers:      o.FetchWorkers,
+		MaxRetries:   o.FetchRetries,
+		RetryBackoff: 2 * time.Second,
+		Resume:       o.FetchResume,
+
+		TestGridEndpoint: o.getTestGridEndpoint(),
+	}
+}
+
+func (o *Options) toRefreshConfig(ownershipConfig *testidentification.OwnershipConfig) sippyserver.RefreshConfig {
+	return sippyserver.RefreshConfig{
+		Interval:                 o.RefreshInterval,
+		Jitter:                   o.RefreshJitter,
+		FetchOptions:             o.toFetchOptions(),
+		IdentificationConfigFile: o.IdentificationConfig,
+		OwnershipConfig:          ownershipConfig,
 	}
 }
 
@@ -279,10 +699,12 @@ func (o *Options) toRawJobResultsAnalysisConfig() sippyserver.RawJobResultsAnaly
 		NumDays:  o.NumDays,
 	}
 }
-func (o *Options) toDisplayDataConfig() sippyserver.DisplayDataConfig {
+func (o *Options) toDisplayDataConfig(testOwnership testidentification.TestOwnership) sippyserver.DisplayDataConfig {
 	return sippyserver.DisplayDataConfig{
 		MinTestRuns:             o.MinTestRuns,
 		TestSuccessThreshold:    o.TestSuccessThreshold,
 		FailureClusterThreshold: o.FailureClusterThreshold,
+		TestOwnership:           testOwnership,
+		CISearchURL:             o.CISearchURL,
 	}
 }
@@ -0,0 +1,222 @@
+package alerting
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/util/sets"
+	"k8s.io/klog"
+)
+
+// Reports are the reports of a release that the rules are evaluated against.  PreviousWeek tells which tests and jobs
+// are new.
+type Reports struct {
+	Current      sippyprocessingv1.TestReport
+	PreviousWeek sippyprocessingv1.TestReport
+}
+
+// Manager evaluates the rules against the reports after every refresh and remembers which alerts are firing, so that
+// every notifier is sent an alert once when it starts firing and once when it is resolved, not on every refresh.
+type Manager struct {
+	rules          []compiledRule
+	notifiers      []Notifier
+	repeatInterval time.Duration
+
+	// evaluateLock serializes Evaluate, which does not hold lock while it notifies
+	evaluateLock sync.Mutex
+	// lock guards firing and resolved.  Only Evaluate changes them.
+	lock sync.Mutex
+	// firing are the alerts that matched the last evaluation, by key
+	firing map[string]*trackedAlert
+	// resolved are the alerts that were resolved but have not been sent to every notifier yet, by key
+	resolved map[string]*trackedAlert
+}
+
+type trackedAlert struct {
+	alert sippyv1.Alert
+	// sent is when each notifier, by index, was last sent the alert in its current state.  Notifiers that failed are
+	// missing, so that they are sent the alert again on the next evaluation.
+	sent map[int]time.Time
+}
+
+// NewManager returns a Manager for the config, or an error if the config is invalid.
+func NewManager(config Config) (*Manager, error) {
+	repeatInterval, err := config.repeatInterval()
+	if err != nil {
+		return nil, err
+	}
+	m := &Manager{
+		repeatInterval: repeatInterval,
+		firing:         map[string]*trackedAlert{},
+		resolved:       map[string]*trackedAlert{},
+	}
+
+	names := sets.NewString()
+	for _, rule := range config.Rules {
+		compiled, err := compileRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		if names.Has(rule.Name) {
+			return nil, fmt.Errorf("rule %q is defined more than once", rule.Name)
+		}
+		names.Insert(rule.Name)
+		m.rules = append(m.rules, compiled)
+	}
+	for i, notifierConfig := range config.Notifiers {
+		notifier, err := NewNotifier(notifierConfig)
+		if err != nil {
+			return nil, fmt.Errorf("notifier %d: %v", i, err)
+		}
+		m.notifiers = append(m.notifiers, notifier)
+	}
+	return m, nil
+}
+
+// Evaluate evaluates every rule against the reports of every release, by release name, and notifies the notifiers of
+// the alerts that started firing or were resolved.  Alerts of releases that are missing from reports are resolved.  It
+// returns an error if any notifier failed; the alerts it was not sent are sent again on the next evaluation.  The
+// notifiers are sent the alerts without holding the lock, so that Alerts does not wait for a slow notifier.
+func (m *Manager) Evaluate(reports map[string]Reports, now time.Time) error {
+	m.evaluateLock.Lock()
+	defer m.evaluateLock.Unlock()
+
+	notifications := m.update(reports, now)
+
+	errs := []string{}
+	sent := []notification{}
+	for _, n := range notifications {
+		notifier := m.notifiers[n.notifier]
+		if err := notifier.Notify(n.alerts); err != nil {
+			klog.Errorf("Error sending %d alerts to %s: %v", len(n.alerts), notifier.Name(), err)
+			errs = append(errs, fmt.Sprintf("%s: %v", notifier.Name(), err))
+			continue
+		}
+		sent = append(sent, n)
+	}
+
+	m.recordSent(sent, now)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("could not send alerts: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// notification is the alerts that a notifier, by index, is due to be sent.
+type notification struct {
+	notifier int
+	toSend   []*trackedAlert
+	// alerts are copies of the alerts of toSend, so that they can be sent without the lock
+	alerts []sippyv1.Alert
+}
+
+// update evaluates the rules and tracks the alerts that started firing or were resolved.  It returns the notifications
+// that are due.
+func (m *Manager) update(reports map[string]Reports, now time.Time) []notification {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	matched := map[string]sippyv1.Alert{}
+	for release, releaseReports := range reports {
+		for _, rule := range m.rules {
+			for _, alert := range rule.evaluate(release, releaseReports.Current, releaseReports.PreviousWeek) {
+				matched[alert.Key] = alert
+			}
+		}
+	}
+
+	for key, alert := range matched {
+		tracked, ok := m.firing[key]
+		if !ok {
+			klog.Infof("Alert %s is firing: %s", key, alert.Summary)
+			alert.StartsAt = now
+			tracked = &trackedAlert{sent: map[int]time.Time{}}
+			m.firing[key] = tracked
+			// an alert that fires again before its resolution was sent just keeps firing
+			delete(m.resolved, key)
+		} else {
+			alert.StartsAt = tracked.alert.StartsAt
+		}
+		tracked.alert = alert
+	}
+	for key, tracked := range m.firing {
+		if _, ok := matched[key]; ok {
+			continue
+		}
+		klog.Infof("Alert %s is resolved", key)
+		delete(m.firing, key)
+		endsAt := now
+		tracked.alert.State = StateResolved
+		tracked.alert.EndsAt = &endsAt
+		tracked.sent = map[int]time.Time{}
+		m.resolved[key] = tracked
+	}
+
+	notifications := []notification{}
+	for i := range m.notifiers {
+		toSend := []*trackedAlert{}
+		for _, tracked := range m.firing {
+			lastSent, ok := tracked.sent[i]
+			if !ok || (m.repeatInterval > 0 && now.Sub(lastSent) >= m.repeatInterval) {
+				toSend = append(toSend, tracked)
+			}
+		}
+		for _, tracked := range m.resolved {
+			if _, ok := tracked.sent[i]; !ok {
+				toSend = append(toSend, tracked)
+			}
+		}
+		if len(toSend) == 0 {
+			continue
+		}
+
+		sort.Slice(toSend, func(a, b int) bool {
+			return toSend[a].alert.Key < toSend[b].alert.Key
+		})
+		alerts := []sippyv1.Alert{}
+		for _, tracked := range toSend {
+			alerts = append(alerts, tracked.alert)
+		}
+		notifications = append(notifications, notification{notifier: i, toSend: toSend, alerts: alerts})
+	}
+	return notifications
+}
+
+// recordSent records that the notifications were sent at now, and forgets the resolved alerts that every notifier was
+// sent.  The tracked alerts are still the ones update returned, because only Evaluate changes them.
+func (m *Manager) recordSent(sent []notification, now time.Time) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, n := range sent {
+		for _, tracked := range n.toSend {
+			tracked.sent[n.notifier] = now
+		}
+	}
+	for key, tracked := range m.resolved {
+		if len(tracked.sent) == len(m.notifiers) {
+			delete(m.resolved, key)
+		}
+	}
+}
+
+// Alerts returns the firing alerts, sorted by key.
+func (m *Manager) Alerts() []sippyv1.Alert {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	ret := []sippyv1.Alert{}
+	for _, tracked := range m.firing {
+		ret = append(ret, tracked.alert)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Key < ret[j].Key
+	})
+	return ret
+}
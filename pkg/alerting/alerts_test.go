@@ -0,0 +1,315 @@
+package alerting
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	bugsv1 "github.com/openshift/sippy/pkg/apis/bugs/v1"
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+)
+
+type recordingNotifier struct {
+	sent [][]sippyv1.Alert
+	err  error
+}
+
+func (n *recordingNotifier) Name() string {
+	return "recording"
+}
+
+func (n *recordingNotifier) Notify(alerts []sippyv1.Alert) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.sent = append(n.sent, alerts)
+	return nil
+}
+
+func float(f float64) *float64 {
+	return &f
+}
+
+func testResult(name string, successes, failures int, bugs ...bugsv1.Bug) sippyprocessingv1.FailingTestResult {
+	return sippyprocessingv1.FailingTestResult{
+		TestName: name,
+		TestResultAcrossAllJobs: sippyprocessingv1.TestResult{
+			Name:           name,
+			Successes:      successes,
+			Failures:       failures,
+			PassPercentage: float64(successes) * 100 / float64(successes+failures),
+			BugList:        bugs,
+		},
+	}
+}
+
+func installReport(successes, failures int) sippyprocessingv1.TestReport {
+	return sippyprocessingv1.TestReport{
+		TopLevelIndicators: sippyprocessingv1.TopLevelIndicators{Install: testResult("install should work", successes, failures)},
+	}
+}
+
+func TestRules(t *testing.T) {
+	report := sippyprocessingv1.TestReport{
+		TopLevelIndicators: sippyprocessingv1.TopLevelIndicators{Install: testResult("install should work", 85, 15)},
+		ByTest: []sippyprocessingv1.FailingTestResult{
+			testResult("new test", 7, 3),
+			testResult("new test with bug", 7, 3, bugsv1.Bug{ID: 1}),
+			testResult("old test", 7, 3),
+			testResult("rare test", 1, 1),
+		},
+		ByJob: []sippyprocessingv1.JobResult{
+			{Name: "job-aws", Successes: 2, Failures: 8, PassPercentage: 20, InfrastructureFailures: 6},
+		},
+		ByVariant: []sippyprocessingv1.VariantResults{
+			{VariantName: "aws", JobRunSuccesses: 2, JobRunFailures: 8, JobRunPassPercentage: 20},
+		},
+	}
+	prevReport := sippyprocessingv1.TestReport{
+		ByTest: []sippyprocessingv1.FailingTestResult{testResult("old test", 10, 0)},
+	}
+
+	tests := []struct {
+		name     string
+		rule     Rule
+		release  string
+		expected []string
+	}{
+		{
+			name:     "install pass rate",
+			rule:     Rule{Name: "install", Releases: []string{"4.8"}, Indicator: "install", Below: float(90), MinRuns: 50},
+			release:  "4.8",
+			expected: []string{"install/4.8/indicator/install"},
+		},
+		{
+			name:    "other release",
+			rule:    Rule{Name: "install", Releases: []string{"4.8"}, Indicator: "install", Below: float(90)},
+			release: "4.7",
+		},
+		{
+			name:    "too few runs",
+			rule:    Rule{Name: "install", Indicator: "install", Below: float(90), MinRuns: 101},
+			release: "4.8",
+		},
+		{
+			name:     "new tests without bugs",
+			rule:     Rule{Name: "new-tests", Tests: ".", Below: float(80), MinRuns: 5, New: true, WithoutBug: true},
+			release:  "4.8",
+			expected: []string{"new-tests/4.8/test/new test"},
+		},
+		{
+			name:     "infrastructure failures of jobs",
+			rule:     Rule{Name: "infra", Jobs: "aws", Field: "infrastructureFailures", Above: float(5)},
+			release:  "4.8",
+			expected: []string{"infra/4.8/job/job-aws"},
+		},
+		{
+			name:     "variants default to the job run pass rate",
+			rule:     Rule{Name: "variants", Variants: ".", Below: float(50)},
+			release:  "4.8",
+			expected: []string{"variants/4.8/variant/aws"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, err := compileRule(tc.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := []string{}
+			for _, alert := range rule.evaluate(tc.release, report, prevReport) {
+				actual = append(actual, alert.Key)
+			}
+			if len(actual) != len(tc.expected) || (len(actual) > 0 && !reflect.DeepEqual(actual, tc.expected)) {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNewManagerValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "no name", config: Config{Rules: []Rule{{Indicator: "install", Below: float(90)}}}},
+		{name: "no subject", config: Config{Rules: []Rule{{Name: "a", Below: float(90)}}}},
+		{name: "two subjects", config: Config{Rules: []Rule{{Name: "a", Tests: ".", Jobs: ".", Below: float(90)}}}},
+		{name: "unknown indicator", config: Config{Rules: []Rule{{Name: "a", Indicator: "bootstrap", Below: float(90)}}}},
+		{name: "no threshold", config: Config{Rules: []Rule{{Name: "a", Indicator: "install"}}}},
+		{name: "unknown field", config: Config{Rules: []Rule{{Name: "a", Jobs: ".", Field: "flakes", Below: float(90)}}}},
+		{name: "new variants", config: Config{Rules: []Rule{{Name: "a", Variants: ".", New: true, Below: float(90)}}}},
+		{name: "duplicate names", config: Config{Rules: []Rule{{Name: "a", Indicator: "install", Below: float(90)}, {Name: "a", Indicator: "upgrade", Below: float(90)}}}},
+		{name: "bad repeat interval", config: Config{RepeatInterval: "daily"}},
+		{name: "unknown notifier", config: Config{Notifiers: []NotifierConfig{{Type: "pager"}}}},
+		{name: "webhook without url", config: Config{Notifiers: []NotifierConfig{{Type: "webhook"}}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewManager(tc.config); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestManagerEvaluate(t *testing.T) {
+	manager, err := NewManager(Config{
+		Rules:          []Rule{{Name: "install", Indicator: "install", Below: float(90), Severity: "critical"}},
+		RepeatInterval: "24h",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifier := &recordingNotifier{}
+	manager.notifiers = []Notifier{notifier}
+
+	start := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	evaluate := func(hours int, successes, failures int) {
+		t.Helper()
+		reports := map[string]Reports{"4.8": {Current: installReport(successes, failures)}}
+		if err := manager.Evaluate(reports, start.Add(time.Duration(hours)*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lastSent := func() string {
+		t.Helper()
+		if len(notifier.sent) == 0 {
+			return ""
+		}
+		alerts := notifier.sent[len(notifier.sent)-1]
+		ret := ""
+		for _, alert := range alerts {
+			ret += fmt.Sprintf("%s %s %s;", alert.Key, alert.State, alert.StartsAt.Format(time.RFC3339))
+		}
+		return ret
+	}
+
+	evaluate(0, 95, 5)
+	if len(notifier.sent) != 0 || len(manager.Alerts()) != 0 {
+		t.Fatalf("expected nothing to fire, got %v", notifier.sent)
+	}
+
+	evaluate(1, 80, 20)
+	if actual := lastSent(); actual != "install/4.8/indicator/install firing 2021-03-01T01:00:00Z;" {
+		t.Errorf("expected the alert to fire, got %s", actual)
+	}
+	if alerts := manager.Alerts(); len(alerts) != 1 || alerts[0].Value != 80 || alerts[0].Severity != "critical" {
+		t.Errorf("expected the firing alert, got %v", alerts)
+	}
+
+	// still firing, so it is not sent again until the repeat interval has passed
+	evaluate(2, 70, 30)
+	if len(notifier.sent) != 1 {
+		t.Errorf("expected the alert to be deduplicated, got %v", notifier.sent)
+	}
+	if alerts := manager.Alerts(); len(alerts) != 1 || alerts[0].Value != 70 || !alerts[0].StartsAt.Equal(start.Add(time.Hour)) {
+		t.Errorf("expected the alert to be updated and keep its start, got %v", alerts)
+	}
+	evaluate(25, 70, 30)
+	if len(notifier.sent) != 2 {
+		t.Errorf("expected the alert to be repeated, got %v", notifier.sent)
+	}
+
+	evaluate(26, 95, 5)
+	if actual := lastSent(); actual != "install/4.8/indicator/install resolved 2021-03-01T01:00:00Z;" {
+		t.Errorf("expected the alert to be resolved, got %s", actual)
+	}
+	if len(manager.Alerts()) != 0 {
+		t.Errorf("expected no firing alerts, got %v", manager.Alerts())
+	}
+	evaluate(27, 95, 5)
+	if len(notifier.sent) != 3 {
+		t.Errorf("expected the resolution to be sent once, got %v", notifier.sent)
+	}
+}
+
+func TestManagerRetriesFailedNotifiers(t *testing.T) {
+	manager, err := NewManager(Config{Rules: []Rule{{Name: "install", Indicator: "install", Below: float(90)}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	working, broken := &recordingNotifier{}, &recordingNotifier{err: fmt.Errorf("down")}
+	manager.notifiers = []Notifier{working, broken}
+
+	now := time.Now()
+	firing := map[string]Reports{"4.8": {Current: installReport(80, 20)}}
+	if err := manager.Evaluate(firing, now); err == nil {
+		t.Errorf("expected an error from the broken notifier")
+	}
+
+	broken.err = nil
+	if err := manager.Evaluate(firing, now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if len(working.sent) != 1 || len(broken.sent) != 1 {
+		t.Errorf("expected the alert to be sent once to each notifier, got %v and %v", working.sent, broken.sent)
+	}
+
+	// the resolution is kept until every notifier has it
+	broken.err = fmt.Errorf("down")
+	if err := manager.Evaluate(map[string]Reports{}, now.Add(2*time.Hour)); err == nil {
+		t.Errorf("expected an error from the broken notifier")
+	}
+	broken.err = nil
+	if err := manager.Evaluate(map[string]Reports{}, now.Add(3*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if len(working.sent) != 2 || len(broken.sent) != 2 || broken.sent[1][0].State != StateResolved {
+		t.Errorf("expected the resolution to be sent once to each notifier, got %v and %v", working.sent, broken.sent)
+	}
+	if len(manager.resolved) != 0 {
+		t.Errorf("expected the resolved alert to be forgotten, got %v", manager.resolved)
+	}
+}
+
+// blockingNotifier blocks in Notify until release is closed.
+type blockingNotifier struct {
+	notifying chan struct{}
+	release   chan struct{}
+}
+
+func (n *blockingNotifier) Name() string {
+	return "blocking"
+}
+
+func (n *blockingNotifier) Notify(alerts []sippyv1.Alert) error {
+	close(n.notifying)
+	<-n.release
+	return nil
+}
+
+func TestManagerAlertsDuringNotify(t *testing.T) {
+	manager, err := NewManager(Config{Rules: []Rule{{Name: "install", Indicator: "install", Below: float(90)}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifier := &blockingNotifier{notifying: make(chan struct{}), release: make(chan struct{})}
+	manager.notifiers = []Notifier{notifier}
+
+	evaluated := make(chan error)
+	go func() {
+		evaluated <- manager.Evaluate(map[string]Reports{"4.8": {Current: installReport(80, 20)}}, time.Now())
+	}()
+	<-notifier.notifying
+
+	alerts := make(chan []sippyv1.Alert)
+	go func() {
+		alerts <- manager.Alerts()
+	}()
+	select {
+	case firing := <-alerts:
+		if len(firing) != 1 {
+			t.Errorf("expected the alert to be firing while it is sent, got %v", firing)
+		}
+	case <-time.After(10 * time.Second):
+		t.Errorf("expected the alerts to be listed while a notifier is slow")
+	}
+
+	close(notifier.release)
+	if err := <-evaluated; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,89 @@
+package alerting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/openshift/sippy/pkg/util"
+)
+
+// Config declares the alerting rules and where alerts are sent.
+type Config struct {
+	Rules     []Rule           `json:"rules"`
+	Notifiers []NotifierConfig `json:"notifiers,omitempty"`
+	// RepeatInterval, like "24h", sends firing alerts again once they have been firing this long since they were last
+	// sent.  If it is empty, an alert is only sent when it starts firing and when it is resolved.
+	RepeatInterval string `json:"repeatInterval,omitempty"`
+}
+
+// Rule fires an alert for every top level indicator, test, job, or variant of a release whose field is below or above
+// a threshold.  Exactly one of Indicator, Tests, Jobs, and Variants chooses what the rule is evaluated against.
+type Rule struct {
+	Name     string `json:"name"`
+	Severity string `json:"severity,omitempty"`
+	// Releases are the release reports the rule applies to.  If empty, it applies to all of them.
+	Releases []string `json:"releases,omitempty"`
+
+	// Indicator is infrastructure, install, upgrade, or finalOperatorHealth.
+	Indicator string `json:"indicator,omitempty"`
+	// Tests, Jobs, and Variants are regexes of the names of the tests, jobs, or variants the rule applies to.
+	Tests    string `json:"tests,omitempty"`
+	Jobs     string `json:"jobs,omitempty"`
+	Variants string `json:"variants,omitempty"`
+
+	// Field is the JSON name of the numeric field to compare, as in the /json report, or runs.  It defaults to
+	// passPercentage, or jobRunPassPercentage for variants.
+	Field string `json:"field,omitempty"`
+	// Below and Above are the thresholds.  The rule fires if the field is below Below or above Above.
+	Below *float64 `json:"below,omitempty"`
+	Above *float64 `json:"above,omitempty"`
+	// MinRuns keeps the rule from firing on results with fewer runs, which are too noisy to alert on.
+	MinRuns int `json:"minRuns,omitempty"`
+	// WithoutBug only fires for tests and jobs that have no bug in the release.
+	WithoutBug bool `json:"withoutBug,omitempty"`
+	// New only fires for tests and jobs that did not run in the week before the report.
+	New bool `json:"new,omitempty"`
+}
+
+// NotifierConfig is where alerts are sent.  Type is webhook, smtp, or file.
+type NotifierConfig struct {
+	Type string `json:"type"`
+	// URL is where a webhook notifier posts the alerts as JSON.
+	URL string `json:"url,omitempty"`
+	// SMTP is the mail server of an smtp notifier.
+	SMTP *SMTPConfig `json:"smtp,omitempty"`
+	// Path is the file a file notifier appends the alerts to, one JSON object per line.
+	Path string `json:"path,omitempty"`
+}
+
+type SMTPConfig struct {
+	// Address is the host:port of the mail server.
+	Address string   `json:"address"`
+	From    string   `json:"from"`
+	To      []string `json:"to"`
+	// Username and PasswordFile, if set, authenticate with PLAIN auth.  The password is read from the file so that it
+	// is not part of the config.
+	Username     string `json:"username,omitempty"`
+	PasswordFile string `json:"passwordFile,omitempty"`
+}
+
+// LoadConfig reads a Config from a JSON file.
+func LoadConfig(filename string) (Config, error) {
+	config := Config{}
+	err := util.LoadStrictJSON(filename, &config)
+	return config, err
+}
+
+func (c Config) repeatInterval() (time.Duration, error) {
+	if len(c.RepeatInterval) == 0 {
+		return 0, nil
+	}
+	interval, err := time.ParseDuration(c.RepeatInterval)
+	if err != nil {
+		return 0, fmt.Errorf("repeatInterval: %v", err)
+	}
+	if interval <= 0 {
+		return 0, fmt.Errorf("repeatInterval must be positive")
+	}
+	return interval, nil
+}
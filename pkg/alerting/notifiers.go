@@ -0,0 +1,228 @@
+package alerting
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+)
+
+// Notifier sends alerts somewhere people will see them.
+type Notifier interface {
+	// Name describes the notifier in logs.
+	Name() string
+	// Notify sends alerts that started firing, were resolved, or are repeated.
+	Notify(alerts []sippyv1.Alert) error
+}
+
+// notifyTimeout bounds how long a notifier may take to send alerts, so that an unreachable destination cannot hold up
+// the evaluations after it.
+const notifyTimeout = 30 * time.Second
+
+// NewNotifier returns the notifier the config describes, or an error if the config is invalid.
+func NewNotifier(config NotifierConfig) (Notifier, error) {
+	switch config.Type {
+	case "webhook":
+		parsed, err := url.Parse(config.URL)
+		if err != nil {
+			return nil, fmt.Errorf("webhook url: %v", err)
+		}
+		if len(parsed.Scheme) == 0 || len(parsed.Host) == 0 {
+			return nil, fmt.Errorf("webhook url %q must include a scheme and a host", config.URL)
+		}
+		return &webhookNotifier{url: config.URL, client: &http.Client{Timeout: notifyTimeout}}, nil
+	case "smtp":
+		return newSMTPNotifier(config.SMTP)
+	case "file":
+		if len(config.Path) == 0 {
+			return nil, fmt.Errorf("file notifier must have a path")
+		}
+		return &fileNotifier{path: config.Path}, nil
+	default:
+		return nil, fmt.Errorf("notifier type must be webhook, smtp, or file, not %q", config.Type)
+	}
+}
+
+// webhookNotifier posts an AlertList as JSON to a URL.
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func (n *webhookNotifier) Name() string {
+	return "webhook " + n.url
+}
+
+func (n *webhookNotifier) Notify(alerts []sippyv1.Alert) error {
+	body, err := json.Marshal(sippyv1.AlertList{Alerts: alerts})
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// drain the body so the connection can be reused
+	ioutil.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s returned %s", n.url, resp.Status)
+	}
+	return nil
+}
+
+// smtpNotifier mails a summary of the alerts.
+type smtpNotifier struct {
+	config SMTPConfig
+	host   string
+	auth   smtp.Auth
+	// timeout bounds the whole conversation with the server
+	timeout time.Duration
+}
+
+func newSMTPNotifier(config *SMTPConfig) (Notifier, error) {
+	if config == nil {
+		return nil, fmt.Errorf("smtp notifier must have an smtp config")
+	}
+	host, _, err := net.SplitHostPort(config.Address)
+	if err != nil {
+		return nil, fmt.Errorf("smtp address: %v", err)
+	}
+	if len(config.From) == 0 || len(config.To) == 0 {
+		return nil, fmt.Errorf("smtp notifier must have a from address and at least one to address")
+	}
+
+	ret := &smtpNotifier{config: *config, host: host, timeout: notifyTimeout}
+	if len(config.Username) > 0 {
+		password, err := ioutil.ReadFile(config.PasswordFile)
+		if err != nil {
+			return nil, fmt.Errorf("smtp password: %v", err)
+		}
+		ret.auth = smtp.PlainAuth("", config.Username, strings.TrimSpace(string(password)), host)
+	}
+	return ret, nil
+}
+
+func (n *smtpNotifier) Name() string {
+	return "smtp " + n.config.Address
+}
+
+func (n *smtpNotifier) Notify(alerts []sippyv1.Alert) error {
+	firing, resolved := 0, 0
+	for _, alert := range alerts {
+		if alert.State == StateResolved {
+			resolved++
+		} else {
+			firing++
+		}
+	}
+
+	message := &bytes.Buffer{}
+	fmt.Fprintf(message, "From: %s\r\n", n.config.From)
+	fmt.Fprintf(message, "To: %s\r\n", strings.Join(n.config.To, ", "))
+	fmt.Fprintf(message, "Subject: [sippy] %d alerts firing, %d resolved\r\n", firing, resolved)
+	fmt.Fprintf(message, "Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	for _, alert := range alerts {
+		severity := ""
+		if len(alert.Severity) > 0 {
+			severity = " " + alert.Severity
+		}
+		fmt.Fprintf(message, "[%s%s] %s: %s\r\n", strings.ToUpper(alert.State), severity, alert.Rule, alert.Summary)
+	}
+	return n.send(message.Bytes())
+}
+
+// send mails the message like smtp.SendMail, but gives up once the timeout has passed.
+func (n *smtpNotifier) send(message []byte) error {
+	conn, err := net.DialTimeout("tcp", n.config.Address, n.timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(n.timeout)); err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.host}); err != nil {
+			return err
+		}
+	}
+	if n.auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return fmt.Errorf("smtp server %s does not support authentication", n.config.Address)
+		}
+		if err := client.Auth(n.auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(n.config.From); err != nil {
+		return err
+	}
+	for _, to := range n.config.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	data, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := data.Write(message); err != nil {
+		return err
+	}
+	if err := data.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// fileNotifier appends the alerts to a file, one JSON object per line.
+type fileNotifier struct {
+	path string
+	lock sync.Mutex
+}
+
+func (n *fileNotifier) Name() string {
+	return "file " + n.path
+}
+
+func (n *fileNotifier) Notify(alerts []sippyv1.Alert) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	for _, alert := range alerts {
+		if err := encoder.Encode(alert); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,205 @@
+package alerting
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+)
+
+var testAlerts = []sippyv1.Alert{
+	{Key: "install/4.8/indicator/install", Rule: "install", Severity: "critical", State: StateFiring, Summary: "install is failing"},
+	{Key: "upgrade/4.8/indicator/upgrade", Rule: "upgrade", State: StateResolved, Summary: "upgrade is fixed"},
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var received sippyv1.AlertList
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost || req.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request %s %s", req.Method, req.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(req.Body).Decode(&received); err != nil {
+			t.Errorf("unable to decode alerts: %v", err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	notifier, err := NewNotifier(NotifierConfig{Type: "webhook", URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := notifier.Notify(testAlerts); err != nil {
+		t.Fatal(err)
+	}
+	if len(received.Alerts) != 2 || received.Alerts[0].Key != testAlerts[0].Key {
+		t.Errorf("expected the alerts to be posted, got %v", received)
+	}
+
+	status = http.StatusInternalServerError
+	if err := notifier.Notify(testAlerts); err == nil {
+		t.Errorf("expected an error when the webhook fails")
+	}
+}
+
+func TestFileNotifier(t *testing.T) {
+	dir, err := ioutil.TempDir("", "alerting")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "alerts.jsonl")
+	notifier, err := NewNotifier(NotifierConfig{Type: "file", Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := notifier.Notify(testAlerts); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected every alert to be appended, got %s", content)
+	}
+	alert := sippyv1.Alert{}
+	if err := json.Unmarshal([]byte(lines[3]), &alert); err != nil || alert.Key != testAlerts[1].Key {
+		t.Errorf("expected one alert per line, got %s: %v", lines[3], err)
+	}
+}
+
+// fakeSMTPServer accepts one mail and sends what it received on the channel.
+func fakeSMTPServer(t *testing.T) (string, chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := make(chan string, 1)
+	go func() {
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) {
+			conn.Write([]byte(line + "\r\n"))
+		}
+		reply("220 localhost ESMTP")
+		transcript := &strings.Builder{}
+		inData := false
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				received <- transcript.String()
+				return
+			}
+			transcript.WriteString(line)
+			switch {
+			case inData && line == ".\r\n":
+				inData = false
+				reply("250 OK")
+			case inData:
+			case strings.HasPrefix(line, "EHLO"):
+				reply("250 localhost")
+			case strings.HasPrefix(line, "DATA"):
+				inData = true
+				reply("354 go ahead")
+			case strings.HasPrefix(line, "QUIT"):
+				reply("221 bye")
+				received <- transcript.String()
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+	return listener.Addr().String(), received
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	address, received := fakeSMTPServer(t)
+	notifier, err := NewNotifier(NotifierConfig{Type: "smtp", SMTP: &SMTPConfig{
+		Address: address,
+		From:    "sippy@example.com",
+		To:      []string{"release@example.com", "ci@example.com"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := notifier.Notify(testAlerts); err != nil {
+		t.Fatal(err)
+	}
+
+	transcript := <-received
+	for _, expected := range []string{
+		"MAIL FROM:<sippy@example.com>",
+		"RCPT TO:<release@example.com>",
+		"RCPT TO:<ci@example.com>",
+		"Subject: [sippy] 1 alerts firing, 1 resolved",
+		"[FIRING critical] install: install is failing",
+		"[RESOLVED] upgrade: upgrade is fixed",
+	} {
+		if !strings.Contains(transcript, expected) {
+			t.Errorf("expected the mail to contain %q, got %s", expected, transcript)
+		}
+	}
+}
+
+func TestSMTPNotifierTimeout(t *testing.T) {
+	// the server accepts the connection but never greets the client
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		ioutil.ReadAll(conn)
+	}()
+
+	notifier, err := NewNotifier(NotifierConfig{Type: "smtp", SMTP: &SMTPConfig{
+		Address: listener.Addr().String(),
+		From:    "sippy@example.com",
+		To:      []string{"release@example.com"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifier.(*smtpNotifier).timeout = 100 * time.Millisecond
+
+	done := make(chan error, 1)
+	go func() {
+		done <- notifier.Notify(testAlerts)
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Errorf("expected a server that never answers to fail the notification")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("expected the notification to give up after the timeout")
+	}
+}
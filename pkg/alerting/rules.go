@@ -0,0 +1,222 @@
+package alerting
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/openshift/sippy/pkg/api"
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+// the kinds of subjects a rule is evaluated against
+const (
+	KindIndicator = "indicator"
+	KindTest      = "test"
+	KindJob       = "job"
+	KindVariant   = "variant"
+)
+
+// the states of an alert
+const (
+	StateFiring   = "firing"
+	StateResolved = "resolved"
+)
+
+// indicators are the top level indicators a rule can name
+var indicators = map[string]func(sippyprocessingv1.TopLevelIndicators) sippyprocessingv1.FailingTestResult{
+	"infrastructure": func(in sippyprocessingv1.TopLevelIndicators) sippyprocessingv1.FailingTestResult {
+		return in.Infrastructure
+	},
+	"install": func(in sippyprocessingv1.TopLevelIndicators) sippyprocessingv1.FailingTestResult {
+		return in.Install
+	},
+	"upgrade": func(in sippyprocessingv1.TopLevelIndicators) sippyprocessingv1.FailingTestResult {
+		return in.Upgrade
+	},
+	"finalOperatorHealth": func(in sippyprocessingv1.TopLevelIndicators) sippyprocessingv1.FailingTestResult {
+		return in.FinalOperatorHealth
+	},
+}
+
+// subjectTypes are the report types each kind of rule reads its field from
+var subjectTypes = map[string]reflect.Type{
+	KindIndicator: reflect.TypeOf(sippyprocessingv1.FailingTestResult{}),
+	KindTest:      reflect.TypeOf(sippyprocessingv1.FailingTestResult{}),
+	KindJob:       reflect.TypeOf(sippyprocessingv1.JobResult{}),
+	KindVariant:   reflect.TypeOf(sippyprocessingv1.VariantResults{}),
+}
+
+type compiledRule struct {
+	Rule
+	kind     string
+	releases sets.String
+	names    *regexp.Regexp
+	field    func(result reflect.Value) float64
+	runs     func(result reflect.Value) float64
+}
+
+// compileRule checks the rule and prepares it for evaluation.
+func compileRule(rule Rule) (compiledRule, error) {
+	ret := compiledRule{Rule: rule, releases: sets.NewString(rule.Releases...)}
+	if len(rule.Name) == 0 {
+		return ret, fmt.Errorf("every rule must have a name")
+	}
+
+	subjects := 0
+	namesRegex := ""
+	if len(rule.Indicator) > 0 {
+		subjects++
+		ret.kind = KindIndicator
+		if _, ok := indicators[rule.Indicator]; !ok {
+			return ret, fmt.Errorf("rule %q: indicator must be infrastructure, install, upgrade, or finalOperatorHealth, not %q", rule.Name, rule.Indicator)
+		}
+	}
+	for kind, regex := range map[string]string{KindTest: rule.Tests, KindJob: rule.Jobs, KindVariant: rule.Variants} {
+		if len(regex) > 0 {
+			subjects++
+			ret.kind = kind
+			namesRegex = regex
+		}
+	}
+	if subjects != 1 {
+		return ret, fmt.Errorf("rule %q must set exactly one of indicator, tests, jobs, and variants", rule.Name)
+	}
+	if len(namesRegex) > 0 {
+		names, err := regexp.Compile(namesRegex)
+		if err != nil {
+			return ret, fmt.Errorf("rule %q has an invalid regex: %v", rule.Name, err)
+		}
+		ret.names = names
+	}
+	if (rule.WithoutBug || rule.New) && ret.kind != KindTest && ret.kind != KindJob {
+		return ret, fmt.Errorf("rule %q: withoutBug and new only apply to tests and jobs", rule.Name)
+	}
+
+	if rule.Below == nil && rule.Above == nil {
+		return ret, fmt.Errorf("rule %q must set below or above", rule.Name)
+	}
+	if len(ret.Field) == 0 {
+		ret.Field = "passPercentage"
+		if ret.kind == KindVariant {
+			ret.Field = "jobRunPassPercentage"
+		}
+	}
+	subjectType := subjectTypes[ret.kind]
+	field, ok := api.NumericProperty(subjectType, ret.Field)
+	if !ok {
+		return ret, fmt.Errorf("rule %q: %q is not a numeric field of %s", rule.Name, ret.Field, subjectType.Name())
+	}
+	ret.field = field
+	ret.runs, _ = api.NumericProperty(subjectType, "runs")
+	return ret, nil
+}
+
+// subject is one top level indicator, test, job, or variant of a report.
+type subject struct {
+	name   string
+	result reflect.Value
+	hasBug bool
+	// new is true if the subject did not run in the week before the report
+	new bool
+}
+
+func (r compiledRule) subjects(report, prevReport sippyprocessingv1.TestReport) []subject {
+	ret := []subject{}
+	switch r.kind {
+	case KindIndicator:
+		result := indicators[r.Indicator](report.TopLevelIndicators)
+		ret = append(ret, subject{name: r.Indicator, result: reflect.ValueOf(result)})
+	case KindTest:
+		previous := sets.NewString()
+		for _, test := range prevReport.ByTest {
+			if test.TestResultAcrossAllJobs.Successes+test.TestResultAcrossAllJobs.Failures > 0 {
+				previous.Insert(test.TestName)
+			}
+		}
+		for _, test := range report.ByTest {
+			if !r.names.MatchString(test.TestName) {
+				continue
+			}
+			ret = append(ret, subject{
+				name:   test.TestName,
+				result: reflect.ValueOf(test),
+				hasBug: len(test.TestResultAcrossAllJobs.BugList) > 0,
+				new:    !previous.Has(test.TestName),
+			})
+		}
+	case KindJob:
+		previous := sets.NewString()
+		for _, job := range prevReport.ByJob {
+			if job.Successes+job.Failures > 0 {
+				previous.Insert(job.Name)
+			}
+		}
+		for _, job := range report.ByJob {
+			if !r.names.MatchString(job.Name) {
+				continue
+			}
+			ret = append(ret, subject{
+				name:   job.Name,
+				result: reflect.ValueOf(job),
+				hasBug: len(job.BugList) > 0,
+				new:    !previous.Has(job.Name),
+			})
+		}
+	case KindVariant:
+		for _, variant := range report.ByVariant {
+			if r.names.MatchString(variant.VariantName) {
+				ret = append(ret, subject{name: variant.VariantName, result: reflect.ValueOf(variant)})
+			}
+		}
+	}
+	return ret
+}
+
+// evaluate returns the firing alerts of the rule for the report of the release.
+func (r compiledRule) evaluate(release string, report, prevReport sippyprocessingv1.TestReport) []sippyv1.Alert {
+	if r.releases.Len() > 0 && !r.releases.Has(release) {
+		return nil
+	}
+
+	ret := []sippyv1.Alert{}
+	for _, subject := range r.subjects(report, prevReport) {
+		if (r.WithoutBug && subject.hasBug) || (r.New && !subject.new) {
+			continue
+		}
+		runs := 0
+		if r.runs != nil {
+			runs = int(r.runs(subject.result))
+		}
+		if runs < r.MinRuns {
+			continue
+		}
+
+		value := r.field(subject.result)
+		var comparison string
+		switch {
+		case r.Below != nil && value < *r.Below:
+			comparison = fmt.Sprintf("below %g", *r.Below)
+		case r.Above != nil && value > *r.Above:
+			comparison = fmt.Sprintf("above %g", *r.Above)
+		default:
+			continue
+		}
+
+		ret = append(ret, sippyv1.Alert{
+			Key:      fmt.Sprintf("%s/%s/%s/%s", r.Name, release, r.kind, subject.name),
+			Rule:     r.Name,
+			Severity: r.Severity,
+			Release:  release,
+			Kind:     r.kind,
+			Subject:  subject.name,
+			State:    StateFiring,
+			Summary:  fmt.Sprintf("%s of %s %q in %s is %.2f, %s, over %d runs", r.Field, r.kind, subject.name, release, value, comparison, runs),
+			Value:    value,
+			Runs:     runs,
+		})
+	}
+	return ret
+}
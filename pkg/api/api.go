@@ -2,12 +2,10 @@ package api
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
-	"net/url"
-	"regexp"
 
-	"github.com/openshift/sippy/pkg/html/releasehtml"
+	"github.com/openshift/sippy/pkg/buganalysis"
+	"github.com/openshift/sippy/pkg/regressionanalysis"
 
 	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
 	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
@@ -80,22 +78,23 @@ func summaryJobsByVariant(report, reportPrev sippyprocessingv1.TestReport) []sip
 }
 
 // top failing tests with a bug
-func summaryTopFailingTestsWithBug(topFailingTestsWithBug, prevTestResults []sippyprocessingv1.FailingTestResult) []sippyv1.FailingTestBug {
+func summaryTopFailingTestsWithBug(topFailingTestsWithBug, prevTestResults []sippyprocessingv1.FailingTestResult, ciSearchURL string) []sippyv1.FailingTestBug {
 
 	var topFailingTests []sippyv1.FailingTestBug
 
 	for _, test := range topFailingTestsWithBug {
-		encodedTestName := url.QueryEscape(regexp.QuoteMeta(test.TestName))
-
-		testLink := fmt.Sprintf("%s%s", releasehtml.BugSearchUrl, encodedTestName)
+		testLink := buganalysis.TestSearchURL(ciSearchURL, "", test.TestName)
 		testPrev := util.FindFailedTestResult(test.TestName, prevTestResults)
 
 		var failedTestWithBug sippyv1.FailingTestBug
 
 		if testPrev != nil {
 			failedTestWithBug = sippyv1.FailingTestBug{
-				Name: test.TestName,
-				Url:  testLink,
+				Name:           test.TestName,
+				Url:            testLink,
+				Classification: string(test.Classification),
+				Component:      test.TestResultAcrossAllJobs.Owner.Component,
+				Team:           test.TestResultAcrossAllJobs.Owner.Team,
 				PassRates: map[string]sippyv1.PassRate{
 					"latest": sippyv1.PassRate{
 						Percentage: test.TestResultAcrossAllJobs.PassPercentage,
@@ -106,21 +105,26 @@ func summaryTopFailingTestsWithBug(topFailingTestsWithBug, prevTestResults []sip
 						Runs:       testPrev.TestResultAcrossAllJobs.Successes + testPrev.TestResultAcrossAllJobs.Failures,
 					},
 				},
-				Bugs:           test.TestResultAcrossAllJobs.BugList,
-				AssociatedBugs: test.TestResultAcrossAllJobs.AssociatedBugList,
+				Bugs:            test.TestResultAcrossAllJobs.BugList,
+				AssociatedBugs:  test.TestResultAcrossAllJobs.AssociatedBugList,
+				BugLookupFailed: test.TestResultAcrossAllJobs.BugLookupFailed,
 			}
 		} else {
 			failedTestWithBug = sippyv1.FailingTestBug{
-				Name: test.TestResultAcrossAllJobs.Name,
-				Url:  testLink,
+				Name:           test.TestResultAcrossAllJobs.Name,
+				Url:            testLink,
+				Classification: string(test.Classification),
+				Component:      test.TestResultAcrossAllJobs.Owner.Component,
+				Team:           test.TestResultAcrossAllJobs.Owner.Team,
 				PassRates: map[string]sippyv1.PassRate{
 					"latest": sippyv1.PassRate{
 						Percentage: test.TestResultAcrossAllJobs.PassPercentage,
 						Runs:       test.TestResultAcrossAllJobs.Successes + test.TestResultAcrossAllJobs.Failures,
 					},
 				},
-				Bugs:           test.TestResultAcrossAllJobs.BugList,
-				AssociatedBugs: test.TestResultAcrossAllJobs.AssociatedBugList,
+				Bugs:            test.TestResultAcrossAllJobs.BugList,
+				AssociatedBugs:  test.TestResultAcrossAllJobs.AssociatedBugList,
+				BugLookupFailed: test.TestResultAcrossAllJobs.BugLookupFailed,
 			}
 		}
 
@@ -132,22 +136,24 @@ func summaryTopFailingTestsWithBug(topFailingTestsWithBug, prevTestResults []sip
 }
 
 // top failing tests without a bug
-func summaryTopFailingTestsWithoutBug(topFailingTestsWithoutBug, prevTopFailingTestsWithoutBug []sippyprocessingv1.FailingTestResult) []sippyv1.FailingTestBug {
+func summaryTopFailingTestsWithoutBug(topFailingTestsWithoutBug, prevTopFailingTestsWithoutBug []sippyprocessingv1.FailingTestResult, ciSearchURL string) []sippyv1.FailingTestBug {
 	var topFailingTests []sippyv1.FailingTestBug
 
 	for _, test := range topFailingTestsWithoutBug {
-		encodedTestName := url.QueryEscape(regexp.QuoteMeta(test.TestName))
-
-		testLink := fmt.Sprintf("%s%s", releasehtml.BugSearchUrl, encodedTestName)
+		testLink := buganalysis.TestSearchURL(ciSearchURL, "", test.TestName)
 		testPrev := util.FindFailedTestResult(test.TestName, prevTopFailingTestsWithoutBug)
 
 		var failedTestWithoutBug sippyv1.FailingTestBug
 
 		if testPrev != nil {
 			failedTestWithoutBug = sippyv1.FailingTestBug{
-				Name:           test.TestName,
-				Url:            testLink,
-				AssociatedBugs: test.TestResultAcrossAllJobs.AssociatedBugList,
+				Name:            test.TestName,
+				Url:             testLink,
+				Classification:  string(test.Classification),
+				Component:       test.TestResultAcrossAllJobs.Owner.Component,
+				Team:            test.TestResultAcrossAllJobs.Owner.Team,
+				AssociatedBugs:  test.TestResultAcrossAllJobs.AssociatedBugList,
+				BugLookupFailed: test.TestResultAcrossAllJobs.BugLookupFailed,
 				PassRates: map[string]sippyv1.PassRate{
 					"latest": sippyv1.PassRate{
 						Percentage: test.TestResultAcrossAllJobs.PassPercentage,
@@ -162,9 +168,13 @@ func summaryTopFailingTestsWithoutBug(topFailingTestsWithoutBug, prevTopFailingT
 
 		} else {
 			failedTestWithoutBug = sippyv1.FailingTestBug{
-				Name:           test.TestName,
-				Url:            testLink,
-				AssociatedBugs: test.TestResultAcrossAllJobs.AssociatedBugList,
+				Name:            test.TestName,
+				Url:             testLink,
+				Classification:  string(test.Classification),
+				Component:       test.TestResultAcrossAllJobs.Owner.Component,
+				Team:            test.TestResultAcrossAllJobs.Owner.Team,
+				AssociatedBugs:  test.TestResultAcrossAllJobs.AssociatedBugList,
+				BugLookupFailed: test.TestResultAcrossAllJobs.BugLookupFailed,
 				PassRates: map[string]sippyv1.PassRate{
 					"latest": sippyv1.PassRate{
 						Percentage: test.TestResultAcrossAllJobs.PassPercentage,
@@ -227,7 +237,7 @@ func summaryJobPassRatesByJobName(report, reportPrev sippyprocessingv1.TestRepor
 }
 
 // canaryTestFailures section
-func canaryTestFailures(all []sippyprocessingv1.FailingTestResult) []sippyv1.CanaryTestFailInstance {
+func canaryTestFailures(all []sippyprocessingv1.FailingTestResult, ciSearchURL string) []sippyv1.CanaryTestFailInstance {
 	var canaryFailures []sippyv1.CanaryTestFailInstance
 
 	if len(all) <= 0 {
@@ -244,11 +254,10 @@ func canaryTestFailures(all []sippyprocessingv1.FailingTestResult) []sippyv1.Can
 		if foundCount > 10 {
 			break
 		}
-		encodedTestName := url.QueryEscape(regexp.QuoteMeta(test.TestName))
 		canaryFailures = append(canaryFailures,
 			sippyv1.CanaryTestFailInstance{
 				Name: test.TestName,
-				Url:  fmt.Sprintf("%s%s", releasehtml.BugSearchUrl, encodedTestName),
+				Url:  buganalysis.TestSearchURL(ciSearchURL, "", test.TestName),
 				PassRate: sippyv1.PassRate{
 					Percentage: test.TestResultAcrossAllJobs.PassPercentage,
 					Runs:       test.TestResultAcrossAllJobs.Successes + test.TestResultAcrossAllJobs.Failures,
@@ -291,40 +300,54 @@ func failureGroupList(report sippyprocessingv1.TestReport) []sippyv1.FailureGrou
 			Job:          fg.Job,
 			Url:          fg.Url,
 			TestFailures: fg.TestFailures,
+			Outcome:      string(fg.Outcome),
 		})
 	}
 	return failureGroups
 }
 
-func formatJSONReport(report, prevReport sippyprocessingv1.TestReport, numDays, jobTestCount int) map[string]interface{} {
-	data := releasehtml.TestReports{
-		Current:      report,
-		Prev:         prevReport,
-		NumDays:      numDays,
-		JobTestCount: jobTestCount,
-		Release:      report.Release}
-
-	jsonObject := map[string]interface{}{
-		"failureGroupings":               failureGroups(data.Current.FailureGroups, data.Prev.FailureGroups),
-		"jobPassRateByVariant":           summaryJobsByVariant(data.Current, data.Prev),
-		"topFailingTestsWithoutBug":      summaryTopFailingTestsWithoutBug(data.Current.TopFailingTestsWithoutBug, data.Prev.TopFailingTestsWithoutBug),
-		"topFailingTestsWithBug":         summaryTopFailingTestsWithBug(data.Current.TopFailingTestsWithBug, data.Prev.ByTest),
-		"jobPassRatesByName":             summaryJobPassRatesByJobName(data.Current, data.Prev),
-		"minimumJobPassRatesByComponent": minimumJobPassRateByBugzillaComponent(data.Current, data.Prev),
-		"canaryTestFailures":             canaryTestFailures(data.Current.ByTest),
-		"jobRunsWithFailureGroups":       failureGroupList(data.Current),
-		"testImpactingBugs":              data.Current.BugsByFailureCount,
+func formatJSONReport(report, prevReport sippyprocessingv1.TestReport, ciSearchURL string) sippyv1.Report {
+	return sippyv1.Report{
+		FailureGroupings:               failureGroups(report.FailureGroups, prevReport.FailureGroups),
+		JobPassRateByVariant:           summaryJobsByVariant(report, prevReport),
+		TopFailingTestsWithoutBug:      summaryTopFailingTestsWithoutBug(report.TopFailingTestsWithoutBug, prevReport.TopFailingTestsWithoutBug, ciSearchURL),
+		TopFailingTestsWithBug:         summaryTopFailingTestsWithBug(report.TopFailingTestsWithBug, prevReport.ByTest, ciSearchURL),
+		JobPassRatesByName:             summaryJobPassRatesByJobName(report, prevReport),
+		MinimumJobPassRatesByComponent: minimumJobPassRateByBugzillaComponent(report, prevReport),
+		CanaryTestFailures:             canaryTestFailures(report.ByTest, ciSearchURL),
+		JobRunsWithFailureGroups:       failureGroupList(report),
+		FailureClusters:                failureClusters(report.FailureClusters),
+		TestImpactingBugs:              report.BugsByFailureCount,
+		PassRateChanges:                regressionanalysis.DefaultOptions().Analyze(report, prevReport),
+	}
+}
+
+func failureClusters(in []sippyprocessingv1.FailureCluster) []sippyv1.FailureCluster {
+	if in == nil {
+		return nil
+	}
+	ret := []sippyv1.FailureCluster{}
+	for _, cluster := range in {
+		ret = append(ret, sippyv1.FailureCluster{
+			TestNames:         cluster.TestNames,
+			Support:           cluster.Support,
+			Confidence:        cluster.Confidence,
+			Jobs:              cluster.Jobs,
+			Variants:          cluster.Variants,
+			ExampleJobRunURLs: cluster.ExampleJobRunURLs,
+		})
 	}
-	return jsonObject
+	return ret
 }
 
-// PrintJSONReport prints json format of the reports
-func PrintJSONReport(w http.ResponseWriter, req *http.Request, releaseReports map[string][]sippyprocessingv1.TestReport, numDays, jobTestCount int) {
-	reportObjects := make(map[string]interface{})
+// PrintJSONReport prints json format of the reports.  Failing tests link to their failures in the ci-search instance at
+// ciSearchURL.
+func PrintJSONReport(w http.ResponseWriter, req *http.Request, releaseReports map[string][]sippyprocessingv1.TestReport, ciSearchURL string) {
+	reportObjects := make(map[string]sippyv1.Report)
 	for _, reports := range releaseReports {
 		report := reports[0]
 		prevReport := reports[1]
-		reportObjects[report.Release] = formatJSONReport(report, prevReport, numDays, jobTestCount)
+		reportObjects[report.Release] = formatJSONReport(report, prevReport, ciSearchURL)
 	}
 	enc := json.NewEncoder(w)
 	enc.SetEscapeHTML(false)
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"sort"
+
+	bugsv1 "github.com/openshift/sippy/pkg/apis/bugs/v1"
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/buganalysis"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testgridanalysisapi"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testidentification"
+	"github.com/openshift/sippy/pkg/util"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+// ComponentReport summarizes the health of the bugzilla component in the report.  The previous report provides the
+// prev pass rates.  Failing tests link to their failures in the ci-search instance at ciSearchURL.
+func ComponentReport(report, prevReport sippyprocessingv1.TestReport, component, ciSearchURL string) sippyv1.ComponentReport {
+	ret := sippyv1.ComponentReport{
+		Release:      report.Release,
+		Component:    component,
+		FailingTests: []sippyv1.FailingTestBug{},
+		BrokenJobs:   componentJobs(report, prevReport, component),
+		Bugs:         []bugsv1.Bug{},
+		Operators:    componentOperators(report, prevReport, component),
+	}
+
+	teams := sets.NewString()
+	bugs := map[string]bugsv1.Bug{}
+	// ByTest is ordered by pass rate, so the failing tests are too
+	for _, test := range report.ByTest {
+		owned := test.TestResultAcrossAllJobs.Owner.Component == component
+		if owned && len(test.TestResultAcrossAllJobs.Owner.Team) > 0 {
+			teams.Insert(test.TestResultAcrossAllJobs.Owner.Team)
+		}
+		for _, bug := range test.TestResultAcrossAllJobs.BugList {
+			if !util.IsActiveBug(bug) {
+				continue
+			}
+			if owned || (len(bug.Component) > 0 && bug.Component[0] == component) {
+				bugs[bug.Tracker+"/"+bug.Name()] = bug
+			}
+		}
+
+		if !owned || test.TestResultAcrossAllJobs.Failures == 0 {
+			continue
+		}
+		ret.FailingTests = append(ret.FailingTests, failingTestBug(test, util.FindFailedTestResult(test.TestName, prevReport.ByTest), ciSearchURL))
+	}
+
+	ret.Teams = teams.List()
+	for _, bug := range bugs {
+		ret.Bugs = append(ret.Bugs, bug)
+	}
+	// jira bugs have no numeric ID, so they are ordered by tracker and name
+	sort.Slice(ret.Bugs, func(i, j int) bool {
+		if ret.Bugs[i].ID != ret.Bugs[j].ID {
+			return ret.Bugs[i].ID < ret.Bugs[j].ID
+		}
+		if ret.Bugs[i].Tracker != ret.Bugs[j].Tracker {
+			return ret.Bugs[i].Tracker < ret.Bugs[j].Tracker
+		}
+		return ret.Bugs[i].Name() < ret.Bugs[j].Name()
+	})
+
+	return ret
+}
+
+func failingTestBug(test sippyprocessingv1.FailingTestResult, testPrev *sippyprocessingv1.FailingTestResult, ciSearchURL string) sippyv1.FailingTestBug {
+	ret := sippyv1.FailingTestBug{
+		Name:           test.TestName,
+		Url:            buganalysis.TestSearchURL(ciSearchURL, "", test.TestName),
+		Classification: string(test.Classification),
+		Component:      test.TestResultAcrossAllJobs.Owner.Component,
+		Team:           test.TestResultAcrossAllJobs.Owner.Team,
+		PassRates: map[string]sippyv1.PassRate{
+			"latest": testResultPassRate(test.TestResultAcrossAllJobs),
+		},
+		Bugs:            test.TestResultAcrossAllJobs.BugList,
+		AssociatedBugs:  test.TestResultAcrossAllJobs.AssociatedBugList,
+		BugLookupFailed: test.TestResultAcrossAllJobs.BugLookupFailed,
+	}
+	if testPrev != nil {
+		ret.PassRates["prev"] = testResultPassRate(testPrev.TestResultAcrossAllJobs)
+	}
+	return ret
+}
+
+func testResultPassRate(testResult sippyprocessingv1.TestResult) sippyv1.PassRate {
+	return sippyv1.PassRate{
+		Percentage: testResult.PassPercentage,
+		Runs:       testResult.Successes + testResult.Failures,
+	}
+}
+
+func componentJobs(report, prevReport sippyprocessingv1.TestReport, component string) []sippyv1.ComponentJob {
+	prevFailPercentages := map[string]float64{}
+	for _, job := range prevReport.JobFailuresByBugzillaComponent[component].JobsFailed {
+		prevFailPercentages[job.JobName] = job.FailPercentage
+	}
+
+	ret := []sippyv1.ComponentJob{}
+	for _, job := range report.JobFailuresByBugzillaComponent[component].JobsFailed {
+		componentJob := sippyv1.ComponentJob{
+			Name:            job.JobName,
+			FailPercentages: map[string]float64{"latest": job.FailPercentage},
+			Runs:            job.TotalRuns,
+			RunsFailed:      job.NumberOfJobRunsFailed,
+			FailingTests:    []string{},
+		}
+		if prev, ok := prevFailPercentages[job.JobName]; ok {
+			componentJob.FailPercentages["prev"] = prev
+		}
+		for _, failure := range job.Failures {
+			componentJob.FailingTests = append(componentJob.FailingTests, failure.Name)
+		}
+		ret = append(ret, componentJob)
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].FailPercentages["latest"] > ret[j].FailPercentages["latest"]
+	})
+	return ret
+}
+
+// componentOperators returns the operator tests of the operators that the identification config maps to the component.
+func componentOperators(report, prevReport sippyprocessingv1.TestReport, component string) []sippyv1.ComponentOperator {
+	operators := sets.NewString()
+	for operator, operatorComponent := range testidentification.CurrentIdentificationConfig().OperatorComponents {
+		if operatorComponent == component {
+			operators.Insert(operator)
+		}
+	}
+
+	passRates := func(testName string) map[string]sippyv1.PassRate {
+		test := util.FindFailedTestResult(testName, report.ByTest)
+		if test == nil {
+			return nil
+		}
+		ret := map[string]sippyv1.PassRate{"latest": testResultPassRate(test.TestResultAcrossAllJobs)}
+		if prev := util.FindFailedTestResult(testName, prevReport.ByTest); prev != nil {
+			ret["prev"] = testResultPassRate(prev.TestResultAcrossAllJobs)
+		}
+		return ret
+	}
+
+	ret := []sippyv1.ComponentOperator{}
+	for _, operator := range operators.List() {
+		ret = append(ret, sippyv1.ComponentOperator{
+			Name:    operator,
+			Install: passRates(testgridanalysisapi.OperatorInstallPrefix + operator),
+			Upgrade: passRates(testgridanalysisapi.OperatorUpgradePrefix + operator),
+			Health:  passRates(testgridanalysisapi.OperatorFinalHealthPrefix + operator),
+		})
+	}
+	return ret
+}
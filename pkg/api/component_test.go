@@ -0,0 +1,103 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	bugsv1 "github.com/openshift/sippy/pkg/apis/bugs/v1"
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+)
+
+func TestComponentReport(t *testing.T) {
+	etcd := sippyprocessingv1.TestOwner{Component: "Etcd", Team: "etcd-team"}
+	openBug := bugsv1.Bug{ID: 2, Status: "NEW", Component: []string{"Etcd"}}
+	closedBug := bugsv1.Bug{ID: 3, Status: "CLOSED", Component: []string{"Etcd"}}
+	otherBug := bugsv1.Bug{ID: 4, Status: "NEW", Component: []string{"Networking"}}
+	jiraBugA := bugsv1.Bug{Key: "OCPBUGS-1", Tracker: bugsv1.TrackerJira, Status: "NEW", Component: []string{"Etcd"}}
+	jiraBugB := bugsv1.Bug{Key: "OCPBUGS-2", Tracker: bugsv1.TrackerJira, Status: "NEW", Component: []string{"Etcd"}}
+	failingTest := func(name string, successes, failures int, owner sippyprocessingv1.TestOwner, bugs ...bugsv1.Bug) sippyprocessingv1.FailingTestResult {
+		return sippyprocessingv1.FailingTestResult{
+			TestName: name,
+			TestResultAcrossAllJobs: sippyprocessingv1.TestResult{
+				Name:           name,
+				Successes:      successes,
+				Failures:       failures,
+				PassPercentage: float64(successes) * 100 / float64(successes+failures),
+				Owner:          owner,
+				BugList:        bugs,
+			},
+		}
+	}
+
+	report := sippyprocessingv1.TestReport{
+		Release: "4.7",
+		ByTest: []sippyprocessingv1.FailingTestResult{
+			failingTest("operator install etcd", 5, 5, etcd, jiraBugB, jiraBugA),
+			failingTest("[sig-etcd] leader changes", 8, 2, etcd, openBug, closedBug),
+			failingTest("[sig-network] dns", 8, 2, sippyprocessingv1.TestOwner{Component: "Networking"}, otherBug, openBug),
+			failingTest("[sig-etcd] passes", 10, 0, etcd),
+		},
+		JobFailuresByBugzillaComponent: map[string]sippyprocessingv1.SortedBugzillaComponentResult{
+			"Etcd": {Name: "Etcd", JobsFailed: []sippyprocessingv1.BugzillaJobResult{
+				{JobName: "job-a", FailPercentage: 10, TotalRuns: 10, NumberOfJobRunsFailed: 1, Failures: []sippyprocessingv1.TestResult{{Name: "[sig-etcd] leader changes"}}},
+				{JobName: "job-b", FailPercentage: 50, TotalRuns: 10, NumberOfJobRunsFailed: 5, Failures: []sippyprocessingv1.TestResult{{Name: "operator install etcd"}}},
+			}},
+		},
+	}
+	prevReport := sippyprocessingv1.TestReport{
+		ByTest: []sippyprocessingv1.FailingTestResult{
+			failingTest("operator install etcd", 10, 0, etcd),
+		},
+		JobFailuresByBugzillaComponent: map[string]sippyprocessingv1.SortedBugzillaComponentResult{
+			"Etcd": {Name: "Etcd", JobsFailed: []sippyprocessingv1.BugzillaJobResult{{JobName: "job-b", FailPercentage: 20}}},
+		},
+	}
+
+	actual := ComponentReport(report, prevReport, "Etcd", "")
+
+	if !reflect.DeepEqual(actual.Teams, []string{"etcd-team"}) {
+		t.Errorf("expected the team of the owned tests, got %v", actual.Teams)
+	}
+	failingTestNames := []string{}
+	for _, test := range actual.FailingTests {
+		failingTestNames = append(failingTestNames, test.Name)
+	}
+	if !reflect.DeepEqual(failingTestNames, []string{"operator install etcd", "[sig-etcd] leader changes"}) {
+		t.Errorf("expected the failing owned tests in pass rate order, got %v", failingTestNames)
+	}
+	if prev, ok := actual.FailingTests[0].PassRates["prev"]; !ok || prev.Percentage != 100 {
+		t.Errorf("expected the prev pass rate of the install test, got %v", actual.FailingTests[0].PassRates)
+	}
+
+	if len(actual.BrokenJobs) != 2 || actual.BrokenJobs[0].Name != "job-b" {
+		t.Fatalf("expected the broken jobs with the highest fail rate first, got %v", actual.BrokenJobs)
+	}
+	if !reflect.DeepEqual(actual.BrokenJobs[0].FailPercentages, map[string]float64{"latest": 50, "prev": 20}) {
+		t.Errorf("expected the latest and prev fail rates, got %v", actual.BrokenJobs[0].FailPercentages)
+	}
+
+	bugNames := []string{}
+	for _, bug := range actual.Bugs {
+		bugNames = append(bugNames, bug.Name())
+	}
+	if !reflect.DeepEqual(bugNames, []string{"OCPBUGS-1", "OCPBUGS-2", "2"}) {
+		t.Errorf("expected only the open bugs of the component in a stable order, got %v", bugNames)
+	}
+
+	var etcdOperator *sippyv1.ComponentOperator
+	for i := range actual.Operators {
+		if actual.Operators[i].Name == "etcd" {
+			etcdOperator = &actual.Operators[i]
+		}
+	}
+	if etcdOperator == nil {
+		t.Fatalf("expected the etcd operator, got %v", actual.Operators)
+	}
+	if etcdOperator.Install["latest"].Percentage != 50 || etcdOperator.Install["prev"].Percentage != 100 {
+		t.Errorf("expected the install pass rates of etcd, got %v", etcdOperator.Install)
+	}
+	if etcdOperator.Upgrade != nil {
+		t.Errorf("expected no upgrade pass rates because the test did not run, got %v", etcdOperator.Upgrade)
+	}
+}
@@ -0,0 +1,19 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	"k8s.io/klog"
+)
+
+// PrintError writes a sippyv1.Error with the status code, which is how every JSON endpoint reports errors.
+func PrintError(w http.ResponseWriter, status int, detail string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(sippyv1.Error{Code: strconv.Itoa(status), Detail: detail}); err != nil {
+		klog.Errorf("unable to render json %v", err)
+	}
+}
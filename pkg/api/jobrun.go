@@ -0,0 +1,83 @@
+package api
+
+import (
+	bugsv1 "github.com/openshift/sippy/pkg/apis/bugs/v1"
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/buganalysis"
+	"github.com/openshift/sippy/pkg/util"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+// JobRunReport describes the run with the URL, from the results of the runs of its job.  The failed tests and bugs are
+// looked up in the report and prevReport of the release, and the failed tests link to their failures in the ci-search
+// instance at ciSearchURL.  It returns false if no job has a run with the URL.
+func JobRunReport(jobRunResults JobRunResults, ciSearchURL, runURL string, report, prevReport sippyprocessingv1.TestReport) (sippyv1.JobRunReport, bool) {
+	details, ok := jobRunResults.runs[runURL]
+	if !ok {
+		return sippyv1.JobRunReport{}, false
+	}
+	result := details.result
+
+	ret := sippyv1.JobRunReport{
+		Release:                            report.Release,
+		Job:                                details.run.Job,
+		URL:                                runURL,
+		TestGridURL:                        details.testGridURL,
+		BuildID:                            details.buildID,
+		Timestamp:                          details.run.Timestamp,
+		Classification:                     details.run.Classification,
+		ClassificationReason:               details.run.ClassificationReason,
+		Succeeded:                          result.Succeeded,
+		Failed:                             result.Failed,
+		SetupStatus:                        result.SetupStatus,
+		OpenShiftTestsStatus:               result.OpenShiftTestsStatus,
+		UpgradeStarted:                     result.UpgradeStarted,
+		UpgradeForOperatorsStatus:          result.UpgradeForOperatorsStatus,
+		UpgradeForMachineConfigPoolsStatus: result.UpgradeForMachineConfigPoolsStatus,
+		Operators:                          []sippyv1.JobRunOperator{},
+		FailedTests:                        []sippyv1.FailingTestBug{},
+		Bugs:                               []bugsv1.Bug{},
+	}
+	for _, operator := range result.FinalOperatorStates {
+		ret.Operators = append(ret.Operators, sippyv1.JobRunOperator{Name: operator.Name, State: operator.State})
+	}
+
+	// bugs of different trackers may share IDs, so they are told apart by tracker and name
+	bugKeys := sets.NewString()
+	addBugs := func(bugs []bugsv1.Bug) {
+		for _, bug := range bugs {
+			key := bug.Tracker + "/" + bug.Name()
+			if !bugKeys.Has(key) {
+				bugKeys.Insert(key)
+				ret.Bugs = append(ret.Bugs, bug)
+			}
+		}
+	}
+	if jobResult := util.FindJobResultForJobName(details.run.Job, report.ByJob); jobResult != nil {
+		addBugs(jobResult.BugList)
+	}
+
+	seen := sets.NewString()
+	for _, testName := range result.FailedTestNames {
+		if seen.Has(testName) {
+			continue
+		}
+		seen.Insert(testName)
+
+		test := util.FindFailedTestResult(testName, report.ByTest)
+		if test == nil {
+			// the test is not in the report, for instance because the run is older than the report
+			ret.FailedTests = append(ret.FailedTests, sippyv1.FailingTestBug{
+				Name:      testName,
+				Url:       buganalysis.TestSearchURL(ciSearchURL, "", testName),
+				PassRates: map[string]sippyv1.PassRate{},
+			})
+			continue
+		}
+		failedTest := failingTestBug(*test, util.FindFailedTestResult(testName, prevReport.ByTest), ciSearchURL)
+		ret.FailedTests = append(ret.FailedTests, failedTest)
+		addBugs(failedTest.Bugs)
+	}
+	return ret, true
+}
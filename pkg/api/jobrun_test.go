@@ -0,0 +1,103 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	bugsv1 "github.com/openshift/sippy/pkg/apis/bugs/v1"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	testgridv1 "github.com/openshift/sippy/pkg/apis/testgrid/v1"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testgridconversion"
+)
+
+func TestJobRunReport(t *testing.T) {
+	// the runs must be recent enough for sippy to look at them
+	now := int(time.Now().Unix() * 1000)
+	failure := testgridv1.TestResult{Count: 1, Value: testgridv1.TestStatusFailure}
+	success := testgridv1.TestResult{Count: 1, Value: testgridv1.TestStatusSuccess}
+	jobs := []testgridv1.JobDetails{
+		{
+			Name:        "job-aws",
+			Query:       "origin-ci-test/logs/job-aws",
+			ChangeLists: []string{"2", "1"},
+			Timestamps:  []int{now - 3600000, now - 2*3600000},
+			TestGridUrl: "https://testgrid/job-aws",
+			Tests: []testgridv1.Test{
+				{Name: "Overall", Statuses: []testgridv1.TestResult{failure, success}},
+				{Name: "e2e-aws-proxy-ipi-install-install", Statuses: []testgridv1.TestResult{success, success}},
+				{Name: "operator install etcd", Statuses: []testgridv1.TestResult{failure, success}},
+				{Name: "[sig-etcd] leader changes", Statuses: []testgridv1.TestResult{failure, success}},
+				{Name: "[sig-network] dns", Statuses: []testgridv1.TestResult{failure, success}},
+			},
+		},
+	}
+	bug := bugsv1.Bug{ID: 1, Tracker: bugsv1.TrackerBugzilla}
+	jobBug := bugsv1.Bug{ID: 2, Tracker: bugsv1.TrackerBugzilla}
+	// a jira bug with the same ID as a bugzilla bug is a different bug
+	jiraBug := bugsv1.Bug{ID: 1, Tracker: bugsv1.TrackerJira, Key: "OCPBUGS-1"}
+	report := sippyprocessingv1.TestReport{
+		Release: "4.7",
+		ByTest: []sippyprocessingv1.FailingTestResult{
+			{
+				TestName: "[sig-etcd] leader changes",
+				TestResultAcrossAllJobs: sippyprocessingv1.TestResult{
+					Successes:      9,
+					Failures:       1,
+					PassPercentage: 90,
+					Owner:          sippyprocessingv1.TestOwner{Component: "Etcd"},
+					BugList:        []bugsv1.Bug{bug, jobBug, jiraBug},
+				},
+			},
+		},
+		ByJob: []sippyprocessingv1.JobResult{{Name: "job-aws", BugList: []bugsv1.Bug{jobBug}}},
+	}
+
+	runURL := testgridconversion.JobRunURL("", jobs[0], 0)
+	jobRunResults := NewJobRunResults(testgridconversion.NewEmptySythenticTestManager(), "", jobs)
+	actual, found := JobRunReport(jobRunResults, "", runURL, report, sippyprocessingv1.TestReport{})
+	if !found {
+		t.Fatalf("expected to find %s", runURL)
+	}
+	if runs := jobRunResults.JobRuns("job-aws"); len(runs) != 2 || runs[0].URL != runURL {
+		t.Errorf("expected both runs of the job, newest first, got %v", runs)
+	}
+	if runs := jobRunResults.JobRuns("job-gcp"); len(runs) != 0 {
+		t.Errorf("expected no runs of a job without data, got %v", runs)
+	}
+
+	if actual.BuildID != "2" || actual.Job != "job-aws" || actual.TestGridURL != "https://testgrid/job-aws" {
+		t.Errorf("expected the run to be identified, got %+v", actual)
+	}
+	if actual.Classification != string(sippyprocessingv1.JobRunTestFailure) {
+		t.Errorf("expected a test failure, got %s", actual.Classification)
+	}
+	if actual.SetupStatus != "Success" || actual.OpenShiftTestsStatus != "Failure" {
+		t.Errorf("expected setup to succeed and tests to fail, got %q and %q", actual.SetupStatus, actual.OpenShiftTestsStatus)
+	}
+	if len(actual.Operators) != 1 || actual.Operators[0].Name != "etcd" || actual.Operators[0].State != "Failure" {
+		t.Errorf("expected the failed etcd operator, got %v", actual.Operators)
+	}
+
+	if len(actual.FailedTests) != 3 {
+		t.Fatalf("expected 3 failed tests, got %v", actual.FailedTests)
+	}
+	for _, test := range actual.FailedTests {
+		switch test.Name {
+		case "[sig-etcd] leader changes":
+			if test.PassRates["latest"].Percentage != 90 || test.Component != "Etcd" {
+				t.Errorf("expected the pass rate and component of the test, got %+v", test)
+			}
+		default:
+			if len(test.PassRates) != 0 {
+				t.Errorf("expected no pass rates for %s, which is not in the report, got %v", test.Name, test.PassRates)
+			}
+		}
+	}
+	if len(actual.Bugs) != 3 || actual.Bugs[0].ID != 2 || actual.Bugs[1].ID != 1 || actual.Bugs[2].Key != "OCPBUGS-1" {
+		t.Errorf("expected the job bug then the test bugs, once each, got %v", actual.Bugs)
+	}
+
+	if _, found := JobRunReport(jobRunResults, "", runURL+"0", report, sippyprocessingv1.TestReport{}); found {
+		t.Errorf("expected an unknown run not to be found")
+	}
+}
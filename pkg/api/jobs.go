@@ -2,81 +2,152 @@ package api
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"time"
 
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
 	testgridv1 "github.com/openshift/sippy/pkg/apis/testgrid/v1"
 	"github.com/openshift/sippy/pkg/testgridanalysis/testgridanalysisapi"
 	"github.com/openshift/sippy/pkg/testgridanalysis/testgridconversion"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testreportconversion"
+	"k8s.io/klog"
 )
 
-func jobRunStatus(result testgridanalysisapi.RawJobRunResult) string {
-	if result.Succeeded {
-		return "S" // Success
-	}
-
-	if !result.Failed {
-		return "R" // Running
-	}
-
-	if result.SetupStatus == "Failure" {
-		if len(result.FinalOperatorStates) == 0 {
-			return "N" // iNfrastructure failure
-		}
-		return "I" // Install failure
-	}
-	if result.UpgradeStarted && (result.UpgradeForOperatorsStatus == "Failure" || result.UpgradeForMachineConfigPoolsStatus == "Failure") {
-		return "U" // Upgrade failure
-	}
-	if result.OpenShiftTestsStatus == "Failure" {
-		return "F" // Failure
-	}
-	if result.SetupStatus == "" {
-		return "n" // no setup results
-	}
-	return "f" // unknown failure
+// jobRunStatusCodes are the one letter codes of the job run outcomes in the jobs grid
+var jobRunStatusCodes = map[sippyprocessingv1.JobRunOutcome]string{
+	sippyprocessingv1.JobRunSucceeded:             "S",
+	sippyprocessingv1.JobRunRunning:               "R",
+	sippyprocessingv1.JobRunInfrastructureFailure: "N",
+	sippyprocessingv1.JobRunInstallFailure:        "I",
+	sippyprocessingv1.JobRunUpgradeFailure:        "U",
+	sippyprocessingv1.JobRunTestFailure:           "F",
+	sippyprocessingv1.JobRunNoSetupResults:        "n",
+	sippyprocessingv1.JobRunUnknownFailure:        "f",
 }
 
-func PrintJobsReport(w http.ResponseWriter, syntheticTestManager testgridconversion.SythenticTestManager, testGridJobDetails []testgridv1.JobDetails, lastUpdateTime time.Time) {
-	rawJobResultOptions := testgridconversion.ProcessingOptions{
-		SythenticTestManager: syntheticTestManager,
-		StartDay:             0,
-		NumDays:              1000,
+// jobRunOutcome classifies the run of the job with the URL.  Runs without results are still running, like testgrid
+// shows them.
+func jobRunOutcome(results testgridanalysisapi.RawJobResult, runURL string) (sippyprocessingv1.JobRunOutcome, string) {
+	result, ok := results.JobRunResults[runURL]
+	if !ok {
+		return sippyprocessingv1.JobRunRunning, "the job has no results yet"
 	}
-	rawJobResults, _ := rawJobResultOptions.ProcessTestGridDataIntoRawJobResults(testGridJobDetails)
+	return testreportconversion.ClassifyJobRun(result)
+}
 
-	type jsonJob struct {
-		Name        string   `json:"name"`
-		Timestamps  []int    `json:"timestamps"`
-		Results     []string `json:"results"`
-		BuildIDs    []string `json:"build_ids"`
-		TestGridURL string   `json:"testgrid_url"`
-	}
-	type jsonResponse struct {
-		Jobs           []jsonJob `json:"jobs"`
-		LastUpdateTime time.Time `json:"last_update_time"`
-	}
+// JobGrid returns the result of every run of every job in the testgrid data.
+func JobGrid(syntheticTestManager testgridconversion.SythenticTestManager, prowURL string, testGridJobDetails []testgridv1.JobDetails, lastUpdateTime time.Time) sippyv1.JobGrid {
+	rawJobResults, _ := allJobRuns(syntheticTestManager, prowURL, testGridJobDetails)
 
-	response := jsonResponse{
+	response := sippyv1.JobGrid{
 		LastUpdateTime: lastUpdateTime,
-		Jobs:           []jsonJob{},
+		Jobs:           []sippyv1.JobGridJob{},
 	}
 	for _, job := range testGridJobDetails {
 		results := rawJobResults.JobResults[job.Name]
-		var statuses []string
+		var statuses, runURLs []string
 		for i := range job.Timestamps {
-			joburl := fmt.Sprintf("https://prow.svc.ci.openshift.org/view/gcs/%s/%s", job.Query, job.ChangeLists[i])
-			statuses = append(statuses, jobRunStatus(results.JobRunResults[joburl]))
+			joburl := testgridconversion.JobRunURL(prowURL, job, i)
+			outcome, _ := jobRunOutcome(results, joburl)
+			statuses = append(statuses, jobRunStatusCodes[outcome])
+			runURLs = append(runURLs, joburl)
 		}
-		response.Jobs = append(response.Jobs, jsonJob{
+		response.Jobs = append(response.Jobs, sippyv1.JobGridJob{
 			Name:        job.Name,
 			Timestamps:  job.Timestamps,
 			Results:     statuses,
 			BuildIDs:    job.ChangeLists,
+			RunURLs:     runURLs,
 			TestGridURL: job.TestGridUrl,
 		})
 	}
+	return response
+}
+
+// JobRunResults are the runs of every job in the testgrid data, with the results sippy found for them.  Processing the
+// testgrid data is expensive, so they are meant to be computed once for every refresh of the data.
+type JobRunResults struct {
+	// Runs are every run of every job, newest first within each job
+	Runs []sippyv1.JobRun
+	// Warnings are the problems found while processing the testgrid data
+	Warnings []string
+
+	// runs are the details of the Runs that JobRunReport needs, by run URL
+	runs map[string]jobRunDetails
+}
+
+type jobRunDetails struct {
+	run         sippyv1.JobRun
+	testGridURL string
+	buildID     string
+	result      testgridanalysisapi.RawJobRunResult
+}
 
-	json.NewEncoder(w).Encode(response)
+// NewJobRunResults processes every run of every job in the testgrid data.
+func NewJobRunResults(syntheticTestManager testgridconversion.SythenticTestManager, prowURL string, testGridJobDetails []testgridv1.JobDetails) JobRunResults {
+	rawJobResults, warnings := allJobRuns(syntheticTestManager, prowURL, testGridJobDetails)
+
+	ret := JobRunResults{
+		Runs:     []sippyv1.JobRun{},
+		Warnings: warnings,
+		runs:     map[string]jobRunDetails{},
+	}
+	for _, job := range testGridJobDetails {
+		results := rawJobResults.JobResults[job.Name]
+		for i, timestamp := range job.Timestamps {
+			joburl := testgridconversion.JobRunURL(prowURL, job, i)
+			result := results.JobRunResults[joburl]
+			outcome, outcomeReason := jobRunOutcome(results, joburl)
+			failedTestNames := result.FailedTestNames
+			if failedTestNames == nil {
+				failedTestNames = []string{}
+			}
+			run := sippyv1.JobRun{
+				Job:                  job.Name,
+				URL:                  joburl,
+				Timestamp:            time.Unix(0, int64(timestamp)*int64(time.Millisecond)).UTC(),
+				Classification:       string(outcome),
+				ClassificationReason: outcomeReason,
+				Succeeded:            result.Succeeded,
+				Failed:               result.Failed,
+				TestFailures:         result.TestFailures,
+				FailedTestNames:      failedTestNames,
+			}
+			ret.Runs = append(ret.Runs, run)
+			ret.runs[joburl] = jobRunDetails{run: run, testGridURL: job.TestGridUrl, buildID: job.ChangeLists[i], result: result}
+		}
+	}
+	return ret
+}
+
+// JobRuns returns the runs of the job, or every run if jobName is empty.
+func (r JobRunResults) JobRuns(jobName string) []sippyv1.JobRun {
+	if len(jobName) == 0 {
+		return r.Runs
+	}
+	ret := []sippyv1.JobRun{}
+	for _, run := range r.Runs {
+		if run.Job == jobName {
+			ret = append(ret, run)
+		}
+	}
+	return ret
+}
+
+func allJobRuns(syntheticTestManager testgridconversion.SythenticTestManager, prowURL string, testGridJobDetails []testgridv1.JobDetails) (testgridanalysisapi.RawData, []string) {
+	rawJobResultOptions := testgridconversion.ProcessingOptions{
+		SythenticTestManager: syntheticTestManager,
+		StartDay:             0,
+		NumDays:              1000,
+		ProwURL:              prowURL,
+	}
+	return rawJobResultOptions.ProcessTestGridDataIntoRawJobResults(testGridJobDetails)
+}
+
+func PrintJobsReport(w http.ResponseWriter, syntheticTestManager testgridconversion.SythenticTestManager, prowURL string, testGridJobDetails []testgridv1.JobDetails, lastUpdateTime time.Time) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(JobGrid(syntheticTestManager, prowURL, testGridJobDetails, lastUpdateTime)); err != nil {
+		klog.Errorf("unable to render json %v", err)
+	}
 }
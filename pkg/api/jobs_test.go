@@ -0,0 +1,18 @@
+package api
+
+import (
+	"testing"
+
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testgridanalysisapi"
+)
+
+func TestJobRunOutcomeWithoutResults(t *testing.T) {
+	outcome, reason := jobRunOutcome(testgridanalysisapi.RawJobResult{}, "https://prow/job-aws/1")
+	if outcome != sippyprocessingv1.JobRunRunning || len(reason) == 0 {
+		t.Errorf("expected a run without results to be running, got %q: %q", outcome, reason)
+	}
+	if code := jobRunStatusCodes[outcome]; code != "R" {
+		t.Errorf("expected the grid to show a run without results as R, got %q", code)
+	}
+}
@@ -0,0 +1,233 @@
+package api
+
+import (
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+)
+
+// Endpoint describes a GET endpoint of the JSON API for its OpenAPI document.
+type Endpoint struct {
+	Path        string
+	Summary     string
+	Description string
+	Parameters  []Parameter
+	// Response is a value of the type of the response body.  Its schema is generated from the type.
+	Response interface{}
+}
+
+// Parameter is a query parameter of an Endpoint.
+type Parameter struct {
+	Name        string `json:"name"`
+	In          string `json:"in"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	Schema      Schema `json:"schema"`
+}
+
+// QueryParameter returns a string query parameter.
+func QueryParameter(name, description string, required bool) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Required: required, Schema: Schema{Type: "string"}}
+}
+
+// OpenAPIDocument is an OpenAPI 3.0 document.  It only has the parts of the specification that sippy uses.
+type OpenAPIDocument struct {
+	OpenAPI    string                     `json:"openapi"`
+	Info       OpenAPIInfo                `json:"info"`
+	Paths      map[string]OpenAPIPathItem `json:"paths"`
+	Components OpenAPIComponents          `json:"components"`
+}
+
+type OpenAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type OpenAPIPathItem struct {
+	Get OpenAPIOperation `json:"get"`
+}
+
+type OpenAPIOperation struct {
+	Summary     string                     `json:"summary,omitempty"`
+	Description string                     `json:"description,omitempty"`
+	Parameters  []Parameter                `json:"parameters,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses"`
+}
+
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content"`
+}
+
+type OpenAPIMediaType struct {
+	Schema Schema `json:"schema"`
+}
+
+type OpenAPIComponents struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Schema is a JSON schema as OpenAPI 3.0 restricts it.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+// OpenAPI generates the OpenAPI document of the endpoints.  The schemas of the responses are generated from their Go
+// types by the same rules that encoding/json uses, so the document cannot drift from what the endpoints return.  Every
+// named struct becomes a component schema, and every endpoint may return a sippyv1.Error instead.
+func OpenAPI(title, version string, endpoints []Endpoint) OpenAPIDocument {
+	generator := newSchemaGenerator()
+	errorSchema := generator.schemaFor(reflect.TypeOf(sippyv1.Error{}))
+
+	doc := OpenAPIDocument{
+		OpenAPI:    "3.0.3",
+		Info:       OpenAPIInfo{Title: title, Version: version},
+		Paths:      map[string]OpenAPIPathItem{},
+		Components: OpenAPIComponents{Schemas: generator.schemas},
+	}
+	for _, endpoint := range endpoints {
+		doc.Paths[endpoint.Path] = OpenAPIPathItem{
+			Get: OpenAPIOperation{
+				Summary:     endpoint.Summary,
+				Description: endpoint.Description,
+				Parameters:  endpoint.Parameters,
+				Responses: map[string]OpenAPIResponse{
+					"200":     jsonResponse("OK", *generator.schemaFor(reflect.TypeOf(endpoint.Response))),
+					"default": jsonResponse("Error", *errorSchema),
+				},
+			},
+		}
+	}
+	return doc
+}
+
+func jsonResponse(description string, schema Schema) OpenAPIResponse {
+	return OpenAPIResponse{
+		Description: description,
+		Content:     map[string]OpenAPIMediaType{"application/json": {Schema: schema}},
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+type schemaGenerator struct {
+	schemas map[string]*Schema
+	// names holds the component name of every named struct seen so far
+	names map[reflect.Type]string
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		schemas: map[string]*Schema{},
+		names:   map[reflect.Type]string{},
+	}
+}
+
+func (g *schemaGenerator) schemaFor(t reflect.Type) *Schema {
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schemaFor(t.Elem())
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json writes []byte as base64
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if len(t.Name()) == 0 {
+			return g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + g.componentName(t)}
+	default:
+		// interfaces can hold anything
+		return &Schema{}
+	}
+}
+
+// componentName registers the named struct as a component schema, once, and returns its name.
+func (g *schemaGenerator) componentName(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := g.schemas[name]; taken {
+		// the same name in another package, like v1.Bug and v1.Job, is qualified by the package
+		name = strings.Title(path.Base(path.Dir(t.PkgPath()))) + name
+	}
+	g.names[t] = name
+	// register before generating the properties so that recursive types end in a reference
+	g.schemas[name] = &Schema{}
+	*g.schemas[name] = *g.structSchema(t)
+	return name
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.addFields(schema, t)
+	return schema
+}
+
+func (g *schemaGenerator) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if len(field.PkgPath) > 0 && !field.Anonymous {
+			continue // unexported
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options := tag, ""
+		if comma := strings.Index(tag, ","); comma >= 0 {
+			name, options = tag[:comma], tag[comma+1:]
+		}
+
+		// encoding/json promotes the fields of untagged embedded structs
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && len(name) == 0 && fieldType.Kind() == reflect.Struct {
+			g.addFields(schema, fieldType)
+			continue
+		}
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+
+		if len(name) == 0 {
+			name = field.Name
+		}
+		schema.Properties[name] = g.schemaFor(field.Type)
+		if !strings.Contains(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
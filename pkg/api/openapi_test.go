@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+)
+
+func TestOpenAPI(t *testing.T) {
+	doc := OpenAPI("Sippy", "v1", []Endpoint{
+		{
+			Path:       "/api/v1/jobs",
+			Parameters: []Parameter{QueryParameter("release", "", true)},
+			Response:   sippyv1.JobList{},
+		},
+		{
+			Path:     "/api/v1/jobruns",
+			Response: sippyv1.JobRunList{},
+		},
+		{
+			Path:     "/api/v1/releases",
+			Response: sippyv1.ReleaseList{},
+		},
+	})
+
+	// every reference must resolve, including those in the document as it is served
+	serialized, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ref := range strings.Split(string(serialized), `"$ref":"`)[1:] {
+		ref = ref[:strings.Index(ref, `"`)]
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		if _, ok := doc.Components.Schemas[name]; !ok || name == ref {
+			t.Errorf("reference %q does not resolve", ref)
+		}
+	}
+
+	if errorRef := doc.Paths["/api/v1/jobs"].Get.Responses["default"].Content["application/json"].Schema.Ref; errorRef != "#/components/schemas/Error" {
+		t.Errorf("expected the default response to be an Error, got %q", errorRef)
+	}
+
+	job := doc.Components.Schemas["Job"]
+	if job == nil {
+		t.Fatalf("expected a Job schema, got %v", doc.Components.Schemas)
+	}
+	if job.Properties["passRates"].Type != "object" || job.Properties["passRates"].AdditionalProperties.Ref != "#/components/schemas/PassRate" {
+		t.Errorf("expected passRates to be a map of PassRate, got %+v", job.Properties["passRates"])
+	}
+
+	// the fields of the embedded bugzilla bug are promoted like encoding/json does
+	bug := doc.Components.Schemas["Bug"]
+	if bug == nil {
+		t.Fatalf("expected a Bug schema, got %v", doc.Components.Schemas)
+	}
+	for _, property := range []string{"id", "status", "failureCount"} {
+		if _, ok := bug.Properties[property]; !ok {
+			t.Errorf("expected Bug to have %q, got %v", property, bug.Properties)
+		}
+	}
+
+	if timestamp := doc.Components.Schemas["JobRun"].Properties["timestamp"]; timestamp.Type != "string" || timestamp.Format != "date-time" {
+		t.Errorf("expected timestamps to be date-times, got %+v", timestamp)
+	}
+
+	release := doc.Components.Schemas["Release"]
+	if !reflect.DeepEqual(release.Required, []string{"name", "dashboards", "analysisWarnings"}) {
+		t.Errorf("expected the omitempty fields to be optional, got %v", release.Required)
+	}
+}
@@ -0,0 +1,525 @@
+package api
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+)
+
+// ListOptions filter, sort, and page a list of sippyprocessingv1 results, like []FailingTestResult, []JobResult, or
+// []VariantResults.  The results are inspected by reflection, so a filter works on every result type that has the
+// property it filters on, wherever the property is nested.  For instance the component of a FailingTestResult is
+// TestResultAcrossAllJobs.Owner.Component.
+type ListOptions struct {
+	// Name matches the name of the result, like the test, job, or variant name
+	Name *regexp.Regexp
+	// Variant is one of the variants of a job, or the name of a variant
+	Variant string
+	// Component is the bugzilla component that owns a test
+	Component         string
+	MinPassPercentage *float64
+	MaxPassPercentage *float64
+	// MinRuns is the lowest number of successes plus failures
+	MinRuns int
+
+	// SortBy is the JSON name of a numeric field, or runs.  A name without dots is looked for at every depth, the
+	// shallowest first, so passPercentage sorts tests by results.passPercentage.
+	SortBy     string
+	Descending bool
+
+	// Limit is the largest number of results to return, or 0 for all of them
+	Limit  int
+	Offset int
+}
+
+// runsProperty is the number of successes plus failures, which no result type has as a field
+const runsProperty = "runs"
+
+// the properties of the results that the filters look for, by the Go names of the fields that hold them
+var (
+	nameFields           = []string{"TestName", "Name", "VariantName", "JobName"}
+	variantFields        = []string{"Variants", "VariantName"}
+	componentFields      = []string{"Component", "BugzillaComponent"}
+	passPercentageFields = []string{"PassPercentage", "JobRunPassPercentage"}
+	runFields            = [][]string{{"Successes", "Failures"}, {"JobRunSuccesses", "JobRunFailures"}, {"TotalRuns"}}
+
+	// filterFields are the fields that each filter parameter looks for, except minRuns, which looks for runFields
+	filterFields = map[string][]string{
+		"name":              nameFields,
+		"variant":           variantFields,
+		"component":         componentFields,
+		"minPassPercentage": passPercentageFields,
+		"maxPassPercentage": passPercentageFields,
+	}
+)
+
+// pagingParameters are the parameters of ParseListOptions that sort or page a list rather than filter it.
+var pagingParameters = []string{"sort", "order", "limit", "offset", "cursor"}
+
+// ParseListOptions reads the list options from the query parameters:
+//
+//	name=<regex>, variant=<variant>, component=<component>, minPassPercentage=<float>, maxPassPercentage=<float>,
+//	minRuns=<int>, sort=<field>, order=asc|desc, limit=<int>, offset=<int>, and cursor=<cursor>.
+//
+// A cursor is the nextCursor of a previous page.  It only resumes the list it came from, so version must identify the
+// data being listed, like the timestamp of the report; a cursor from another version is an error.
+func ParseListOptions(query url.Values, version string) (ListOptions, error) {
+	options := ListOptions{
+		Variant:   query.Get("variant"),
+		Component: query.Get("component"),
+		SortBy:    query.Get("sort"),
+	}
+
+	var err error
+	if name := query.Get("name"); len(name) > 0 {
+		if options.Name, err = regexp.Compile(name); err != nil {
+			return ListOptions{}, fmt.Errorf("name: %v", err)
+		}
+	}
+	if options.MinPassPercentage, err = parseOptionalFloat(query, "minPassPercentage"); err != nil {
+		return ListOptions{}, err
+	}
+	if options.MaxPassPercentage, err = parseOptionalFloat(query, "maxPassPercentage"); err != nil {
+		return ListOptions{}, err
+	}
+	if options.MinRuns, err = parseNonNegativeInt(query, "minRuns"); err != nil {
+		return ListOptions{}, err
+	}
+
+	switch order := query.Get("order"); order {
+	case "", "asc":
+	case "desc":
+		options.Descending = true
+	default:
+		return ListOptions{}, fmt.Errorf("order: %q is not one of asc or desc", order)
+	}
+
+	if options.Limit, err = parseNonNegativeInt(query, "limit"); err != nil {
+		return ListOptions{}, err
+	}
+	if options.Offset, err = parseNonNegativeInt(query, "offset"); err != nil {
+		return ListOptions{}, err
+	}
+	if cursor := query.Get("cursor"); len(cursor) > 0 {
+		if len(query.Get("offset")) > 0 {
+			return ListOptions{}, fmt.Errorf("only one of offset and cursor may be set")
+		}
+		if options.Offset, err = decodeCursor(cursor, version); err != nil {
+			return ListOptions{}, err
+		}
+	}
+
+	return options, nil
+}
+
+// ParseFilterOptions reads the filters of ParseListOptions from the query parameters, for results that are not a single
+// list and so cannot be sorted or paged.  It is an error to set sort, order, limit, offset, or cursor.
+func ParseFilterOptions(query url.Values) (ListOptions, error) {
+	for _, param := range pagingParameters {
+		if len(query.Get(param)) > 0 {
+			return ListOptions{}, fmt.Errorf("%s: only the lists of /api/v1 can be sorted or paged", param)
+		}
+	}
+	return ParseListOptions(query, "")
+}
+
+// CanFilter returns true if the filter parameter of ParseListOptions, like name or minRuns, can filter a list of the
+// results, which must be a slice of structs.  Parameters that do not filter, like sort, return false.
+func CanFilter(results interface{}, param string) bool {
+	elemType := reflect.TypeOf(results).Elem()
+	if param == "minRuns" {
+		_, ok := runsOf(elemType)
+		return ok
+	}
+	fieldNames, ok := filterFields[param]
+	if !ok {
+		return false
+	}
+	_, ok = findFieldByName(elemType, fieldNames)
+	return ok
+}
+
+func parseOptionalFloat(query url.Values, param string) (*float64, error) {
+	value := query.Get(param)
+	if len(value) == 0 {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %q is not a number", param, value)
+	}
+	return &f, nil
+}
+
+func parseNonNegativeInt(query url.Values, param string) (int, error) {
+	value := query.Get(param)
+	if len(value) == 0 {
+		return 0, nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("%s: %q is not a non-negative integer", param, value)
+	}
+	return i, nil
+}
+
+func encodeCursor(offset int, version string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%s", offset, version)))
+}
+
+func decodeCursor(cursor, version string) (int, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("cursor: %q is not a cursor", cursor)
+	}
+	parts := strings.SplitN(string(decoded), ":", 2)
+	offset, err := strconv.Atoi(parts[0])
+	if err != nil || offset < 0 || len(parts) != 2 {
+		return 0, fmt.Errorf("cursor: %q is not a cursor", cursor)
+	}
+	if parts[1] != version {
+		return 0, fmt.Errorf("cursor: the data changed since the cursor was returned, start again without it")
+	}
+	return offset, nil
+}
+
+// Page filters, sorts, and pages the results, which must be a slice of structs, and returns a slice of the same type.
+// It is an error to filter or sort on a property that the results do not have.
+func Page(results interface{}, options ListOptions, version string) (interface{}, sippyv1.ListMeta, error) {
+	filtered, err := filterResults(results, options, true)
+	if err != nil {
+		return nil, sippyv1.ListMeta{}, err
+	}
+	if err := sortResults(filtered, options); err != nil {
+		return nil, sippyv1.ListMeta{}, err
+	}
+
+	total := filtered.Len()
+	start := options.Offset
+	if start > total {
+		start = total
+	}
+	end := total
+	if options.Limit > 0 && start+options.Limit < total {
+		end = start + options.Limit
+	}
+
+	meta := sippyv1.ListMeta{Total: total, Offset: start, Limit: options.Limit}
+	if end < total {
+		meta.NextCursor = encodeCursor(end, version)
+	}
+	return filtered.Slice(start, end).Interface(), meta, nil
+}
+
+// FilterTestReport applies the filters of the options, but not the sorting or paging, to the test, job, and variant
+// lists of the report.  Each list is only filtered by the properties its results have, so variant= filters the jobs
+// and variants but not the tests.
+func FilterTestReport(report sippyprocessingv1.TestReport, options ListOptions) sippyprocessingv1.TestReport {
+	filter := func(results interface{}) interface{} {
+		filtered, _ := filterResults(results, options, false)
+		return filtered.Interface()
+	}
+	report.ByTest = filter(report.ByTest).([]sippyprocessingv1.FailingTestResult)
+	report.TopFailingTestsWithBug = filter(report.TopFailingTestsWithBug).([]sippyprocessingv1.FailingTestResult)
+	report.TopFailingTestsWithoutBug = filter(report.TopFailingTestsWithoutBug).([]sippyprocessingv1.FailingTestResult)
+	report.CuratedTests = filter(report.CuratedTests).([]sippyprocessingv1.FailingTestResult)
+	report.ByJob = filter(report.ByJob).([]sippyprocessingv1.JobResult)
+	report.FrequentJobResults = filter(report.FrequentJobResults).([]sippyprocessingv1.JobResult)
+	report.InfrequentJobResults = filter(report.InfrequentJobResults).([]sippyprocessingv1.JobResult)
+	report.ByVariant = filter(report.ByVariant).([]sippyprocessingv1.VariantResults)
+	return report
+}
+
+// filterResults returns a new slice of the results that match the options.  If strict, a filter on a property the
+// results do not have is an error, otherwise it is ignored.
+func filterResults(results interface{}, options ListOptions, strict bool) (reflect.Value, error) {
+	in := reflect.ValueOf(results)
+	if in.Kind() != reflect.Slice || in.Type().Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("%T is not a list of results", results)
+	}
+	elemType := in.Type().Elem()
+
+	type predicate func(result reflect.Value) bool
+	predicates := []predicate{}
+	addFilter := func(param string, matches func(field reflect.Value) bool) error {
+		index, ok := findFieldByName(elemType, filterFields[param])
+		if !ok {
+			if strict {
+				return fmt.Errorf("%s cannot filter a list of %s", param, elemType.Name())
+			}
+			return nil
+		}
+		predicates = append(predicates, func(result reflect.Value) bool {
+			return matches(result.FieldByIndex(index))
+		})
+		return nil
+	}
+
+	if options.Name != nil {
+		if err := addFilter("name", func(field reflect.Value) bool {
+			return options.Name.MatchString(field.String())
+		}); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	if len(options.Variant) > 0 {
+		if err := addFilter("variant", func(field reflect.Value) bool {
+			if field.Kind() == reflect.Slice {
+				for i := 0; i < field.Len(); i++ {
+					if field.Index(i).String() == options.Variant {
+						return true
+					}
+				}
+				return false
+			}
+			return field.String() == options.Variant
+		}); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	if len(options.Component) > 0 {
+		if err := addFilter("component", func(field reflect.Value) bool {
+			return field.String() == options.Component
+		}); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	if options.MinPassPercentage != nil {
+		if err := addFilter("minPassPercentage", func(field reflect.Value) bool {
+			return field.Float() >= *options.MinPassPercentage
+		}); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	if options.MaxPassPercentage != nil {
+		if err := addFilter("maxPassPercentage", func(field reflect.Value) bool {
+			return field.Float() <= *options.MaxPassPercentage
+		}); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	if options.MinRuns > 0 {
+		runs, ok := runsOf(elemType)
+		if ok {
+			predicates = append(predicates, func(result reflect.Value) bool {
+				return runs(result) >= float64(options.MinRuns)
+			})
+		} else if strict {
+			return reflect.Value{}, fmt.Errorf("minRuns cannot filter a list of %s", elemType.Name())
+		}
+	}
+
+	out := reflect.MakeSlice(in.Type(), 0, in.Len())
+	for i := 0; i < in.Len(); i++ {
+		result := in.Index(i)
+		matches := true
+		for _, p := range predicates {
+			if !p(result) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			out = reflect.Append(out, result)
+		}
+	}
+	return out, nil
+}
+
+// sortResults sorts the slice in place by options.SortBy, keeping the order of the report for ties.
+func sortResults(results reflect.Value, options ListOptions) error {
+	if len(options.SortBy) == 0 {
+		if options.Descending {
+			// without a field, desc reverses the order of the report
+			swap := reflect.Swapper(results.Interface())
+			for i, j := 0, results.Len()-1; i < j; i, j = i+1, j-1 {
+				swap(i, j)
+			}
+		}
+		return nil
+	}
+
+	elemType := results.Type().Elem()
+	key, ok := NumericProperty(elemType, options.SortBy)
+	if !ok {
+		return fmt.Errorf("sort: %q is not a numeric field of %s", options.SortBy, elemType.Name())
+	}
+
+	keys := make([]float64, results.Len())
+	for i := range keys {
+		keys[i] = key(results.Index(i))
+	}
+	sort.Stable(&resultSorter{keys: keys, descending: options.Descending, swap: reflect.Swapper(results.Interface())})
+	return nil
+}
+
+type resultSorter struct {
+	keys       []float64
+	descending bool
+	swap       func(i, j int)
+}
+
+func (s *resultSorter) Len() int { return len(s.keys) }
+func (s *resultSorter) Less(i, j int) bool {
+	if s.descending {
+		return s.keys[i] > s.keys[j]
+	}
+	return s.keys[i] < s.keys[j]
+}
+func (s *resultSorter) Swap(i, j int) {
+	s.swap(i, j)
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
+
+// NumericProperty returns a function that reads a numeric property of values of type t: the JSON name of a numeric
+// field, the dotted path of a nested one, or runs.
+func NumericProperty(t reflect.Type, name string) (func(result reflect.Value) float64, bool) {
+	if name == runsProperty {
+		if runs, ok := runsOf(t); ok {
+			return runs, true
+		}
+	}
+	index, found := findNumericFieldByJSONName(t, name)
+	if !found {
+		return nil, false
+	}
+	return func(result reflect.Value) float64 {
+		return numericValue(result.FieldByIndex(index))
+	}, true
+}
+
+// runsOf returns a function that counts the runs of a result, if the result type records them.
+func runsOf(t reflect.Type) (func(result reflect.Value) float64, bool) {
+	for _, fieldNames := range runFields {
+		indexes := [][]int{}
+		for _, fieldName := range fieldNames {
+			index, ok := findFieldByName(t, []string{fieldName})
+			if !ok {
+				break
+			}
+			indexes = append(indexes, index)
+		}
+		if len(indexes) != len(fieldNames) {
+			continue
+		}
+		return func(result reflect.Value) float64 {
+			runs := 0.0
+			for _, index := range indexes {
+				runs += numericValue(result.FieldByIndex(index))
+			}
+			return runs
+		}, true
+	}
+	return nil, false
+}
+
+func numericValue(field reflect.Value) float64 {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(field.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(field.Uint())
+	default:
+		return field.Float()
+	}
+}
+
+func isNumeric(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// findFieldByName returns the index of the shallowest non-struct field with one of the names, so that the TestName of a
+// FailingTestResult wins over its results.name.  It looks into nested structs, but not into slices, maps, or pointers.
+func findFieldByName(t reflect.Type, names []string) ([]int, bool) {
+	return findField(t, func(field reflect.StructField) bool {
+		if field.Type.Kind() == reflect.Struct {
+			return false
+		}
+		for _, name := range names {
+			if field.Name == name {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// findNumericFieldByJSONName returns the index of the numeric field with the JSON name.  A dotted name is a path from
+// the result, like results.passPercentage; a name without dots matches the shallowest field with that name.
+func findNumericFieldByJSONName(t reflect.Type, name string) ([]int, bool) {
+	if !strings.Contains(name, ".") {
+		return findField(t, func(field reflect.StructField) bool {
+			return jsonName(field) == name && isNumeric(field.Type)
+		})
+	}
+
+	index := []int{}
+	for _, part := range strings.Split(name, ".") {
+		if t.Kind() != reflect.Struct {
+			return nil, false
+		}
+		found := false
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if len(field.PkgPath) == 0 && jsonName(field) == part {
+				index = append(index, i)
+				t = field.Type
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return index, isNumeric(t)
+}
+
+// findField searches the struct breadth first, so that the shallowest match wins.
+func findField(t reflect.Type, matches func(field reflect.StructField) bool) ([]int, bool) {
+	type candidate struct {
+		t     reflect.Type
+		index []int
+	}
+	queue := []candidate{{t: t}}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for i := 0; i < current.t.NumField(); i++ {
+			field := current.t.Field(i)
+			if len(field.PkgPath) > 0 {
+				continue // unexported
+			}
+			index := append(append([]int{}, current.index...), i)
+			if matches(field) {
+				return index, true
+			}
+			if field.Type.Kind() == reflect.Struct {
+				queue = append(queue, candidate{t: field.Type, index: index})
+			}
+		}
+	}
+	return nil, false
+}
+
+func jsonName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if len(name) == 0 {
+		return field.Name
+	}
+	return name
+}
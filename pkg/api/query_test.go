@@ -0,0 +1,297 @@
+package api
+
+import (
+	"net/url"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/buganalysis"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testgridanalysisapi"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testidentification"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testreportconversion"
+)
+
+func TestPage(t *testing.T) {
+	test := func(name, component string, successes, failures, flakes int) sippyprocessingv1.FailingTestResult {
+		return sippyprocessingv1.FailingTestResult{
+			TestName: name,
+			TestResultAcrossAllJobs: sippyprocessingv1.TestResult{
+				Name:           name,
+				Successes:      successes,
+				Failures:       failures,
+				Flakes:         flakes,
+				PassPercentage: float64(successes) * 100 / float64(successes+failures),
+				Owner:          sippyprocessingv1.TestOwner{Component: component},
+			},
+		}
+	}
+	tests := []sippyprocessingv1.FailingTestResult{
+		test("[sig-etcd] a", "Etcd", 1, 9, 0),
+		test("[sig-network] b", "Networking", 5, 5, 3),
+		test("[sig-etcd] c", "Etcd", 40, 10, 1),
+		test("[sig-etcd] d", "Etcd", 9, 1, 2),
+	}
+	jobs := []sippyprocessingv1.JobResult{
+		{Name: "job-aws", Variants: []string{"aws"}, Successes: 1, Failures: 1, PassPercentage: 50},
+		{Name: "job-gcp", Variants: []string{"gcp"}, Successes: 3, Failures: 1, PassPercentage: 75},
+	}
+
+	testNames := func(page interface{}) []string {
+		names := []string{}
+		for _, test := range page.([]sippyprocessingv1.FailingTestResult) {
+			names = append(names, test.TestName)
+		}
+		return names
+	}
+
+	testCases := []struct {
+		name          string
+		results       interface{}
+		query         string
+		expectedNames []string
+		expectedTotal int
+		expectedError bool
+	}{
+		{
+			name:          "no options",
+			results:       tests,
+			query:         "",
+			expectedNames: []string{"[sig-etcd] a", "[sig-network] b", "[sig-etcd] c", "[sig-etcd] d"},
+			expectedTotal: 4,
+		},
+		{
+			name:          "name and component filters",
+			results:       tests,
+			query:         "name=(c|d)$&component=Etcd",
+			expectedNames: []string{"[sig-etcd] c", "[sig-etcd] d"},
+			expectedTotal: 2,
+		},
+		{
+			name:          "pass rate and runs filters",
+			results:       tests,
+			query:         "minPassPercentage=50&maxPassPercentage=85&minRuns=20",
+			expectedNames: []string{"[sig-etcd] c"},
+			expectedTotal: 1,
+		},
+		{
+			name:          "sort by a nested field",
+			results:       tests,
+			query:         "sort=flakes&order=desc",
+			expectedNames: []string{"[sig-network] b", "[sig-etcd] d", "[sig-etcd] c", "[sig-etcd] a"},
+			expectedTotal: 4,
+		},
+		{
+			name:          "sort by runs",
+			results:       tests,
+			query:         "sort=runs&order=desc&limit=2",
+			expectedNames: []string{"[sig-etcd] c", "[sig-etcd] a"},
+			expectedTotal: 4,
+		},
+		{
+			name:          "sort by a path",
+			results:       tests,
+			query:         "sort=results.passPercentage&offset=1&limit=2",
+			expectedNames: []string{"[sig-network] b", "[sig-etcd] c"},
+			expectedTotal: 4,
+		},
+		{
+			name:          "variant does not filter tests",
+			results:       tests,
+			query:         "variant=aws",
+			expectedError: true,
+		},
+		{
+			name:          "component does not filter jobs",
+			results:       jobs,
+			query:         "component=Etcd",
+			expectedError: true,
+		},
+		{
+			name:          "not a numeric field",
+			results:       tests,
+			query:         "sort=testName",
+			expectedError: true,
+		},
+		{
+			name:          "variant filters jobs",
+			results:       jobs,
+			query:         "variant=gcp",
+			expectedTotal: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			options, err := ParseListOptions(query, "v1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			page, meta, err := Page(tc.results, options, "v1")
+			if tc.expectedError {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", page)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if meta.Total != tc.expectedTotal {
+				t.Errorf("expected %d results in total, got %d", tc.expectedTotal, meta.Total)
+			}
+			if tc.expectedNames != nil && !reflect.DeepEqual(testNames(page), tc.expectedNames) {
+				t.Errorf("expected %v, got %v", tc.expectedNames, testNames(page))
+			}
+		})
+	}
+
+	if !reflect.DeepEqual(testNames(tests), []string{"[sig-etcd] a", "[sig-network] b", "[sig-etcd] c", "[sig-etcd] d"}) {
+		t.Errorf("expected the results to be left alone, got %v", testNames(tests))
+	}
+}
+
+func TestPageCursor(t *testing.T) {
+	jobs := []sippyprocessingv1.JobResult{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+
+	names := []string{}
+	query := url.Values{"limit": []string{"2"}}
+	for pages := 0; pages < 3; pages++ {
+		options, err := ParseListOptions(query, "v1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		page, meta, err := Page(jobs, options, "v1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, job := range page.([]sippyprocessingv1.JobResult) {
+			names = append(names, job.Name)
+		}
+		if len(meta.NextCursor) == 0 {
+			break
+		}
+		query.Set("cursor", meta.NextCursor)
+	}
+	if !reflect.DeepEqual(names, []string{"a", "b", "c"}) {
+		t.Errorf("expected every job once, got %v", names)
+	}
+
+	if _, err := ParseListOptions(query, "v2"); err == nil {
+		t.Errorf("expected a cursor from another version to be rejected")
+	}
+	query.Set("offset", "1")
+	if _, err := ParseListOptions(query, "v1"); err == nil {
+		t.Errorf("expected offset and cursor together to be rejected")
+	}
+}
+
+func TestFilterTestReport(t *testing.T) {
+	report := sippyprocessingv1.TestReport{
+		ByTest: []sippyprocessingv1.FailingTestResult{{TestName: "a"}},
+		ByJob: []sippyprocessingv1.JobResult{
+			{Name: "job-aws", Variants: []string{"aws"}},
+			{Name: "job-gcp", Variants: []string{"gcp"}},
+		},
+		ByVariant: []sippyprocessingv1.VariantResults{{VariantName: "aws"}, {VariantName: "gcp"}},
+	}
+
+	filtered := FilterTestReport(report, ListOptions{Variant: "aws"})
+	if len(filtered.ByTest) != 1 {
+		t.Errorf("expected the tests to be left alone, got %v", filtered.ByTest)
+	}
+	if len(filtered.ByJob) != 1 || filtered.ByJob[0].Name != "job-aws" {
+		t.Errorf("expected only the aws job, got %v", filtered.ByJob)
+	}
+	if len(filtered.ByVariant) != 1 || filtered.ByVariant[0].VariantName != "aws" {
+		t.Errorf("expected only the aws variant, got %v", filtered.ByVariant)
+	}
+}
+
+func TestFilterConvertedTestReport(t *testing.T) {
+	rawData := testgridanalysisapi.RawData{JobResults: map[string]testgridanalysisapi.RawJobResult{}}
+	for _, jobName := range []string{"job-aws", "job-aws-upgrade", "job-gcp"} {
+		runURL := "https://prow/" + jobName + "/1"
+		rawData.JobResults[jobName] = testgridanalysisapi.RawJobResult{
+			JobName: jobName,
+			JobRunResults: map[string]testgridanalysisapi.RawJobRunResult{
+				runURL: {Job: jobName, JobRunURL: runURL, Succeeded: true},
+			},
+			TestResults: map[string]testgridanalysisapi.RawTestResult{},
+		}
+	}
+	variantManager, err := testidentification.NewConfigVariantManager(testidentification.VariantConfig{
+		Variants: []testidentification.VariantDefinition{
+			{Name: "aws", Include: []string{"aws"}},
+			{Name: "gcp", Include: []string{"gcp"}},
+			{Name: "upgrade", Include: []string{"upgrade"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := testreportconversion.PrepareTestReport("4.8", rawData, variantManager, testidentification.DefaultTestOwnership(),
+		buganalysis.NewNoOpBugCache(), "4.8", 1, 100, 7, nil, time.Now(), 10)
+
+	jobNames := func(jobs []sippyprocessingv1.JobResult) []string {
+		names := []string{}
+		for _, job := range jobs {
+			names = append(names, job.Name)
+		}
+		sort.Strings(names)
+		return names
+	}
+	filtered := FilterTestReport(report, ListOptions{Variant: "aws"})
+	if names := jobNames(filtered.ByJob); !reflect.DeepEqual(names, []string{"job-aws", "job-aws-upgrade"}) {
+		t.Errorf("expected the jobs of the aws variant, got %v", names)
+	}
+	filtered = FilterTestReport(report, ListOptions{Variant: "upgrade"})
+	if names := jobNames(filtered.ByJob); !reflect.DeepEqual(names, []string{"job-aws-upgrade"}) {
+		t.Errorf("expected the jobs of the upgrade variant, got %v", names)
+	}
+	if jobs := Jobs(filtered.ByJob, sippyprocessingv1.TestReport{}); len(jobs) != 1 || !reflect.DeepEqual(jobs[0].Variants, []string{"aws", "upgrade"}) {
+		t.Errorf("expected the API job to list its variants, got %v", jobs)
+	}
+}
+
+func TestParseFilterOptions(t *testing.T) {
+	options, err := ParseFilterOptions(url.Values{"name": []string{"^a"}, "minRuns": []string{"3"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if options.Name == nil || options.MinRuns != 3 {
+		t.Errorf("expected the filters to be read, got %+v", options)
+	}
+
+	for _, param := range []string{"sort", "order", "limit", "offset", "cursor"} {
+		if _, err := ParseFilterOptions(url.Values{param: []string{"1"}}); err == nil {
+			t.Errorf("expected %s to be rejected", param)
+		}
+	}
+}
+
+func TestCanFilter(t *testing.T) {
+	tests := []struct {
+		results   interface{}
+		param     string
+		canFilter bool
+	}{
+		{results: []sippyprocessingv1.FailingTestResult{}, param: "component", canFilter: true},
+		{results: []sippyprocessingv1.FailingTestResult{}, param: "variant", canFilter: false},
+		{results: []sippyprocessingv1.JobResult{}, param: "variant", canFilter: true},
+		{results: []sippyprocessingv1.JobResult{}, param: "component", canFilter: false},
+		{results: []sippyprocessingv1.VariantResults{}, param: "minRuns", canFilter: true},
+		{results: []sippyprocessingv1.VariantResults{}, param: "sort", canFilter: false},
+	}
+	for _, tc := range tests {
+		if canFilter := CanFilter(tc.results, tc.param); canFilter != tc.canFilter {
+			t.Errorf("expected CanFilter(%T, %s) to be %v", tc.results, tc.param, tc.canFilter)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	testgridv1 "github.com/openshift/sippy/pkg/apis/testgrid/v1"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testgridconversion"
+	"k8s.io/klog"
+)
+
+// PrintTestHistoryReport writes the day by day results of the requested tests across every column of the testgrid data.
+func PrintTestHistoryReport(w http.ResponseWriter, release string, options testgridconversion.TestHistoryOptions, testGridJobDetails []testgridv1.JobDetails, lastUpdateTime time.Time) {
+	response := sippyv1.TestHistoryReport{
+		Release:        release,
+		Tests:          options.ComputeTestHistory(testGridJobDetails),
+		LastUpdateTime: lastUpdateTime,
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(response); err != nil {
+		klog.Errorf("unable to render json %v", err)
+	}
+}
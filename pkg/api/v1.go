@@ -0,0 +1,102 @@
+package api
+
+import (
+	"sort"
+
+	bugsv1 "github.com/openshift/sippy/pkg/apis/bugs/v1"
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/util"
+)
+
+// This file converts reports into the resources of /api/v1.  Lists are never nil so that they are always JSON arrays.
+
+// Jobs converts the jobs of a report, like a page of its ByJob, keeping their order.
+func Jobs(jobs []sippyprocessingv1.JobResult, prevReport sippyprocessingv1.TestReport) []sippyv1.Job {
+	jobPassRate := func(job sippyprocessingv1.JobResult) sippyv1.PassRate {
+		return sippyv1.PassRate{
+			Percentage:          job.PassPercentage,
+			ProjectedPercentage: job.PassPercentageWithoutInfrastructureFailures,
+			Runs:                job.Successes + job.Failures,
+		}
+	}
+
+	ret := []sippyv1.Job{}
+	for _, job := range jobs {
+		apiJob := sippyv1.Job{
+			Name:           job.Name,
+			Variants:       nonNilStrings(job.Variants),
+			TestGridURL:    job.TestGridUrl,
+			PassRates:      map[string]sippyv1.PassRate{"latest": jobPassRate(job)},
+			Bugs:           nonNilBugs(job.BugList),
+			AssociatedBugs: nonNilBugs(job.AssociatedBugList),
+		}
+		if prev := util.FindJobResultForJobName(job.Name, prevReport.ByJob); prev != nil {
+			apiJob.PassRates["prev"] = jobPassRate(*prev)
+		}
+		ret = append(ret, apiJob)
+	}
+	return ret
+}
+
+// Tests converts the tests of a report, like a page of its ByTest, keeping their order.  The tests link to their
+// failures in the ci-search instance at ciSearchURL.
+func Tests(tests []sippyprocessingv1.FailingTestResult, prevReport sippyprocessingv1.TestReport, ciSearchURL string) []sippyv1.FailingTestBug {
+	ret := []sippyv1.FailingTestBug{}
+	for _, test := range tests {
+		ret = append(ret, failingTestBug(test, util.FindFailedTestResult(test.TestName, prevReport.ByTest), ciSearchURL))
+	}
+	return ret
+}
+
+// Variants converts the variants of a report, like a page of its ByVariant, keeping their order.
+func Variants(variants []sippyprocessingv1.VariantResults, prevReport sippyprocessingv1.TestReport) []sippyv1.Variant {
+	jobs := map[string][]string{}
+	for _, variant := range variants {
+		jobs[variant.VariantName] = []string{}
+		for _, job := range variant.JobResults {
+			jobs[variant.VariantName] = append(jobs[variant.VariantName], job.Name)
+		}
+	}
+
+	ret := []sippyv1.Variant{}
+	for _, summary := range summaryJobsByVariant(sippyprocessingv1.TestReport{ByVariant: variants}, prevReport) {
+		ret = append(ret, sippyv1.Variant{
+			Name:      summary.Variant,
+			PassRates: summary.PassRates,
+			Jobs:      jobs[summary.Variant],
+		})
+	}
+	return ret
+}
+
+// Bugs returns the bugs that match failing tests, most failures first.
+func Bugs(report sippyprocessingv1.TestReport) []bugsv1.Bug {
+	return nonNilBugs(report.BugsByFailureCount)
+}
+
+// Components returns the lowest job pass rate that each bugzilla component causes, lowest first.
+func Components(report, prevReport sippyprocessingv1.TestReport) []sippyv1.MinimumPassRatesByComponent {
+	ret := append([]sippyv1.MinimumPassRatesByComponent{}, minimumJobPassRateByBugzillaComponent(report, prevReport)...)
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].PassRates["latest"].Percentage != ret[j].PassRates["latest"].Percentage {
+			return ret[i].PassRates["latest"].Percentage < ret[j].PassRates["latest"].Percentage
+		}
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
+func nonNilBugs(bugs []bugsv1.Bug) []bugsv1.Bug {
+	if bugs == nil {
+		return []bugsv1.Bug{}
+	}
+	return bugs
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -1,22 +1,41 @@
 package v1
 
-import "time"
+import (
+	"strconv"
+	"time"
+)
 
-// Bug is used to represent bugs in some serialized content.  It also tracks some additional metadata.
-type Bug struct {
-	BugzillaBug  `json:",inline"`
-	Url          string `json:"url"`
-	FailureCount int    `json:"failureCount,omitempty"`
-	FlakeCount   int    `json:"flakeCount,omitempty"`
-}
+// the bug trackers that bugs are filed in
+const (
+	TrackerBugzilla = "bugzilla"
+	TrackerJira     = "jira"
+)
 
-// BugzillaBug matches the bugzilla API.  We cannot change this and should consider writing a converter instead of having
-// a serialization we don't own.
-type BugzillaBug struct {
-	ID             int64     `json:"id"`
+// Bug is used to represent bugs in some serialized content.  It is the same for every bug tracker and source of bugs,
+// and also tracks some additional metadata.
+type Bug struct {
+	// ID is the numeric ID of the bug in its tracker
+	ID int64 `json:"id"`
+	// Key is how the bug is referred to in its tracker, like OCPBUGS-123.  If it is empty, the bug is referred to by ID.
+	Key            string    `json:"key,omitempty"`
 	Status         string    `json:"status"`
 	LastChangeTime time.Time `json:"last_change_time"`
 	Summary        string    `json:"summary"`
 	TargetRelease  []string  `json:"target_release"`
 	Component      []string  `json:"component"`
+	// Tracker is the bug tracker the bug is filed in, like bugzilla or jira
+	Tracker string `json:"tracker,omitempty"`
+	// Source is where sippy found the bug, like ci-search, jira, or file
+	Source       string `json:"source,omitempty"`
+	Url          string `json:"url"`
+	FailureCount int    `json:"failureCount,omitempty"`
+	FlakeCount   int    `json:"flakeCount,omitempty"`
+}
+
+// Name is how the bug is referred to in its tracker: its key, or its ID if it has no key.
+func (b Bug) Name() string {
+	if len(b.Key) > 0 {
+		return b.Key
+	}
+	return strconv.FormatInt(b.ID, 10)
 }
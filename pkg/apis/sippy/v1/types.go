@@ -1,6 +1,10 @@
 package v1
 
-import bugsv1 "github.com/openshift/sippy/pkg/apis/bugs/v1"
+import (
+	"time"
+
+	bugsv1 "github.com/openshift/sippy/pkg/apis/bugs/v1"
+)
 
 // PassRate describes statistics on a pass rate
 type PassRate struct {
@@ -53,9 +57,16 @@ type FailingTestBug struct {
 	Name      string              `json:"name"`
 	Url       string              `json:"url"`
 	PassRates map[string]PassRate `json:"passRates"`
-	Bugs      []bugsv1.Bug        `json:"bugs,omitempty"`
+	// Classification is Stable, Flaky, PermanentlyBroken, or NewlyBroken
+	Classification string `json:"classification,omitempty"`
+	// Component and Team own the test
+	Component string       `json:"component,omitempty"`
+	Team      string       `json:"team,omitempty"`
+	Bugs      []bugsv1.Bug `json:"bugs,omitempty"`
 	// AssociatedBugs are bugs that match the test/job, but do not match the target release
 	AssociatedBugs []bugsv1.Bug `json:"associatedBugs,omitempty"`
+	// BugLookupFailed is set when the bugs of the test could not be looked up, so Bugs may be missing bugs
+	BugLookupFailed bool `json:"bugLookupFailed,omitempty"`
 }
 
 // JobSummaryVariant describes a single variant and its associated jobs, their pass rates, and failing tests
@@ -69,4 +80,392 @@ type FailureGroup struct {
 	Job          string `json:"job"`
 	Url          string `json:"url"`
 	TestFailures int    `json:"testFailures"`
+	// Outcome is the classification of the run, like TestFailure or InfrastructureFailure
+	Outcome string `json:"outcome"`
+}
+
+// RefreshStatus describes when the server last refreshed its reports and last fetched testgrid data, and whether the
+// most recent attempts failed.  Times are nil if the server has never done the work.
+type RefreshStatus struct {
+	LastRefreshAttempt    *time.Time `json:"lastRefreshAttempt,omitempty"`
+	LastSuccessfulRefresh *time.Time `json:"lastSuccessfulRefresh,omitempty"`
+	// LastRefreshError is empty if the most recent refresh succeeded
+	LastRefreshError string `json:"lastRefreshError,omitempty"`
+
+	LastFetchAttempt    *time.Time `json:"lastFetchAttempt,omitempty"`
+	LastSuccessfulFetch *time.Time `json:"lastSuccessfulFetch,omitempty"`
+	// LastFetchError is empty if the most recent fetch succeeded
+	LastFetchError string `json:"lastFetchError,omitempty"`
+
+	// NextScheduledRefresh is nil if periodic refresh is disabled
+	NextScheduledRefresh *time.Time `json:"nextScheduledRefresh,omitempty"`
+}
+
+type RefreshJobState string
+
+const (
+	RefreshJobPending   RefreshJobState = "Pending"
+	RefreshJobRunning   RefreshJobState = "Running"
+	RefreshJobSucceeded RefreshJobState = "Succeeded"
+	RefreshJobFailed    RefreshJobState = "Failed"
+)
+
+// RefreshJob describes a requested refresh of the server's reports.  Requests made while a refresh is still pending share
+// that refresh, and so share its ID.
+type RefreshJob struct {
+	ID    string          `json:"id"`
+	State RefreshJobState `json:"state"`
+	// Fetch is true if testgrid data is fetched before the reports are rebuilt
+	Fetch       bool       `json:"fetch"`
+	RequestTime time.Time  `json:"requestTime"`
+	StartTime   *time.Time `json:"startTime,omitempty"`
+	EndTime     *time.Time `json:"endTime,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// Snapshot describes a stored copy of the reports for one release as they were computed at Timestamp.
+type Snapshot struct {
+	Release string `json:"release"`
+	// ID identifies the snapshot within its release.  Pass it as ?snapshot= to view the snapshot instead of the live report.
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	// Size is the compressed size on disk in bytes
+	Size int64 `json:"size"`
+}
+
+// TestHistory describes the results of a single test, day by day, over all the data sippy has for a release.
+type TestHistory struct {
+	TestName string `json:"testName"`
+	// Days are sorted from oldest to newest and only include days the test ran
+	Days []TestDayResult `json:"days"`
+	// ByJob holds the same history for every job the test ran in, keyed by job name
+	ByJob map[string][]TestDayResult `json:"byJob,omitempty"`
+	// ByVariant holds the same history for every variant the test ran in, keyed by variant name
+	ByVariant map[string][]TestDayResult `json:"byVariant,omitempty"`
+}
+
+// TestDayResult counts the results of a test on a single day (UTC).  As everywhere else in sippy, flakes are also counted
+// as successes.
+type TestDayResult struct {
+	// Date is formatted as YYYY-MM-DD
+	Date           string  `json:"date"`
+	Successes      int     `json:"successes"`
+	Failures       int     `json:"failures"`
+	Flakes         int     `json:"flakes"`
+	PassPercentage float64 `json:"passPercentage"`
+}
+
+type RegressionKind string
+
+const (
+	TestRegression    RegressionKind = "Test"
+	JobRegression     RegressionKind = "Job"
+	VariantRegression RegressionKind = "Variant"
+)
+
+// PassRateChange describes a statistically significant change in the pass rate of a test, job, or variant between the
+// previous period and the current period.
+type PassRateChange struct {
+	Kind RegressionKind `json:"kind"`
+	Name string         `json:"name"`
+	// PassRates are keyed by "latest" and "prev", as they are everywhere else
+	PassRates map[string]PassRate `json:"passRates"`
+	// PValue is the two-sided p-value of Fisher's exact test on the passes and failures of the two periods
+	PValue float64 `json:"pValue"`
+}
+
+// RegressionReport lists the significant changes in pass rates between two periods, most significant first.
+type RegressionReport struct {
+	// SignificanceLevel is the false discovery rate used to decide which changes are significant
+	SignificanceLevel float64          `json:"significanceLevel"`
+	Regressions       []PassRateChange `json:"regressions"`
+	Improvements      []PassRateChange `json:"improvements"`
+}
+
+// VariantMatrix compares the pass rates of combinations of variants.  Rows and Columns name the variant dimensions that
+// identify the rows and columns, and every cell has one value for each of them.  Dimensions that are neither rows nor
+// columns are aggregated.
+type VariantMatrix struct {
+	Rows         []string            `json:"rows"`
+	Columns      []string            `json:"columns"`
+	RowValues    [][]string          `json:"rowValues"`
+	ColumnValues [][]string          `json:"columnValues"`
+	Cells        []VariantMatrixCell `json:"cells"`
+}
+
+// VariantMatrixCell holds the results of the jobs in one combination of variants.
+type VariantMatrixCell struct {
+	Row                  []string `json:"row"`
+	Column               []string `json:"column"`
+	JobRunSuccesses      int      `json:"jobRunSuccesses"`
+	JobRunFailures       int      `json:"jobRunFailures"`
+	JobRunPassPercentage float64  `json:"jobRunPassPercentage"`
+	Jobs                 []string `json:"jobs"`
+	// FailingTests are the tests with the lowest pass rates across the jobs, lowest first
+	FailingTests []VariantMatrixTest `json:"failingTests"`
+}
+
+// VariantMatrixTest is the pass rate of a test across the jobs in a cell.
+type VariantMatrixTest struct {
+	Name           string  `json:"name"`
+	PassPercentage float64 `json:"passPercentage"`
+	Runs           int     `json:"runs"`
+}
+
+// ComponentReport is the health of one bugzilla component: the failing tests it owns, the jobs that its failures
+// break, its open bugs, and the install, upgrade, and final health of its operators.  Pass rates are keyed by latest
+// and prev.
+type ComponentReport struct {
+	Release   string `json:"release"`
+	Component string `json:"component"`
+	// Teams own the tests of the component
+	Teams []string `json:"teams"`
+	// FailingTests are the tests of the component that failed, lowest pass rate first
+	FailingTests []FailingTestBug `json:"failingTests"`
+	// BrokenJobs are the jobs that failed because of the component, highest fail rate first
+	BrokenJobs []ComponentJob `json:"brokenJobs"`
+	// Bugs are the open bugs of the component that match failing tests
+	Bugs      []bugsv1.Bug        `json:"bugs"`
+	Operators []ComponentOperator `json:"operators"`
+}
+
+// ComponentJob is a job that failed because of the tests of a component.
+type ComponentJob struct {
+	Name string `json:"name"`
+	// FailPercentages are the percentages of job runs that failed because of the component, keyed by latest and prev
+	FailPercentages map[string]float64 `json:"failPercentages"`
+	Runs            int                `json:"runs"`
+	RunsFailed      int                `json:"runsFailed"`
+	// FailingTests are the tests of the component that failed in the job
+	FailingTests []string `json:"failingTests"`
+}
+
+// ComponentOperator holds the pass rates of the operator tests of an operator that belongs to a component.  Tests that
+// did not run are left out.
+type ComponentOperator struct {
+	Name    string              `json:"name"`
+	Install map[string]PassRate `json:"install,omitempty"`
+	Upgrade map[string]PassRate `json:"upgrade,omitempty"`
+	Health  map[string]PassRate `json:"health,omitempty"`
+}
+
+// Error is the body of every error response of the JSON API.
+type Error struct {
+	// Code is the HTTP status code
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+// Report summarizes one release for /json.  Pass rates are keyed by latest and prev.
+type Report struct {
+	FailureGroupings               *FailureGroups                `json:"failureGroupings"`
+	JobPassRateByVariant           []JobSummaryVariant           `json:"jobPassRateByVariant"`
+	TopFailingTestsWithoutBug      []FailingTestBug              `json:"topFailingTestsWithoutBug"`
+	TopFailingTestsWithBug         []FailingTestBug              `json:"topFailingTestsWithBug"`
+	JobPassRatesByName             []PassRatesByJobName          `json:"jobPassRatesByName"`
+	MinimumJobPassRatesByComponent []MinimumPassRatesByComponent `json:"minimumJobPassRatesByComponent"`
+	CanaryTestFailures             []CanaryTestFailInstance      `json:"canaryTestFailures"`
+	JobRunsWithFailureGroups       []FailureGroup                `json:"jobRunsWithFailureGroups"`
+	FailureClusters                []FailureCluster              `json:"failureClusters"`
+	TestImpactingBugs              []bugsv1.Bug                  `json:"testImpactingBugs"`
+	PassRateChanges                RegressionReport              `json:"passRateChanges"`
+}
+
+// FailureCluster is a set of tests that repeatedly fail in the same job runs, which usually means they share a root cause.
+type FailureCluster struct {
+	// TestNames are the tests in the cluster, sorted by name
+	TestNames []string `json:"testNames"`
+	// Support is the number of job runs in which every test in the cluster failed
+	Support int `json:"support"`
+	// Confidence is the lowest fraction, of any test in the cluster, of the runs that test failed in which every other
+	// test in the cluster failed too.
+	Confidence float64  `json:"confidence"`
+	Jobs       []string `json:"jobs"`
+	Variants   []string `json:"variants"`
+	// ExampleJobRunURLs are some of the job runs in which every test in the cluster failed
+	ExampleJobRunURLs []string `json:"exampleJobRunURLs"`
+}
+
+// JobGrid holds the result of every run of every job for the jobs grid.
+type JobGrid struct {
+	Jobs           []JobGridJob `json:"jobs"`
+	LastUpdateTime time.Time    `json:"last_update_time"`
+}
+
+// JobGridJob holds the results of the runs of one job, oldest last.  Results are one letter codes: S success, R running,
+// N infrastructure failure, I install failure, U upgrade failure, F test failure, n no setup results, f unknown failure.
+type JobGridJob struct {
+	Name       string   `json:"name"`
+	Timestamps []int    `json:"timestamps"`
+	Results    []string `json:"results"`
+	BuildIDs   []string `json:"build_ids"`
+	// RunURLs are the prow URLs of the runs, which identify them to /jobrun
+	RunURLs     []string `json:"run_urls"`
+	TestGridURL string   `json:"testgrid_url"`
+}
+
+// TestHistoryReport holds the history of the tests that were asked for.
+type TestHistoryReport struct {
+	Release        string        `json:"release"`
+	Tests          []TestHistory `json:"tests"`
+	LastUpdateTime time.Time     `json:"lastUpdateTime"`
+}
+
+// Release is a report that sippy serves, named by its ?release=.
+type Release struct {
+	Name            string   `json:"name"`
+	Dashboards      []string `json:"dashboards"`
+	BugzillaRelease string   `json:"bugzillaRelease,omitempty"`
+	// Timestamp is when the data of the report was last updated.  It is missing until the first report is built.
+	Timestamp        *time.Time `json:"timestamp,omitempty"`
+	AnalysisWarnings []string   `json:"analysisWarnings"`
+}
+
+type ReleaseList struct {
+	Items []Release `json:"items"`
+}
+
+// Job is the pass rate of a job, keyed by latest and prev.
+type Job struct {
+	Name           string              `json:"name"`
+	Variants       []string            `json:"variants"`
+	TestGridURL    string              `json:"testGridURL"`
+	PassRates      map[string]PassRate `json:"passRates"`
+	Bugs           []bugsv1.Bug        `json:"bugs"`
+	AssociatedBugs []bugsv1.Bug        `json:"associatedBugs"`
+}
+
+type JobList struct {
+	Release  string `json:"release"`
+	ListMeta `json:",inline"`
+	Items    []Job `json:"items"`
+}
+
+// ListMeta describes the page of a list that was filtered, sorted, and paged by the query parameters.
+type ListMeta struct {
+	// Total is the number of items that matched the filters, on every page
+	Total  int `json:"total"`
+	Offset int `json:"offset"`
+	// Limit is the largest number of items on a page, or 0 if there is a single page
+	Limit int `json:"limit,omitempty"`
+	// NextCursor resumes the list with ?cursor=.  It is missing on the last page.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// JobRun is the result of one run of a job.
+type JobRun struct {
+	Job       string    `json:"job"`
+	URL       string    `json:"url"`
+	Timestamp time.Time `json:"timestamp"`
+	// Classification is the first step of the run that failed, like JobRunReport.Classification
+	Classification string `json:"classification"`
+	// ClassificationReason explains the classification in words
+	ClassificationReason string   `json:"classificationReason"`
+	Succeeded            bool     `json:"succeeded"`
+	Failed               bool     `json:"failed"`
+	TestFailures         int      `json:"testFailures"`
+	FailedTestNames      []string `json:"failedTestNames"`
+}
+
+// JobRunList holds the runs of the jobs of a release, newest first within each job unless sorted otherwise.
+type JobRunList struct {
+	Release  string `json:"release"`
+	ListMeta `json:",inline"`
+	// AnalysisWarnings are the problems found while processing the runs
+	AnalysisWarnings []string `json:"analysisWarnings"`
+	Items            []JobRun `json:"items"`
+}
+
+// TestList holds the tests of a release, lowest pass rate first unless sorted otherwise.
+type TestList struct {
+	Release  string `json:"release"`
+	ListMeta `json:",inline"`
+	Items    []FailingTestBug `json:"items"`
+}
+
+// Variant is the pass rate of the jobs of a variant, keyed by latest and prev.
+type Variant struct {
+	Name      string              `json:"name"`
+	PassRates map[string]PassRate `json:"passRates"`
+	Jobs      []string            `json:"jobs"`
+}
+
+type VariantList struct {
+	Release  string `json:"release"`
+	ListMeta `json:",inline"`
+	Items    []Variant `json:"items"`
+}
+
+// BugList holds the bugs that match failing tests, most failures first.
+type BugList struct {
+	Release string       `json:"release"`
+	Items   []bugsv1.Bug `json:"items"`
+}
+
+// ComponentList holds the lowest job pass rate that each bugzilla component causes.
+type ComponentList struct {
+	Release string                        `json:"release"`
+	Items   []MinimumPassRatesByComponent `json:"items"`
+}
+
+// JobRunReport is everything sippy knows about one run of a job.
+type JobRunReport struct {
+	Release     string    `json:"release"`
+	Job         string    `json:"job"`
+	URL         string    `json:"url"`
+	TestGridURL string    `json:"testGridURL"`
+	BuildID     string    `json:"buildID"`
+	Timestamp   time.Time `json:"timestamp"`
+	// Classification is the first step of the run that failed: Succeeded, Running, InfrastructureFailure,
+	// InstallFailure, UpgradeFailure, TestFailure, NoSetupResults, or UnknownFailure.
+	Classification string `json:"classification"`
+	// ClassificationReason explains the classification in words
+	ClassificationReason string `json:"classificationReason"`
+	Succeeded            bool   `json:"succeeded"`
+	Failed               bool   `json:"failed"`
+	// SetupStatus, OpenShiftTestsStatus, and the upgrade statuses are Success, Failure, or missing if the run did not
+	// report them.
+	SetupStatus                        string `json:"setupStatus,omitempty"`
+	OpenShiftTestsStatus               string `json:"openShiftTestsStatus,omitempty"`
+	UpgradeStarted                     bool   `json:"upgradeStarted"`
+	UpgradeForOperatorsStatus          string `json:"upgradeForOperatorsStatus,omitempty"`
+	UpgradeForMachineConfigPoolsStatus string `json:"upgradeForMachineConfigPoolsStatus,omitempty"`
+	// Operators are the final states of the operators, as the run reported them
+	Operators []JobRunOperator `json:"operators"`
+	// FailedTests are the tests that failed in the run, with their pass rates across all jobs
+	FailedTests []FailingTestBug `json:"failedTests"`
+	// Bugs are the bugs of the job and of the failed tests
+	Bugs []bugsv1.Bug `json:"bugs"`
+}
+
+type JobRunOperator struct {
+	Name string `json:"name"`
+	// State is Success or Failure
+	State string `json:"state"`
+}
+
+// Alert is a rule of the alerting config that matches a top level indicator, test, job, or variant of a release.
+type Alert struct {
+	// Key identifies the alert.  Every evaluation of the same rule against the same subject has the same key, so an
+	// alert is only sent when it starts firing and when it is resolved.
+	Key      string `json:"key"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity,omitempty"`
+	Release  string `json:"release"`
+	// Kind is indicator, test, job, or variant, and Subject is the name of the indicator, test, job, or variant.
+	Kind    string `json:"kind"`
+	Subject string `json:"subject"`
+	// State is firing or resolved
+	State   string `json:"state"`
+	Summary string `json:"summary"`
+	// Value is the value of the field of the rule when the alert was last evaluated, and Runs are the runs it is
+	// based on.
+	Value    float64    `json:"value"`
+	Runs     int        `json:"runs"`
+	StartsAt time.Time  `json:"startsAt"`
+	EndsAt   *time.Time `json:"endsAt,omitempty"`
+}
+
+type AlertList struct {
+	Alerts []Alert `json:"alerts"`
 }
@@ -20,18 +20,23 @@ type TestReport struct {
 	// individual jobs or variants.
 	TopLevelIndicators TopLevelIndicators `json:"topLevelIndicators"`
 
-	// ByVariant organizes jobs and tests by variant, sorted by job pass rate from low to high
-	ByVariant []VariantResults `json:"byPlatform`
+	// ByVariant organizes jobs and tests by variant, sorted by job pass rate from low to high.  It has always been
+	// serialized under its field name, and /json consumers depend on that.
+	ByVariant []VariantResults `json:"ByVariant"`
 
 	// ByTest organizes every test ordered by pass rate from low to high.
 	ByTest []FailingTestResult `json:"byTest"`
 
 	FailureGroups []JobRunResult `json:"failureGroups"`
 
-	// ByJob are all the available job results by their job, sorted from low to high pass rate
-	ByJob []JobResult `json:"frequentJobResults"`
+	// FailureClusters are sets of tests that repeatedly fail in the same job runs, sorted from most to least support
+	FailureClusters []FailureCluster `json:"failureClusters"`
+
+	// ByJob are all the available job results by their job, sorted from low to high pass rate.  ByJob and
+	// FrequentJobResults used to share a json tag, which left both out of /json, so they are still left out.
+	ByJob []JobResult `json:"-"`
 	// FrequentJobResults are jobresults for jobs that run more than 1.5 times per day
-	FrequentJobResults []JobResult `json:"frequentJobResults"`
+	FrequentJobResults []JobResult `json:"-"`
 	// InfrequentJobResults are jobresults for jobs that run less than 1.5 times per day
 	InfrequentJobResults []JobResult `json:"infrequentJobResults"`
 
@@ -98,6 +103,35 @@ type FailingTestResult struct {
 
 	// JobResults for all jobs that failed on this test ordered by the pass percentage of the test on a given job
 	JobResults []FailingTestJobResult `json:"jobResults"`
+
+	// Classification describes how the test fails based on the order of its passes and failures, not just how often.
+	// Each job is classified on its own runs, and the test takes the most severe classification of any of its jobs.
+	Classification TestClassification `json:"classification"`
+}
+
+// TestClassification distinguishes tests that fail intermittently from tests that fail consistently.
+type TestClassification string
+
+const (
+	// TestStable tests pass, or failed in a single streak that has since been fixed.
+	TestStable TestClassification = "Stable"
+	// TestFlaky tests alternate between passing and failing, or only pass on retry.
+	TestFlaky TestClassification = "Flaky"
+	// TestPermanentlyBroken tests (almost) never pass.
+	TestPermanentlyBroken TestClassification = "PermanentlyBroken"
+	// TestNewlyBroken tests used to pass reliably, but have failed every run recently.
+	TestNewlyBroken TestClassification = "NewlyBroken"
+)
+
+// TestRunPattern summarizes the order of the passes and failures of a test, rather than just their counts.  Flakes count
+// as passes.
+type TestRunPattern struct {
+	// Transitions is the number of times consecutive runs of the test switched between passing and failing.
+	Transitions int `json:"transitions"`
+	// CurrentFailureStreak is the number of most recent runs that failed in a row.
+	CurrentFailureStreak int `json:"currentFailureStreak"`
+	// LongestFailureStreak is the largest number of runs that failed in a row.
+	LongestFailureStreak int `json:"longestFailureStreak"`
 }
 
 // FailingTestJobResult is a job summary for the number of runs failed by this
@@ -107,6 +141,10 @@ type FailingTestJobResult struct {
 	TestSuccesses  int     `json:"testSuccesses"`
 	PassPercentage float64 `json:"passPercentage"`
 	TestGridUrl    string  `json:"testGridUrl"`
+	// RunPattern is the pattern of the runs of the test in this job
+	RunPattern TestRunPattern `json:"runPattern"`
+	// Classification is the classification of the test from the runs of this job alone
+	Classification TestClassification `json:"classification"`
 }
 
 // TestResult is a reporting type, not an intermediate type.  It represents the complete view of a given test.  It should
@@ -117,6 +155,11 @@ type TestResult struct {
 	Failures       int     `json:"failures"`
 	Flakes         int     `json:"flakes"`
 	PassPercentage float64 `json:"passPercentage"`
+	// RunPattern is the pattern of the runs of the test.  When runs from several jobs are combined, transitions are
+	// summed and the streaks are the longest of any single job.
+	RunPattern TestRunPattern `json:"runPattern"`
+	// Owner is the component and team responsible for the test.
+	Owner TestOwner `json:"owner"`
 	// BugList shows all applicable bugs for the context.
 	// Inside of a release, only bugs matching the release are present.
 	// TODO Inside a particular job, only bugs matching the job are present.
@@ -124,6 +167,35 @@ type TestResult struct {
 	BugList []bugsv1.Bug `json:"bugList"`
 	// AssociatedBugList are bugs that match the test/job, but do not match the target release
 	AssociatedBugList []bugsv1.Bug `json:"associatedBugList"`
+	// BugLookupFailed is set when the bugs of the test could not be looked up, so BugList may be missing bugs.
+	BugLookupFailed bool `json:"bugLookupFailed,omitempty"`
+}
+
+// TestOwner is the component and team responsible for a test, and how they were determined.
+type TestOwner struct {
+	// Component is the bugzilla component of the test.  It is "Unknown" if no ownership source matched the test.
+	Component string `json:"component"`
+	Team      string `json:"team,omitempty"`
+	// Source is the ownership source that matched the test, like "override", "rule", "sig", or "operator", or
+	// "default" if none matched.
+	Source string `json:"source"`
+}
+
+// FailureCluster is a set of tests that repeatedly fail in the same job runs, which usually means they share a root cause.
+type FailureCluster struct {
+	// TestNames are the tests in the cluster, sorted by name
+	TestNames []string `json:"testNames"`
+	// Support is the number of job runs in which every test in the cluster failed
+	Support int `json:"support"`
+	// Confidence is the lowest fraction, of any test in the cluster, of the runs that test failed in which every other
+	// test in the cluster failed too.
+	Confidence float64 `json:"confidence"`
+	// Jobs are the jobs in which every test in the cluster failed together
+	Jobs []string `json:"jobs"`
+	// Variants are the variants of those jobs
+	Variants []string `json:"variants"`
+	// ExampleJobRunURLs are some of the job runs in which every test in the cluster failed
+	ExampleJobRunURLs []string `json:"exampleJobRunURLs"`
 }
 
 type JobRunResult struct {
@@ -134,8 +206,35 @@ type JobRunResult struct {
 	Failed             bool     `json:"failed"`
 	HasUnknownFailures bool     `json:"hasUnknownFailures"`
 	Succeeded          bool     `json:"succeeded"`
+	// Outcome is how the run ended, and OutcomeReason explains it in words
+	Outcome       JobRunOutcome `json:"outcome"`
+	OutcomeReason string        `json:"outcomeReason"`
 }
 
+// JobRunOutcome classifies how a job run ended.  A run has exactly one outcome.  When several steps of a run failed, the
+// outcome is the first of these that applies, which is the order the steps run in:
+//  1. Succeeded
+//  2. Running: the run has not finished
+//  3. InfrastructureFailure: the run failed before any operator reported its state, and setup did not succeed.  Jobs
+//     that are known not to have a setup step are never infrastructure failures.
+//  4. InstallFailure: setup failed after operators reported their state
+//  5. UpgradeFailure: the upgrade of the operators or of the machine config pools failed
+//  6. TestFailure: openshift-tests failed
+//  7. NoSetupResults: the run failed without a setup result, and no later step failed
+//  8. UnknownFailure: the run failed, but no step that sippy knows of did
+type JobRunOutcome string
+
+const (
+	JobRunSucceeded             JobRunOutcome = "Succeeded"
+	JobRunRunning               JobRunOutcome = "Running"
+	JobRunInfrastructureFailure JobRunOutcome = "InfrastructureFailure"
+	JobRunInstallFailure        JobRunOutcome = "InstallFailure"
+	JobRunUpgradeFailure        JobRunOutcome = "UpgradeFailure"
+	JobRunTestFailure           JobRunOutcome = "TestFailure"
+	JobRunNoSetupResults        JobRunOutcome = "NoSetupResults"
+	JobRunUnknownFailure        JobRunOutcome = "UnknownFailure"
+)
+
 type JobResult struct {
 	Name                                        string       `json:"name"`
 	Variant                                     string       `json:"platform"`
@@ -150,6 +249,8 @@ type JobResult struct {
 	BugList                                     []bugsv1.Bug `json:"bugList"`
 	// AssociatedBugList are bugs that match the test/job, but do not match the target release
 	AssociatedBugList []bugsv1.Bug `json:"associatedBugList"`
+	// Variants are the variants that the variant manager of the report identifies the job as
+	Variants []string `json:"variants"`
 
 	// TestResults holds entries for each test that is a part of this aggregation.  Each entry aggregates the results of all runs of a single test.  The array is sorted from lowest PassPercentage to highest PassPercentage
 	TestResults []TestResult `json:"results"`
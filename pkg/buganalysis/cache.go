@@ -2,18 +2,18 @@ package buganalysis
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
-	"net/url"
-	"regexp"
-	"regexp/syntax"
+	"io/ioutil"
+	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	bugsv1 "github.com/openshift/sippy/pkg/apis/bugs/v1"
-	"github.com/openshift/sippy/pkg/buganalysis/internal"
 	"github.com/openshift/sippy/pkg/util"
+	"github.com/openshift/sippy/pkg/util/sets"
 	"k8s.io/klog"
 )
 
@@ -26,6 +26,8 @@ type BugCache interface {
 	ListAssociatedBugs(release, variant, testName string) []bugsv1.Bug
 	UpdateForFailedTests(failedTestNames ...string) error
 	UpdateJobBlockers(jobNames ...string) error
+	// BugLookupFailed returns whether the last lookup of the bugs of the test failed, so that its bugs may be missing
+	BugLookupFailed(testName string) bool
 
 	Clear()
 	// LastUpdateError returns the last update error, if one exists
@@ -52,6 +54,9 @@ func (*noOpBugCache) UpdateForFailedTests(failedTestNames ...string) error {
 func (*noOpBugCache) UpdateJobBlockers(jobNames ...string) error {
 	return nil
 }
+func (*noOpBugCache) BugLookupFailed(testName string) bool {
+	return false
+}
 func (*noOpBugCache) Clear() {}
 func (*noOpBugCache) LastUpdateError() error {
 	return nil
@@ -61,119 +66,320 @@ func NewNoOpBugCache() BugCache {
 	return &noOpBugCache{}
 }
 
+// DefaultTTL is how long the bugs found for a test or job are used before they are looked up again.
+const DefaultTTL = time.Hour
+
+// maxUnusedAge is how long an entry that is no longer asked for, like a test that stopped failing, is kept.
+const maxUnusedAge = 7 * 24 * time.Hour
+
+// failedLookupInterval is how long after a failed lookup the entry is looked up again.  It is shorter than the TTL so
+// missing bugs are filled in soon, but keeps the many updates of one refresh from retrying a failing source every time.
+const failedLookupInterval = 5 * time.Minute
+
+// CacheOptions controls how bugs are cached and looked up.
+type CacheOptions struct {
+	// Filename is where the found bugs are persisted, so that they survive restarts.  If empty, they are not persisted.
+	Filename string
+	// TTL is how long the bugs of a test or job are used before they are looked up again.  If 0, DefaultTTL is used.
+	TTL time.Duration
+	// Workers is the maximum number of concurrent lookups
+	Workers int
+	// RequestInterval is the minimum time between the starts of two requests to the source, across all workers.  0 does
+	// not limit them.
+	RequestInterval time.Duration
+	// MaxRetries is the number of times a failed lookup is retried before its tests and jobs are recorded as failed
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry.  It doubles on every subsequent retry.
+	RetryBackoff time.Duration
+}
+
 type bugCache struct {
+	// source is where bugs are looked up
+	source  BugSource
+	options CacheOptions
+	limiter *rateLimiter
+	// now is replaced in tests
+	now func() time.Time
+
 	lock  sync.RWMutex
-	cache map[string][]bugsv1.Bug
+	cache map[string]*cacheEntry
 	// jobBlockers is indexed by getJobKey(jobName) and lists the bugs that are considered to be responsible for all failures on a job
-	jobBlockers     map[string][]bugsv1.Bug
-	lastUpdateError error
+	jobBlockers map[string]*cacheEntry
+	// lastUpdateErrors are the errors of the last update of each kind of entries that failed, so that a successful
+	// update of jobs does not hide that the tests are failing
+	lastUpdateErrors map[string]error
 }
 
-func NewBugCache() BugCache {
-	return &bugCache{
-		cache: map[string][]bugsv1.Bug{},
-	}
+// cacheEntry holds the bugs found for one test or job.  An entry with no bugs records that none were found, so that
+// they are not looked up again until the entry is stale.
+type cacheEntry struct {
+	Bugs []bugsv1.Bug `json:"bugs"`
+	// Fetched is when the bugs were looked up
+	Fetched time.Time `json:"fetched"`
+	// Requested is when an update last asked for the entry
+	Requested time.Time `json:"requested"`
+	// LookupFailed is set when the last lookup failed.  Bugs are then the ones found by an earlier lookup, if any.
+	LookupFailed bool `json:"lookupFailed,omitempty"`
+	// Attempted is when the last lookup was attempted, if it failed
+	Attempted time.Time `json:"attempted,omitempty"`
 }
 
-// updates a global variable with the bug mapping based on current failures.
-func (c *bugCache) UpdateForFailedTests(failedTestNames ...string) error {
-	c.lock.RLock()
-	newFailedTestNames := []string{}
-	for _, testName := range failedTestNames {
-		if _, found := c.cache[testName]; !found {
-			newFailedTestNames = append(newFailedTestNames, testName)
-		}
+// stale returns whether the bugs of the entry should be looked up again.
+func (e *cacheEntry) stale(now time.Time, ttl time.Duration) bool {
+	if e.LookupFailed {
+		return now.Sub(e.Attempted) >= failedLookupInterval
 	}
-	c.lock.RUnlock()
-	newBugs, lastUpdateError := findBugsForFailedTests(newFailedTestNames...)
+	return e.Fetched.IsZero() || now.Sub(e.Fetched) >= ttl
+}
 
-	c.lock.Lock()
-	defer c.lock.Unlock()
+// persistedCache is the content of the cache file.
+type persistedCache struct {
+	// Source is the name of the source the bugs were found in.  Entries found in another source are not loaded.
+	Source      string                 `json:"source"`
+	Tests       map[string]*cacheEntry `json:"tests"`
+	JobBlockers map[string]*cacheEntry `json:"jobBlockers"`
+}
 
-	for testName, bug := range newBugs {
-		c.cache[testName] = bug
+// NewBugCache returns a BugCache that looks up bugs in source.  The bugs of a test or job are looked up again once they
+// are older than the TTL; in the meantime, and when the lookup fails, the bugs found before are used.  If a Filename is
+// set, the entries are loaded from it and written back to it after every lookup, so that they survive restarts.  A
+// missing or unreadable file starts an empty cache.
+func NewBugCache(source BugSource, options CacheOptions) BugCache {
+	if options.TTL <= 0 {
+		options.TTL = DefaultTTL
+	}
+	if options.Workers < 1 {
+		options.Workers = 1
+	}
+	if options.MaxRetries < 0 {
+		options.MaxRetries = 0
+	}
+	c := &bugCache{
+		source:           source,
+		options:          options,
+		limiter:          &rateLimiter{interval: options.RequestInterval},
+		now:              time.Now,
+		cache:            map[string]*cacheEntry{},
+		jobBlockers:      map[string]*cacheEntry{},
+		lastUpdateErrors: map[string]error{},
+	}
+	if len(options.Filename) > 0 {
+		if err := c.load(); err != nil {
+			klog.Errorf("Starting with an empty bug cache: %v", err)
+		} else {
+			klog.Infof("Loaded %d test and %d job entries from bug cache %s", len(c.cache), len(c.jobBlockers), options.Filename)
+		}
 	}
-	c.lastUpdateError = lastUpdateError
-	return lastUpdateError
+	return c
 }
 
-func GetJobKey(jobName string) string {
-	return fmt.Sprintf("job=%v=all", jobName)
+func (c *bugCache) load() error {
+	content, err := ioutil.ReadFile(c.options.Filename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	persisted := persistedCache{}
+	if err := json.Unmarshal(content, &persisted); err != nil {
+		return fmt.Errorf("could not parse bug cache %s: %v", c.options.Filename, err)
+	}
+	if persisted.Source != c.source.Name() {
+		return fmt.Errorf("bug cache %s holds bugs from %q, not %s", c.options.Filename, persisted.Source, c.source.Name())
+	}
+	for key, entry := range persisted.Tests {
+		c.cache[key] = entry
+	}
+	for key, entry := range persisted.JobBlockers {
+		c.jobBlockers[key] = entry
+	}
+	return nil
 }
 
-// updates a global variable with the bug mapping based on current failures.
-func (c *bugCache) UpdateJobBlockers(jobNames ...string) error {
-	c.lock.RLock()
-	jobSearchStrings := []string{}
-	for _, jobName := range jobNames {
-		jobKey := GetJobKey(jobName)
-		if _, found := c.jobBlockers[jobKey]; !found {
-			jobSearchStrings = append(jobSearchStrings, jobKey)
+// save writes the entries to the cache file, dropping the ones that have not been asked for in maxUnusedAge.  The
+// caller must hold the lock.
+func (c *bugCache) save() error {
+	if len(c.options.Filename) == 0 {
+		return nil
+	}
+	cutoff := c.now().Add(-maxUnusedAge)
+	for _, entries := range []map[string]*cacheEntry{c.cache, c.jobBlockers} {
+		for key, entry := range entries {
+			if entry.Requested.Before(cutoff) {
+				delete(entries, key)
+			}
 		}
 	}
-	c.lock.RUnlock()
-	newBugs, lastUpdateError := findBugsForFailedTests(jobSearchStrings...)
+	content, err := json.Marshal(persistedCache{Source: c.source.Name(), Tests: c.cache, JobBlockers: c.jobBlockers})
+	if err != nil {
+		return err
+	}
+	if err := util.WriteFileAtomically(c.options.Filename, content); err != nil {
+		return fmt.Errorf("could not write bug cache %s: %v", c.options.Filename, err)
+	}
+	return nil
+}
 
-	c.lock.Lock()
-	defer c.lock.Unlock()
+// the kinds of entries, as LookupError.Kind names them
+const (
+	testEntries = "tests"
+	jobEntries  = "jobs"
+)
 
-	for testName, bug := range newBugs {
-		c.jobBlockers[testName] = bug
+// entries returns the entries of the kind.  Clear replaces them, so the caller must hold the lock and must not use them
+// after releasing it.
+func (c *bugCache) entries(kind string) map[string]*cacheEntry {
+	if kind == jobEntries {
+		return c.jobBlockers
 	}
-	c.lastUpdateError = lastUpdateError
-	return lastUpdateError
+	return c.cache
 }
 
-// finds bugs given the test names
-func findBugsForFailedTests(failedTestNames ...string) (map[string][]bugsv1.Bug, error) {
-	ret := map[string][]bugsv1.Bug{}
+// errEarlierLookupFailed is the error of entries whose lookup failed before the update and is not retried yet.
+var errEarlierLookupFailed = errors.New("an earlier lookup failed and is not retried yet")
+
+// update looks up the bugs of the keys of the kind that are missing or stale and stores the ones that were found.
+// Entries that could not be looked up keep their previous bugs, are marked as failed, and are looked up again on the
+// next update.  namesByKey maps the keys to the names of the tests or jobs.  It returns a LookupError of the names of
+// the keys whose last lookup failed, in this update or an earlier one, if any.
+func (c *bugCache) update(kind string, namesByKey map[string]string) *LookupError {
+	now := c.now()
+	keys := sets.StringKeySet(namesByKey).List()
 
-	var lastUpdateError error
-	batchTestNames := []string{}
-	for i, testName := range failedTestNames {
-		if _, found := ret[testName]; found {
-			continue
+	c.lock.Lock()
+	entries := c.entries(kind)
+	staleKeys := []string{}
+	for _, key := range keys {
+		entry, found := entries[key]
+		if !found {
+			entry = &cacheEntry{Bugs: []bugsv1.Bug{}}
+			entries[key] = entry
 		}
-		batchTestNames = append(batchTestNames, testName)
-		// we're going to lookup bugs for this test, so put an entry into the map.
-		// if we find a bug for this test, the entry will be replaced with the actual
-		// array of bugs.  if not, this serves as a placeholder so we know not to look
-		// it up again in the future.
-		ret[testName] = []bugsv1.Bug{}
-
-		// continue building our batch until we have a largish set to check
-		onLastItem := (i + 1) == len(failedTestNames)
-		if !onLastItem && len(batchTestNames) <= 50 {
-			continue
+		entry.Requested = now
+		if !found || entry.stale(now, c.options.TTL) {
+			staleKeys = append(staleKeys, key)
 		}
+	}
+	c.lock.Unlock()
+
+	var newBugs map[string][]bugsv1.Bug
+	var failedKeys []string
+	var err error
+	if len(staleKeys) > 0 {
+		newBugs, failedKeys, err = c.findBugs(staleKeys)
+	}
 
-		r, err := findBugs(batchTestNames)
-		for k, v := range r {
-			ret[k] = v
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// the cache may have been cleared during the lookup
+	entries = c.entries(kind)
+	if len(staleKeys) > 0 {
+		for key, bugs := range newBugs {
+			entry, found := entries[key]
+			if !found {
+				entry = &cacheEntry{Requested: now}
+				entries[key] = entry
+			}
+			entry.Bugs = bugs
+			entry.Fetched = now
+			entry.LookupFailed = false
+			entry.Attempted = time.Time{}
+		}
+		for _, key := range failedKeys {
+			entry, found := entries[key]
+			if !found {
+				entry = &cacheEntry{Bugs: []bugsv1.Bug{}, Requested: now}
+				entries[key] = entry
+			}
+			entry.LookupFailed = true
+			entry.Attempted = now
 		}
-		if err != nil {
-			lastUpdateError = err
+		if err := c.save(); err != nil {
+			klog.Error(err)
 		}
-		batchTestNames = []string{}
 	}
 
-	return ret, lastUpdateError
+	failedNames := []string{}
+	for _, key := range keys {
+		if entry, found := entries[key]; found && entry.LookupFailed {
+			failedNames = append(failedNames, namesByKey[key])
+		}
+	}
+	if len(failedNames) == 0 {
+		delete(c.lastUpdateErrors, kind)
+		return nil
+	}
+	sort.Strings(failedNames)
+	if err == nil {
+		err = errEarlierLookupFailed
+	}
+	lookupErr := &LookupError{Source: c.source.Name(), Kind: kind, Failed: failedNames, Err: err}
+	c.lastUpdateErrors[kind] = lookupErr
+	return lookupErr
+}
+
+// updates a global variable with the bug mapping based on current failures.
+func (c *bugCache) UpdateForFailedTests(failedTestNames ...string) error {
+	namesByKey := map[string]string{}
+	for _, testName := range failedTestNames {
+		namesByKey[testName] = testName
+	}
+	if err := c.update(testEntries, namesByKey); err != nil {
+		return err
+	}
+	return nil
+}
+
+func GetJobKey(jobName string) string {
+	return fmt.Sprintf("job=%v=all", jobName)
+}
+
+// updates a global variable with the bug mapping based on current failures.
+func (c *bugCache) UpdateJobBlockers(jobNames ...string) error {
+	namesByKey := map[string]string{}
+	for _, jobName := range jobNames {
+		namesByKey[GetJobKey(jobName)] = jobName
+	}
+	if err := c.update(jobEntries, namesByKey); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *bugCache) BugLookupFailed(testName string) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	entry, ok := c.cache[testName]
+	return ok && entry.LookupFailed
 }
 
 func (c *bugCache) Clear() {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
-	c.cache = map[string][]bugsv1.Bug{}
-	c.jobBlockers = map[string][]bugsv1.Bug{}
-	c.lastUpdateError = nil
+	c.cache = map[string]*cacheEntry{}
+	c.jobBlockers = map[string]*cacheEntry{}
+	c.lastUpdateErrors = map[string]error{}
+	if err := c.save(); err != nil {
+		klog.Error(err)
+	}
 }
 
+// LastUpdateError returns the error of the last update of the tests if it failed, otherwise of the last update of the
+// jobs.
 func (c *bugCache) LastUpdateError() error {
 	c.lock.RLock()
 	defer c.lock.RUnlock()
 
-	return c.lastUpdateError
+	for _, kind := range []string{testEntries, jobEntries} {
+		if err, ok := c.lastUpdateErrors[kind]; ok {
+			return err
+		}
+	}
+	return nil
 }
 
 func (c *bugCache) listBugsInternal(release, jobName, testName string, invertReleaseQuery bool) []bugsv1.Bug {
@@ -184,7 +390,7 @@ func (c *bugCache) listBugsInternal(release, jobName, testName string, invertRel
 
 	// first check if this job is covered by a job-blocking bug.  If so, all test
 	// failures are attributed to that bug instead of to individual test bugs.
-	bugList := c.jobBlockers[GetJobKey(jobName)]
+	bugList := c.jobBlockers[GetJobKey(jobName)].bugs()
 	for i := range bugList {
 		bug := bugList[i]
 		for _, r := range bug.TargetRelease {
@@ -198,7 +404,7 @@ func (c *bugCache) listBugsInternal(release, jobName, testName string, invertRel
 		return ret
 	}
 
-	bugList = c.cache[testName]
+	bugList = c.cache[testName].bugs()
 	for i := range bugList {
 		bug := bugList[i]
 		for _, r := range bug.TargetRelease {
@@ -224,63 +430,13 @@ func (c *bugCache) ListJobBlockingBugs(jobName string) []bugsv1.Bug {
 	c.lock.RLock()
 	defer c.lock.RUnlock()
 
-	return c.jobBlockers[GetJobKey(jobName)]
+	return c.jobBlockers[GetJobKey(jobName)].bugs()
 }
 
-func findBugs(testNames []string) (map[string][]bugsv1.Bug, error) {
-	searchResults := make(map[string][]bugsv1.Bug)
-
-	v := url.Values{}
-	v.Set("type", "bug")
-	v.Set("context", "-1")
-	for _, testName := range testNames {
-		testName = regexp.QuoteMeta(testName)
-		klog.V(4).Infof("Searching bugs for test name: %s\n", testName)
-		v.Add("search", testName)
-	}
-
-	//searchUrl:="https://search.apps.build01.ci.devcluster.openshift.com/search"
-	searchUrl := "https://search.ci.openshift.org/v2/search"
-	resp, err := http.PostForm(searchUrl, v)
-	if err != nil {
-		e := fmt.Errorf("error during bug search against %s: %w", searchUrl, err)
-		klog.Errorf(e.Error())
-		return searchResults, e
-	}
-	if resp.StatusCode != 200 {
-		e := fmt.Errorf("Non-200 response code during bug search against %s: %s", searchUrl, resp.Status)
-		klog.Errorf(e.Error())
-		return searchResults, e
-	}
-
-	search := internal.Search{}
-	err = json.NewDecoder(resp.Body).Decode(&search)
-
-	for searchString, result := range search.Results {
-		// reverse the regex escaping we did earlier, so we get back the pure test name string.
-		r, _ := syntax.Parse(searchString, 0)
-		searchString = string(r.Rune)
-		for _, match := range result.Matches {
-			bug := match.Bug
-			bug.Url = fmt.Sprintf("https://bugzilla.redhat.com/show_bug.cgi?id=%d", bug.ID)
-
-			// search.ci.openshift.org seems to occasionally return empty BZ results, filter
-			// them out.
-			if bug.ID == 0 {
-				continue
-			}
-
-			// ignore any bugs verified over a week ago, they cannot be responsible for test failures
-			// (or the bug was incorrectly verified and needs to be revisited)
-			if !util.IsActiveBug(bug) {
-				if bug.LastChangeTime.Add(time.Hour * 24 * 7).Before(time.Now()) {
-					continue
-				}
-			}
-			searchResults[searchString] = append(searchResults[searchString], bug)
-		}
+// bugs returns the bugs of the entry, which may be nil.
+func (e *cacheEntry) bugs() []bugsv1.Bug {
+	if e == nil {
+		return nil
 	}
-
-	klog.V(2).Infof("Found bugs: %v", searchResults)
-	return searchResults, nil
+	return e.Bugs
 }
@@ -0,0 +1,348 @@
+package buganalysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	bugsv1 "github.com/openshift/sippy/pkg/apis/bugs/v1"
+	"github.com/openshift/sippy/pkg/buganalysis/internal"
+)
+
+// fakeCISearch answers every search for a test in bugs with its bugs, and counts the searches.
+type fakeCISearch struct {
+	bugs     map[string][]internal.BugzillaBug
+	failing  bool
+	searched []string
+}
+
+func (f *fakeCISearch) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if f.failing {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	if err := req.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	search := internal.Search{Results: internal.Results{}}
+	for _, searchString := range req.Form["search"] {
+		f.searched = append(f.searched, searchString)
+		for testName, bugs := range f.bugs {
+			if regexp.QuoteMeta(testName) != searchString {
+				continue
+			}
+			result := internal.Result{}
+			for _, bug := range bugs {
+				result.Matches = append(result.Matches, internal.Match{Bug: bug})
+			}
+			search.Results[searchString] = result
+		}
+	}
+	json.NewEncoder(w).Encode(search)
+}
+
+func TestBugCachePersistenceAndTTL(t *testing.T) {
+	dir, err := ioutil.TempDir("", "buganalysis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "bugs.json")
+
+	ciSearch := &fakeCISearch{bugs: map[string][]internal.BugzillaBug{
+		"[sig-network] pods should talk": {{ID: 1, Status: "NEW", TargetRelease: []string{"4.8.0"}}},
+	}}
+	server := httptest.NewServer(ciSearch)
+	defer server.Close()
+
+	now := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	newCache := func() *bugCache {
+		c := NewBugCache(NewCISearchSource(server.URL), CacheOptions{Filename: filename, TTL: time.Hour}).(*bugCache)
+		c.now = func() time.Time {
+			return now
+		}
+		return c
+	}
+	listed := func(c BugCache) int {
+		return len(c.ListBugs("4.8", "job", "[sig-network] pods should talk"))
+	}
+
+	c := newCache()
+	if err := c.UpdateForFailedTests("[sig-network] pods should talk", "[sig-node] other test"); err != nil {
+		t.Fatal(err)
+	}
+	if len(ciSearch.searched) != 2 || listed(c) != 1 {
+		t.Fatalf("expected both tests to be searched and the bug to be found, got %v and %d bugs", ciSearch.searched, listed(c))
+	}
+	bug := c.ListBugs("4.8", "job", "[sig-network] pods should talk")[0]
+	if bug.Tracker != bugsv1.TrackerBugzilla || bug.Source != "ci-search" || bug.Url != "https://bugzilla.redhat.com/show_bug.cgi?id=1" {
+		t.Errorf("expected a bugzilla bug found by ci-search, got %#v", bug)
+	}
+
+	// fresh entries, including tests without bugs, are not searched again
+	now = now.Add(30 * time.Minute)
+	if err := c.UpdateForFailedTests("[sig-network] pods should talk", "[sig-node] other test"); err != nil {
+		t.Fatal(err)
+	}
+	if len(ciSearch.searched) != 2 {
+		t.Errorf("expected fresh entries to be used, got %v", ciSearch.searched)
+	}
+
+	// stale entries are searched again, and keep their bugs when the search fails
+	now = now.Add(time.Hour)
+	ciSearch.failing = true
+	if err := c.UpdateForFailedTests("[sig-network] pods should talk"); err == nil {
+		t.Errorf("expected the failed search to be reported")
+	}
+	if listed(c) != 1 {
+		t.Errorf("expected the bug to be kept when the search fails")
+	}
+
+	if !c.BugLookupFailed("[sig-network] pods should talk") || c.BugLookupFailed("[sig-node] other test") {
+		t.Errorf("expected only the test that was searched to be marked as failed")
+	}
+
+	// a new cache starts from the file, and searches for failed entries again after a while
+	ciSearch.failing = false
+	ciSearch.searched = nil
+	restarted := newCache()
+	if listed(restarted) != 1 || !restarted.BugLookupFailed("[sig-network] pods should talk") {
+		t.Errorf("expected the bug and the failure to be loaded from %s", filename)
+	}
+	err = restarted.UpdateForFailedTests("[sig-network] pods should talk")
+	if lookupErr, ok := err.(*LookupError); !ok || !reflect.DeepEqual(lookupErr.Failed, []string{"[sig-network] pods should talk"}) {
+		t.Errorf("expected the failure that is not retried yet to be reported, got %v", err)
+	}
+	if len(ciSearch.searched) != 0 {
+		t.Errorf("expected the loaded entries not to be searched yet, got %v", ciSearch.searched)
+	}
+	now = now.Add(failedLookupInterval)
+	if err := restarted.UpdateForFailedTests("[sig-network] pods should talk"); err != nil {
+		t.Fatal(err)
+	}
+	if len(ciSearch.searched) != 1 || restarted.BugLookupFailed("[sig-network] pods should talk") {
+		t.Errorf("expected the failed entry to be searched again, got %v", ciSearch.searched)
+	}
+}
+
+// fakeSource fails the lookups of batches that contain a search string in failures, as many times as it says, and
+// records how many lookups ran at once.
+type fakeSource struct {
+	lock       sync.Mutex
+	failures   map[string]int
+	lookups    int
+	running    int
+	maxRunning int
+}
+
+func (s *fakeSource) Name() string {
+	return "fake"
+}
+
+func (s *fakeSource) FindBugs(searchStrings []string) (map[string][]bugsv1.Bug, error) {
+	s.lock.Lock()
+	s.lookups++
+	s.running++
+	if s.running > s.maxRunning {
+		s.maxRunning = s.running
+	}
+	s.lock.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.running--
+	for _, searchString := range searchStrings {
+		if s.failures[searchString] > 0 {
+			s.failures[searchString]--
+			return nil, fmt.Errorf("%s is down", searchString)
+		}
+	}
+	return map[string][]bugsv1.Bug{
+		searchStrings[0]: {{ID: 1, Status: "NEW", TargetRelease: []string{"4.8.0"}}},
+	}, nil
+}
+
+func TestBugCacheLookups(t *testing.T) {
+	testNames := []string{}
+	for i := 0; i < 120; i++ {
+		testNames = append(testNames, fmt.Sprintf("test %03d", i))
+	}
+	// the first batch fails once and is retried, the second fails every time
+	source := &fakeSource{failures: map[string]int{"test 010": 1, "test 060": 5}}
+	c := NewBugCache(source, CacheOptions{Workers: 2, MaxRetries: 1}).(*bugCache)
+
+	err := c.UpdateForFailedTests(testNames...)
+	lookupErr, ok := err.(*LookupError)
+	if !ok {
+		t.Fatalf("expected a LookupError, got %v", err)
+	}
+	if !reflect.DeepEqual(lookupErr.Failed, testNames[50:100]) || lookupErr.Kind != "tests" || lookupErr.Source != "fake" {
+		t.Errorf("expected exactly the tests of the second batch to fail, got %v", lookupErr)
+	}
+	if source.lookups != 5 || source.maxRunning != 2 {
+		t.Errorf("expected 3 batches and 2 retries with 2 at a time, got %d lookups with %d at a time", source.lookups, source.maxRunning)
+	}
+	if !c.BugLookupFailed("test 060") || !c.BugLookupFailed("test 099") || c.BugLookupFailed("test 100") || c.BugLookupFailed("test 010") {
+		t.Errorf("expected only the tests of the second batch to be marked as failed")
+	}
+	if len(c.ListBugs("4.8", "job", "test 000")) != 1 || len(c.ListBugs("4.8", "job", "test 100")) != 1 {
+		t.Errorf("expected the bugs of the batches that were looked up")
+	}
+	if c.LastUpdateError() != err {
+		t.Errorf("expected the error to be the last update error, got %v", c.LastUpdateError())
+	}
+
+	// a successful update of the jobs does not hide that the tests are failing
+	if err := c.UpdateJobBlockers("periodic-ci-gcp"); err != nil {
+		t.Fatal(err)
+	}
+	if c.LastUpdateError() != lookupErr {
+		t.Errorf("expected the error of the tests to be the last update error, got %v", c.LastUpdateError())
+	}
+
+	source.failures = map[string]int{"job=periodic-ci-aws=all": 5}
+	err = c.UpdateJobBlockers("periodic-ci-aws")
+	if lookupErr, ok := err.(*LookupError); !ok || !reflect.DeepEqual(lookupErr.Failed, []string{"periodic-ci-aws"}) || lookupErr.Kind != "jobs" {
+		t.Errorf("expected the job to be reported by name, got %v", err)
+	}
+
+	// once the tests are found, the failing jobs are the last update error
+	if err := c.UpdateForFailedTests("test 000"); err != nil {
+		t.Fatal(err)
+	}
+	if lookupErr, ok := c.LastUpdateError().(*LookupError); !ok || !reflect.DeepEqual(lookupErr.Failed, []string{"periodic-ci-aws"}) || lookupErr.Kind != "jobs" {
+		t.Errorf("expected the failing job to be the last update error, got %v", c.LastUpdateError())
+	}
+}
+
+// clearingSource clears the cache during every lookup, and finds a bug for every search string.
+type clearingSource struct {
+	cache BugCache
+}
+
+func (s *clearingSource) Name() string {
+	return "clearing"
+}
+
+func (s *clearingSource) FindBugs(searchStrings []string) (map[string][]bugsv1.Bug, error) {
+	s.cache.Clear()
+	ret := map[string][]bugsv1.Bug{}
+	for _, searchString := range searchStrings {
+		ret[searchString] = []bugsv1.Bug{{ID: 1, Status: "NEW", TargetRelease: []string{"4.8.0"}}}
+	}
+	return ret, nil
+}
+
+func TestBugCacheClearedDuringLookup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "buganalysis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "bugs.json")
+
+	source := &clearingSource{}
+	c := NewBugCache(source, CacheOptions{Filename: filename, TTL: time.Hour})
+	source.cache = c
+	if err := c.UpdateForFailedTests("test"); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.ListBugs("4.8", "job", "test")) != 1 {
+		t.Errorf("expected the bugs found during the clear to be kept")
+	}
+
+	restarted := NewBugCache(source, CacheOptions{Filename: filename, TTL: time.Hour})
+	if len(restarted.ListBugs("4.8", "job", "test")) != 1 {
+		t.Errorf("expected the bugs found during the clear to be persisted")
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := &rateLimiter{interval: 20 * time.Millisecond}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		limiter.wait()
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("expected the third wait to start two intervals after the first, took %v", elapsed)
+	}
+}
+
+func TestBugCacheDropsUnusedEntries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "buganalysis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "bugs.json")
+
+	server := httptest.NewServer(&fakeCISearch{})
+	defer server.Close()
+
+	now := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	c := NewBugCache(NewCISearchSource(server.URL), CacheOptions{Filename: filename, TTL: time.Hour}).(*bugCache)
+	c.now = func() time.Time {
+		return now
+	}
+	if err := c.UpdateJobBlockers("old-job"); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(maxUnusedAge + time.Hour)
+	if err := c.UpdateJobBlockers("new-job"); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted := NewBugCache(NewCISearchSource(server.URL), CacheOptions{Filename: filename, TTL: time.Hour}).(*bugCache)
+	if _, ok := restarted.jobBlockers[GetJobKey("old-job")]; ok {
+		t.Errorf("expected the unused entry to be dropped")
+	}
+	if _, ok := restarted.jobBlockers[GetJobKey("new-job")]; !ok {
+		t.Errorf("expected the new entry to be persisted")
+	}
+}
+
+func TestNewBugCacheIgnoresBadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "buganalysis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "bugs.json")
+	if err := ioutil.WriteFile(filename, []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewBugCache(NewCISearchSource(""), CacheOptions{Filename: filename, TTL: time.Hour}).(*bugCache)
+	if len(c.cache) != 0 || len(c.jobBlockers) != 0 {
+		t.Errorf("expected an empty cache, got %v and %v", c.cache, c.jobBlockers)
+	}
+}
+
+func TestNewBugCacheIgnoresOtherSources(t *testing.T) {
+	dir, err := ioutil.TempDir("", "buganalysis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "bugs.json")
+	if err := ioutil.WriteFile(filename, []byte(`{"source": "jira", "tests": {"a": {"bugs": [{"id": 1}]}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewBugCache(NewCISearchSource(""), CacheOptions{Filename: filename, TTL: time.Hour}).(*bugCache)
+	if len(c.cache) != 0 {
+		t.Errorf("expected the bugs found in jira not to be loaded, got %v", c.cache)
+	}
+}
@@ -0,0 +1,120 @@
+package buganalysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"time"
+
+	bugsv1 "github.com/openshift/sippy/pkg/apis/bugs/v1"
+	"github.com/openshift/sippy/pkg/buganalysis/internal"
+	"k8s.io/klog"
+)
+
+// DefaultCISearchURL is the ci-search instance queried for bugs unless another one is configured.
+const DefaultCISearchURL = "https://search.ci.openshift.org"
+
+// ciSearchTimeout bounds a search, which covers a whole batch of search strings.
+const ciSearchTimeout = 2 * time.Minute
+
+// ciSearchSource finds bugzilla bugs with ci-search, which indexes the comments of bugs.
+type ciSearchSource struct {
+	// ciSearchURL is the base url of the ci-search instance used to find bugs
+	ciSearchURL string
+	client      *http.Client
+}
+
+// NewCISearchSource returns a BugSource that searches the ci-search instance at ciSearchURL.  If ciSearchURL is empty,
+// DefaultCISearchURL is used.
+func NewCISearchSource(ciSearchURL string) BugSource {
+	if len(ciSearchURL) == 0 {
+		ciSearchURL = DefaultCISearchURL
+	}
+	return &ciSearchSource{
+		ciSearchURL: strings.TrimSuffix(ciSearchURL, "/"),
+		client:      &http.Client{Timeout: ciSearchTimeout},
+	}
+}
+
+// TestSearchURL links to a search of the ci-search instance at ciSearchURL for the failures of testName in the last
+// week, in the jobs whose names match jobName.  An empty jobName matches every job.
+func TestSearchURL(ciSearchURL, jobName, testName string) string {
+	if len(ciSearchURL) == 0 {
+		ciSearchURL = DefaultCISearchURL
+	}
+	return fmt.Sprintf("%s/?maxAge=168h&context=1&type=bug%%2Bjunit&name=%s&maxMatches=5&maxBytes=20971520&groupBy=job&search=%s",
+		strings.TrimSuffix(ciSearchURL, "/"), url.QueryEscape(jobName), url.QueryEscape(regexp.QuoteMeta(testName)))
+}
+
+func (s *ciSearchSource) Name() string {
+	return "ci-search"
+}
+
+func (s *ciSearchSource) FindBugs(testNames []string) (map[string][]bugsv1.Bug, error) {
+	searchResults := make(map[string][]bugsv1.Bug)
+
+	v := url.Values{}
+	v.Set("type", "bug")
+	v.Set("context", "-1")
+	for _, testName := range testNames {
+		testName = regexp.QuoteMeta(testName)
+		klog.V(4).Infof("Searching bugs for test name: %s\n", testName)
+		v.Add("search", testName)
+	}
+
+	searchUrl := s.ciSearchURL + "/v2/search"
+	resp, err := s.client.PostForm(searchUrl, v)
+	if err != nil {
+		e := fmt.Errorf("error during bug search against %s: %w", searchUrl, err)
+		klog.Errorf(e.Error())
+		return searchResults, e
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		e := fmt.Errorf("Non-200 response code during bug search against %s: %s", searchUrl, resp.Status)
+		klog.Errorf(e.Error())
+		return searchResults, e
+	}
+
+	search := internal.Search{}
+	if err := json.NewDecoder(resp.Body).Decode(&search); err != nil {
+		e := fmt.Errorf("could not decode bug search results from %s: %v", searchUrl, err)
+		klog.Errorf(e.Error())
+		return searchResults, e
+	}
+
+	for searchString, result := range search.Results {
+		// reverse the regex escaping we did earlier, so we get back the pure test name string.
+		r, _ := syntax.Parse(searchString, 0)
+		searchString = string(r.Rune)
+		for _, match := range result.Matches {
+			// search.ci.openshift.org seems to occasionally return empty BZ results, filter
+			// them out.
+			if match.Bug.ID == 0 {
+				continue
+			}
+			searchResults[searchString] = append(searchResults[searchString], bugFromBugzilla(match.Bug))
+		}
+	}
+
+	klog.V(2).Infof("Found bugs: %v", searchResults)
+	return searchResults, nil
+}
+
+func bugFromBugzilla(bug internal.BugzillaBug) bugsv1.Bug {
+	return bugsv1.Bug{
+		ID:             bug.ID,
+		Status:         bug.Status,
+		LastChangeTime: bug.LastChangeTime,
+		Summary:        bug.Summary,
+		TargetRelease:  bug.TargetRelease,
+		Component:      bug.Component,
+		Tracker:        bugsv1.TrackerBugzilla,
+		Source:         "ci-search",
+		Url:            fmt.Sprintf("https://bugzilla.redhat.com/show_bug.cgi?id=%d", bug.ID),
+	}
+}
@@ -1,7 +1,7 @@
-// These types are used to decode information from ci-search, but we don't want to expose these for anyone else.
+// These types are used to decode information from ci-search and jira, but we don't want to expose these for anyone else.
 package internal
 
-import bugsv1 "github.com/openshift/sippy/pkg/apis/bugs/v1"
+import "time"
 
 type Search struct {
 	Results Results `json:"results"`
@@ -15,5 +15,41 @@ type Result struct {
 }
 
 type Match struct {
-	Bug bugsv1.Bug `json:"bugInfo"`
+	Bug BugzillaBug `json:"bugInfo"`
+}
+
+// BugzillaBug matches the bugzilla API, which ci-search returns bugs in.
+type BugzillaBug struct {
+	ID             int64     `json:"id"`
+	Status         string    `json:"status"`
+	LastChangeTime time.Time `json:"last_change_time"`
+	Summary        string    `json:"summary"`
+	TargetRelease  []string  `json:"target_release"`
+	Component      []string  `json:"component"`
+}
+
+// JiraSearch is the response of the jira search API.
+type JiraSearch struct {
+	Issues []JiraIssue `json:"issues"`
+}
+
+type JiraIssue struct {
+	// ID is numeric, but jira returns it as a string
+	ID     string          `json:"id"`
+	Key    string          `json:"key"`
+	Fields JiraIssueFields `json:"fields"`
+}
+
+type JiraIssueFields struct {
+	Summary     string     `json:"summary"`
+	Status      JiraName   `json:"status"`
+	FixVersions []JiraName `json:"fixVersions"`
+	Components  []JiraName `json:"components"`
+	// Updated is formatted like 2021-03-01T10:00:00.000+0000, which is not RFC 3339
+	Updated string `json:"updated"`
+}
+
+// JiraName is any jira object that is identified by its name, like a status, version, or component.
+type JiraName struct {
+	Name string `json:"name"`
 }
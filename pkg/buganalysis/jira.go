@@ -0,0 +1,151 @@
+package buganalysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	bugsv1 "github.com/openshift/sippy/pkg/apis/bugs/v1"
+	"github.com/openshift/sippy/pkg/buganalysis/internal"
+	"k8s.io/klog"
+)
+
+// jiraTimeFormat is how jira formats timestamps.
+const jiraTimeFormat = "2006-01-02T15:04:05.000-0700"
+
+// maxJiraResults is the most issues returned for one search string.
+const maxJiraResults = 50
+
+// jiraPhraseEscaper escapes a search string so it can be quoted as a phrase in a jira text search.
+var jiraPhraseEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// jiraSource finds jira issues with the jira REST API, by searching their text for each search string.
+type jiraSource struct {
+	jiraURL string
+	// query is a JQL clause that restricts the issues that are searched, like project = OCPBUGS
+	query string
+	// token is sent as a bearer token, if set
+	token  string
+	client *http.Client
+}
+
+// NewJiraSource returns a BugSource that searches the issues of the jira instance at jiraURL that match the JQL query,
+// which may be empty.  If tokenFile is set, the token it holds is used to authenticate.
+func NewJiraSource(jiraURL, query, tokenFile string) (BugSource, error) {
+	if len(jiraURL) == 0 {
+		return nil, fmt.Errorf("the url of the jira instance is required")
+	}
+	s := &jiraSource{
+		jiraURL: strings.TrimSuffix(jiraURL, "/"),
+		query:   query,
+		client:  &http.Client{Timeout: time.Minute},
+	}
+	if len(tokenFile) > 0 {
+		token, err := ioutil.ReadFile(tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read jira token: %v", err)
+		}
+		s.token = strings.TrimSpace(string(token))
+	}
+	return s, nil
+}
+
+func (s *jiraSource) Name() string {
+	return "jira"
+}
+
+// maxBatchSize is 1 because every search string is a search of its own.
+func (s *jiraSource) maxBatchSize() int {
+	return 1
+}
+
+func (s *jiraSource) FindBugs(searchStrings []string) (map[string][]bugsv1.Bug, error) {
+	searchResults := map[string][]bugsv1.Bug{}
+	for _, searchString := range searchStrings {
+		bugs, err := s.search(searchString)
+		if err != nil {
+			klog.Error(err)
+			return searchResults, err
+		}
+		if len(bugs) > 0 {
+			searchResults[searchString] = bugs
+		}
+	}
+	klog.V(2).Infof("Found bugs: %v", searchResults)
+	return searchResults, nil
+}
+
+// jql returns the JQL that finds the issues that mention the search string as a phrase.
+func (s *jiraSource) jql(searchString string) string {
+	phrase := `"` + jiraPhraseEscaper.Replace(searchString) + `"`
+	jql := fmt.Sprintf(`text ~ "%s"`, jiraPhraseEscaper.Replace(phrase))
+	if len(s.query) > 0 {
+		jql = fmt.Sprintf("(%s) AND %s", s.query, jql)
+	}
+	return jql
+}
+
+func (s *jiraSource) search(searchString string) ([]bugsv1.Bug, error) {
+	v := url.Values{}
+	v.Set("jql", s.jql(searchString))
+	v.Set("fields", "summary,status,fixVersions,components,updated")
+	v.Set("maxResults", strconv.Itoa(maxJiraResults))
+	searchURL := s.jiraURL + "/rest/api/2/search"
+
+	req, err := http.NewRequest(http.MethodGet, searchURL+"?"+v.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if len(s.token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error during bug search against %s: %w", searchURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Non-200 response code during bug search against %s: %s", searchURL, resp.Status)
+	}
+
+	search := internal.JiraSearch{}
+	if err := json.NewDecoder(resp.Body).Decode(&search); err != nil {
+		return nil, fmt.Errorf("could not decode bug search results from %s: %v", searchURL, err)
+	}
+	bugs := []bugsv1.Bug{}
+	for _, issue := range search.Issues {
+		bugs = append(bugs, s.bugFromIssue(issue))
+	}
+	return bugs, nil
+}
+
+func (s *jiraSource) bugFromIssue(issue internal.JiraIssue) bugsv1.Bug {
+	bug := bugsv1.Bug{
+		Key:           issue.Key,
+		Status:        issue.Fields.Status.Name,
+		Summary:       issue.Fields.Summary,
+		TargetRelease: []string{},
+		Component:     []string{},
+		Tracker:       bugsv1.TrackerJira,
+		Source:        s.Name(),
+		Url:           s.jiraURL + "/browse/" + issue.Key,
+	}
+	// jira returns the numeric ID as a string
+	bug.ID, _ = strconv.ParseInt(issue.ID, 10, 64)
+	if updated, err := time.Parse(jiraTimeFormat, issue.Fields.Updated); err == nil {
+		bug.LastChangeTime = updated.UTC()
+	}
+	for _, version := range issue.Fields.FixVersions {
+		bug.TargetRelease = append(bug.TargetRelease, version.Name)
+	}
+	for _, component := range issue.Fields.Components {
+		bug.Component = append(bug.Component, component.Name)
+	}
+	return bug
+}
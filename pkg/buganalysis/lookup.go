@@ -0,0 +1,129 @@
+package buganalysis
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	bugsv1 "github.com/openshift/sippy/pkg/apis/bugs/v1"
+	"github.com/openshift/sippy/pkg/util"
+	"github.com/openshift/sippy/pkg/util/sets"
+	"k8s.io/klog"
+)
+
+// lookupBatchSize is the number of test names or job keys looked up in one request to the source.
+const lookupBatchSize = 50
+
+// LookupError is returned when the bugs of some tests or jobs could not be looked up, even after retries.  The bugs of
+// the others were looked up.
+type LookupError struct {
+	// Source is the name of the source the bugs were looked up in
+	Source string
+	// Kind is what Failed lists, tests or jobs
+	Kind string
+	// Failed are the names of the tests or jobs whose bugs could not be looked up, sorted
+	Failed []string
+	// Err is the error of the last lookup that failed
+	Err error
+}
+
+func (e *LookupError) Error() string {
+	return fmt.Sprintf("could not look up the bugs of %d %s in %s: %v", len(e.Failed), e.Kind, e.Source, e.Err)
+}
+
+// rateLimiter spaces out lookups, so that at most one starts every interval.
+type rateLimiter struct {
+	interval time.Duration
+
+	lock sync.Mutex
+	next time.Time
+}
+
+// wait blocks until the next lookup may start.
+func (l *rateLimiter) wait() {
+	if l.interval <= 0 {
+		return
+	}
+	l.lock.Lock()
+	now := time.Now()
+	start := l.next
+	if start.Before(now) {
+		start = now
+	}
+	l.next = start.Add(l.interval)
+	l.lock.Unlock()
+
+	time.Sleep(start.Sub(now))
+}
+
+// findBugs looks up the bugs of the search strings in batches, with at most Workers batches at a time, and retries the
+// batches that fail.  It returns the bugs of every search string that was looked up, including the ones without bugs,
+// the search strings that could not be looked up, sorted, and the error of the last batch that failed.
+func (c *bugCache) findBugs(searchStrings []string) (map[string][]bugsv1.Bug, []string, error) {
+	batchSize := lookupBatchSize
+	if limited, ok := c.source.(batchLimitedSource); ok {
+		batchSize = limited.maxBatchSize()
+	}
+	batches := [][]string{}
+	batch := []string{}
+	for _, searchString := range sets.NewString(searchStrings...).List() {
+		batch = append(batch, searchString)
+		if len(batch) == batchSize {
+			batches = append(batches, batch)
+			batch = []string{}
+		}
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	lock := sync.Mutex{}
+	found := map[string][]bugsv1.Bug{}
+	failed := []string{}
+	var lastErr error
+	util.RunWorkers(c.options.Workers, len(batches), func(i int) {
+		bugs, err := c.findBatch(batches[i])
+
+		lock.Lock()
+		defer lock.Unlock()
+		if err != nil {
+			failed = append(failed, batches[i]...)
+			lastErr = err
+			return
+		}
+		// every search string of the batch gets an entry, so that the ones without bugs are not looked up again until
+		// they are stale.
+		for _, searchString := range batches[i] {
+			found[searchString] = []bugsv1.Bug{}
+		}
+		for searchString, searchStringBugs := range bugs {
+			for _, bug := range searchStringBugs {
+				// ignore any bugs verified over a week ago, they cannot be responsible for test failures
+				// (or the bug was incorrectly verified and needs to be revisited)
+				if !util.IsActiveBug(bug) && bug.LastChangeTime.Add(time.Hour*24*7).Before(time.Now()) {
+					continue
+				}
+				found[searchString] = append(found[searchString], bug)
+			}
+		}
+	})
+
+	sort.Strings(failed)
+	return found, failed, lastErr
+}
+
+// findBatch looks up the bugs of one batch, retrying it up to MaxRetries times.
+func (c *bugCache) findBatch(batch []string) (map[string][]bugsv1.Bug, error) {
+	backoff := c.options.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		c.limiter.wait()
+		bugs, err := c.source.FindBugs(batch)
+		if err == nil || attempt > c.options.MaxRetries {
+			return bugs, err
+		}
+		klog.V(2).Infof("Retrying a lookup of %d bugs in %s in %v after attempt %d failed: %v", len(batch), c.source.Name(), backoff, attempt, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
@@ -0,0 +1,22 @@
+package buganalysis
+
+import (
+	bugsv1 "github.com/openshift/sippy/pkg/apis/bugs/v1"
+)
+
+// BugSource finds the bugs that mention failing tests and jobs.  The bug cache asks it for the bugs of test names, and
+// of job keys from GetJobKey, in batches.
+type BugSource interface {
+	// Name identifies the source in logs and in the persisted bug cache, like ci-search or jira.
+	Name() string
+	// FindBugs returns the bugs that mention each of the search strings, by search string.  Search strings without
+	// bugs may be missing.  It returns an error if the search failed.
+	FindBugs(searchStrings []string) (map[string][]bugsv1.Bug, error)
+}
+
+// batchLimitedSource is a BugSource that makes a request for every few search strings, rather than one for every
+// batch.  The bug cache gives it batches of at most maxBatchSize search strings, so that every request is rate limited
+// and retried.
+type batchLimitedSource interface {
+	maxBatchSize() int
+}
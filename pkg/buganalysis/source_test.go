@@ -0,0 +1,174 @@
+package buganalysis
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	bugsv1 "github.com/openshift/sippy/pkg/apis/bugs/v1"
+)
+
+func TestJiraSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "buganalysis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tokenFile := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(tokenFile, []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	jqls := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/rest/api/2/search" || req.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("unexpected request %s with %q", req.URL.Path, req.Header.Get("Authorization"))
+		}
+		jql := req.URL.Query().Get("jql")
+		jqls = append(jqls, jql)
+		if jql != `(project = OCPBUGS) AND text ~ "\"[sig-network] pods \\\"should\\\" talk\""` {
+			w.Write([]byte(`{"issues": []}`))
+			return
+		}
+		w.Write([]byte(`{"issues": [{"id": "10001", "key": "OCPBUGS-1", "fields": {
+			"summary": "pods do not talk", "status": {"name": "Release Pending"}, "fixVersions": [{"name": "4.8.0"}],
+			"components": [{"name": "Networking"}], "updated": "2021-03-01T10:00:00.000+0000"}}]}`))
+	}))
+	defer server.Close()
+
+	source, err := NewJiraSource(server.URL+"/", "project = OCPBUGS", tokenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bugs, err := source.FindBugs([]string{`[sig-network] pods "should" talk`, "other test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jqls) != 2 || len(bugs) != 1 {
+		t.Fatalf("expected one search per string and one match, got %v and %v", jqls, bugs)
+	}
+
+	bug := bugs[`[sig-network] pods "should" talk`][0]
+	expected := bugsv1.Bug{
+		ID:             10001,
+		Key:            "OCPBUGS-1",
+		Status:         "Release Pending",
+		LastChangeTime: time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC),
+		Summary:        "pods do not talk",
+		TargetRelease:  []string{"4.8.0"},
+		Component:      []string{"Networking"},
+		Tracker:        bugsv1.TrackerJira,
+		Source:         "jira",
+		Url:            server.URL + "/browse/OCPBUGS-1",
+	}
+	if !reflect.DeepEqual(bug, expected) {
+		t.Errorf("expected %#v, got %#v", expected, bug)
+	}
+}
+
+func TestJiraSourceFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	source, err := NewJiraSource(server.URL, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := source.FindBugs([]string{"a"}); err == nil {
+		t.Errorf("expected an error when jira refuses the search")
+	}
+}
+
+func TestJiraSourceRequestsAreRateLimited(t *testing.T) {
+	lock := sync.Mutex{}
+	searches := []time.Time{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		lock.Lock()
+		searches = append(searches, time.Now())
+		lock.Unlock()
+		w.Write([]byte(`{"issues": []}`))
+	}))
+	defer server.Close()
+
+	source, err := NewJiraSource(server.URL, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	interval := 20 * time.Millisecond
+	c := NewBugCache(source, CacheOptions{Workers: 3, RequestInterval: interval})
+	if err := c.UpdateForFailedTests("a", "b", "c"); err != nil {
+		t.Fatal(err)
+	}
+	if len(searches) != 3 {
+		t.Fatalf("expected a search per test, got %d", len(searches))
+	}
+	sort.Slice(searches, func(i, j int) bool {
+		return searches[i].Before(searches[j])
+	})
+	// allow for the clock resolution of some platforms
+	if elapsed := searches[2].Sub(searches[0]); elapsed < 2*interval-5*time.Millisecond {
+		t.Errorf("expected every jira search to be rate limited, the searches took %v", elapsed)
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "buganalysis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "bugs.json")
+	content := `{
+		"[sig-network] pods should talk": [{"id": 1, "status": "NEW", "target_release": ["4.8.0"], "tracker": "bugzilla"}],
+		"job=periodic-ci-aws=all": [{"id": 2, "key": "OCPBUGS-2", "tracker": "jira"}]
+	}`
+	if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := NewFileSource(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewBugCache(source, CacheOptions{TTL: time.Hour})
+	if err := c.UpdateForFailedTests("[sig-network] pods should talk", "other test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.UpdateJobBlockers("periodic-ci-aws"); err != nil {
+		t.Fatal(err)
+	}
+
+	if bugs := c.ListBugs("4.8", "periodic-ci-gcp", "[sig-network] pods should talk"); len(bugs) != 1 || bugs[0].Source != "file" {
+		t.Errorf("expected the bug of the test from the file, got %v", bugs)
+	}
+	if bugs := c.ListJobBlockingBugs("periodic-ci-aws"); len(bugs) != 1 || bugs[0].Name() != "OCPBUGS-2" {
+		t.Errorf("expected the bug of the job from the file, got %v", bugs)
+	}
+
+	if err := ioutil.WriteFile(filename, []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileSource(filename); err == nil {
+		t.Errorf("expected an error for a file that is not JSON")
+	}
+}
+
+func TestTestSearchURL(t *testing.T) {
+	expected := "https://search.example.com/search/?maxAge=168h&context=1&type=bug%2Bjunit&name=4.8&maxMatches=5&maxBytes=20971520&groupBy=job&search=%5C%5Bsig-network%5C%5D+pods+should+talk"
+	if actual := TestSearchURL("https://search.example.com/search/", "4.8", "[sig-network] pods should talk"); actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+	if actual := TestSearchURL("", "", "a"); !strings.HasPrefix(actual, DefaultCISearchURL+"/?") {
+		t.Errorf("expected a link to %s, got %s", DefaultCISearchURL, actual)
+	}
+}
@@ -3,25 +3,31 @@ package generichtml
 import (
 	"fmt"
 	"net/url"
-	"regexp"
 
 	bugsv1 "github.com/openshift/sippy/pkg/apis/bugs/v1"
 	"github.com/openshift/sippy/pkg/buganalysis"
 	"github.com/openshift/sippy/pkg/util"
 )
 
+// BugLookupFailedHTML marks a test whose bugs could not be looked up, so that missing bugs are not mistaken for none.
+const BugLookupFailedHTML = `<span class="badge badge-warning" title="The bugs of this test could not be looked up, so it may have bugs that are not listed">bug lookup failed</span>`
+
 func bugLink(bug bugsv1.Bug) string {
 	if !util.IsActiveBug(bug) {
-		return fmt.Sprintf(`<a target="_blank" href="%s"><strike>%d</strike></a> `, bug.Url, bug.ID)
+		return fmt.Sprintf(`<a target="_blank" href="%s"><strike>%s</strike></a> `, bug.Url, bug.Name())
 	}
-	return fmt.Sprintf(`<a target="_blank" href="%s">%d</a> `, bug.Url, bug.ID)
+	return fmt.Sprintf(`<a target="_blank" href="%s">%s</a> `, bug.Url, bug.Name())
 }
 
-// bugHTMLForTest release and testName are required.
-func bugHTMLForTest(bugList, associatedBugList []bugsv1.Bug, release, testName string) string {
+// bugHTMLForTest release and testName are required.  bugLookupFailed tells that the list of bugs may be incomplete.
+// ciSearchURL is the ci-search instance that new bugs link to.
+func bugHTMLForTest(bugList, associatedBugList []bugsv1.Bug, bugLookupFailed bool, release, ciSearchURL, testName string) string {
 	bugHTML := ""
+	if bugLookupFailed {
+		bugHTML += BugLookupFailedHTML + "<br>"
+	}
 	if len(bugList) == 0 {
-		bugHTML += openATestBugHTML(testName, release)
+		bugHTML += openATestBugHTML(testName, release, ciSearchURL)
 		bugHTML += "<br>"
 	}
 
@@ -68,12 +74,12 @@ func bugHTMLForJob(bugList, associatedBugList []bugsv1.Bug, release, jobName, te
 	return bugHTML
 }
 
-func openATestBugHTML(testName, release string) string {
+func openATestBugHTML(testName, release, ciSearchURL string) string {
 	short_desc := testName
 	if len(short_desc) > 255 {
 		short_desc = short_desc[:255]
 	}
-	searchURL := testToSearchURL(testName)
+	searchURL := buganalysis.TestSearchURL(ciSearchURL, "", testName)
 
 	exampleJob :=
 		`
@@ -117,9 +123,3 @@ FIXME: Provide a snippet of the test failure or error from the job log
 
 	return bug
 }
-
-// testName is the non-encoded test.Name
-func testToSearchURL(testName string) string {
-	encodedTestName := url.QueryEscape(regexp.QuoteMeta(testName))
-	return fmt.Sprintf("https://search.ci.openshift.org/?maxAge=168h&context=1&type=bug%%2Bjunit&name=&maxMatches=5&maxBytes=20971520&groupBy=job&search=%s", encodedTestName)
-}
@@ -111,3 +111,8 @@ func GetTestDetailsButtonHTML(release string, testNames ...string) string {
 		"testNames": escapedTestNames,
 	})
 }
+
+func GetComponentButtonHTML(release, component string) string {
+	return fmt.Sprintf(`<a class="btn btn-primary btn-sm py-0" style="font-size: 0.8em" href="/component?release=%s&name=%s" target="_blank" role="button">Component Dashboard</a>`,
+		url.QueryEscape(release), url.QueryEscape(component))
+}
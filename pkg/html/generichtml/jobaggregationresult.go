@@ -8,7 +8,9 @@ import (
 
 // VariantResults
 type jobAggregationDisplay struct {
-	displayName            string
+	displayName string
+	// componentName links the row to the dashboard of the bugzilla component, if it is one
+	componentName          string
 	totalJobRuns           int
 	displayPercentage      float64
 	parenDisplayPercentage float64
@@ -42,6 +44,7 @@ func bugzillaComponentReportToDisplay(in sippyprocessingv1.SortedBugzillaCompone
 	worstJob := in.JobsFailed[0]
 	ret := jobAggregationDisplay{
 		displayName:            in.Name,
+		componentName:          in.Name,
 		totalJobRuns:           worstJob.TotalRuns,
 		displayPercentage:      100.0 - worstJob.FailPercentage,
 		parenDisplayPercentage: 100.0 - worstJob.FailPercentage, // this is the same because infrastructure isn't different for these.
@@ -63,17 +66,19 @@ type jobAggregationResultRenderBuilder struct {
 	prevAggregationResult *jobAggregationDisplay
 
 	release              string
+	ciSearchURL          string
 	maxTestResultsToShow int
 	maxJobResultsToShow  int
 	colors               ColorizationCriteria
 	collapsedAs          string
 }
 
-func NewJobAggregationResultRenderer(sectionBlock string, currJobResult jobAggregationDisplay, release string) *jobAggregationResultRenderBuilder {
+func NewJobAggregationResultRenderer(sectionBlock string, currJobResult jobAggregationDisplay, release, ciSearchURL string) *jobAggregationResultRenderBuilder {
 	return &jobAggregationResultRenderBuilder{
 		sectionBlock:          sectionBlock,
 		currAggregationResult: currJobResult,
 		release:               release,
+		ciSearchURL:           ciSearchURL,
 		maxTestResultsToShow:  10, // just a default, can be overridden
 		maxJobResultsToShow:   10, // just a default, can be overridden
 		colors: ColorizationCriteria{
@@ -84,12 +89,12 @@ func NewJobAggregationResultRenderer(sectionBlock string, currJobResult jobAggre
 	}
 }
 
-func NewJobAggregationResultRendererFromVariantResults(sectionBlock string, curr sippyprocessingv1.VariantResults, release string) *jobAggregationResultRenderBuilder {
-	return NewJobAggregationResultRenderer(sectionBlock, variantResultToDisplay(curr), release)
+func NewJobAggregationResultRendererFromVariantResults(sectionBlock string, curr sippyprocessingv1.VariantResults, release, ciSearchURL string) *jobAggregationResultRenderBuilder {
+	return NewJobAggregationResultRenderer(sectionBlock, variantResultToDisplay(curr), release, ciSearchURL)
 }
 
-func NewJobAggregationResultRendererFromBugzillaComponentResult(sectionBlock string, curr sippyprocessingv1.SortedBugzillaComponentResult, release string) *jobAggregationResultRenderBuilder {
-	return NewJobAggregationResultRenderer(sectionBlock, bugzillaComponentReportToDisplay(curr), release)
+func NewJobAggregationResultRendererFromBugzillaComponentResult(sectionBlock string, curr sippyprocessingv1.SortedBugzillaComponentResult, release, ciSearchURL string) *jobAggregationResultRenderBuilder {
+	return NewJobAggregationResultRenderer(sectionBlock, bugzillaComponentReportToDisplay(curr), release, ciSearchURL)
 }
 
 func (b *jobAggregationResultRenderBuilder) WithPrevious(prevJobResult *jobAggregationDisplay) *jobAggregationResultRenderBuilder {
@@ -191,8 +196,11 @@ func (b *jobAggregationResultRenderBuilder) ToHTML() string {
 
 	testCollapseSectionName := MakeSafeForCollapseName(b.sectionBlock + "---" + b.currAggregationResult.displayName + "---tests")
 	jobsCollapseName := MakeSafeForCollapseName(b.sectionBlock + "---" + b.currAggregationResult.displayName + "---jobs")
-	testRows, displayedTests := getTestRowHTML(b.release, testCollapseSectionName, b.currAggregationResult.testResults, prevTestResults, b.maxTestResultsToShow)
+	testRows, displayedTests := getTestRowHTML(b.release, b.ciSearchURL, testCollapseSectionName, b.currAggregationResult.testResults, prevTestResults, b.maxTestResultsToShow)
 	button := "					" + GetExpandingButtonHTML(jobsCollapseName, "Expand Failing Jobs")
+	if len(b.currAggregationResult.componentName) > 0 {
+		button += " " + GetComponentButtonHTML(b.release, b.currAggregationResult.componentName)
+	}
 	if len(displayedTests) > 0 { // add the button if we have tests to show
 		button += " " + GetExpandingButtonHTML(testCollapseSectionName, "Expand Failing Tests")
 		button += " " + GetTestDetailsButtonHTML(b.release, displayedTests...)
@@ -246,7 +254,7 @@ func (b *jobAggregationResultRenderBuilder) ToHTML() string {
 			}
 		}
 
-		jobRows = jobRows + NewJobResultRenderer(jobsCollapseName, job, b.release).
+		jobRows = jobRows + NewJobResultRenderer(jobsCollapseName, job, b.release, b.ciSearchURL).
 			WithPrevious(prev).
 			WithMaxTestResultsToShow(b.maxTestResultsToShow).
 			StartCollapsed().
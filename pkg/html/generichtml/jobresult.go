@@ -28,6 +28,7 @@ type jobResultRenderBuilder struct {
 	prevJobResult *jobResultDisplay
 
 	release              string
+	ciSearchURL          string
 	maxTestResultsToShow int
 	colors               ColorizationCriteria
 	startCollapsedBool   bool
@@ -86,11 +87,12 @@ func failingJobResultToDisplay(in sippyprocessingv1.FailingTestJobResult) jobRes
 	return ret
 }
 
-func NewJobResultRenderer(sectionBlock string, curr jobResultDisplay, release string) *jobResultRenderBuilder {
+func NewJobResultRenderer(sectionBlock string, curr jobResultDisplay, release, ciSearchURL string) *jobResultRenderBuilder {
 	return &jobResultRenderBuilder{
 		sectionBlock:         sectionBlock,
 		currJobResult:        curr,
 		release:              release,
+		ciSearchURL:          ciSearchURL,
 		maxTestResultsToShow: 10, // just a default, can be overridden
 		colors: ColorizationCriteria{
 			MinRedPercent:    0,  // failure.  In this range, there is a systemic failure so severe that a reliable signal isn't available.
@@ -100,8 +102,8 @@ func NewJobResultRenderer(sectionBlock string, curr jobResultDisplay, release st
 	}
 }
 
-func NewJobResultRendererFromJobResult(sectionBlock string, curr sippyprocessingv1.JobResult, release string) *jobResultRenderBuilder {
-	return NewJobResultRenderer(sectionBlock, jobResultToDisplay(curr), release)
+func NewJobResultRendererFromJobResult(sectionBlock string, curr sippyprocessingv1.JobResult, release, ciSearchURL string) *jobResultRenderBuilder {
+	return NewJobResultRenderer(sectionBlock, jobResultToDisplay(curr), release, ciSearchURL)
 }
 
 func (b *jobResultRenderBuilder) WithPrevious(prevJobResult *jobResultDisplay) *jobResultRenderBuilder {
@@ -195,7 +197,7 @@ func (b *jobResultRenderBuilder) ToHTML() string {
 		prevTestResults = b.prevJobResult.testResults
 	}
 
-	testRows, displayedTests := getTestRowHTML(b.release, testCollapseSectionName, b.currJobResult.testResults, prevTestResults, b.maxTestResultsToShow)
+	testRows, displayedTests := getTestRowHTML(b.release, b.ciSearchURL, testCollapseSectionName, b.currJobResult.testResults, prevTestResults, b.maxTestResultsToShow)
 
 	button := ""
 	if len(displayedTests) > 0 {
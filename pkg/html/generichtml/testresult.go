@@ -2,10 +2,9 @@ package generichtml
 
 import (
 	"fmt"
-	"net/url"
-	"regexp"
 
 	bugsv1 "github.com/openshift/sippy/pkg/apis/bugs/v1"
+	"github.com/openshift/sippy/pkg/buganalysis"
 	"k8s.io/klog"
 
 	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
@@ -29,16 +28,19 @@ type testResultDisplay struct {
 	flakedRuns        int
 	bugList           []bugsv1.Bug
 	associatedBugList []bugsv1.Bug
+	bugLookupFailed   bool
+	classification    sippyprocessingv1.TestClassification
 
 	jobResults []jobResultDisplay
 }
 
 func testResultToDisplay(in sippyprocessingv1.TestResult) testResultDisplay {
 	ret := testResultDisplay{
-		displayName:    in.Name,
-		displayPercent: in.PassPercentage,
-		totalRuns:      in.Successes + in.Failures,
-		flakedRuns:     in.Flakes,
+		displayName:     in.Name,
+		displayPercent:  in.PassPercentage,
+		totalRuns:       in.Successes + in.Failures,
+		flakedRuns:      in.Flakes,
+		bugLookupFailed: in.BugLookupFailed,
 	}
 	for _, bug := range in.BugList {
 		ret.bugList = append(ret.bugList, bug)
@@ -51,6 +53,7 @@ func testResultToDisplay(in sippyprocessingv1.TestResult) testResultDisplay {
 
 func failedTestResultToDisplay(in sippyprocessingv1.FailingTestResult) testResultDisplay {
 	ret := testResultToDisplay(in.TestResultAcrossAllJobs)
+	ret.classification = in.Classification
 
 	for _, jobResult := range in.JobResults {
 		ret.jobResults = append(ret.jobResults, failingJobResultToDisplay(jobResult))
@@ -67,17 +70,19 @@ type testResultRenderBuilder struct {
 	prevTestResult *testResultDisplay
 
 	release             string
+	ciSearchURL         string
 	maxJobResultsToShow int
 	colors              ColorizationCriteria
 	startCollapsedBool  bool
 	baseIndentDepth     int
 }
 
-func NewTestResultRenderer(sectionBlock string, curr testResultDisplay, release string) *testResultRenderBuilder {
+func NewTestResultRenderer(sectionBlock string, curr testResultDisplay, release, ciSearchURL string) *testResultRenderBuilder {
 	return &testResultRenderBuilder{
 		sectionBlock:        sectionBlock,
 		currTestResult:      curr,
 		release:             release,
+		ciSearchURL:         ciSearchURL,
 		maxJobResultsToShow: 10, // just a default, can be overridden
 		colors: ColorizationCriteria{
 			MinRedPercent:    0,  // failure.  In this range, there is a systemic failure so severe that a reliable signal isn't available.
@@ -87,12 +92,12 @@ func NewTestResultRenderer(sectionBlock string, curr testResultDisplay, release
 	}
 }
 
-func NewTestResultRendererForTestResult(sectionBlock string, curr sippyprocessingv1.TestResult, release string) *testResultRenderBuilder {
-	return NewTestResultRenderer(sectionBlock, testResultToDisplay(curr), release)
+func NewTestResultRendererForTestResult(sectionBlock string, curr sippyprocessingv1.TestResult, release, ciSearchURL string) *testResultRenderBuilder {
+	return NewTestResultRenderer(sectionBlock, testResultToDisplay(curr), release, ciSearchURL)
 }
 
-func NewTestResultRendererForFailedTestResult(sectionBlock string, curr sippyprocessingv1.FailingTestResult, release string) *testResultRenderBuilder {
-	return NewTestResultRenderer(sectionBlock, failedTestResultToDisplay(curr), release)
+func NewTestResultRendererForFailedTestResult(sectionBlock string, curr sippyprocessingv1.FailingTestResult, release, ciSearchURL string) *testResultRenderBuilder {
+	return NewTestResultRenderer(sectionBlock, failedTestResultToDisplay(curr), release, ciSearchURL)
 }
 
 func (b *testResultRenderBuilder) WithPrevious(prev *testResultDisplay) *testResultRenderBuilder {
@@ -177,11 +182,11 @@ func (b *testResultRenderBuilder) ToHTML() string {
 
 	s := ""
 
-	encodedTestName := url.QueryEscape(regexp.QuoteMeta(b.currTestResult.displayName))
-	testLink := fmt.Sprintf("<a target=\"_blank\" href=\"https://search.ci.openshift.org/?maxAge=168h&context=1&type=bug%%2Bjunit&name=%s&maxMatches=5&maxBytes=20971520&groupBy=job&search=%s\">%s</a>", b.release, encodedTestName, b.currTestResult.displayName)
+	testLink := fmt.Sprintf("<a target=\"_blank\" href=\"%s\">%s</a>", buganalysis.TestSearchURL(b.ciSearchURL, b.release, b.currTestResult.displayName), b.currTestResult.displayName)
+	testLink += classificationBadgeHTML(b.currTestResult.classification)
 
 	klog.V(2).Infof("processing top failing tests %s, bugs: %v", b.currTestResult.displayName, b.currTestResult.bugList)
-	bugHTML := bugHTMLForTest(b.currTestResult.bugList, b.currTestResult.associatedBugList, b.release, b.currTestResult.displayName)
+	bugHTML := bugHTMLForTest(b.currTestResult.bugList, b.currTestResult.associatedBugList, b.currTestResult.bugLookupFailed, b.release, b.ciSearchURL, b.currTestResult.displayName)
 	if b.prevTestResult != nil {
 		arrow := GetArrow(b.currTestResult.totalRuns, b.currTestResult.displayPercent, b.prevTestResult.displayPercent)
 
@@ -217,7 +222,7 @@ func (b *testResultRenderBuilder) ToHTML() string {
 			}
 		}
 
-		rows = rows + NewJobResultRenderer(jobCollapseSectionName, failingTestJobResult, b.release).
+		rows = rows + NewJobResultRenderer(jobCollapseSectionName, failingTestJobResult, b.release, b.ciSearchURL).
 			WithIndent(b.baseIndentDepth+1).
 			WithPrevious(prevTestJobResult).
 			StartCollapsed().
@@ -241,7 +246,7 @@ func (b *testResultRenderBuilder) ToHTML() string {
 }
 
 // returns the table row html and a list of tests displayed
-func getTestRowHTML(release, testsCollapseName string, currTestResults, prevTestResults []testResultDisplay, maxTestResultsToShow int) (string, []string) {
+func getTestRowHTML(release, ciSearchURL, testsCollapseName string, currTestResults, prevTestResults []testResultDisplay, maxTestResultsToShow int) (string, []string) {
 	s := ""
 	testNames := []string{}
 
@@ -268,7 +273,7 @@ func getTestRowHTML(release, testsCollapseName string, currTestResults, prevTest
 		}
 
 		testRows = testRows +
-			NewTestResultRenderer(testsCollapseName, test, release).
+			NewTestResultRenderer(testsCollapseName, test, release, ciSearchURL).
 				WithIndent(1).
 				WithPrevious(prev).
 				StartCollapsed().
@@ -291,3 +296,21 @@ func getTestRowHTML(release, testsCollapseName string, currTestResults, prevTest
 
 	return s, testNames
 }
+
+var classificationBadges = map[sippyprocessingv1.TestClassification]struct {
+	class string
+	title string
+}{
+	sippyprocessingv1.TestFlaky:             {class: "badge-warning", title: "Alternates between passing and failing, or only passes on retry"},
+	sippyprocessingv1.TestPermanentlyBroken: {class: "badge-dark", title: "Almost never passes"},
+	sippyprocessingv1.TestNewlyBroken:       {class: "badge-danger", title: "Passed reliably, but has failed every recent run"},
+}
+
+// classificationBadgeHTML returns a badge for the classification of a failing test.  Stable tests have no badge.
+func classificationBadgeHTML(classification sippyprocessingv1.TestClassification) string {
+	badge, ok := classificationBadges[classification]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(` <span class="badge %s" title="%s">%s</span>`, badge.class, badge.title, classification)
+}
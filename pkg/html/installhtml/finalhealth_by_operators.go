@@ -42,7 +42,7 @@ func operatorHealthTests(curr, prev sippyprocessingv1.TestReport) string {
 	return dataForTestsByVariant.getTableHTML("Operator Health by Operator", "OperatorHealthByOperator", "Operator Health by Operator by Variant", columnNames, getOperatorFromTest)
 }
 
-func summaryOperatorHealthRelatedTests(curr, prev sippyprocessingv1.TestReport, numDays int, release string) string {
+func summaryOperatorHealthRelatedTests(curr, prev sippyprocessingv1.TestReport, numDays int, release, ciSearchURL string) string {
 	// test name | bug | pass rate | higher/lower | pass rate
 	s := fmt.Sprintf(`
 	<table class="table">
@@ -60,7 +60,7 @@ func summaryOperatorHealthRelatedTests(curr, prev sippyprocessingv1.TestReport,
 		</tr>
 	`, numDays)
 
-	s += failingTestsRows(curr.ByTest, prev.ByTest, release, isOperatorHealthRelatedTest)
+	s += failingTestsRows(curr.ByTest, prev.ByTest, release, ciSearchURL, isOperatorHealthRelatedTest)
 
 	s = s + "</table>"
 
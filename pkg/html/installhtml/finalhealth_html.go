@@ -2,6 +2,7 @@ package installhtml
 
 import (
 	"fmt"
+	"html"
 	"net/http"
 
 	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
@@ -26,16 +27,16 @@ var (
 `
 )
 
-func PrintOperatorHealthHtmlReport(w http.ResponseWriter, req *http.Request, report, prevReport sippyprocessingv1.TestReport, numDays int, release string) {
+func PrintOperatorHealthHtmlReport(w http.ResponseWriter, req *http.Request, report, prevReport sippyprocessingv1.TestReport, numDays int, release, ciSearchURL string) {
 	w.Header().Set("Content-Type", "text/html;charset=UTF-8")
 	fmt.Fprintf(w, generichtml.HTMLPageStart, "Release "+release+" Install Dashboard")
 	if len(prevReport.AnalysisWarnings)+len(report.AnalysisWarnings) > 0 {
 		warningsHTML := ""
 		for _, analysisWarning := range prevReport.AnalysisWarnings {
-			warningsHTML += "<p>" + analysisWarning + "</p>\n"
+			warningsHTML += "<p>" + html.EscapeString(analysisWarning) + "</p>\n"
 		}
 		for _, analysisWarning := range report.AnalysisWarnings {
-			warningsHTML += "<p>" + analysisWarning + "</p>\n"
+			warningsHTML += "<p>" + html.EscapeString(analysisWarning) + "</p>\n"
 		}
 		fmt.Fprintf(w, generichtml.WarningHeader, warningsHTML)
 	}
@@ -49,7 +50,7 @@ func PrintOperatorHealthHtmlReport(w http.ResponseWriter, req *http.Request, rep
 	fmt.Fprintln(w)
 
 	fmt.Fprintln(w)
-	fmt.Fprint(w, summaryOperatorHealthRelatedTests(report, prevReport, numDays, release))
+	fmt.Fprint(w, summaryOperatorHealthRelatedTests(report, prevReport, numDays, release, ciSearchURL))
 	fmt.Fprintln(w)
 
 	//w.Write(result)
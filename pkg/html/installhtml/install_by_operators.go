@@ -46,7 +46,7 @@ func installOperatorTests(curr, prev sippyprocessingv1.TestReport) string {
 	return dataForTestsByVariant.getTableHTML("Install Rates by Operator", "InstallRatesByOperator", "Install Rates by Operator by Variant", columnNames, getOperatorFromTest)
 }
 
-func summaryInstallRelatedTests(curr, prev sippyprocessingv1.TestReport, numDays int, release string) string {
+func summaryInstallRelatedTests(curr, prev sippyprocessingv1.TestReport, numDays int, release, ciSearchURL string) string {
 	// test name | bug | pass rate | higher/lower | pass rate
 	s := fmt.Sprintf(`
 	<table class="table">
@@ -64,7 +64,7 @@ func summaryInstallRelatedTests(curr, prev sippyprocessingv1.TestReport, numDays
 		</tr>
 	`, numDays)
 
-	s += failingTestsRows(curr.ByTest, prev.ByTest, release, isInstallRelatedTest)
+	s += failingTestsRows(curr.ByTest, prev.ByTest, release, ciSearchURL, isInstallRelatedTest)
 
 	s = s + "</table>"
 
@@ -24,7 +24,7 @@ func testDetailTests(curr, prev sippyprocessingv1.TestReport, testSubstrings []s
 	return dataForTestsByVariant.getTableHTML("Details for Tests", "TestDetailByVariant", "Test Details by Variant", variants.List(), noChange)
 }
 
-func summaryTestDetailRelatedTests(curr, prev sippyprocessingv1.TestReport, testSubstrings []string, numDays int, release string) string {
+func summaryTestDetailRelatedTests(curr, prev sippyprocessingv1.TestReport, testSubstrings []string, numDays int, release, ciSearchURL string) string {
 	// test name | test | pass rate | higher/lower | pass rate
 	s := fmt.Sprintf(`
 	<table class="table">
@@ -42,7 +42,7 @@ func summaryTestDetailRelatedTests(curr, prev sippyprocessingv1.TestReport, test
 		</tr>
 	`, numDays)
 
-	s += failingTestsRows(curr.ByTest, prev.ByTest, release, isTestDetailRelatedTest(testSubstrings))
+	s += failingTestsRows(curr.ByTest, prev.ByTest, release, ciSearchURL, isTestDetailRelatedTest(testSubstrings))
 
 	s = s + "</table>"
 
@@ -50,7 +50,7 @@ func upgradeOperatorTests(curr, prev sippyprocessingv1.TestReport) string {
 	return dataForTestsByVariant.getTableHTML("Upgrade Rates by Operator", "UpgradeRatesByOperator", "Upgrade Rates by Operator by Variant", columnNames, getOperatorFromTest)
 }
 
-func summaryUpgradeRelatedTests(curr, prev sippyprocessingv1.TestReport, numDays int, release string) string {
+func summaryUpgradeRelatedTests(curr, prev sippyprocessingv1.TestReport, numDays int, release, ciSearchURL string) string {
 	// test name | bug | pass rate | higher/lower | pass rate
 	s := fmt.Sprintf(`
 	<table class="table">
@@ -68,7 +68,7 @@ func summaryUpgradeRelatedTests(curr, prev sippyprocessingv1.TestReport, numDays
 		</tr>
 	`, numDays)
 
-	s += failingTestsRows(curr.ByTest, prev.ByTest, release, isUpgradeRelatedTest)
+	s += failingTestsRows(curr.ByTest, prev.ByTest, release, ciSearchURL, isUpgradeRelatedTest)
 
 	s = s + "</table>"
 
@@ -83,7 +83,7 @@ func neverMatch(testResult sippyprocessingv1.TestResult) bool {
 	return false
 }
 
-func summaryUpgradeRelatedJobs(report, reportPrev sippyprocessingv1.TestReport, numDays int, release string) string {
+func summaryUpgradeRelatedJobs(report, reportPrev sippyprocessingv1.TestReport, numDays int, release, ciSearchURL string) string {
 	s := fmt.Sprintf(`
 	<table class="table">
 		<tr>
@@ -102,7 +102,7 @@ func summaryUpgradeRelatedJobs(report, reportPrev sippyprocessingv1.TestReport,
 			continue
 		}
 		prevJobResult := util.FindJobResultForJobName(currJobResult.Name, reportPrev.InfrequentJobResults)
-		jobHTML := generichtml.NewJobResultRendererFromJobResult("by-infrequent-job-name", currJobResult, release).
+		jobHTML := generichtml.NewJobResultRendererFromJobResult("by-infrequent-job-name", currJobResult, release, ciSearchURL).
 			WithPreviousJobResult(prevJobResult).
 			ToHTML()
 
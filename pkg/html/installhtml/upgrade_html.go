@@ -2,6 +2,7 @@ package installhtml
 
 import (
 	"fmt"
+	"html"
 	"net/http"
 
 	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
@@ -26,16 +27,16 @@ var (
 `
 )
 
-func PrintUpgradeHtmlReport(w http.ResponseWriter, req *http.Request, report, prevReport sippyprocessingv1.TestReport, numDays int, release string) {
+func PrintUpgradeHtmlReport(w http.ResponseWriter, req *http.Request, report, prevReport sippyprocessingv1.TestReport, numDays int, release, ciSearchURL string) {
 	w.Header().Set("Content-Type", "text/html;charset=UTF-8")
 	fmt.Fprintf(w, generichtml.HTMLPageStart, "Release "+release+" Upgrade Dashboard")
 	if len(prevReport.AnalysisWarnings)+len(report.AnalysisWarnings) > 0 {
 		warningsHTML := ""
 		for _, analysisWarning := range prevReport.AnalysisWarnings {
-			warningsHTML += "<p>" + analysisWarning + "</p>\n"
+			warningsHTML += "<p>" + html.EscapeString(analysisWarning) + "</p>\n"
 		}
 		for _, analysisWarning := range report.AnalysisWarnings {
-			warningsHTML += "<p>" + analysisWarning + "</p>\n"
+			warningsHTML += "<p>" + html.EscapeString(analysisWarning) + "</p>\n"
 		}
 		fmt.Fprintf(w, generichtml.WarningHeader, warningsHTML)
 	}
@@ -49,11 +50,11 @@ func PrintUpgradeHtmlReport(w http.ResponseWriter, req *http.Request, report, pr
 	fmt.Fprintln(w)
 
 	fmt.Fprintln(w)
-	fmt.Fprint(w, summaryUpgradeRelatedTests(report, prevReport, numDays, release))
+	fmt.Fprint(w, summaryUpgradeRelatedTests(report, prevReport, numDays, release, ciSearchURL))
 	fmt.Fprintln(w)
 
 	fmt.Fprintln(w)
-	fmt.Fprint(w, summaryUpgradeRelatedJobs(report, prevReport, numDays, release))
+	fmt.Fprint(w, summaryUpgradeRelatedJobs(report, prevReport, numDays, release, ciSearchURL))
 	fmt.Fprintln(w)
 
 	//w.Write(result)
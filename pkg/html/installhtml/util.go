@@ -195,7 +195,7 @@ func installCellHTMLFromTestResult(cellResult *currPrevTestResult, colors generi
 
 type testFilterFunc func(testResult sippyprocessingv1.TestResult) bool
 
-func failingTestsRows(topFailingTests, prevTests []sippyprocessingv1.FailingTestResult, release string, testFilterFn testFilterFunc) string {
+func failingTestsRows(topFailingTests, prevTests []sippyprocessingv1.FailingTestResult, release, ciSearchURL string, testFilterFn testFilterFunc) string {
 	s := ""
 
 	for _, testResult := range topFailingTests {
@@ -204,7 +204,7 @@ func failingTestsRows(topFailingTests, prevTests []sippyprocessingv1.FailingTest
 		}
 
 		s = s +
-			generichtml.NewTestResultRendererForFailedTestResult("", testResult, release).
+			generichtml.NewTestResultRendererForFailedTestResult("", testResult, release, ciSearchURL).
 				WithPreviousFailedTestResult(util.FindFailedTestResult(testResult.TestName, prevTests)).
 				ToHTML()
 	}
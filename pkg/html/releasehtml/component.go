@@ -0,0 +1,149 @@
+package releasehtml
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	"github.com/openshift/sippy/pkg/html/generichtml"
+)
+
+// PrintComponentHtmlReport writes the dashboard of one bugzilla component: its failing tests, the jobs it breaks, its
+// open bugs, and the health of its operators.
+func PrintComponentHtmlReport(w http.ResponseWriter, report sippyv1.ComponentReport, numDays int, timestamp time.Time) {
+	w.Header().Set("Content-Type", "text/html;charset=UTF-8")
+	fmt.Fprintf(w, generichtml.HTMLPageStart, "Release "+report.Release+" "+report.Component+" Dashboard")
+
+	teams := "no team"
+	if len(report.Teams) > 0 {
+		teams = strings.Join(report.Teams, ", ")
+	}
+	fmt.Fprintf(w, `<h1 class=text-center>Release %s %s Dashboard</h1>
+<p class="small mb-3">
+	Owned by %s.  Jump to: <a href="#ComponentOperators">Operators</a> | <a href="#ComponentFailingTests">Failing Tests</a> | <a href="#ComponentBrokenJobs">Broken Jobs</a> | <a href="#ComponentBugs">Open Bugs</a> |
+	<a href="/api/component?release=%s&name=%s">JSON</a>
+</p>
+`, html.EscapeString(report.Release), html.EscapeString(report.Component), html.EscapeString(teams), url.QueryEscape(report.Release), url.QueryEscape(report.Component))
+
+	fmt.Fprint(w, componentOperatorsTable(report))
+	fmt.Fprint(w, componentFailingTestsTable(report, numDays))
+	fmt.Fprint(w, componentBrokenJobsTable(report, numDays))
+	fmt.Fprint(w, componentBugsTable(report))
+
+	fmt.Fprintf(w, generichtml.HTMLPageEnd, timestamp.Format("Jan 2 15:04 2006 MST"))
+}
+
+func componentTableHeader(columns int, anchor, title, description string) string {
+	return fmt.Sprintf(`
+	<table class="table">
+		<tr>
+			<th colspan=%d class="text-center">
+				<a class="text-dark" id="%s" href="#%s">%s</a>
+				<i class="fa fa-info-circle" title=%q></i>
+			</th>
+		</tr>
+`, columns, anchor, anchor, title, description)
+}
+
+// passRateCells renders the latest pass rate, the trend, and the prev pass rate.
+func passRateCells(passRates map[string]sippyv1.PassRate, colors generichtml.ColorizationCriteria) string {
+	latest, ok := passRates["latest"]
+	if !ok {
+		return `<td class="text-center table-secondary">no-data</td><td/><td class="text-center table-secondary">no-data</td>`
+	}
+	prev, ok := passRates["prev"]
+	if !ok {
+		return fmt.Sprintf(`<td class="text-center %s">%0.2f%% <span class="text-nowrap">(%d runs)</span></td><td>%s</td><td class="text-center">NA</td>`,
+			colors.GetColor(latest.Percentage, latest.Runs), latest.Percentage, latest.Runs, generichtml.Flat)
+	}
+	return fmt.Sprintf(`<td class="text-center %s">%0.2f%% <span class="text-nowrap">(%d runs)</span></td><td>%s</td><td class="text-center %s">%0.2f%% <span class="text-nowrap">(%d runs)</span></td>`,
+		colors.GetColor(latest.Percentage, latest.Runs), latest.Percentage, latest.Runs,
+		generichtml.GetArrow(latest.Runs, latest.Percentage, prev.Percentage),
+		colors.GetColor(prev.Percentage, prev.Runs), prev.Percentage, prev.Runs)
+}
+
+func componentOperatorsTable(report sippyv1.ComponentReport) string {
+	if len(report.Operators) == 0 {
+		return ""
+	}
+	s := componentTableHeader(10, "ComponentOperators", "Operators", "Install, upgrade, and final health pass rates of the operators of the component, latest and previous 7 days.")
+	s += `		<tr><th>Operator</th><th colspan=3 class="text-center">Install</th><th colspan=3 class="text-center">Upgrade</th><th colspan=3 class="text-center">Health</th></tr>
+`
+	for _, operator := range report.Operators {
+		s += fmt.Sprintf("		<tr><td>%s</td>%s%s%s</tr>\n",
+			html.EscapeString(operator.Name),
+			passRateCells(operator.Install, generichtml.OverallInstallUpgradeColors),
+			passRateCells(operator.Upgrade, generichtml.OverallInstallUpgradeColors),
+			passRateCells(operator.Health, generichtml.OverallInstallUpgradeColors),
+		)
+	}
+	s += "	</table>\n"
+	return s
+}
+
+func componentFailingTestsTable(report sippyv1.ComponentReport, numDays int) string {
+	s := componentTableHeader(6, "ComponentFailingTests", "Failing Tests", "Tests owned by the component that failed, sorted by passing rate.")
+	s += fmt.Sprintf(`		<tr><th>Test Name</th><th>Team</th><th>Bugs</th><th class="text-center">Latest %d Days</th><th/><th class="text-center">Previous 7 Days</th></tr>
+`, numDays)
+	if len(report.FailingTests) == 0 {
+		s += `		<tr><td colspan=6 class="text-center">No failing tests</td></tr>
+`
+	}
+	for _, test := range report.FailingTests {
+		bugs := fmt.Sprintf(`<a target="_blank" href="%s">search</a>`, html.EscapeString(test.Url))
+		if test.BugLookupFailed {
+			bugs += " " + generichtml.BugLookupFailedHTML
+		}
+		for _, bug := range test.Bugs {
+			bugs += fmt.Sprintf(` <a target="_blank" href="%s">%s</a>`, html.EscapeString(bug.Url), html.EscapeString(bug.Name()))
+		}
+		s += fmt.Sprintf("		<tr><td>%s %s</td><td>%s</td><td>%s</td>%s</tr>\n",
+			html.EscapeString(test.Name), generichtml.GetTestDetailsButtonHTML(report.Release, test.Name),
+			html.EscapeString(test.Team), bugs, passRateCells(test.PassRates, generichtml.StandardColors))
+	}
+	s += "	</table>\n"
+	return s
+}
+
+func componentBrokenJobsTable(report sippyv1.ComponentReport, numDays int) string {
+	s := componentTableHeader(5, "ComponentBrokenJobs", "Broken Jobs", "Jobs with runs that failed because of tests of the component, sorted by fail rate.  Hover over the test count for the tests.")
+	s += fmt.Sprintf(`		<tr><th>Job Name</th><th>Failing Tests</th><th class="text-center">Latest %d Days</th><th/><th class="text-center">Previous 7 Days</th></tr>
+`, numDays)
+	if len(report.BrokenJobs) == 0 {
+		s += `		<tr><td colspan=5 class="text-center">No broken jobs</td></tr>
+`
+	}
+	for _, job := range report.BrokenJobs {
+		// fail rates are shown as pass rates so that they are colored and trended like everything else
+		passRates := map[string]sippyv1.PassRate{
+			"latest": {Percentage: 100 - job.FailPercentages["latest"], Runs: job.Runs},
+		}
+		if prev, ok := job.FailPercentages["prev"]; ok {
+			passRates["prev"] = sippyv1.PassRate{Percentage: 100 - prev, Runs: job.Runs}
+		}
+		s += fmt.Sprintf("		<tr><td>%s</td><td title=\"%s\">%d tests, %d of %d runs failed</td>%s</tr>\n",
+			html.EscapeString(job.Name), html.EscapeString(strings.Join(job.FailingTests, "\n")), len(job.FailingTests), job.RunsFailed, job.Runs,
+			passRateCells(passRates, generichtml.StandardColors))
+	}
+	s += "	</table>\n"
+	return s
+}
+
+func componentBugsTable(report sippyv1.ComponentReport) string {
+	s := componentTableHeader(3, "ComponentBugs", "Open Bugs", "Open bugs that match failing tests and belong to the component, or to tests that the component owns.")
+	s += "		<tr><th>Bug</th><th>Status</th><th>Summary</th></tr>\n"
+	if len(report.Bugs) == 0 {
+		s += `		<tr><td colspan=3 class="text-center">No open bugs</td></tr>
+`
+	}
+	for _, bug := range report.Bugs {
+		s += fmt.Sprintf("		<tr><td><a target=\"_blank\" href=\"%s\">%s</a></td><td>%s</td><td>%s</td></tr>\n",
+			html.EscapeString(bug.Url), html.EscapeString(bug.Name()), html.EscapeString(bug.Status), html.EscapeString(bug.Summary))
+	}
+	s += "	</table>\n"
+	return s
+}
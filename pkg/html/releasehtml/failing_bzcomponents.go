@@ -11,7 +11,7 @@ import (
 	"github.com/openshift/sippy/pkg/util"
 )
 
-func summaryJobsFailuresByBugzillaComponent(report, reportPrev sippyprocessingv1.TestReport, numDays int, release string) string {
+func summaryJobsFailuresByBugzillaComponent(report, reportPrev sippyprocessingv1.TestReport, numDays int, release, ciSearchURL string) string {
 	failuresByBugzillaComponent := summarizeJobsFailuresByBugzillaComponent(report)
 	failuresByBugzillaComponentPrev := summarizeJobsFailuresByBugzillaComponent(reportPrev)
 
@@ -41,7 +41,7 @@ func summaryJobsFailuresByBugzillaComponent(report, reportPrev sippyprocessingv1
 	for _, bugzillaComponentResult := range failuresByBugzillaComponent {
 		prev := util.FindBugzillaJobFailures(bugzillaComponentResult.Name, failuresByBugzillaComponentPrev)
 
-		bugzillaComponentHTML := generichtml.NewJobAggregationResultRendererFromBugzillaComponentResult("by-bugzilla-component", bugzillaComponentResult, release).
+		bugzillaComponentHTML := generichtml.NewJobAggregationResultRendererFromBugzillaComponentResult("by-bugzilla-component", bugzillaComponentResult, release, ciSearchURL).
 			WithColors(colors).
 			WithPreviousBugzillaComponentResult(prev).
 			ToHTML()
@@ -10,12 +10,12 @@ import (
 	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
 )
 
-func summaryTopFailingTestsWithBug(topFailingTestsWithBug, allTests []sippyprocessingv1.FailingTestResult, numDays int, release string) string {
+func summaryTopFailingTestsWithBug(topFailingTestsWithBug, allTests []sippyprocessingv1.FailingTestResult, numDays int, release, ciSearchURL string) string {
 	if len(topFailingTestsWithBug) == 0 {
 		return ""
 	}
 
-	rows, testNames := topFailingTestsRows(topFailingTestsWithBug, allTests, release)
+	rows, testNames := topFailingTestsRows(topFailingTestsWithBug, allTests, release, ciSearchURL)
 	s := fmt.Sprintf(`
 	<table class="table">
 		<tr>
@@ -39,8 +39,8 @@ func summaryTopFailingTestsWithBug(topFailingTestsWithBug, allTests []sippyproce
 	return s
 }
 
-func summaryTopFailingTestsWithoutBug(topFailingTestsWithBug, allTests []sippyprocessingv1.FailingTestResult, numDays int, release string) string {
-	rows, testNames := topFailingTestsRows(topFailingTestsWithBug, allTests, release)
+func summaryTopFailingTestsWithoutBug(topFailingTestsWithBug, allTests []sippyprocessingv1.FailingTestResult, numDays int, release, ciSearchURL string) string {
+	rows, testNames := topFailingTestsRows(topFailingTestsWithBug, allTests, release, ciSearchURL)
 	s := fmt.Sprintf(`
 	<table class="table">
 		<tr>
@@ -64,11 +64,11 @@ func summaryTopFailingTestsWithoutBug(topFailingTestsWithBug, allTests []sippypr
 	return s
 }
 
-func summaryCuratedTests(curr, prev sippyprocessingv1.TestReport, numDays int, release string) string {
+func summaryCuratedTests(curr, prev sippyprocessingv1.TestReport, numDays int, release, ciSearchURL string) string {
 	if len(curr.CuratedTests) == 0 {
 		return ""
 	}
-	rows, testNames := topFailingTestsRows(curr.CuratedTests, prev.ByTest, release)
+	rows, testNames := topFailingTestsRows(curr.CuratedTests, prev.ByTest, release, ciSearchURL)
 
 	s := fmt.Sprintf(`
 	<table class="table">
@@ -94,7 +94,7 @@ func summaryCuratedTests(curr, prev sippyprocessingv1.TestReport, numDays int, r
 }
 
 // returns the rows to display and the names of the tests being shown
-func topFailingTestsRows(topFailingTests, prevTests []sippyprocessingv1.FailingTestResult, release string) (string, []string) {
+func topFailingTestsRows(topFailingTests, prevTests []sippyprocessingv1.FailingTestResult, release, ciSearchURL string) (string, []string) {
 	// test name | bug | pass rate | higher/lower | pass rate
 	s := ""
 	testNames := []string{}
@@ -115,7 +115,7 @@ func topFailingTestsRows(topFailingTests, prevTests []sippyprocessingv1.FailingT
 		testPrev := util.FindFailedTestResult(testResult.TestName, prevTests)
 
 		s = s +
-			generichtml.NewTestResultRendererForFailedTestResult("", testResult, release).
+			generichtml.NewTestResultRendererForFailedTestResult("", testResult, release, ciSearchURL).
 				WithPreviousFailedTestResult(testPrev).
 				ToHTML()
 	}
@@ -0,0 +1,63 @@
+package releasehtml
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+)
+
+// maxFailureClusters limits how many clusters are listed on the page.  The JSON has all of them.
+const maxFailureClusters = 20
+
+func failureClusters(report sippyprocessingv1.TestReport, release string) string {
+	s := `
+	<table class="table">
+		<tr>
+			<th colspan=4 class="text-center">
+				<a class="text-dark" id="FailureClusters" href="#FailureClusters">Tests That Fail Together</a>
+				<i class="fa fa-info-circle" title="Sets of tests that repeatedly fail in the same job runs, which usually means they share a root cause.  Confidence is the lowest fraction of the failures of any test in the set that happened together with the rest of the set.  Job runs with a large number of failures are excluded."></i>
+			</th>
+		</tr>
+		<tr>
+			<th>Tests</th><th>Failed Together</th><th>Jobs</th><th>Example Runs</th>
+		</tr>
+	`
+
+	if len(report.FailureClusters) == 0 {
+		s += `<tr><td colspan=4 class="font-italic">None</td></tr>`
+	}
+
+	template := `
+		<tr>
+			<td>%s</td><td>%d runs <span class="text-nowrap">(%0.0f%% confidence)</span></td><td>%s</td><td>%s</td>
+		</tr>
+	`
+	for i, cluster := range report.FailureClusters {
+		if i >= maxFailureClusters {
+			break
+		}
+		tests := []string{}
+		for _, testName := range cluster.TestNames {
+			tests = append(tests, fmt.Sprintf(`<a target="_blank" href="/testdetails?release=%s&test=%s">%s</a>`, url.QueryEscape(release), url.QueryEscape(testName), html.EscapeString(testName)))
+		}
+		jobs := []string{}
+		for _, job := range cluster.Jobs {
+			jobs = append(jobs, html.EscapeString(job))
+		}
+		if len(cluster.Variants) > 0 {
+			jobs = append(jobs, fmt.Sprintf(`<span class="font-italic">variants: %s</span>`, html.EscapeString(strings.Join(cluster.Variants, ", "))))
+		}
+		examples := []string{}
+		for j, runURL := range cluster.ExampleJobRunURLs {
+			examples = append(examples, JobRunLink(release, runURL, fmt.Sprintf("run %d", j+1)))
+		}
+
+		s += fmt.Sprintf(template, strings.Join(tests, "<br/>"), cluster.Support, cluster.Confidence*100, strings.Join(jobs, "<br/>"), strings.Join(examples, " "))
+	}
+
+	s = s + "</table>"
+	return s
+}
@@ -13,7 +13,7 @@ import (
 	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
 )
 
-func summaryTopNegativelyMovingJobs(twoDaysJobs, prevJobs []sippyprocessingv1.JobResult, jobTestCount int, release string) string {
+func summaryTopNegativelyMovingJobs(twoDaysJobs, prevJobs []sippyprocessingv1.JobResult, jobTestCount int, release, ciSearchURL string) string {
 	type jobPassChange struct {
 		jobName              string
 		passPercentageChange float64
@@ -75,7 +75,7 @@ func summaryTopNegativelyMovingJobs(twoDaysJobs, prevJobs []sippyprocessingv1.Jo
 		currJobResult = testreportconversion.FilterJobResultTests(currJobResult, testFilterFn)
 		prevJobResult = testreportconversion.FilterJobResultTests(prevJobResult, testFilterFn)
 
-		jobHTML := generichtml.NewJobResultRendererFromJobResult("by-job-name", *currJobResult, release).
+		jobHTML := generichtml.NewJobResultRendererFromJobResult("by-job-name", *currJobResult, release, ciSearchURL).
 			WithMaxTestResultsToShow(jobTestCount).
 			WithPreviousJobResult(prevJobResult).
 			ToHTML()
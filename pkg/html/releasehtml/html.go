@@ -2,17 +2,20 @@ package releasehtml
 
 import (
 	"fmt"
+	"html"
 	"net/http"
-	"net/url"
 	"regexp"
 	"sort"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/openshift/sippy/pkg/html/generichtml"
 
 	bugsv1 "github.com/openshift/sippy/pkg/apis/bugs/v1"
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
 	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/buganalysis"
 	"github.com/openshift/sippy/pkg/util"
 	"k8s.io/klog"
 )
@@ -22,8 +25,6 @@ var (
 )
 
 const (
-	BugSearchUrl = "https://search.ci.openshift.org/?maxAge=168h&context=1&type=bug%%2Bjunit&name=&maxMatches=5&maxBytes=20971520&groupBy=job&search="
-
 	landingHtmlPageEnd = `
 </div>
 <p>
@@ -49,36 +50,40 @@ const (
 <p class="small mb-3 text-nowrap">
 	Jump to: <a href="#JobPassRatesByVariant">Job Pass Rates By Variant</a> | <a href="#CuratedTRTTests">Curated TRT Tests</a> | <a href="#TopFailingTestsWithoutABug">Top Failing Tests Without a Bug</a> | <a href="#TopFailingTestsWithABug">Top Failing Tests With a Bug</a> | <a href="#JobPassRatesByJobName">Job Pass Rates By Job Name</a> |
 			 <br/>	          
-	         <a href="#JobByMostReducedPassRate">Job Pass Rates By Most Reduced Pass Rate</a> | <a href="#InfrequentJobPassRatesByJobName">Infrequent Job Pass Rates By Job Name</a> | <a href="#CanaryTestFailures">Canary Test Failures</a> | <a href="#JobRunsWithFailureGroups">Job Runs With Failure Groups</a> | <a href="#TestImpactingBugs">Test Impacting Bugs</a> |
+	         <a href="#JobByMostReducedPassRate">Job Pass Rates By Most Reduced Pass Rate</a> | <a href="#SignificantPassRateChanges">Significant Pass Rate Changes</a> | <a href="#InfrequentJobPassRatesByJobName">Infrequent Job Pass Rates By Job Name</a> | <a href="#CanaryTestFailures">Canary Test Failures</a> | <a href="#JobRunsWithFailureGroups">Job Runs With Failure Groups</a> | <a href="#FailureClusters">Tests That Fail Together</a> | <a href="#TestImpactingBugs">Test Impacting Bugs</a> |
 	         <br/>
-             <a href="#TestImpactingComponents">Test Impacting Components</a> | <a href="#JobImpactingBZComponents">Job Impacting BZ Components</a> | <a href="/jobs?release={{ .Release }}" target="_blank">Jobs Grid</a>
+             <a href="#TestImpactingComponents">Test Impacting Components</a> | <a href="#JobImpactingBZComponents">Job Impacting BZ Components</a> | <a href="/jobs?release={{ .Release }}" target="_blank">Jobs Grid</a> | <a href="/variants/matrix?release={{ .Release }}" target="_blank">Variant Matrix</a>
 </p>
 
 {{ topLevelIndicators .Current .Prev .Release }}
 
-{{ summaryJobsByVariant .Current .Prev .NumDays .JobTestCount .Release }}
+{{ summaryJobsByVariant .Current .Prev .NumDays .JobTestCount .Release .CISearchURL }}
+
+{{ summaryCuratedTests .Current .Prev .NumDays .Release .CISearchURL }} 
 
-{{ summaryCuratedTests .Current .Prev .NumDays .Release }} 
+{{ summaryTopFailingTestsWithoutBug .Current.TopFailingTestsWithoutBug .Prev.ByTest .NumDays .Release .CISearchURL }}
 
-{{ summaryTopFailingTestsWithoutBug .Current.TopFailingTestsWithoutBug .Prev.ByTest .NumDays .Release }}
+{{ summaryTopFailingTestsWithBug .Current.TopFailingTestsWithBug .Prev.ByTest .NumDays .Release .CISearchURL }}
 
-{{ summaryTopFailingTestsWithBug .Current.TopFailingTestsWithBug .Prev.ByTest .NumDays .Release }}
+{{ summaryTopNegativelyMovingJobs .TwoDay.ByJob .Prev.ByJob .JobTestCount .Release .CISearchURL }}
 
-{{ summaryTopNegativelyMovingJobs .TwoDay.ByJob .Prev.ByJob .JobTestCount .Release }}
+{{ passRateChanges .Current .Prev .NumDays .Release }}
 
-{{ summaryFrequentJobPassRatesByJobName .Current .Prev .Release .NumDays .JobTestCount }}
+{{ summaryFrequentJobPassRatesByJobName .Current .Prev .Release .CISearchURL .NumDays .JobTestCount }}
 
-{{ summaryInfrequentJobPassRatesByJobName .Current .Prev .Release .NumDays .JobTestCount }}
+{{ summaryInfrequentJobPassRatesByJobName .Current .Prev .Release .CISearchURL .NumDays .JobTestCount }}
 
-{{ canaryTestFailures .Current.ByTest .Prev.ByTest }}
+{{ canaryTestFailures .Current.ByTest .Prev.ByTest .CISearchURL }}
 
 {{ failureGroupList .Current }}
 
+{{ failureClusters .Current .Release }}
+
 {{ testImpactingBugs .Current.BugsByFailureCount }}
 
 {{ testImpactingComponents .Current.BugsByFailureCount }}
 
-{{ summaryJobsFailuresByBugzillaComponent .Current .Prev .NumDays .Release }}
+{{ summaryJobsFailuresByBugzillaComponent .Current .Prev .NumDays .Release .CISearchURL }}
 
 `
 )
@@ -112,7 +117,7 @@ func failureGroups(failureGroups, failureGroupsPrev []sippyprocessingv1.JobRunRe
 	return s
 }
 
-func summaryJobsByVariant(report, reportPrev sippyprocessingv1.TestReport, numDays, jobTestCount int, release string) string {
+func summaryJobsByVariant(report, reportPrev sippyprocessingv1.TestReport, numDays, jobTestCount int, release, ciSearchURL string) string {
 	if len(report.ByVariant) == 0 {
 		return ""
 	}
@@ -131,7 +136,7 @@ func summaryJobsByVariant(report, reportPrev sippyprocessingv1.TestReport, numDa
 	`, numDays)
 
 	for _, currVariant := range report.ByVariant {
-		variantHTML := generichtml.NewJobAggregationResultRendererFromVariantResults("by-variant", currVariant, release).
+		variantHTML := generichtml.NewJobAggregationResultRendererFromVariantResults("by-variant", currVariant, release, ciSearchURL).
 			WithMaxTestResultsToShow(jobTestCount).
 			WithPreviousVariantResults(util.FindVariantResultsForName(currVariant.VariantName, reportPrev.ByVariant)).
 			ToHTML()
@@ -143,7 +148,7 @@ func summaryJobsByVariant(report, reportPrev sippyprocessingv1.TestReport, numDa
 	return s
 }
 
-func summaryFrequentJobPassRatesByJobName(report, reportPrev sippyprocessingv1.TestReport, release string, numDays, jobTestCount int) string {
+func summaryFrequentJobPassRatesByJobName(report, reportPrev sippyprocessingv1.TestReport, release, ciSearchURL string, numDays, jobTestCount int) string {
 	s := fmt.Sprintf(`
 	<table class="table">
 		<tr>
@@ -159,7 +164,7 @@ func summaryFrequentJobPassRatesByJobName(report, reportPrev sippyprocessingv1.T
 
 	for _, currJobResult := range report.FrequentJobResults {
 		prevJobResult := util.FindJobResultForJobName(currJobResult.Name, reportPrev.FrequentJobResults)
-		jobHTML := generichtml.NewJobResultRendererFromJobResult("by-job-name", currJobResult, release).
+		jobHTML := generichtml.NewJobResultRendererFromJobResult("by-job-name", currJobResult, release, ciSearchURL).
 			WithMaxTestResultsToShow(jobTestCount).
 			WithPreviousJobResult(prevJobResult).
 			ToHTML()
@@ -171,7 +176,7 @@ func summaryFrequentJobPassRatesByJobName(report, reportPrev sippyprocessingv1.T
 	return s
 }
 
-func summaryInfrequentJobPassRatesByJobName(report, reportPrev sippyprocessingv1.TestReport, release string, numDays, jobTestCount int) string {
+func summaryInfrequentJobPassRatesByJobName(report, reportPrev sippyprocessingv1.TestReport, release, ciSearchURL string, numDays, jobTestCount int) string {
 	s := fmt.Sprintf(`
 	<table class="table">
 		<tr>
@@ -187,7 +192,7 @@ func summaryInfrequentJobPassRatesByJobName(report, reportPrev sippyprocessingv1
 
 	for _, currJobResult := range report.InfrequentJobResults {
 		prevJobResult := util.FindJobResultForJobName(currJobResult.Name, reportPrev.InfrequentJobResults)
-		jobHTML := generichtml.NewJobResultRendererFromJobResult("by-infrequent-job-name", currJobResult, release).
+		jobHTML := generichtml.NewJobResultRendererFromJobResult("by-infrequent-job-name", currJobResult, release, ciSearchURL).
 			WithMaxTestResultsToShow(jobTestCount).
 			WithPreviousJobResult(prevJobResult).
 			ToHTML()
@@ -199,7 +204,7 @@ func summaryInfrequentJobPassRatesByJobName(report, reportPrev sippyprocessingv1
 	return s
 }
 
-func canaryTestFailures(all, prevAll []sippyprocessingv1.FailingTestResult) string {
+func canaryTestFailures(all, prevAll []sippyprocessingv1.FailingTestResult, ciSearchURL string) string {
 
 	// test name | bug | pass rate | higher/lower | pass rate
 	s := `
@@ -234,9 +239,7 @@ func canaryTestFailures(all, prevAll []sippyprocessingv1.FailingTestResult) stri
 		// TODO use a standard presentation for the failed test
 		util.FindFailedTestResult(test.TestName, prevAll)
 
-		encodedTestName := url.QueryEscape(regexp.QuoteMeta(test.TestName))
-
-		testLink := fmt.Sprintf("<a target=\"_blank\" href=\"https://search.ci.openshift.org/?maxAge=168h&context=1&type=bug%%2Bjunit&name=&maxMatches=5&maxBytes=20971520&groupBy=job&search=%s\">%s</a>", encodedTestName, test.TestName)
+		testLink := fmt.Sprintf("<a target=\"_blank\" href=\"%s\">%s</a>", buganalysis.TestSearchURL(ciSearchURL, "", test.TestName), test.TestName)
 
 		s += fmt.Sprintf(template, testLink, test.TestResultAcrossAllJobs.PassPercentage, test.TestResultAcrossAllJobs.Successes+test.TestResultAcrossAllJobs.Failures)
 	}
@@ -247,22 +250,22 @@ func failureGroupList(report sippyprocessingv1.TestReport) string {
 	s := `
 	<table class="table">
 		<tr>
-			<th colspan=2 class="text-center">
+			<th colspan=4 class="text-center">
 				<a class="text-dark" id="JobRunsWithFailureGroups" href="#JobRunsWithFailureGroups">Job Runs With Failure Groups</a>
 				<i class="fa fa-info-circle" title="Job runs where a large number of tests failed.  This is usually indicative of a cluster infrastructure problem, not a test issue, and should be investigated as such."></i>
 			</th>
 		</tr>
 		<tr>
-			<th>Job</th><th>Failed Test Count</th>
+			<th>Job</th><th>Failed Test Count</th><th>Outcome</th><th>Run</th>
 		</tr>
 	`
 
 	template := `
 	<tr>
-		<td><a target="_blank" href=%s>%s</a></td><td>%d</td>
+		<td><a target="_blank" href=%s>%s</a></td><td>%d</td><td title="%s">%s</td><td>%s</td>
 	</tr>`
 	for _, fg := range report.FailureGroups {
-		s += fmt.Sprintf(template, fg.Url, fg.Job, fg.TestFailures)
+		s += fmt.Sprintf(template, fg.Url, fg.Job, fg.TestFailures, html.EscapeString(fg.OutcomeReason), fg.Outcome, JobRunLink(report.Release, fg.Url, "details"))
 	}
 	s = s + "</table>"
 	return s
@@ -283,7 +286,7 @@ func testImpactingBugs(testImpactingBugs []bugsv1.Bug) string {
 	`
 
 	for _, bug := range testImpactingBugs {
-		s += fmt.Sprintf("<tr><td><a target=\"_blank\" href=%s>%d: %s</a></td><td>%d</td><td>%d</td></tr> ", bug.Url, bug.ID, bug.Summary, bug.FailureCount, bug.FlakeCount)
+		s += fmt.Sprintf("<tr><td><a target=\"_blank\" href=%s>%s: %s</a></td><td>%d</td><td>%d</td></tr> ", bug.Url, bug.Name(), bug.Summary, bug.FailureCount, bug.FlakeCount)
 	}
 
 	s = s + "</table>"
@@ -309,20 +312,20 @@ func testImpactingComponents(testImpactingBugs []bugsv1.Bug) string {
 		bugCount     int
 		failureCount int
 		flakeCount   int
-		bugIds       []int64
+		bugNames     []string
 		bugUrls      []string
 	}
 	components := make(map[string]Component)
 	for _, bug := range testImpactingBugs {
 		for _, component := range bug.Component {
 			if c, found := components[component]; !found {
-				components[component] = Component{component, 1, bug.FailureCount, bug.FlakeCount, []int64{bug.ID}, []string{bug.Url}}
+				components[component] = Component{component, 1, bug.FailureCount, bug.FlakeCount, []string{bug.Name()}, []string{bug.Url}}
 			} else {
 				c.bugCount++
 				c.failureCount += bug.FailureCount
 				c.flakeCount += bug.FlakeCount
 				c.bugUrls = append(c.bugUrls, bug.Url)
-				c.bugIds = append(c.bugIds, bug.ID)
+				c.bugNames = append(c.bugNames, bug.Name())
 				components[component] = c
 			}
 		}
@@ -342,7 +345,7 @@ func testImpactingComponents(testImpactingBugs []bugsv1.Bug) string {
 
 		links := ""
 		for i, url := range c.bugUrls {
-			links += fmt.Sprintf("<a target=\"_blank\" href=%s>%d</a> ", url, c.bugIds[i])
+			links += fmt.Sprintf("<a target=\"_blank\" href=%s>%s</a> ", url, c.bugNames[i])
 		}
 
 		s += fmt.Sprintf("<tr><td>%s</td><td>%d</td><td>%d</td><td>%d: %s</td></tr> ", c.name, c.failureCount, c.flakeCount, c.bugCount, links)
@@ -360,9 +363,10 @@ type TestReports struct {
 	JobTestCount int
 	Release      string
 	ReportNames  []string
+	CISearchURL  string
 }
 
-func WriteLandingPage(w http.ResponseWriter, displayNames []string) {
+func WriteLandingPage(w http.ResponseWriter, displayNames []string, refreshStatus sippyv1.RefreshStatus) {
 	w.Header().Set("Content-Type", "text/html;charset=UTF-8")
 	w.WriteHeader(http.StatusNotFound)
 	fmt.Fprintf(w, generichtml.HTMLPageStart, "Release CI Health Dashboard")
@@ -371,19 +375,49 @@ func WriteLandingPage(w http.ResponseWriter, displayNames []string) {
 		releaseLinks[i] = fmt.Sprintf(`<li><a href="?release=%s">release-%[1]s</a></li>`, displayNames[i])
 	}
 	fmt.Fprintf(w, "<h1 class='text-center'>CI Release Health Summary</h1><p><ul>%s</ul></p>", strings.Join(releaseLinks, "\n"))
+	fmt.Fprint(w, refreshStatusHTML(refreshStatus))
 	fmt.Fprintf(w, landingHtmlPageEnd)
 }
 
-func PrintHtmlReport(w http.ResponseWriter, req *http.Request, report, twoDayReport, prevReport sippyprocessingv1.TestReport, numDays, jobTestCount int, allReportNames []string) {
+func refreshStatusHTML(status sippyv1.RefreshStatus) string {
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return "never"
+		}
+		return t.Format("Jan 2 15:04 2006 MST")
+	}
+
+	rows := fmt.Sprintf("<tr><td>Last successful refresh</td><td>%s</td></tr>\n", formatTime(status.LastSuccessfulRefresh))
+	if len(status.LastRefreshError) > 0 {
+		rows += fmt.Sprintf("<tr class='table-danger'><td>Last refresh failed at %s</td><td>%s</td></tr>\n",
+			formatTime(status.LastRefreshAttempt), html.EscapeString(status.LastRefreshError))
+	}
+	if status.LastFetchAttempt != nil {
+		rows += fmt.Sprintf("<tr><td>Last successful fetch</td><td>%s</td></tr>\n", formatTime(status.LastSuccessfulFetch))
+		if len(status.LastFetchError) > 0 {
+			rows += fmt.Sprintf("<tr class='table-danger'><td>Last fetch failed at %s</td><td>%s</td></tr>\n",
+				formatTime(status.LastFetchAttempt), html.EscapeString(status.LastFetchError))
+		}
+	}
+	if status.NextScheduledRefresh != nil {
+		rows += fmt.Sprintf("<tr><td>Next scheduled refresh</td><td>%s</td></tr>\n", formatTime(status.NextScheduledRefresh))
+	}
+
+	return fmt.Sprintf("<table class='table table-sm w-auto mx-auto small'>\n%s</table>\n", rows)
+}
+
+// PrintHtmlReport writes the health dashboard of a release.  Failing tests link to their failures in the ci-search
+// instance at ciSearchURL.
+func PrintHtmlReport(w http.ResponseWriter, req *http.Request, report, twoDayReport, prevReport sippyprocessingv1.TestReport, numDays, jobTestCount int, allReportNames []string, ciSearchURL string) {
 	w.Header().Set("Content-Type", "text/html;charset=UTF-8")
 	fmt.Fprintf(w, generichtml.HTMLPageStart, "Release CI Health Dashboard")
 	if len(prevReport.AnalysisWarnings)+len(report.AnalysisWarnings) > 0 {
 		warningsHTML := ""
 		for _, analysisWarning := range prevReport.AnalysisWarnings {
-			warningsHTML += "<p>" + analysisWarning + "</p>\n"
+			warningsHTML += "<p>" + html.EscapeString(analysisWarning) + "</p>\n"
 		}
 		for _, analysisWarning := range report.AnalysisWarnings {
-			warningsHTML += "<p>" + analysisWarning + "</p>\n"
+			warningsHTML += "<p>" + html.EscapeString(analysisWarning) + "</p>\n"
 		}
 		fmt.Fprintf(w, generichtml.WarningHeader, warningsHTML)
 	}
@@ -391,6 +425,7 @@ func PrintHtmlReport(w http.ResponseWriter, req *http.Request, report, twoDayRep
 	var dashboardPage = template.Must(template.New("dashboardPage").Funcs(
 		template.FuncMap{
 			"failureGroups":                          failureGroups,
+			"failureClusters":                        failureClusters,
 			"summaryJobsByVariant":                   summaryJobsByVariant,
 			"summaryTopFailingTestsWithBug":          summaryTopFailingTestsWithBug,
 			"summaryTopFailingTestsWithoutBug":       summaryTopFailingTestsWithoutBug,
@@ -405,6 +440,7 @@ func PrintHtmlReport(w http.ResponseWriter, req *http.Request, report, twoDayRep
 			"summaryTopNegativelyMovingJobs":         summaryTopNegativelyMovingJobs,
 			"topLevelIndicators":                     topLevelIndicators,
 			"releasesList":                           releasesList,
+			"passRateChanges":                        passRateChanges,
 		},
 	).Parse(dashboardPageHtml))
 
@@ -416,6 +452,7 @@ func PrintHtmlReport(w http.ResponseWriter, req *http.Request, report, twoDayRep
 		JobTestCount: jobTestCount,
 		Release:      report.Release,
 		ReportNames:  allReportNames,
+		CISearchURL:  ciSearchURL,
 	}); err != nil {
 		klog.Errorf("Unable to render page: %v", err)
 	}
@@ -0,0 +1,127 @@
+package releasehtml
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"time"
+
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	"github.com/openshift/sippy/pkg/html/generichtml"
+)
+
+// PrintJobRunHtmlReport writes everything sippy knows about one job run: how it failed, the final state of its
+// operators, the tests that failed with their pass rates across all jobs, and the bugs that match.
+func PrintJobRunHtmlReport(w http.ResponseWriter, report sippyv1.JobRunReport, numDays int, timestamp time.Time) {
+	w.Header().Set("Content-Type", "text/html;charset=UTF-8")
+	fmt.Fprintf(w, generichtml.HTMLPageStart, "Job Run "+report.Job+" "+report.BuildID)
+
+	fmt.Fprintf(w, `<h1 class=text-center>%s #%s</h1>
+<p class="small mb-3 text-center">
+	Started %s.  <a target="_blank" href="%s">Prow</a> | <a target="_blank" href="%s">TestGrid</a> |
+	<a href="/api/jobrun?release=%s&url=%s">JSON</a>
+</p>
+`, html.EscapeString(report.Job), html.EscapeString(report.BuildID), report.Timestamp.Format("Jan 2 15:04 2006 MST"),
+		html.EscapeString(report.URL), html.EscapeString(report.TestGridURL), url.QueryEscape(report.Release), url.QueryEscape(report.URL))
+
+	fmt.Fprint(w, jobRunSummaryTable(report))
+	fmt.Fprint(w, jobRunOperatorsTable(report))
+	fmt.Fprint(w, jobRunFailedTestsTable(report, numDays))
+	fmt.Fprint(w, jobRunBugsTable(report))
+
+	fmt.Fprintf(w, generichtml.HTMLPageEnd, timestamp.Format("Jan 2 15:04 2006 MST"))
+}
+
+// jobRunStepCell colors a step of the run by its Success or Failure status.
+func jobRunStepCell(status string) string {
+	switch status {
+	case "Success":
+		return `<td class="text-center table-success">Success</td>`
+	case "Failure":
+		return `<td class="text-center table-danger">Failure</td>`
+	default:
+		return `<td class="text-center table-secondary">no-data</td>`
+	}
+}
+
+func jobRunSummaryTable(report sippyv1.JobRunReport) string {
+	classificationColor := "table-danger"
+	if report.Succeeded {
+		classificationColor = "table-success"
+	} else if !report.Failed {
+		classificationColor = "table-secondary"
+	}
+
+	s := componentTableHeader(5, "JobRunSummary", "Summary", "How far the run got.  The classification is the first step that failed, and why.")
+	s += `		<tr><th>Classification</th><th class="text-center">Setup</th><th class="text-center">Upgrade Operators</th><th class="text-center">Upgrade Machine Config Pools</th><th class="text-center">Tests</th></tr>
+`
+	upgradeOperators, upgradeMachineConfigPools := "", ""
+	if report.UpgradeStarted {
+		upgradeOperators, upgradeMachineConfigPools = report.UpgradeForOperatorsStatus, report.UpgradeForMachineConfigPoolsStatus
+	}
+	s += fmt.Sprintf("		<tr><td class=\"%s\">%s<br/><small>%s</small></td>%s%s%s%s</tr>\n",
+		classificationColor, html.EscapeString(report.Classification), html.EscapeString(report.ClassificationReason),
+		jobRunStepCell(report.SetupStatus),
+		jobRunStepCell(upgradeOperators),
+		jobRunStepCell(upgradeMachineConfigPools),
+		jobRunStepCell(report.OpenShiftTestsStatus),
+	)
+	s += "	</table>\n"
+	return s
+}
+
+func jobRunOperatorsTable(report sippyv1.JobRunReport) string {
+	if len(report.Operators) == 0 {
+		return ""
+	}
+	s := componentTableHeader(2, "JobRunOperators", "Operators", "The final state of the operators at the end of the run.")
+	s += "		<tr><th>Operator</th><th class=\"text-center\">State</th></tr>\n"
+	for _, operator := range report.Operators {
+		s += fmt.Sprintf("		<tr><td>%s</td>%s</tr>\n", html.EscapeString(operator.Name), jobRunStepCell(operator.State))
+	}
+	s += "	</table>\n"
+	return s
+}
+
+func jobRunFailedTestsTable(report sippyv1.JobRunReport, numDays int) string {
+	s := componentTableHeader(6, "JobRunFailedTests", "Failed Tests", "Tests that failed in this run, with how often they pass across all jobs.")
+	s += fmt.Sprintf(`		<tr><th>Test Name</th><th>Component</th><th>Bugs</th><th class="text-center">Latest %d Days</th><th/><th class="text-center">Previous 7 Days</th></tr>
+`, numDays)
+	if len(report.FailedTests) == 0 {
+		s += `		<tr><td colspan=6 class="text-center">No failed tests</td></tr>
+`
+	}
+	for _, test := range report.FailedTests {
+		bugs := fmt.Sprintf(`<a target="_blank" href="%s">search</a>`, html.EscapeString(test.Url))
+		for _, bug := range test.Bugs {
+			bugs += fmt.Sprintf(` <a target="_blank" href="%s">%s</a>`, html.EscapeString(bug.Url), html.EscapeString(bug.Name()))
+		}
+		s += fmt.Sprintf("		<tr><td>%s %s</td><td>%s</td><td>%s</td>%s</tr>\n",
+			html.EscapeString(test.Name), generichtml.GetTestDetailsButtonHTML(report.Release, test.Name),
+			html.EscapeString(test.Component), bugs, passRateCells(test.PassRates, generichtml.StandardColors))
+	}
+	s += "	</table>\n"
+	return s
+}
+
+func jobRunBugsTable(report sippyv1.JobRunReport) string {
+	s := componentTableHeader(3, "JobRunBugs", "Bugs", "Bugs that match the job or the tests that failed in this run.")
+	s += "		<tr><th>Bug</th><th>Status</th><th>Summary</th></tr>\n"
+	if len(report.Bugs) == 0 {
+		s += `		<tr><td colspan=3 class="text-center">No bugs</td></tr>
+`
+	}
+	for _, bug := range report.Bugs {
+		s += fmt.Sprintf("		<tr><td><a target=\"_blank\" href=\"%s\">%s</a></td><td>%s</td><td>%s</td></tr>\n",
+			html.EscapeString(bug.Url), html.EscapeString(bug.Name()), html.EscapeString(bug.Status), html.EscapeString(bug.Summary))
+	}
+	s += "	</table>\n"
+	return s
+}
+
+// JobRunLink links to the job run page of the run.
+func JobRunLink(release, runURL, text string) string {
+	href := fmt.Sprintf("/jobrun?release=%s&url=%s", url.QueryEscape(release), url.QueryEscape(runURL))
+	return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(href), text)
+}
@@ -0,0 +1,64 @@
+package releasehtml
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/regressionanalysis"
+)
+
+// maxPassRateChanges limits how many regressions and improvements are listed on the page.  The JSON has all of them.
+const maxPassRateChanges = 20
+
+func passRateChanges(report, reportPrev sippyprocessingv1.TestReport, numDays int, release string) string {
+	regressions := regressionanalysis.DefaultOptions().Analyze(report, reportPrev)
+
+	s := fmt.Sprintf(`
+	<table class="table">
+		<tr>
+			<th colspan=5 class="text-center">
+				<a class="text-dark" id="SignificantPassRateChanges" href="#SignificantPassRateChanges">Significant Pass Rate Changes</a>
+				<i class="fa fa-info-circle" title="Tests, jobs, and variants whose pass rate changed from the previous period by more than can be explained by chance, according to Fisher's exact test with a false discovery rate of %0.2f.  Most significant first."></i>
+			</th>
+		</tr>
+		<tr>
+			<th>Kind</th><th>Name</th><th>Latest %d days</th><th>Previous 7 days</th><th>p-value</th>
+		</tr>
+	`, regressions.SignificanceLevel, numDays)
+
+	s += passRateChangeRows("Regressions", "table-danger", regressions.Regressions, release)
+	s += passRateChangeRows("Improvements", "table-success", regressions.Improvements, release)
+
+	s = s + "</table>"
+	return s
+}
+
+func passRateChangeRows(title, class string, changes []sippyv1.PassRateChange, release string) string {
+	s := fmt.Sprintf(`<tr class="%s"><td colspan=5 class="font-weight-bold">%s (%d)</td></tr>`, class, title, len(changes))
+	if len(changes) == 0 {
+		s += `<tr><td colspan=5 class="font-italic">None</td></tr>`
+	}
+
+	template := `
+		<tr>
+			<td>%s</td><td>%s</td><td>%0.2f%% <span class="text-nowrap">(%d runs)</span></td><td>%0.2f%% <span class="text-nowrap">(%d runs)</span></td><td>%0.2g</td>
+		</tr>
+	`
+	for i, change := range changes {
+		if i >= maxPassRateChanges {
+			break
+		}
+		name := html.EscapeString(change.Name)
+		if change.Kind == sippyv1.TestRegression {
+			name = fmt.Sprintf(`<a target="_blank" href="/testdetails?release=%s&test=%s">%s</a>`, url.QueryEscape(release), url.QueryEscape(change.Name), name)
+		}
+		s += fmt.Sprintf(template, change.Kind, name,
+			change.PassRates["latest"].Percentage, change.PassRates["latest"].Runs,
+			change.PassRates["prev"].Percentage, change.PassRates["prev"].Runs,
+			change.PValue)
+	}
+	return s
+}
@@ -0,0 +1,75 @@
+package releasehtml
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	"github.com/openshift/sippy/pkg/html/generichtml"
+	"github.com/openshift/sippy/pkg/variantanalysis"
+)
+
+// PrintVariantMatrixHtmlReport writes a heat map of the job pass rates of every combination of variants in the matrix.
+func PrintVariantMatrixHtmlReport(w http.ResponseWriter, matrix sippyv1.VariantMatrix, release string, timestamp time.Time) {
+	w.Header().Set("Content-Type", "text/html;charset=UTF-8")
+	fmt.Fprintf(w, generichtml.HTMLPageStart, "Release "+release+" Variant Matrix")
+	fmt.Fprintf(w, `<h1 class=text-center>Release %s Variant Matrix</h1>
+<p class="small mb-3">
+	Job pass rates of every combination of %s by %s, lowest pass rates in red.  Hover over a cell for its jobs and worst tests.
+	Choose different dimensions with <code>?rows=</code> and <code>?columns=</code>, or get the data from <a href="/api/variants/matrix?release=%s">/api/variants/matrix</a>.
+</p>
+`, html.EscapeString(release), html.EscapeString(strings.Join(matrix.Rows, " and ")), html.EscapeString(strings.Join(matrix.Columns, " and ")), url.QueryEscape(release))
+
+	fmt.Fprint(w, variantMatrixTable(matrix))
+
+	fmt.Fprintf(w, generichtml.HTMLPageEnd, timestamp.Format("Jan 2 15:04 2006 MST"))
+}
+
+func variantMatrixTable(matrix sippyv1.VariantMatrix) string {
+	cells := map[string]sippyv1.VariantMatrixCell{}
+	for _, cell := range matrix.Cells {
+		cells[variantanalysis.MatrixKey(cell.Row, cell.Column)] = cell
+	}
+
+	s := `<table class="table table-sm text-center">
+		<tr>`
+	for _, row := range matrix.Rows {
+		s += fmt.Sprintf("<th>%s</th>", html.EscapeString(row))
+	}
+	for _, column := range matrix.ColumnValues {
+		s += fmt.Sprintf("<th>%s</th>", html.EscapeString(strings.Join(column, " / ")))
+	}
+	s += "</tr>\n"
+
+	for _, row := range matrix.RowValues {
+		s += "<tr>"
+		for _, value := range row {
+			s += fmt.Sprintf("<th>%s</th>", html.EscapeString(value))
+		}
+		for _, column := range matrix.ColumnValues {
+			cell, ok := cells[variantanalysis.MatrixKey(row, column)]
+			if !ok {
+				s += `<td class="table-secondary"/>`
+				continue
+			}
+			runs := cell.JobRunSuccesses + cell.JobRunFailures
+			title := "Jobs:\n" + strings.Join(cell.Jobs, "\n")
+			if len(cell.FailingTests) > 0 {
+				title += "\n\nWorst tests:"
+				for _, test := range cell.FailingTests {
+					title += fmt.Sprintf("\n%0.2f%% (%d runs) %s", test.PassPercentage, test.Runs, test.Name)
+				}
+			}
+			s += fmt.Sprintf(`<td class="%s" title="%s">%0.2f%% <span class="text-nowrap">(%d runs)</span></td>`,
+				generichtml.StandardColors.GetColor(cell.JobRunPassPercentage, runs), html.EscapeString(title), cell.JobRunPassPercentage, runs)
+		}
+		s += "</tr>\n"
+	}
+
+	s += "</table>"
+	return s
+}
@@ -0,0 +1,181 @@
+// Package metrics writes metrics in the Prometheus text exposition format, so that sippy can be scraped without a
+// Prometheus client library.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// the types of metric families
+const (
+	Gauge     = "gauge"
+	Counter   = "counter"
+	Histogram = "histogram"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of the buckets of request latency histograms.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Label distinguishes the samples of a metric family.
+type Label struct {
+	Name  string
+	Value string
+}
+
+type Sample struct {
+	// Suffix is appended to the name of the family, like the _bucket, _sum, and _count of a histogram.
+	Suffix string
+	Labels []Label
+	Value  float64
+}
+
+// Family is a metric and all of its samples.
+type Family struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []Sample
+}
+
+// WriteFamilies writes the families in the text exposition format.
+func WriteFamilies(w io.Writer, families ...Family) error {
+	bw := bufio.NewWriter(w)
+	for _, family := range families {
+		fmt.Fprintf(bw, "# HELP %s %s\n", family.Name, escapeHelp(family.Help))
+		fmt.Fprintf(bw, "# TYPE %s %s\n", family.Name, family.Type)
+		for _, sample := range family.Samples {
+			writeSample(bw, family.Name+sample.Suffix, sample.Labels, sample.Value)
+		}
+	}
+	return bw.Flush()
+}
+
+func writeSample(w io.Writer, name string, labels []Label, value float64) {
+	fmt.Fprint(w, name)
+	if len(labels) > 0 {
+		pairs := []string{}
+		for _, label := range labels {
+			pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", label.Name, escapeLabelValue(label.Value)))
+		}
+		fmt.Fprintf(w, "{%s}", strings.Join(pairs, ","))
+	}
+	fmt.Fprintf(w, " %s\n", formatValue(value))
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+// HistogramVec counts observations into cumulative buckets, separately for every combination of its label values.  It
+// is safe for concurrent use.
+type HistogramVec struct {
+	name       string
+	help       string
+	labelNames []string
+	buckets    []float64
+
+	lock   sync.Mutex
+	series map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	labelValues []string
+	// counts are the number of observations in each bucket, not cumulative
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec returns a histogram with the bucket upper bounds, which must be sorted, and label names.
+func NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	return &HistogramVec{
+		name:       name,
+		help:       help,
+		labelNames: labelNames,
+		buckets:    buckets,
+		series:     map[string]*histogramSeries{},
+	}
+}
+
+// Observe records a value for the label values, which are in the order of the label names.
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	if len(labelValues) != len(h.labelNames) {
+		panic(fmt.Sprintf("%s has %d labels, not %d", h.name, len(h.labelNames), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	series, ok := h.series[key]
+	if !ok {
+		series = &histogramSeries{labelValues: labelValues, counts: make([]uint64, len(h.buckets))}
+		h.series[key] = series
+	}
+	series.count++
+	series.sum += value
+	if i := sort.SearchFloat64s(h.buckets, value); i < len(h.buckets) {
+		series.counts[i]++
+	}
+}
+
+// Family returns the buckets, sum, and count of every series of the histogram, sorted by label values.
+func (h *HistogramVec) Family() Family {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	keys := []string{}
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	family := Family{Name: h.name, Help: h.help, Type: Histogram}
+	for _, key := range keys {
+		series := h.series[key]
+		labels := []Label{}
+		for i, name := range h.labelNames {
+			labels = append(labels, Label{Name: name, Value: series.labelValues[i]})
+		}
+		withLe := func(le string) []Label {
+			return append(append([]Label{}, labels...), Label{Name: "le", Value: le})
+		}
+
+		cumulative := uint64(0)
+		for i, upperBound := range h.buckets {
+			cumulative += series.counts[i]
+			family.Samples = append(family.Samples, Sample{Suffix: "_bucket", Labels: withLe(formatValue(upperBound)), Value: float64(cumulative)})
+		}
+		family.Samples = append(family.Samples, Sample{Suffix: "_bucket", Labels: withLe("+Inf"), Value: float64(series.count)})
+		family.Samples = append(family.Samples, Sample{Suffix: "_sum", Labels: labels, Value: series.sum})
+		family.Samples = append(family.Samples, Sample{Suffix: "_count", Labels: labels, Value: float64(series.count)})
+	}
+	return family
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestWriteFamilies(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := WriteFamilies(buf,
+		Family{
+			Name: "sippy_job_pass_percentage",
+			Help: "The pass rate of a job.\nIn percent.",
+			Type: Gauge,
+			Samples: []Sample{
+				{Labels: []Label{{Name: "release", Value: "4.8"}, {Name: "job", Value: `a "quoted\job"`}}, Value: 87.5},
+				{Labels: []Label{{Name: "release", Value: "4.8"}, {Name: "job", Value: "b"}}, Value: math.NaN()},
+			},
+		},
+		Family{Name: "sippy_up", Help: "Always 1.", Type: Gauge, Samples: []Sample{{Value: 1}}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# HELP sippy_job_pass_percentage The pass rate of a job.\nIn percent.
+# TYPE sippy_job_pass_percentage gauge
+sippy_job_pass_percentage{release="4.8",job="a \"quoted\\job\""} 87.5
+sippy_job_pass_percentage{release="4.8",job="b"} NaN
+# HELP sippy_up Always 1.
+# TYPE sippy_up gauge
+sippy_up 1
+`
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+	}
+}
+
+func TestHistogramVec(t *testing.T) {
+	histogram := NewHistogramVec("sippy_request_duration_seconds", "How long requests take.", []float64{0.1, 1}, "handler")
+	histogram.Observe(0.05, "/json")
+	histogram.Observe(0.1, "/json")
+	histogram.Observe(0.5, "/json")
+	histogram.Observe(2, "/json")
+	histogram.Observe(0.5, "/")
+
+	buf := &bytes.Buffer{}
+	if err := WriteFamilies(buf, histogram.Family()); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# HELP sippy_request_duration_seconds How long requests take.
+# TYPE sippy_request_duration_seconds histogram
+sippy_request_duration_seconds_bucket{handler="/",le="0.1"} 0
+sippy_request_duration_seconds_bucket{handler="/",le="1"} 1
+sippy_request_duration_seconds_bucket{handler="/",le="+Inf"} 1
+sippy_request_duration_seconds_sum{handler="/"} 0.5
+sippy_request_duration_seconds_count{handler="/"} 1
+sippy_request_duration_seconds_bucket{handler="/json",le="0.1"} 2
+sippy_request_duration_seconds_bucket{handler="/json",le="1"} 3
+sippy_request_duration_seconds_bucket{handler="/json",le="+Inf"} 4
+sippy_request_duration_seconds_sum{handler="/json"} 2.65
+sippy_request_duration_seconds_count{handler="/json"} 4
+`
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+	}
+}
@@ -0,0 +1,62 @@
+package regressionanalysis
+
+import "math"
+
+// relativeTolerance allows for floating point error when comparing the probabilities of tables that are mathematically
+// equally likely.
+const relativeTolerance = 1e-7
+
+func logFactorial(n int) float64 {
+	ret, _ := math.Lgamma(float64(n + 1))
+	return ret
+}
+
+// FisherExactTest returns the two-sided p-value of Fisher's exact test for the 2x2 contingency table
+//
+//	a b
+//	c d
+//
+// It is the probability, given the row and column totals, of a table at least as unlikely as the observed one.
+func FisherExactTest(a, b, c, d int) float64 {
+	if a < 0 || b < 0 || c < 0 || d < 0 {
+		return 1
+	}
+	row1 := a + b
+	row2 := c + d
+	col1 := a + c
+	n := row1 + row2
+	if row1 == 0 || row2 == 0 || col1 == 0 || col1 == n {
+		// one of the margins is empty, so every table is the observed table
+		return 1
+	}
+
+	// log of the hypergeometric probability of the table with x in the top left cell
+	logDenominator := logFactorial(n) - logFactorial(row1) - logFactorial(row2) - logFactorial(col1) - logFactorial(n-col1)
+	logProbability := func(x int) float64 {
+		return logFactorial(x) + logFactorial(row1-x) + logFactorial(col1-x) + logFactorial(row2-col1+x)
+	}
+	probability := func(x int) float64 {
+		return math.Exp(-logDenominator - logProbability(x))
+	}
+
+	observed := probability(a)
+	minX := col1 - row2
+	if minX < 0 {
+		minX = 0
+	}
+	maxX := col1
+	if row1 < maxX {
+		maxX = row1
+	}
+
+	pValue := 0.0
+	for x := minX; x <= maxX; x++ {
+		if p := probability(x); p <= observed*(1+relativeTolerance) {
+			pValue += p
+		}
+	}
+	if pValue > 1 {
+		return 1
+	}
+	return pValue
+}
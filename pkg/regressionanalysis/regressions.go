@@ -0,0 +1,143 @@
+package regressionanalysis
+
+import (
+	"sort"
+
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/util"
+)
+
+const (
+	// DefaultSignificanceLevel is the false discovery rate used unless another one is given.
+	DefaultSignificanceLevel = 0.05
+	// DefaultMinRuns is the minimum number of runs in each period for a test, job, or variant to be compared.
+	DefaultMinRuns = 10
+)
+
+type Options struct {
+	// SignificanceLevel is the false discovery rate across every comparison in a report.  Thousands of tests are compared
+	// at once, so controlling the rate per comparison would report dozens of changes that are just noise.
+	SignificanceLevel float64
+	// MinRuns is the minimum number of runs in each period for a test, job, or variant to be compared.
+	MinRuns int
+}
+
+func DefaultOptions() Options {
+	return Options{
+		SignificanceLevel: DefaultSignificanceLevel,
+		MinRuns:           DefaultMinRuns,
+	}
+}
+
+type comparison struct {
+	kind                        sippyv1.RegressionKind
+	name                        string
+	currSuccesses, currFailures int
+	prevSuccesses, prevFailures int
+	pValue                      float64
+}
+
+func (c comparison) currPassPercentage() float64 {
+	return util.Percent(c.currSuccesses, c.currFailures)
+}
+
+func (c comparison) prevPassPercentage() float64 {
+	return util.Percent(c.prevSuccesses, c.prevFailures)
+}
+
+// Analyze compares every test, job, and variant in the current report with the same test, job, or variant in the previous
+// report and returns the changes in pass rate that are statistically significant, most significant first.
+func (o Options) Analyze(current, previous sippyprocessingv1.TestReport) sippyv1.RegressionReport {
+	comparisons := []comparison{}
+	add := func(kind sippyv1.RegressionKind, name string, currSuccesses, currFailures, prevSuccesses, prevFailures int) {
+		if currSuccesses+currFailures < o.MinRuns || prevSuccesses+prevFailures < o.MinRuns {
+			return
+		}
+		comparisons = append(comparisons, comparison{
+			kind:          kind,
+			name:          name,
+			currSuccesses: currSuccesses,
+			currFailures:  currFailures,
+			prevSuccesses: prevSuccesses,
+			prevFailures:  prevFailures,
+			pValue:        FisherExactTest(currSuccesses, currFailures, prevSuccesses, prevFailures),
+		})
+	}
+
+	prevTests := map[string]sippyprocessingv1.TestResult{}
+	for _, test := range previous.ByTest {
+		prevTests[test.TestName] = test.TestResultAcrossAllJobs
+	}
+	for _, test := range current.ByTest {
+		if prev, ok := prevTests[test.TestName]; ok {
+			curr := test.TestResultAcrossAllJobs
+			add(sippyv1.TestRegression, test.TestName, curr.Successes, curr.Failures, prev.Successes, prev.Failures)
+		}
+	}
+
+	prevJobs := map[string]sippyprocessingv1.JobResult{}
+	for _, job := range previous.ByJob {
+		prevJobs[job.Name] = job
+	}
+	for _, job := range current.ByJob {
+		if prev, ok := prevJobs[job.Name]; ok {
+			add(sippyv1.JobRegression, job.Name, job.Successes, job.Failures, prev.Successes, prev.Failures)
+		}
+	}
+
+	prevVariants := map[string]sippyprocessingv1.VariantResults{}
+	for _, variant := range previous.ByVariant {
+		prevVariants[variant.VariantName] = variant
+	}
+	for _, variant := range current.ByVariant {
+		if prev, ok := prevVariants[variant.VariantName]; ok {
+			add(sippyv1.VariantRegression, variant.VariantName, variant.JobRunSuccesses, variant.JobRunFailures, prev.JobRunSuccesses, prev.JobRunFailures)
+		}
+	}
+
+	report := sippyv1.RegressionReport{
+		SignificanceLevel: o.SignificanceLevel,
+		Regressions:       []sippyv1.PassRateChange{},
+		Improvements:      []sippyv1.PassRateChange{},
+	}
+	for _, c := range significant(comparisons, o.SignificanceLevel) {
+		change := sippyv1.PassRateChange{
+			Kind: c.kind,
+			Name: c.name,
+			PassRates: map[string]sippyv1.PassRate{
+				"latest": {Percentage: c.currPassPercentage(), Runs: c.currSuccesses + c.currFailures},
+				"prev":   {Percentage: c.prevPassPercentage(), Runs: c.prevSuccesses + c.prevFailures},
+			},
+			PValue: c.pValue,
+		}
+		if c.currPassPercentage() < c.prevPassPercentage() {
+			report.Regressions = append(report.Regressions, change)
+		} else {
+			report.Improvements = append(report.Improvements, change)
+		}
+	}
+	return report
+}
+
+// significant returns the comparisons whose changes are significant using the Benjamini-Hochberg procedure, which
+// bounds the expected fraction of false discoveries at the significance level.  They are sorted by p-value, and then by
+// the size of the change.
+func significant(comparisons []comparison, significanceLevel float64) []comparison {
+	sort.SliceStable(comparisons, func(i, j int) bool {
+		if comparisons[i].pValue != comparisons[j].pValue {
+			return comparisons[i].pValue < comparisons[j].pValue
+		}
+		deltaI := comparisons[i].currPassPercentage() - comparisons[i].prevPassPercentage()
+		deltaJ := comparisons[j].currPassPercentage() - comparisons[j].prevPassPercentage()
+		return deltaI*deltaI > deltaJ*deltaJ
+	})
+
+	numSignificant := 0
+	for i, c := range comparisons {
+		if c.pValue <= float64(i+1)/float64(len(comparisons))*significanceLevel {
+			numSignificant = i + 1
+		}
+	}
+	return comparisons[:numSignificant]
+}
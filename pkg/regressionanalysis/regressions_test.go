@@ -0,0 +1,88 @@
+package regressionanalysis
+
+import (
+	"math"
+	"testing"
+
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+)
+
+func TestFisherExactTest(t *testing.T) {
+	tests := []struct {
+		name       string
+		a, b, c, d int
+		expected   float64
+	}{
+		{name: "lady tasting tea", a: 3, b: 1, c: 1, d: 3, expected: 0.485714},
+		{name: "wikipedia example", a: 1, b: 9, c: 11, d: 3, expected: 0.002759},
+		{name: "identical rows", a: 10, b: 10, c: 10, d: 10, expected: 1},
+		{name: "empty row", a: 0, b: 0, c: 5, d: 5, expected: 1},
+		{name: "no failures", a: 20, b: 0, c: 20, d: 0, expected: 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := FisherExactTest(tc.a, tc.b, tc.c, tc.d); math.Abs(actual-tc.expected) > 1e-6 {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	testResult := func(name string, successes, failures int) sippyprocessingv1.FailingTestResult {
+		return sippyprocessingv1.FailingTestResult{
+			TestName:                name,
+			TestResultAcrossAllJobs: sippyprocessingv1.TestResult{Name: name, Successes: successes, Failures: failures},
+		}
+	}
+	previous := sippyprocessingv1.TestReport{
+		ByTest: []sippyprocessingv1.FailingTestResult{
+			testResult("regressed", 98, 2),
+			testResult("improved", 50, 50),
+			testResult("unchanged", 90, 10),
+			testResult("too few runs", 5, 0),
+		},
+		ByJob: []sippyprocessingv1.JobResult{{Name: "job", Successes: 40, Failures: 10}},
+		ByVariant: []sippyprocessingv1.VariantResults{
+			{VariantName: "aws", JobRunSuccesses: 80, JobRunFailures: 20},
+		},
+	}
+	current := sippyprocessingv1.TestReport{
+		ByTest: []sippyprocessingv1.FailingTestResult{
+			testResult("regressed", 60, 40),
+			testResult("improved", 95, 5),
+			testResult("unchanged", 88, 12),
+			testResult("too few runs", 0, 5),
+			testResult("new", 0, 100),
+		},
+		ByJob: []sippyprocessingv1.JobResult{{Name: "job", Successes: 38, Failures: 12}},
+		ByVariant: []sippyprocessingv1.VariantResults{
+			{VariantName: "aws", JobRunSuccesses: 20, JobRunFailures: 80},
+		},
+	}
+
+	report := DefaultOptions().Analyze(current, previous)
+
+	regressions := map[string]sippyv1.PassRateChange{}
+	for _, change := range report.Regressions {
+		regressions[change.Name] = change
+	}
+	if len(regressions) != 2 {
+		t.Errorf("expected two regressions, got %#v", report.Regressions)
+	}
+	if regressions["aws"].Kind != sippyv1.VariantRegression {
+		t.Errorf("expected aws variant regression, got %#v", report.Regressions)
+	}
+	if change := regressions["regressed"]; change.Kind != sippyv1.TestRegression || change.PassRates["latest"].Percentage != 60 || change.PassRates["prev"].Percentage != 98 {
+		t.Errorf("unexpected test regression %#v", change)
+	}
+	// the variant change is far more significant, so it is ranked first
+	if report.Regressions[0].Name != "aws" {
+		t.Errorf("expected the most significant regression first, got %#v", report.Regressions)
+	}
+
+	if len(report.Improvements) != 1 || report.Improvements[0].Name != "improved" {
+		t.Errorf("expected one improvement, got %#v", report.Improvements)
+	}
+}
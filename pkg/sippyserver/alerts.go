@@ -0,0 +1,86 @@
+package sippyserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/openshift/sippy/pkg/alerting"
+	"github.com/openshift/sippy/pkg/api"
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	"k8s.io/klog"
+)
+
+// queueAlertEvaluation queues the evaluation of the alerts after the refresh that started at now.  An evaluation that
+// is still queued is replaced, it would only look at the same reports.  It must be called with the refresh lock held, so
+// that evaluations are queued in the order of the refreshes and the send never blocks.
+func (s *Server) queueAlertEvaluation(now time.Time) {
+	if s.alertManager == nil {
+		return
+	}
+	select {
+	case <-s.alertEvaluations:
+	default:
+	}
+	s.alertEvaluations <- now
+}
+
+// runAlertEvaluations evaluates the queued alerts one at a time until stop is closed, so that a slow notifier holds up
+// the next evaluation instead of racing it.
+func (s *Server) runAlertEvaluations(stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-s.alertEvaluations:
+			s.evaluateAlerts(now)
+		}
+	}
+}
+
+// evaluateAlerts evaluates the alerting rules against the current reports.  Failing to send an alert doesn't fail the
+// refresh, the alert manager sends it again after the next one.
+func (s *Server) evaluateAlerts(now time.Time) {
+	if s.alertManager == nil {
+		return
+	}
+	reports := map[string]alerting.Reports{}
+	for reportName, report := range s.testReports() {
+		reports[reportName] = alerting.Reports{
+			Current:      report.CurrentPeriodReport,
+			PreviousWeek: report.PreviousWeekReport,
+		}
+	}
+	if err := s.alertManager.Evaluate(reports, now); err != nil {
+		klog.Errorf("Error evaluating alerts: %v", err)
+	}
+}
+
+// alertsForRequest returns the firing alerts, of the ?release= report if it is set.
+func (s *Server) alertsForRequest(req *http.Request) (sippyv1.AlertList, int, error) {
+	if s.alertManager == nil {
+		return sippyv1.AlertList{}, http.StatusNotFound, fmt.Errorf("alerting is not enabled on this server")
+	}
+	reportName := req.URL.Query().Get("release")
+	ret := sippyv1.AlertList{Alerts: []sippyv1.Alert{}}
+	for _, alert := range s.alertManager.Alerts() {
+		if len(reportName) == 0 || alert.Release == reportName {
+			ret.Alerts = append(ret.Alerts, alert)
+		}
+	}
+	return ret, http.StatusOK, nil
+}
+
+func (s *Server) printAlerts(w http.ResponseWriter, req *http.Request) {
+	alerts, status, err := s.alertsForRequest(req)
+	if err != nil {
+		api.PrintError(w, status, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(alerts); err != nil {
+		klog.Errorf("unable to write alerts: %v", err)
+	}
+}
@@ -23,6 +23,10 @@ type TestGridLoadingConfig struct {
 	LocalData string
 	// JobFilter is a regex run against job names. Only match names are loaded.
 	JobFilter *regexp.Regexp
+	// TestGridEndpoint is the testgrid instance that jobs link back to
+	TestGridEndpoint testgridhelpers.Endpoint
+	// ProwURL is the base url of the prow instance that job runs link to
+	ProwURL string
 }
 
 // RawJobResultsAnalysisOptions control which subset of data from the testgrid data is analyzed into the rawJobResults
@@ -36,6 +40,10 @@ type DisplayDataConfig struct {
 	MinTestRuns             int
 	TestSuccessThreshold    float64
 	FailureClusterThreshold int
+	// TestOwnership resolves the owner of every test.  Nil resolves owners from the sig and operator of the tests only.
+	TestOwnership testidentification.TestOwnership
+	// CISearchURL is the base url of the ci-search instance that failing tests link to
+	CISearchURL string
 }
 
 // TestReportGeneratorConfig is a static configuration that can be re-used across multiple invocations of PrepareTestReport with different versions
@@ -56,7 +64,7 @@ func (a *TestReportGeneratorConfig) PrepareTestReport(
 	variantManager testidentification.VariantManager,
 	bugCache buganalysis.BugCache,
 ) sippyprocessingv1.TestReport {
-	testGridJobDetails, lastUpdateTime := testgridhelpers.LoadTestGridDataFromDisk(a.TestGridLoadingConfig.LocalData, dashboard.TestGridDashboardNames, a.TestGridLoadingConfig.JobFilter)
+	testGridJobDetails, lastUpdateTime := testgridhelpers.LoadTestGridDataFromDisk(a.TestGridLoadingConfig.LocalData, dashboard.TestGridDashboardNames, a.TestGridLoadingConfig.JobFilter, a.TestGridLoadingConfig.TestGridEndpoint)
 	return a.prepareTestReportFromData(dashboard.ReportName, dashboard.BugzillaRelease, syntheticTestManager, variantManager, bugCache, testGridJobDetails, lastUpdateTime)
 }
 
@@ -74,6 +82,7 @@ func (a *TestReportGeneratorConfig) prepareTestReportFromData(
 		SythenticTestManager: syntheticTestManager,
 		StartDay:             a.RawJobResultsAnalysisConfig.StartDay,
 		NumDays:              a.RawJobResultsAnalysisConfig.NumDays,
+		ProwURL:              a.TestGridLoadingConfig.ProwURL,
 	}
 	rawJobResults, processingWarnings := rawJobResultOptions.ProcessTestGridDataIntoRawJobResults(testGridJobDetails)
 	bugCacheWarnings := updateBugCacheForJobResults(bugCache, rawJobResults)
@@ -85,6 +94,7 @@ func (a *TestReportGeneratorConfig) prepareTestReportFromData(
 		reportName,
 		rawJobResults,
 		variantManager,
+		a.DisplayDataConfig.TestOwnership,
 		bugCache,
 		bugzillaRelease,
 		a.DisplayDataConfig.MinTestRuns,
@@ -103,8 +113,24 @@ func (a TestReportGeneratorConfig) PrepareStandardTestReports(
 	variantManager testidentification.VariantManager,
 	bugCache buganalysis.BugCache,
 ) StandardReport {
-	testGridJobDetails, lastUpdateTime := testgridhelpers.LoadTestGridDataFromDisk(a.TestGridLoadingConfig.LocalData, dashboard.TestGridDashboardNames, a.TestGridLoadingConfig.JobFilter)
+	testGridJobDetails, lastUpdateTime := a.loadTestGridData(dashboard)
+	return a.prepareStandardTestReportsFromData(dashboard, syntheticTestManager, variantManager, bugCache, testGridJobDetails, lastUpdateTime)
+}
+
+// loadTestGridData reads the testgrid data of the dashboard from disk.
+func (a TestReportGeneratorConfig) loadTestGridData(dashboard TestGridDashboardCoordinates) ([]testgridv1.JobDetails, time.Time) {
+	return testgridhelpers.LoadTestGridDataFromDisk(a.TestGridLoadingConfig.LocalData, dashboard.TestGridDashboardNames, a.TestGridLoadingConfig.JobFilter, a.TestGridLoadingConfig.TestGridEndpoint)
+}
 
+// prepareStandardTestReportsFromData is PrepareStandardTestReports with testgrid data that was already loaded.
+func (a TestReportGeneratorConfig) prepareStandardTestReportsFromData(
+	dashboard TestGridDashboardCoordinates,
+	syntheticTestManager testgridconversion.SythenticTestManager,
+	variantManager testidentification.VariantManager,
+	bugCache buganalysis.BugCache,
+	testGridJobDetails []testgridv1.JobDetails,
+	lastUpdateTime time.Time,
+) StandardReport {
 	currTimePeriodConfig := a.deepCopy()
 	currentTimePeriodReport := currTimePeriodConfig.prepareTestReportFromData(dashboard.ReportName, dashboard.BugzillaRelease, syntheticTestManager, variantManager, bugCache, testGridJobDetails, lastUpdateTime)
 
@@ -137,16 +163,25 @@ func updateBugCacheForJobResults(bugCache buganalysis.BugCache, rawJobResults te
 	failedTestNamesAcrossAllJobRuns := getFailedTestNamesFromJobResults(rawJobResults.JobResults)
 	if err := bugCache.UpdateForFailedTests(failedTestNamesAcrossAllJobRuns.List()...); err != nil {
 		klog.Error(err)
-		warnings = append(warnings, fmt.Sprintf("Bugzilla Lookup Error: an error was encountered looking up existing bugs for failing tests, some test failures may have associated bugs that are not listed below.  Lookup error: %v", err.Error()))
+		warnings = append(warnings, bugLookupWarning(err))
 	}
 	if err := bugCache.UpdateJobBlockers(sets.StringKeySet(rawJobResults.JobResults).List()...); err != nil {
 		klog.Error(err)
-		warnings = append(warnings, fmt.Sprintf("Bugzilla Lookup Error: an error was encountered looking up existing bugs for failing tests, some test failures may have associated bugs that are not listed below.  Lookup error: %v", err.Error()))
+		warnings = append(warnings, bugLookupWarning(err))
 	}
 
 	return warnings
 }
 
+// bugLookupWarning describes a failed bug lookup, including the tests or jobs whose bugs could not be looked up.
+func bugLookupWarning(err error) string {
+	warning := fmt.Sprintf("Bug Lookup Error: an error was encountered looking up existing bugs for failing tests, some test failures may have associated bugs that are not listed below.  Lookup error: %v", err.Error())
+	if lookupErr, ok := err.(*buganalysis.LookupError); ok {
+		warning += fmt.Sprintf("  The bugs of these %s could not be looked up: %q", lookupErr.Kind, lookupErr.Failed)
+	}
+	return warning
+}
+
 func getFailedTestNamesFromJobResults(jobResults map[string]testgridanalysisapi.RawJobResult) sets.String {
 	failedTestNames := sets.NewString()
 	for _, jobResult := range jobResults {
@@ -160,7 +195,9 @@ func getFailedTestNamesFromJobResults(jobResults map[string]testgridanalysisapi.
 func (a TestReportGeneratorConfig) deepCopy() TestReportGeneratorConfig {
 	ret := TestReportGeneratorConfig{
 		TestGridLoadingConfig: TestGridLoadingConfig{
-			LocalData: a.TestGridLoadingConfig.LocalData,
+			LocalData:        a.TestGridLoadingConfig.LocalData,
+			TestGridEndpoint: a.TestGridLoadingConfig.TestGridEndpoint,
+			ProwURL:          a.TestGridLoadingConfig.ProwURL,
 		},
 		RawJobResultsAnalysisConfig: RawJobResultsAnalysisConfig{
 			StartDay: a.RawJobResultsAnalysisConfig.StartDay,
@@ -170,6 +207,8 @@ func (a TestReportGeneratorConfig) deepCopy() TestReportGeneratorConfig {
 			MinTestRuns:             a.DisplayDataConfig.MinTestRuns,
 			TestSuccessThreshold:    a.DisplayDataConfig.TestSuccessThreshold,
 			FailureClusterThreshold: a.DisplayDataConfig.FailureClusterThreshold,
+			TestOwnership:           a.DisplayDataConfig.TestOwnership,
+			CISearchURL:             a.DisplayDataConfig.CISearchURL,
 		},
 	}
 	if a.TestGridLoadingConfig.JobFilter != nil {
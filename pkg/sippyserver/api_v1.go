@@ -0,0 +1,404 @@
+package sippyserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/openshift/sippy/pkg/api"
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"k8s.io/klog"
+)
+
+const apiV1Prefix = "/api/v1/"
+
+// apiV1Endpoint is a resource of /api/v1.  The handler returns the response body, or the status code and error to report
+// instead.
+type apiV1Endpoint struct {
+	api.Endpoint
+	handler func(req *http.Request) (interface{}, int, error)
+}
+
+var (
+	releaseParameter  = api.QueryParameter("release", "The name of the release report, as listed by /api/v1/releases.", true)
+	snapshotParameter = api.QueryParameter("snapshot", "The ID or YYYY-MM-DD date of a stored snapshot to read instead of the live report.", false)
+	teamParameter     = api.QueryParameter("team", "Only include the tests owned by this team.", false)
+)
+
+// reportParameters are the parameters of every endpoint that reads a release report.
+var reportParameters = []api.Parameter{releaseParameter, snapshotParameter, teamParameter}
+
+// filterParameters are the filters of api.ParseListOptions.  Each list only accepts the ones that can filter it.
+var filterParameters = []api.Parameter{
+	api.QueryParameter("name", "Only include the results whose name matches this regular expression.", false),
+	api.QueryParameter("variant", "Only include the jobs or variants of this variant.", false),
+	api.QueryParameter("component", "Only include the tests owned by this bugzilla component.", false),
+	numberParameter("minPassPercentage", "Only include the results that pass at least this percentage of the time."),
+	numberParameter("maxPassPercentage", "Only include the results that pass at most this percentage of the time."),
+	integerParameter("minRuns", "Only include the results that ran at least this many times."),
+}
+
+// pagingParameters sort and page every list, as api.ParseListOptions reads them.
+var pagingParameters = []api.Parameter{
+	api.QueryParameter("sort", "The JSON name of the numeric field to sort by, or runs.  Nested fields may be named by their path, like results.flakes.", false),
+	api.QueryParameter("order", "asc or desc.  Without sort, desc reverses the default order.", false),
+	integerParameter("limit", "The largest number of results to return."),
+	integerParameter("offset", "The number of results to skip."),
+	api.QueryParameter("cursor", "The nextCursor of the previous page.  It cannot be combined with offset.", false),
+}
+
+// listParameters returns the parameters of an endpoint that lists the results of a release report, which are a slice
+// like []sippyprocessingv1.JobResult.  Filters that the results do not support are rejected, so they are left out.
+func listParameters(results interface{}) []api.Parameter {
+	return pageParameters(append([]api.Parameter{}, reportParameters...), results)
+}
+
+// pageParameters returns the parameters, followed by the filter and paging parameters of the results.
+func pageParameters(parameters []api.Parameter, results interface{}) []api.Parameter {
+	for _, parameter := range filterParameters {
+		if api.CanFilter(results, parameter.Name) {
+			parameters = append(parameters, parameter)
+		}
+	}
+	return append(parameters, pagingParameters...)
+}
+
+func numberParameter(name, description string) api.Parameter {
+	parameter := api.QueryParameter(name, description, false)
+	parameter.Schema = api.Schema{Type: "number", Format: "double"}
+	return parameter
+}
+
+func integerParameter(name, description string) api.Parameter {
+	parameter := api.QueryParameter(name, description, false)
+	parameter.Schema = api.Schema{Type: "integer", Format: "int32"}
+	return parameter
+}
+
+// apiV1Endpoints lists the resources of /api/v1.  The OpenAPI document is generated from this list, so every resource
+// must be registered here.
+func (s *Server) apiV1Endpoints() []apiV1Endpoint {
+	return []apiV1Endpoint{
+		{
+			Endpoint: api.Endpoint{
+				Path:        apiV1Prefix + "releases",
+				Summary:     "List the releases",
+				Description: "Every release report the server serves, with the testgrid dashboards it is built from.",
+				Response:    sippyv1.ReleaseList{},
+			},
+			handler: s.apiV1Releases,
+		},
+		{
+			Endpoint: api.Endpoint{
+				Path:        apiV1Prefix + "jobs",
+				Summary:     "List the jobs of a release",
+				Description: "The pass rate of the jobs in the current period and the previous week, lowest first unless sorted otherwise.",
+				Parameters:  listParameters([]sippyprocessingv1.JobResult{}),
+				Response:    sippyv1.JobList{},
+			},
+			handler: s.apiV1Jobs,
+		},
+		{
+			Endpoint: api.Endpoint{
+				Path:        apiV1Prefix + "jobruns",
+				Summary:     "List the job runs of a release",
+				Description: "Every run of every job in the data of the live report, newest first within each job unless sorted otherwise.  Snapshots do not keep the runs.",
+				Parameters: pageParameters([]api.Parameter{
+					releaseParameter,
+					api.QueryParameter("job", "Only include the runs of the job with this name.", false),
+				}, []sippyv1.JobRun{}),
+				Response: sippyv1.JobRunList{},
+			},
+			handler: s.apiV1JobRuns,
+		},
+		{
+			Endpoint: api.Endpoint{
+				Path:        apiV1Prefix + "jobrun",
+				Summary:     "Get a job run",
+				Description: "How one job run failed, the final state of its operators, its failed tests with their pass rates, and the bugs that match.",
+				Parameters: []api.Parameter{
+					api.QueryParameter("url", "The prow URL of the run, as listed by /api/v1/jobruns.", true),
+					api.QueryParameter("release", "The name of the release report.  If missing, the first release that has the job is used.", false),
+					snapshotParameter,
+				},
+				Response: sippyv1.JobRunReport{},
+			},
+			handler: func(req *http.Request) (interface{}, int, error) {
+				jobRunReport, _, status, err := s.jobRunReportForRequest(req)
+				return jobRunReport, status, err
+			},
+		},
+		{
+			Endpoint: api.Endpoint{
+				Path:        apiV1Prefix + "tests",
+				Summary:     "List the tests of a release",
+				Description: "The pass rate of the tests across all jobs in the current period and the previous week, lowest first unless sorted otherwise.",
+				Parameters:  listParameters([]sippyprocessingv1.FailingTestResult{}),
+				Response:    sippyv1.TestList{},
+			},
+			handler: s.apiV1Tests,
+		},
+		{
+			Endpoint: api.Endpoint{
+				Path:        apiV1Prefix + "variants",
+				Summary:     "List the variants of a release",
+				Description: "The pass rate of the jobs of the variants in the current period and the previous week, lowest first unless sorted otherwise.",
+				Parameters:  listParameters([]sippyprocessingv1.VariantResults{}),
+				Response:    sippyv1.VariantList{},
+			},
+			handler: s.apiV1Variants,
+		},
+		{
+			Endpoint: api.Endpoint{
+				Path:        apiV1Prefix + "bugs",
+				Summary:     "List the bugs of a release",
+				Description: "The bugs that match failing tests, most failures first.",
+				Parameters:  reportParameters,
+				Response:    sippyv1.BugList{},
+			},
+			handler: s.apiV1Bugs,
+		},
+		{
+			Endpoint: api.Endpoint{
+				Path:        apiV1Prefix + "components",
+				Summary:     "List the bugzilla components of a release",
+				Description: "The lowest job pass rate that the failing tests of each bugzilla component cause, lowest first.",
+				Parameters:  reportParameters,
+				Response:    sippyv1.ComponentList{},
+			},
+			handler: s.apiV1Components,
+		},
+		{
+			Endpoint: api.Endpoint{
+				Path:        apiV1Prefix + "component",
+				Summary:     "Get the health of a bugzilla component",
+				Description: "The failing tests, broken jobs, open bugs, and operators of one bugzilla component.",
+				Parameters: append(append([]api.Parameter{}, reportParameters...),
+					api.QueryParameter("name", "The name of the bugzilla component.", true)),
+				Response: sippyv1.ComponentReport{},
+			},
+			handler: func(req *http.Request) (interface{}, int, error) {
+				componentReport, _, status, err := s.componentReportForRequest(req)
+				return componentReport, status, err
+			},
+		},
+		{
+			Endpoint: api.Endpoint{
+				Path:        apiV1Prefix + "alerts",
+				Summary:     "List the firing alerts",
+				Description: "The alerts of the alerting rules that fired when the reports were last refreshed.  Alerting must be enabled with --alert-config.",
+				Parameters:  []api.Parameter{api.QueryParameter("release", "Only include the alerts of this release.", false)},
+				Response:    sippyv1.AlertList{},
+			},
+			handler: func(req *http.Request) (interface{}, int, error) {
+				return s.alertsForRequest(req)
+			},
+		},
+	}
+}
+
+// reportForRequest returns the ?release= report, honoring ?snapshot= and ?team=.
+func (s *Server) reportForRequest(req *http.Request) (StandardReport, int, error) {
+	reportName := req.URL.Query().Get("release")
+	if len(reportName) == 0 {
+		return StandardReport{}, http.StatusBadRequest, fmt.Errorf("release is required")
+	}
+	currTestReports, err := s.testReportsForRequest(req)
+	if err != nil {
+		return StandardReport{}, http.StatusNotFound, err
+	}
+	report, ok := currTestReports[reportName]
+	if !ok {
+		return StandardReport{}, http.StatusNotFound, fmt.Errorf("release %s not found", reportName)
+	}
+	return report, http.StatusOK, nil
+}
+
+func (s *Server) apiV1Releases(req *http.Request) (interface{}, int, error) {
+	testReports := s.testReports()
+	releases := sippyv1.ReleaseList{Items: []sippyv1.Release{}}
+	for _, dashboard := range s.dashboardCoordinates {
+		release := sippyv1.Release{
+			Name:             dashboard.ReportName,
+			Dashboards:       dashboard.TestGridDashboardNames,
+			BugzillaRelease:  dashboard.BugzillaRelease,
+			AnalysisWarnings: []string{},
+		}
+		if report, ok := testReports[dashboard.ReportName]; ok {
+			if timestamp := report.CurrentPeriodReport.Timestamp; !timestamp.IsZero() {
+				release.Timestamp = &timestamp
+			}
+			if report.CurrentPeriodReport.AnalysisWarnings != nil {
+				release.AnalysisWarnings = report.CurrentPeriodReport.AnalysisWarnings
+			}
+		}
+		releases.Items = append(releases.Items, release)
+	}
+	return releases, http.StatusOK, nil
+}
+
+// reportPageForRequest returns the ?release= report and the page of its results that the list options of the request
+// select.  results picks the list from the current period of the report.
+func (s *Server) reportPageForRequest(req *http.Request, results func(report sippyprocessingv1.TestReport) interface{}) (StandardReport, interface{}, sippyv1.ListMeta, int, error) {
+	report, status, err := s.reportForRequest(req)
+	if err != nil {
+		return StandardReport{}, nil, sippyv1.ListMeta{}, status, err
+	}
+	// cursors are only valid for the report they paged through
+	version := strconv.FormatInt(report.CurrentPeriodReport.Timestamp.UnixNano(), 10)
+	options, err := api.ParseListOptions(req.URL.Query(), version)
+	if err != nil {
+		return StandardReport{}, nil, sippyv1.ListMeta{}, http.StatusBadRequest, err
+	}
+	page, meta, err := api.Page(results(report.CurrentPeriodReport), options, version)
+	if err != nil {
+		return StandardReport{}, nil, sippyv1.ListMeta{}, http.StatusBadRequest, err
+	}
+	return report, page, meta, http.StatusOK, nil
+}
+
+func (s *Server) apiV1Jobs(req *http.Request) (interface{}, int, error) {
+	report, page, meta, status, err := s.reportPageForRequest(req, func(report sippyprocessingv1.TestReport) interface{} {
+		return report.ByJob
+	})
+	if err != nil {
+		return nil, status, err
+	}
+	return sippyv1.JobList{
+		Release:  report.CurrentPeriodReport.Release,
+		ListMeta: meta,
+		Items:    api.Jobs(page.([]sippyprocessingv1.JobResult), report.PreviousWeekReport),
+	}, http.StatusOK, nil
+}
+
+func (s *Server) apiV1JobRuns(req *http.Request) (interface{}, int, error) {
+	if len(req.URL.Query().Get("snapshot")) > 0 {
+		return nil, http.StatusBadRequest, fmt.Errorf("snapshots do not keep the job runs, only the live report has them")
+	}
+	report, status, err := s.reportForRequest(req)
+	if err != nil {
+		return nil, status, err
+	}
+	reportName := req.URL.Query().Get("release")
+	if report.jobRuns == nil {
+		return nil, http.StatusNotFound, fmt.Errorf("the job runs of release %s are not loaded yet", reportName)
+	}
+
+	jobName := req.URL.Query().Get("job")
+	runs := report.jobRuns.JobRuns(jobName)
+	if len(jobName) > 0 && len(runs) == 0 {
+		return nil, http.StatusNotFound, fmt.Errorf("job %s not found in release %s", jobName, reportName)
+	}
+
+	// cursors are only valid for the runs they paged through, which change with every refresh
+	version := strconv.FormatInt(report.CurrentPeriodReport.Timestamp.UnixNano(), 10)
+	options, err := api.ParseListOptions(req.URL.Query(), version)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	page, meta, err := api.Page(runs, options, version)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	warnings := report.jobRuns.Warnings
+	if warnings == nil {
+		warnings = []string{}
+	}
+	return sippyv1.JobRunList{
+		Release:          reportName,
+		ListMeta:         meta,
+		AnalysisWarnings: warnings,
+		Items:            page.([]sippyv1.JobRun),
+	}, http.StatusOK, nil
+}
+
+func (s *Server) apiV1Tests(req *http.Request) (interface{}, int, error) {
+	report, page, meta, status, err := s.reportPageForRequest(req, func(report sippyprocessingv1.TestReport) interface{} {
+		return report.ByTest
+	})
+	if err != nil {
+		return nil, status, err
+	}
+	return sippyv1.TestList{
+		Release:  report.CurrentPeriodReport.Release,
+		ListMeta: meta,
+		Items:    api.Tests(page.([]sippyprocessingv1.FailingTestResult), report.PreviousWeekReport, s.testReportGeneratorConfig.DisplayDataConfig.CISearchURL),
+	}, http.StatusOK, nil
+}
+
+func (s *Server) apiV1Variants(req *http.Request) (interface{}, int, error) {
+	report, page, meta, status, err := s.reportPageForRequest(req, func(report sippyprocessingv1.TestReport) interface{} {
+		return report.ByVariant
+	})
+	if err != nil {
+		return nil, status, err
+	}
+	return sippyv1.VariantList{
+		Release:  report.CurrentPeriodReport.Release,
+		ListMeta: meta,
+		Items:    api.Variants(page.([]sippyprocessingv1.VariantResults), report.PreviousWeekReport),
+	}, http.StatusOK, nil
+}
+
+func (s *Server) apiV1Bugs(req *http.Request) (interface{}, int, error) {
+	report, status, err := s.reportForRequest(req)
+	if err != nil {
+		return nil, status, err
+	}
+	return sippyv1.BugList{
+		Release: report.CurrentPeriodReport.Release,
+		Items:   api.Bugs(report.CurrentPeriodReport),
+	}, http.StatusOK, nil
+}
+
+func (s *Server) apiV1Components(req *http.Request) (interface{}, int, error) {
+	report, status, err := s.reportForRequest(req)
+	if err != nil {
+		return nil, status, err
+	}
+	return sippyv1.ComponentList{
+		Release: report.CurrentPeriodReport.Release,
+		Items:   api.Components(report.CurrentPeriodReport, report.PreviousWeekReport),
+	}, http.StatusOK, nil
+}
+
+// serveAPIV1 wraps the handler of an endpoint so that it only answers GET and writes either the body or an error.
+func serveAPIV1(handler func(req *http.Request) (interface{}, int, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			api.PrintError(w, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", req.Method))
+			return
+		}
+		body, status, err := handler(req)
+		if err != nil {
+			api.PrintError(w, status, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if err := json.NewEncoder(w).Encode(body); err != nil {
+			klog.Errorf("unable to render json %v", err)
+		}
+	}
+}
+
+// registerAPIV1 registers every resource of /api/v1, its OpenAPI document, and a JSON 404 for everything else under
+// /api/v1/.
+func (s *Server) registerAPIV1(mux *http.ServeMux) {
+	endpoints := s.apiV1Endpoints()
+	apiEndpoints := []api.Endpoint{}
+	for _, endpoint := range endpoints {
+		mux.HandleFunc(endpoint.Path, serveAPIV1(endpoint.handler))
+		apiEndpoints = append(apiEndpoints, endpoint.Endpoint)
+	}
+
+	openAPI := api.OpenAPI("Sippy", "v1", apiEndpoints)
+	mux.HandleFunc(apiV1Prefix+"openapi.json", serveAPIV1(func(req *http.Request) (interface{}, int, error) {
+		return openAPI, http.StatusOK, nil
+	}))
+	mux.HandleFunc(apiV1Prefix, serveAPIV1(func(req *http.Request) (interface{}, int, error) {
+		return nil, http.StatusNotFound, fmt.Errorf("%s is not a resource, the resources are described by %sopenapi.json", req.URL.Path, apiV1Prefix)
+	}))
+}
@@ -0,0 +1,56 @@
+package sippyserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/openshift/sippy/pkg/api"
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/html/releasehtml"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testidentification"
+	"k8s.io/klog"
+)
+
+// componentReportForRequest summarizes the health of the ?name= bugzilla component in the current period of the
+// ?release=, compared to the previous week.
+func (s *Server) componentReportForRequest(req *http.Request) (sippyv1.ComponentReport, sippyprocessingv1.TestReport, int, error) {
+	currTestReports, err := s.testReportsForRequest(req)
+	if err != nil {
+		return sippyv1.ComponentReport{}, sippyprocessingv1.TestReport{}, http.StatusNotFound, err
+	}
+	reportName := req.URL.Query().Get("release")
+	report, ok := currTestReports[reportName]
+	if !ok {
+		return sippyv1.ComponentReport{}, sippyprocessingv1.TestReport{}, http.StatusNotFound, fmt.Errorf("release %s not found", reportName)
+	}
+	component := req.URL.Query().Get("name")
+	if !testidentification.IsValidBugzillaComponent(component) {
+		return sippyv1.ComponentReport{}, sippyprocessingv1.TestReport{}, http.StatusNotFound, fmt.Errorf("%q is not a bugzilla component", component)
+	}
+
+	return api.ComponentReport(report.CurrentPeriodReport, report.PreviousWeekReport, component, s.testReportGeneratorConfig.DisplayDataConfig.CISearchURL), report.CurrentPeriodReport, http.StatusOK, nil
+}
+
+func (s *Server) printComponentReport(w http.ResponseWriter, req *http.Request) {
+	componentReport, _, status, err := s.componentReportForRequest(req)
+	if err != nil {
+		api.PrintError(w, status, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(componentReport); err != nil {
+		klog.Errorf("unable to write component report: %v", err)
+	}
+}
+
+func (s *Server) printComponentHtmlReport(w http.ResponseWriter, req *http.Request) {
+	componentReport, report, status, err := s.componentReportForRequest(req)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	releasehtml.PrintComponentHtmlReport(w, componentReport, s.testReportGeneratorConfig.RawJobResultsAnalysisConfig.NumDays, report.Timestamp)
+}
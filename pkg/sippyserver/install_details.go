@@ -7,58 +7,82 @@ import (
 )
 
 func (s *Server) printInstallHtmlReport(w http.ResponseWriter, req *http.Request) {
+	currTestReports, err := s.testReportsForRequest(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
 	reportName := req.URL.Query().Get("release")
-	if _, ok := s.currTestReports[reportName]; !ok {
+	if _, ok := currTestReports[reportName]; !ok {
 		w.WriteHeader(http.StatusNotFound)
 		return
 	}
 	installhtml.PrintInstallHtmlReport(w, req,
-		s.currTestReports[reportName].CurrentPeriodReport,
-		s.currTestReports[reportName].PreviousWeekReport,
+		currTestReports[reportName].CurrentPeriodReport,
+		currTestReports[reportName].PreviousWeekReport,
 		s.testReportGeneratorConfig.RawJobResultsAnalysisConfig.NumDays,
 		reportName,
+		s.testReportGeneratorConfig.DisplayDataConfig.CISearchURL,
 	)
 }
 
 func (s *Server) printUpgradeHtmlReport(w http.ResponseWriter, req *http.Request) {
+	currTestReports, err := s.testReportsForRequest(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
 	reportName := req.URL.Query().Get("release")
-	if _, ok := s.currTestReports[reportName]; !ok {
+	if _, ok := currTestReports[reportName]; !ok {
 		w.WriteHeader(http.StatusNotFound)
 		return
 	}
 	installhtml.PrintUpgradeHtmlReport(w, req,
-		s.currTestReports[reportName].CurrentPeriodReport,
-		s.currTestReports[reportName].PreviousWeekReport,
+		currTestReports[reportName].CurrentPeriodReport,
+		currTestReports[reportName].PreviousWeekReport,
 		s.testReportGeneratorConfig.RawJobResultsAnalysisConfig.NumDays,
 		reportName,
+		s.testReportGeneratorConfig.DisplayDataConfig.CISearchURL,
 	)
 }
 
 func (s *Server) printOperatorHealthHtmlReport(w http.ResponseWriter, req *http.Request) {
+	currTestReports, err := s.testReportsForRequest(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
 	reportName := req.URL.Query().Get("release")
-	if _, ok := s.currTestReports[reportName]; !ok {
+	if _, ok := currTestReports[reportName]; !ok {
 		w.WriteHeader(http.StatusNotFound)
 		return
 	}
 	installhtml.PrintOperatorHealthHtmlReport(w, req,
-		s.currTestReports[reportName].CurrentPeriodReport,
-		s.currTestReports[reportName].PreviousWeekReport,
+		currTestReports[reportName].CurrentPeriodReport,
+		currTestReports[reportName].PreviousWeekReport,
 		s.testReportGeneratorConfig.RawJobResultsAnalysisConfig.NumDays,
 		reportName,
+		s.testReportGeneratorConfig.DisplayDataConfig.CISearchURL,
 	)
 }
 
 func (s *Server) printTestDetailHtmlReport(w http.ResponseWriter, req *http.Request) {
+	currTestReports, err := s.testReportsForRequest(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
 	reportName := req.URL.Query().Get("release")
-	if _, ok := s.currTestReports[reportName]; !ok {
+	if _, ok := currTestReports[reportName]; !ok {
 		w.WriteHeader(http.StatusNotFound)
 		return
 	}
 	installhtml.PrintTestDetailHtmlReport(w, req,
-		s.currTestReports[reportName].CurrentPeriodReport,
-		s.currTestReports[reportName].PreviousWeekReport,
+		currTestReports[reportName].CurrentPeriodReport,
+		currTestReports[reportName].PreviousWeekReport,
 		req.URL.Query()["test"],
 		s.testReportGeneratorConfig.RawJobResultsAnalysisConfig.NumDays,
 		reportName,
+		s.testReportGeneratorConfig.DisplayDataConfig.CISearchURL,
 	)
 }
@@ -0,0 +1,83 @@
+package sippyserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/openshift/sippy/pkg/api"
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/html/releasehtml"
+	"github.com/openshift/sippy/pkg/util"
+	"k8s.io/klog"
+)
+
+// jobRunReportForRequest describes the job run with the prow ?url=.  The run is looked up in the job runs of the live
+// report, even with ?snapshot=, because snapshots do not keep them.  If ?release= is not set, the run is looked for in
+// the first release that has its job.
+func (s *Server) jobRunReportForRequest(req *http.Request) (sippyv1.JobRunReport, sippyprocessingv1.TestReport, int, error) {
+	runURL := req.URL.Query().Get("url")
+	if len(runURL) == 0 {
+		return sippyv1.JobRunReport{}, sippyprocessingv1.TestReport{}, http.StatusBadRequest, fmt.Errorf("url is required")
+	}
+	parsedURL, err := url.Parse(runURL)
+	if err != nil {
+		return sippyv1.JobRunReport{}, sippyprocessingv1.TestReport{}, http.StatusBadRequest, fmt.Errorf("url: %v", err)
+	}
+	// job run URLs end in <job name>/<build id>
+	jobName := path.Base(path.Dir(parsedURL.Path))
+
+	currTestReports, err := s.testReportsForRequest(req)
+	if err != nil {
+		return sippyv1.JobRunReport{}, sippyprocessingv1.TestReport{}, http.StatusNotFound, err
+	}
+	reportName := req.URL.Query().Get("release")
+	if len(reportName) == 0 {
+		for _, name := range s.reportNames() {
+			if util.FindJobResultForJobName(jobName, currTestReports[name].CurrentPeriodReport.ByJob) != nil {
+				reportName = name
+				break
+			}
+		}
+	}
+	report, ok := currTestReports[reportName]
+	if !ok {
+		return sippyv1.JobRunReport{}, sippyprocessingv1.TestReport{}, http.StatusNotFound, fmt.Errorf("no release has job %s", jobName)
+	}
+	jobRuns := s.testReports()[reportName].jobRuns
+	if jobRuns == nil {
+		return sippyv1.JobRunReport{}, sippyprocessingv1.TestReport{}, http.StatusNotFound, fmt.Errorf("the job runs of release %s are not loaded yet", reportName)
+	}
+
+	jobRunReport, found := api.JobRunReport(*jobRuns, s.testReportGeneratorConfig.DisplayDataConfig.CISearchURL, runURL, report.CurrentPeriodReport, report.PreviousWeekReport)
+	if !found {
+		return sippyv1.JobRunReport{}, sippyprocessingv1.TestReport{}, http.StatusNotFound, fmt.Errorf("job run %s not found in release %s", runURL, reportName)
+	}
+	jobRunReport.Release = reportName
+	return jobRunReport, report.CurrentPeriodReport, http.StatusOK, nil
+}
+
+func (s *Server) printJobRunReport(w http.ResponseWriter, req *http.Request) {
+	jobRunReport, _, status, err := s.jobRunReportForRequest(req)
+	if err != nil {
+		api.PrintError(w, status, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(jobRunReport); err != nil {
+		klog.Errorf("unable to write job run report: %v", err)
+	}
+}
+
+func (s *Server) printJobRunHtmlReport(w http.ResponseWriter, req *http.Request) {
+	jobRunReport, report, status, err := s.jobRunReportForRequest(req)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	releasehtml.PrintJobRunHtmlReport(w, jobRunReport, s.testReportGeneratorConfig.RawJobResultsAnalysisConfig.NumDays, report.Timestamp)
+}
@@ -0,0 +1,183 @@
+package sippyserver
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/metrics"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testgridhelpers"
+	"k8s.io/klog"
+)
+
+// refreshBuckets are the upper bounds, in seconds, of the refresh duration histogram.  Refreshes take minutes.
+var refreshBuckets = []float64{5, 15, 30, 60, 120, 300, 600, 1200}
+
+func newRequestDurationHistogram() *metrics.HistogramVec {
+	return metrics.NewHistogramVec("sippy_http_request_duration_seconds", "How long HTTP requests take, by the path the handler is registered on.",
+		metrics.DefaultBuckets, "handler", "method", "code")
+}
+
+func newRefreshDurationHistogram() *metrics.HistogramVec {
+	return metrics.NewHistogramVec("sippy_refresh_duration_seconds", "How long refreshes of the reports take, by whether they succeeded.",
+		refreshBuckets, "result")
+}
+
+// statusRecorder remembers the status code of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// instrument records how long every request served by the mux takes.  Requests are labeled with the pattern of their
+// handler rather than their path, so that the number of series stays small.
+func (s *Server) instrument(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, pattern := mux.Handler(req)
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		mux.ServeHTTP(recorder, req)
+		s.requestDuration.Observe(time.Since(start).Seconds(), pattern, req.Method, strconv.Itoa(recorder.status))
+	})
+}
+
+func (s *Server) recordRefreshDuration(start time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	s.refreshDuration.Observe(time.Since(start).Seconds(), result)
+}
+
+func (s *Server) printMetrics(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	families := append(reportMetrics(s.testReports()), s.serverMetrics()...)
+	if err := metrics.WriteFamilies(w, families...); err != nil {
+		klog.Errorf("unable to write metrics: %v", err)
+	}
+}
+
+// serverMetrics describe the server itself rather than the releases.
+func (s *Server) serverMetrics() []metrics.Family {
+	filesLoaded, fileLoadErrors := testgridhelpers.LoadedFileCounts()
+	bugLookupFailing := 0.0
+	if s.bugCache.LastUpdateError() != nil {
+		bugLookupFailing = 1
+	}
+	status := s.RefreshStatus()
+	lastSuccessfulRefresh := 0.0
+	if status.LastSuccessfulRefresh != nil {
+		lastSuccessfulRefresh = float64(status.LastSuccessfulRefresh.Unix())
+	}
+
+	return []metrics.Family{
+		{
+			Name:    "sippy_testgrid_files_loaded_total",
+			Help:    "The number of testgrid files read from disk.",
+			Type:    metrics.Counter,
+			Samples: []metrics.Sample{{Value: float64(filesLoaded)}},
+		},
+		{
+			Name:    "sippy_testgrid_file_load_errors_total",
+			Help:    "The number of testgrid files that could not be read or parsed.",
+			Type:    metrics.Counter,
+			Samples: []metrics.Sample{{Value: float64(fileLoadErrors)}},
+		},
+		{
+			Name:    "sippy_bug_lookup_failing",
+			Help:    "1 if the last lookup of bugs failed, otherwise 0.",
+			Type:    metrics.Gauge,
+			Samples: []metrics.Sample{{Value: bugLookupFailing}},
+		},
+		{
+			Name:    "sippy_last_successful_refresh_timestamp_seconds",
+			Help:    "When the last successful refresh started, in seconds since the epoch, or 0 if none has succeeded.",
+			Type:    metrics.Gauge,
+			Samples: []metrics.Sample{{Value: lastSuccessfulRefresh}},
+		},
+		s.refreshDuration.Family(),
+		s.requestDuration.Family(),
+	}
+}
+
+// reportMetrics describe the health of the releases, from their current period reports.
+func reportMetrics(reports map[string]StandardReport) []metrics.Family {
+	indicatorPassPercentage := metrics.Family{
+		Name: "sippy_indicator_pass_percentage",
+		Help: "The pass rate of the top level indicators of a release: infrastructure, install, upgrade, and final operator health.",
+		Type: metrics.Gauge,
+	}
+	indicatorRuns := metrics.Family{Name: "sippy_indicator_runs", Help: "The number of runs of the top level indicators of a release.", Type: metrics.Gauge}
+	variantPassPercentage := metrics.Family{Name: "sippy_variant_pass_percentage", Help: "The pass rate of the job runs of a variant.", Type: metrics.Gauge}
+	variantRuns := metrics.Family{Name: "sippy_variant_runs", Help: "The number of job runs of a variant.", Type: metrics.Gauge}
+	jobPassPercentage := metrics.Family{Name: "sippy_job_pass_percentage", Help: "The pass rate of the runs of a job.", Type: metrics.Gauge}
+	jobRuns := metrics.Family{Name: "sippy_job_runs", Help: "The number of runs of a job.", Type: metrics.Gauge}
+	failingTests := metrics.Family{Name: "sippy_failing_tests", Help: "The number of tests that failed at least once, by whether they have a bug.", Type: metrics.Gauge}
+
+	reportNames := []string{}
+	for reportName := range reports {
+		reportNames = append(reportNames, reportName)
+	}
+	sort.Strings(reportNames)
+
+	for _, reportName := range reportNames {
+		report := reports[reportName].CurrentPeriodReport
+		release := metrics.Label{Name: "release", Value: reportName}
+
+		for _, indicator := range []struct {
+			name   string
+			result sippyprocessingv1.TestResult
+		}{
+			{name: "infrastructure", result: report.TopLevelIndicators.Infrastructure.TestResultAcrossAllJobs},
+			{name: "install", result: report.TopLevelIndicators.Install.TestResultAcrossAllJobs},
+			{name: "upgrade", result: report.TopLevelIndicators.Upgrade.TestResultAcrossAllJobs},
+			{name: "finalOperatorHealth", result: report.TopLevelIndicators.FinalOperatorHealth.TestResultAcrossAllJobs},
+		} {
+			labels := []metrics.Label{release, {Name: "indicator", Value: indicator.name}}
+			indicatorPassPercentage.Samples = append(indicatorPassPercentage.Samples, metrics.Sample{Labels: labels, Value: indicator.result.PassPercentage})
+			indicatorRuns.Samples = append(indicatorRuns.Samples, metrics.Sample{Labels: labels, Value: float64(indicator.result.Successes + indicator.result.Failures)})
+		}
+
+		for _, variant := range report.ByVariant {
+			labels := []metrics.Label{release, {Name: "variant", Value: variant.VariantName}}
+			variantPassPercentage.Samples = append(variantPassPercentage.Samples, metrics.Sample{Labels: labels, Value: variant.JobRunPassPercentage})
+			variantRuns.Samples = append(variantRuns.Samples, metrics.Sample{Labels: labels, Value: float64(variant.JobRunSuccesses + variant.JobRunFailures)})
+		}
+
+		for _, job := range report.ByJob {
+			labels := []metrics.Label{release, {Name: "job", Value: job.Name}}
+			jobPassPercentage.Samples = append(jobPassPercentage.Samples, metrics.Sample{Labels: labels, Value: job.PassPercentage})
+			jobRuns.Samples = append(jobRuns.Samples, metrics.Sample{Labels: labels, Value: float64(job.Successes + job.Failures)})
+		}
+
+		withBug, withoutBug := 0, 0
+		for _, test := range report.ByTest {
+			if test.TestResultAcrossAllJobs.Failures == 0 {
+				continue
+			}
+			if len(test.TestResultAcrossAllJobs.BugList) > 0 {
+				withBug++
+			} else {
+				withoutBug++
+			}
+		}
+		failingTests.Samples = append(failingTests.Samples,
+			metrics.Sample{Labels: []metrics.Label{release, {Name: "bug", Value: "with"}}, Value: float64(withBug)},
+			metrics.Sample{Labels: []metrics.Label{release, {Name: "bug", Value: "without"}}, Value: float64(withoutBug)},
+		)
+	}
+
+	return []metrics.Family{
+		indicatorPassPercentage, indicatorRuns,
+		variantPassPercentage, variantRuns,
+		jobPassPercentage, jobRuns,
+		failingTests,
+	}
+}
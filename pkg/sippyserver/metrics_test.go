@@ -0,0 +1,76 @@
+package sippyserver
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	bugsv1 "github.com/openshift/sippy/pkg/apis/bugs/v1"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/metrics"
+)
+
+func TestReportMetrics(t *testing.T) {
+	failing := func(name string, bugs ...bugsv1.Bug) sippyprocessingv1.FailingTestResult {
+		return sippyprocessingv1.FailingTestResult{
+			TestName:                name,
+			TestResultAcrossAllJobs: sippyprocessingv1.TestResult{Successes: 9, Failures: 1, BugList: bugs},
+		}
+	}
+	report := sippyprocessingv1.TestReport{
+		TopLevelIndicators: sippyprocessingv1.TopLevelIndicators{
+			Install: sippyprocessingv1.FailingTestResult{TestResultAcrossAllJobs: sippyprocessingv1.TestResult{Successes: 45, Failures: 5, PassPercentage: 90}},
+		},
+		ByVariant: []sippyprocessingv1.VariantResults{{VariantName: "aws", JobRunSuccesses: 3, JobRunFailures: 1, JobRunPassPercentage: 75}},
+		ByJob:     []sippyprocessingv1.JobResult{{Name: "job-aws", Successes: 3, Failures: 1, PassPercentage: 75}},
+		ByTest: []sippyprocessingv1.FailingTestResult{
+			failing("a", bugsv1.Bug{ID: 1}),
+			failing("b"),
+			failing("c"),
+			{TestName: "passing", TestResultAcrossAllJobs: sippyprocessingv1.TestResult{Successes: 10}},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := metrics.WriteFamilies(buf, reportMetrics(map[string]StandardReport{"4.8": {CurrentPeriodReport: report}})...); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`sippy_indicator_pass_percentage{release="4.8",indicator="install"} 90`,
+		`sippy_indicator_runs{release="4.8",indicator="install"} 50`,
+		`sippy_indicator_runs{release="4.8",indicator="upgrade"} 0`,
+		`sippy_variant_pass_percentage{release="4.8",variant="aws"} 75`,
+		`sippy_variant_runs{release="4.8",variant="aws"} 4`,
+		`sippy_job_pass_percentage{release="4.8",job="job-aws"} 75`,
+		`sippy_job_runs{release="4.8",job="job-aws"} 4`,
+		`sippy_failing_tests{release="4.8",bug="with"} 1`,
+		`sippy_failing_tests{release="4.8",bug="without"} 2`,
+	} {
+		if !strings.Contains(buf.String(), expected+"\n") {
+			t.Errorf("expected %s in\n%s", expected, buf.String())
+		}
+	}
+}
+
+func TestInstrument(t *testing.T) {
+	s := &Server{requestDuration: newRequestDurationHistogram()}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/jobs", func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "no release", http.StatusBadRequest)
+	})
+	handler := s.instrument(mux)
+	for _, path := range []string{"/api/jobs?release=4.8", "/api/jobs?release=4.9"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	buf := &bytes.Buffer{}
+	if err := metrics.WriteFamilies(buf, s.requestDuration.Family()); err != nil {
+		t.Fatal(err)
+	}
+	expected := `sippy_http_request_duration_seconds_count{handler="/api/jobs",method="GET",code="400"} 2`
+	if !strings.Contains(buf.String(), expected+"\n") {
+		t.Errorf("expected %s in\n%s", expected, buf.String())
+	}
+}
@@ -0,0 +1,134 @@
+package sippyserver
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+)
+
+// maxRetainedRefreshJobs bounds how many finished jobs can still be looked up by ID.
+const maxRetainedRefreshJobs = 100
+
+// refreshQueue runs one refresh at a time and coalesces requests.  A request made while a refresh is pending joins it.
+// A request made while a refresh is running queues a single pending refresh, so that the caller is guaranteed to see
+// data at least as new as their request.
+type refreshQueue struct {
+	// run does the refresh.  It is only ever called from the queue's worker.
+	run func(fetch bool) error
+
+	lock    sync.Mutex
+	nextID  int
+	pending *refreshJob
+	// jobs holds the pending, running, and most recent finished jobs, indexed by ID
+	jobs map[string]*refreshJob
+	// finished holds the IDs of finished jobs, oldest first, so they can be expired
+	finished []string
+	wake     chan struct{}
+}
+
+type refreshJob struct {
+	// status is protected by the refreshQueue lock
+	status sippyv1.RefreshJob
+	// done is closed when the job finishes
+	done chan struct{}
+}
+
+func newRefreshQueue(run func(fetch bool) error) *refreshQueue {
+	return &refreshQueue{
+		run:  run,
+		jobs: map[string]*refreshJob{},
+		wake: make(chan struct{}, 1),
+	}
+}
+
+// request queues a refresh, or joins the pending one, and returns the job and a channel that is closed when it finishes.
+func (q *refreshQueue) request(fetch bool) (sippyv1.RefreshJob, <-chan struct{}) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.pending == nil {
+		q.nextID++
+		q.pending = &refreshJob{
+			status: sippyv1.RefreshJob{
+				ID:          fmt.Sprintf("refresh-%d", q.nextID),
+				State:       sippyv1.RefreshJobPending,
+				RequestTime: time.Now(),
+			},
+			done: make(chan struct{}),
+		}
+		q.jobs[q.pending.status.ID] = q.pending
+	}
+	// if anyone wants fresh data from testgrid, everyone sharing the job gets it.
+	q.pending.status.Fetch = q.pending.status.Fetch || fetch
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return q.pending.status, q.pending.done
+}
+
+// get returns the job with the given ID, if it is pending, running, or finished recently enough to be retained.
+func (q *refreshQueue) get(id string) (sippyv1.RefreshJob, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return sippyv1.RefreshJob{}, false
+	}
+	return job.status, true
+}
+
+// runWorker runs queued refreshes until stop is closed.
+func (q *refreshQueue) runWorker(stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case <-q.wake:
+		}
+
+		for q.runPending() {
+		}
+	}
+}
+
+// runPending runs the pending job, if there is one, and returns whether it did.
+func (q *refreshQueue) runPending() bool {
+	q.lock.Lock()
+	job := q.pending
+	q.pending = nil
+	if job == nil {
+		q.lock.Unlock()
+		return false
+	}
+	start := time.Now()
+	job.status.State = sippyv1.RefreshJobRunning
+	job.status.StartTime = &start
+	fetch := job.status.Fetch
+	q.lock.Unlock()
+
+	err := q.run(fetch)
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	end := time.Now()
+	job.status.EndTime = &end
+	if err != nil {
+		job.status.State = sippyv1.RefreshJobFailed
+		job.status.Error = err.Error()
+	} else {
+		job.status.State = sippyv1.RefreshJobSucceeded
+	}
+	close(job.done)
+
+	q.finished = append(q.finished, job.status.ID)
+	for len(q.finished) > maxRetainedRefreshJobs {
+		delete(q.jobs, q.finished[0])
+		q.finished = q.finished[1:]
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package sippyserver
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+)
+
+func waitForState(t *testing.T, q *refreshQueue, id string, state sippyv1.RefreshJobState) {
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if job, _ := q.get(id); job.State == state {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s to be %s", id, state)
+}
+
+func TestRefreshQueueCoalesces(t *testing.T) {
+	release := make(chan struct{})
+	lock := sync.Mutex{}
+	runs := []bool{}
+	q := newRefreshQueue(func(fetch bool) error {
+		lock.Lock()
+		runs = append(runs, fetch)
+		lock.Unlock()
+		<-release
+		return nil
+	})
+	stop := make(chan struct{})
+	defer close(stop)
+	go q.runWorker(stop)
+
+	first, firstDone := q.request(false)
+	waitForState(t, q, first.ID, sippyv1.RefreshJobRunning)
+
+	// both of these arrive while the first refresh is running, so they share a single new refresh
+	second, _ := q.request(false)
+	third, thirdDone := q.request(true)
+	if second.ID == first.ID {
+		t.Errorf("expected a request made during a running refresh to get a new job")
+	}
+	if third.ID != second.ID {
+		t.Errorf("expected pending requests to be coalesced, got %s and %s", second.ID, third.ID)
+	}
+	if !third.Fetch {
+		t.Errorf("expected the coalesced job to fetch")
+	}
+
+	close(release)
+	<-firstDone
+	<-thirdDone
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(runs) != 2 || runs[0] || !runs[1] {
+		t.Errorf("expected one refresh without fetch then one with fetch, got %v", runs)
+	}
+	for _, id := range []string{first.ID, third.ID} {
+		job, found := q.get(id)
+		if !found || job.State != sippyv1.RefreshJobSucceeded || job.StartTime == nil || job.EndTime == nil {
+			t.Errorf("unexpected job %#v", job)
+		}
+	}
+}
+
+func TestRefreshQueueRecordsFailures(t *testing.T) {
+	q := newRefreshQueue(func(fetch bool) error {
+		return fmt.Errorf("no data")
+	})
+	stop := make(chan struct{})
+	defer close(stop)
+	go q.runWorker(stop)
+
+	job, done := q.request(false)
+	<-done
+	job, _ = q.get(job.ID)
+	if job.State != sippyv1.RefreshJobFailed || job.Error != "no data" {
+		t.Errorf("expected failure to be recorded, got %#v", job)
+	}
+
+	if _, found := q.get("refresh-unknown"); found {
+		t.Errorf("expected unknown job to not be found")
+	}
+}
@@ -0,0 +1,168 @@
+package sippyserver
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testgridhelpers"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testidentification"
+	"k8s.io/klog"
+)
+
+// RefreshConfig controls how the server periodically fetches testgrid data and rebuilds its reports.
+type RefreshConfig struct {
+	// Interval is the time between the end of one periodic refresh and the start of the next. Zero disables periodic refresh.
+	Interval time.Duration
+	// Jitter is the maximum random delay added to every Interval, so that several servers don't hit testgrid in lockstep.
+	Jitter time.Duration
+	// FetchOptions control how testgrid data is downloaded before each periodic refresh.  The StoragePath, JobFilter, and
+	// TestGridEndpoint are always taken from the TestGridLoadingConfig so the server fetches exactly what it loads.
+	FetchOptions testgridhelpers.FetchOptions
+	// IdentificationConfigFile, if set, is the test identification config that is reloaded before every refresh, so
+	// that tests can be curated without restarting the server.
+	IdentificationConfigFile string
+	// OwnershipConfig, if set, is the test ownership config in use.  A reloaded identification config is only used if
+	// the owners of the ownership config are still among its components.
+	OwnershipConfig *testidentification.OwnershipConfig
+}
+
+// clock is where the refresh scheduler gets the time from, so that tests can control it.
+type clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// refreshScheduler requests a refresh with a fetch every interval, plus up to jitter, until it is stopped.
+type refreshScheduler struct {
+	interval time.Duration
+	jitter   time.Duration
+	clock    clock
+	// randInt63n picks the random part of the delay, like rand.Int63n
+	randInt63n func(n int64) int64
+	queue      *refreshQueue
+	// scheduled is called with the time of every refresh as soon as it is scheduled
+	scheduled func(next time.Time)
+}
+
+// runPeriodicRefresh fetches new testgrid data and rebuilds the reports every interval until stop is closed.
+func (s *Server) runPeriodicRefresh(stop <-chan struct{}) {
+	scheduler := &refreshScheduler{
+		interval:   s.refreshConfig.Interval,
+		jitter:     s.refreshConfig.Jitter,
+		clock:      realClock{},
+		randInt63n: rand.Int63n,
+		queue:      s.refreshQueue,
+		scheduled: func(next time.Time) {
+			s.updateRefreshStatus(func(status *sippyv1.RefreshStatus) {
+				status.NextScheduledRefresh = &next
+			})
+			klog.Infof("Next refresh scheduled for %v", next)
+		},
+	}
+	scheduler.run(stop)
+}
+
+func (r *refreshScheduler) run(stop <-chan struct{}) {
+	for {
+		delay := r.nextDelay()
+		r.scheduled(r.clock.Now().Add(delay))
+
+		select {
+		case <-stop:
+			return
+		case <-r.clock.After(delay):
+		}
+
+		// wait for the refresh, so the next interval starts when this one ends
+		_, done := r.queue.request(true)
+		select {
+		case <-stop:
+			return
+		case <-done:
+		}
+	}
+}
+
+func (r *refreshScheduler) nextDelay() time.Duration {
+	if r.jitter <= 0 {
+		return r.interval
+	}
+	return r.interval + time.Duration(r.randInt63n(int64(r.jitter)))
+}
+
+// fetchData downloads the testgrid data for every dashboard into the LocalData directory.  A failed fetch is recorded,
+// but the reports are still rebuilt from whatever is on disk.  It must be called while holding the refreshLock.
+func (s *Server) fetchData() {
+	dashboards := []string{}
+	for _, dashboard := range s.dashboardCoordinates {
+		dashboards = append(dashboards, dashboard.TestGridDashboardNames...)
+	}
+
+	options := s.refreshConfig.FetchOptions
+	options.StoragePath = s.testReportGeneratorConfig.TestGridLoadingConfig.LocalData
+	options.JobFilter = s.testReportGeneratorConfig.TestGridLoadingConfig.JobFilter
+	options.TestGridEndpoint = s.testReportGeneratorConfig.TestGridLoadingConfig.TestGridEndpoint
+
+	klog.Infof("Fetching testgrid data into %s", options.StoragePath)
+	start := time.Now()
+	_, err := testgridhelpers.NewFetcher(options).Fetch(dashboards)
+	if err != nil {
+		klog.Errorf("Error fetching testgrid data: %v", err)
+	}
+
+	s.updateRefreshStatus(func(status *sippyv1.RefreshStatus) {
+		status.LastFetchAttempt = &start
+		if err != nil {
+			status.LastFetchError = err.Error()
+			return
+		}
+		status.LastSuccessfulFetch = &start
+		status.LastFetchError = ""
+	})
+}
+
+// recordRefresh records the outcome of a refresh started at start.
+func (s *Server) recordRefresh(start time.Time, err error) {
+	s.updateRefreshStatus(func(status *sippyv1.RefreshStatus) {
+		status.LastRefreshAttempt = &start
+		if err != nil {
+			status.LastRefreshError = err.Error()
+			return
+		}
+		status.LastSuccessfulRefresh = &start
+		status.LastRefreshError = ""
+	})
+}
+
+// recoverRefreshPanic turns a panic while building reports into an error, so a bad batch of data can't take down the server.
+func recoverRefreshPanic(err *error) {
+	if r := recover(); r != nil {
+		klog.Errorf("Refresh failed: %v", r)
+		*err = fmt.Errorf("refresh failed: %v", r)
+	}
+}
+
+func (s *Server) updateRefreshStatus(update func(status *sippyv1.RefreshStatus)) {
+	s.statusLock.Lock()
+	defer s.statusLock.Unlock()
+	update(&s.refreshStatus)
+}
+
+// RefreshStatus returns when the server last refreshed its reports and fetched testgrid data.
+func (s *Server) RefreshStatus() sippyv1.RefreshStatus {
+	s.statusLock.RLock()
+	defer s.statusLock.RUnlock()
+	return s.refreshStatus
+}
@@ -0,0 +1,201 @@
+package sippyserver
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock only moves when the test advances it.  Every call to After is handed to the test through waits, which
+// fires it when it chooses to.
+type fakeClock struct {
+	lock  sync.Mutex
+	now   time.Time
+	waits chan fakeWait
+}
+
+type fakeWait struct {
+	delay time.Duration
+	fire  chan time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now, waits: make(chan fakeWait, 10)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	fire := make(chan time.Time, 1)
+	c.waits <- fakeWait{delay: d, fire: fire}
+	return fire
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// elapse advances the clock by the delay of the wait and fires it.
+func (c *fakeClock) elapse(wait fakeWait) {
+	c.advance(wait.delay)
+	wait.fire <- c.Now()
+}
+
+func (c *fakeClock) nextWait(t *testing.T) fakeWait {
+	select {
+	case wait := <-c.waits:
+		return wait
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timed out waiting for the scheduler to wait")
+		return fakeWait{}
+	}
+}
+
+func receiveTime(t *testing.T, c <-chan time.Time) time.Time {
+	select {
+	case received := <-c:
+		return received
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timed out waiting for a refresh to be scheduled")
+		return time.Time{}
+	}
+}
+
+func newTestScheduler(clock *fakeClock, queue *refreshQueue, interval, jitter time.Duration) (*refreshScheduler, chan time.Time) {
+	scheduled := make(chan time.Time, 10)
+	return &refreshScheduler{
+		interval: interval,
+		jitter:   jitter,
+		clock:    clock,
+		randInt63n: func(n int64) int64 {
+			return n / 2
+		},
+		queue: queue,
+		scheduled: func(next time.Time) {
+			scheduled <- next
+		},
+	}, scheduled
+}
+
+func TestRefreshSchedulerInterval(t *testing.T) {
+	start := time.Date(2021, 1, 12, 8, 0, 0, 0, time.UTC)
+	clock := newFakeClock(start)
+
+	lock := sync.Mutex{}
+	runs := []bool{}
+	waitsDuringRun := 0
+	q := newRefreshQueue(func(fetch bool) error {
+		lock.Lock()
+		defer lock.Unlock()
+		runs = append(runs, fetch)
+		waitsDuringRun += len(clock.waits)
+		// the refresh takes ten minutes
+		clock.advance(10 * time.Minute)
+		return nil
+	})
+	stop := make(chan struct{})
+	defer close(stop)
+	go q.runWorker(stop)
+
+	scheduler, scheduled := newTestScheduler(clock, q, time.Hour, 0)
+	go scheduler.run(stop)
+
+	wait := clock.nextWait(t)
+	if wait.delay != time.Hour {
+		t.Errorf("expected to wait the interval, got %v", wait.delay)
+	}
+	if next := receiveTime(t, scheduled); !next.Equal(start.Add(time.Hour)) {
+		t.Errorf("expected the first refresh to be scheduled an interval from now, got %v", next)
+	}
+
+	clock.elapse(wait)
+	wait = clock.nextWait(t)
+	if wait.delay != time.Hour {
+		t.Errorf("expected to wait the interval again, got %v", wait.delay)
+	}
+	// the next interval starts when the refresh ends
+	if next := receiveTime(t, scheduled); !next.Equal(start.Add(2*time.Hour + 10*time.Minute)) {
+		t.Errorf("expected the second refresh to be scheduled an interval after the first one ended, got %v", next)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(runs) != 1 || !runs[0] {
+		t.Errorf("expected one refresh with a fetch, got %v", runs)
+	}
+	if waitsDuringRun != 0 {
+		t.Errorf("expected the scheduler to wait for the refresh before starting the next interval")
+	}
+}
+
+func TestRefreshSchedulerJitter(t *testing.T) {
+	clock := newFakeClock(time.Date(2021, 1, 12, 8, 0, 0, 0, time.UTC))
+	scheduler, _ := newTestScheduler(clock, nil, time.Hour, 30*time.Minute)
+	requested := []int64{}
+	scheduler.randInt63n = func(n int64) int64 {
+		requested = append(requested, n)
+		return int64(5 * time.Minute)
+	}
+
+	if delay := scheduler.nextDelay(); delay != 65*time.Minute {
+		t.Errorf("expected the interval plus the random jitter, got %v", delay)
+	}
+	if len(requested) != 1 || requested[0] != int64(30*time.Minute) {
+		t.Errorf("expected the jitter to be picked up to the maximum jitter, got %v", requested)
+	}
+
+	scheduler.jitter = 0
+	if delay := scheduler.nextDelay(); delay != time.Hour || len(requested) != 1 {
+		t.Errorf("expected no jitter to be picked without a maximum jitter, got %v", delay)
+	}
+}
+
+func TestRefreshSchedulerJoinsPendingRefresh(t *testing.T) {
+	clock := newFakeClock(time.Date(2021, 1, 12, 8, 0, 0, 0, time.UTC))
+
+	lock := sync.Mutex{}
+	runs := []bool{}
+	q := newRefreshQueue(func(fetch bool) error {
+		lock.Lock()
+		defer lock.Unlock()
+		runs = append(runs, fetch)
+		return nil
+	})
+	stop := make(chan struct{})
+	defer close(stop)
+
+	scheduler, scheduled := newTestScheduler(clock, q, time.Hour, 0)
+	go scheduler.run(stop)
+	receiveTime(t, scheduled)
+
+	// the worker is not running yet, so this refresh is still pending when the scheduled one is due
+	manual, _ := q.request(false)
+	clock.elapse(clock.nextWait(t))
+	waitForJobFetch(t, q, manual.ID)
+
+	go q.runWorker(stop)
+	clock.nextWait(t)
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(runs) != 1 || !runs[0] {
+		t.Errorf("expected the scheduled refresh to join the pending one and fetch, got %v", runs)
+	}
+}
+
+func waitForJobFetch(t *testing.T, q *refreshQueue, id string) {
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if job, _ := q.get(id); job.Fetch {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s to fetch", id)
+}
@@ -6,14 +6,23 @@ import (
 	"net/http"
 	"regexp"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/openshift/sippy/pkg/alerting"
 	"github.com/openshift/sippy/pkg/api"
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
 	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
 	"github.com/openshift/sippy/pkg/buganalysis"
 	"github.com/openshift/sippy/pkg/html/releasehtml"
+	"github.com/openshift/sippy/pkg/metrics"
 	"github.com/openshift/sippy/pkg/testgridanalysis/testgridconversion"
 	"github.com/openshift/sippy/pkg/testgridanalysis/testgridhelpers"
 	"github.com/openshift/sippy/pkg/testgridanalysis/testidentification"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testreportconversion"
+	"github.com/openshift/sippy/pkg/util/sets"
 	"k8s.io/klog"
 )
 
@@ -26,6 +35,9 @@ func NewServer(
 	syntheticTestManager testgridconversion.SythenticTestManager,
 	variantManager testidentification.VariantManager,
 	bugCache buganalysis.BugCache,
+	refreshConfig RefreshConfig,
+	snapshotStore *SnapshotStore,
+	alertManager *alerting.Manager,
 ) *Server {
 
 	server := &Server{
@@ -40,8 +52,17 @@ func NewServer(
 			RawJobResultsAnalysisConfig: rawJobResultsAnalysisOptions,
 			DisplayDataConfig:           displayDataOptions,
 		},
-		currTestReports: map[string]StandardReport{},
+		refreshConfig: refreshConfig,
+		snapshotStore: snapshotStore,
+		alertManager:  alertManager,
+		// at most one evaluation is queued, see queueAlertEvaluation
+		alertEvaluations: make(chan time.Time, 1),
+
+		requestDuration: newRequestDurationHistogram(),
+		refreshDuration: newRefreshDurationHistogram(),
 	}
+	server.currTestReports.Store(map[string]StandardReport{})
+	server.refreshQueue = newRefreshQueue(server.runRefresh)
 
 	return server
 }
@@ -54,7 +75,28 @@ type Server struct {
 	variantManager            testidentification.VariantManager
 	bugCache                  buganalysis.BugCache
 	testReportGeneratorConfig TestReportGeneratorConfig
-	currTestReports           map[string]StandardReport
+	refreshConfig             RefreshConfig
+	// snapshotStore keeps a copy of every report the server computes.  It is nil if snapshots are disabled.
+	snapshotStore *SnapshotStore
+	// alertManager evaluates the alerting rules after every refresh.  It is nil if alerting is disabled.
+	alertManager *alerting.Manager
+	// alertEvaluations holds the start of the last refresh whose alerts are not evaluated yet.  Only runRefresh sends to
+	// it, under refreshLock.
+	alertEvaluations chan time.Time
+
+	// refreshLock serializes refreshes, whether they are requested, scheduled, or done at startup
+	refreshLock  sync.Mutex
+	refreshQueue *refreshQueue
+	// currTestReports holds a map[string]StandardReport.  The map is never mutated, a refresh builds a new one and
+	// stores it, so a reader that loads it once sees a consistent set of reports.
+	currTestReports atomic.Value
+
+	statusLock    sync.RWMutex
+	refreshStatus sippyv1.RefreshStatus
+
+	// requestDuration and refreshDuration are exposed on /metrics
+	requestDuration *metrics.HistogramVec
+	refreshDuration *metrics.HistogramVec
 }
 
 type TestGridDashboardCoordinates struct {
@@ -64,65 +106,293 @@ type TestGridDashboardCoordinates struct {
 	TestGridDashboardNames []string
 	// this is openshift specific, used for BZ lookup and not required
 	BugzillaRelease string
+	// VariantManager identifies the variants of the jobs on these dashboards.  If nil, the server's variant manager is
+	// used.  This lets a single server host dashboards with different variant schemes.
+	VariantManager testidentification.VariantManager
 }
 
 type StandardReport struct {
-	CurrentPeriodReport sippyprocessingv1.TestReport
-	CurrentTwoDayReport sippyprocessingv1.TestReport
-	PreviousWeekReport  sippyprocessingv1.TestReport
+	CurrentPeriodReport sippyprocessingv1.TestReport `json:"currentPeriodReport"`
+	CurrentTwoDayReport sippyprocessingv1.TestReport `json:"currentTwoDayReport"`
+	PreviousWeekReport  sippyprocessingv1.TestReport `json:"previousWeekReport"`
+
+	// jobRuns are the runs of the jobs in the testgrid data the reports were built from, kept so that the job run
+	// endpoints do not process the data on every request.  Snapshots do not store them, so they are nil in snapshots.
+	jobRuns *api.JobRunResults
 }
 
+// refresh queues a rebuild of the reports from the data on disk and returns the job without waiting for it.  The job
+// can be polled with refreshJobStatus.
 func (s *Server) refresh(w http.ResponseWriter, req *http.Request) {
-	s.RefreshData()
+	job, _ := s.refreshQueue.request(false)
 
-	w.Header().Set("Content-Type", "text/html;charset=UTF-8")
-	w.WriteHeader(http.StatusOK)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(job); err != nil {
+		klog.Errorf("unable to write refresh job: %v", err)
+	}
 }
 
+func (s *Server) refreshJobStatus(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	id := req.URL.Query().Get("id")
+	job, found := s.refreshQueue.get(id)
+	if !found {
+		api.PrintError(w, http.StatusNotFound, fmt.Sprintf("No refresh job with id %q", id))
+		return
+	}
+	if err := json.NewEncoder(w).Encode(job); err != nil {
+		klog.Errorf("unable to write refresh job: %v", err)
+	}
+}
+
+// RefreshData rebuilds every report from the data on disk and swaps them in once they are all built.  It waits for any
+// refresh already in progress.
 func (s *Server) RefreshData() {
+	s.runRefresh(false)
+}
+
+// runRefresh optionally fetches testgrid data, then rebuilds the reports.  Alerts are evaluated in the background by
+// runAlertEvaluations, because a slow notifier must not hold up the next refresh.
+func (s *Server) runRefresh(fetch bool) error {
+	s.refreshLock.Lock()
+	defer s.refreshLock.Unlock()
+
+	if fetch {
+		s.fetchData()
+	}
+
 	klog.Infof("Refreshing data")
-	s.bugCache.Clear()
+	start := time.Now()
+	err := s.buildAndSwapReports()
+	s.recordRefresh(start, err)
+	s.recordRefreshDuration(start, err)
+	klog.Infof("Refresh complete")
+	if err == nil {
+		s.saveSnapshots(start)
+		s.queueAlertEvaluation(start)
+	}
+	return err
+}
+
+// saveSnapshots stores the current reports.  Failing to store a snapshot doesn't fail the refresh, the live reports are fine.
+func (s *Server) saveSnapshots(timestamp time.Time) {
+	if s.snapshotStore == nil {
+		return
+	}
+	for reportName, report := range s.testReports() {
+		snapshot, err := s.snapshotStore.Save(reportName, timestamp, report)
+		if err != nil {
+			klog.Errorf("Error saving snapshot of %s: %v", reportName, err)
+			continue
+		}
+		klog.V(2).Infof("Saved snapshot %s of %s", snapshot.ID, reportName)
+	}
+}
+
+func (s *Server) variantManagerFor(dashboard TestGridDashboardCoordinates) testidentification.VariantManager {
+	if dashboard.VariantManager != nil {
+		return dashboard.VariantManager
+	}
+	return s.variantManager
+}
+
+func (s *Server) buildAndSwapReports() (err error) {
+	defer recoverRefreshPanic(&err)
+
+	if err := s.reloadIdentificationConfig(); err != nil {
+		return err
+	}
+	newTestReports := map[string]StandardReport{}
 	for _, dashboard := range s.dashboardCoordinates {
-		s.currTestReports[dashboard.ReportName] = s.testReportGeneratorConfig.PrepareStandardTestReports(dashboard, s.syntheticTestManager, s.variantManager, s.bugCache)
+		testGridJobDetails, lastUpdateTime := s.testReportGeneratorConfig.loadTestGridData(dashboard)
+		report := s.testReportGeneratorConfig.prepareStandardTestReportsFromData(dashboard, s.syntheticTestManager, s.variantManagerFor(dashboard), s.bugCache, testGridJobDetails, lastUpdateTime)
+		jobRuns := api.NewJobRunResults(s.syntheticTestManager, s.testReportGeneratorConfig.TestGridLoadingConfig.ProwURL, testGridJobDetails)
+		report.jobRuns = &jobRuns
+		newTestReports[dashboard.ReportName] = report
+	}
+
+	s.currTestReports.Store(newTestReports)
+	return nil
+}
+
+// reloadIdentificationConfig reads the test identification config again.  If it is invalid, or no longer has the
+// components of the test owners, the refresh fails and the config in use is kept.
+func (s *Server) reloadIdentificationConfig() error {
+	if len(s.refreshConfig.IdentificationConfigFile) == 0 {
+		return nil
+	}
+	config, err := testidentification.LoadIdentificationConfig(s.refreshConfig.IdentificationConfigFile)
+	if err != nil {
+		klog.Errorf("Keeping the test identification config in use: %v", err)
+		return err
+	}
+	if err := config.Validate(); err != nil {
+		klog.Errorf("Keeping the test identification config in use: %v", err)
+		return err
+	}
+	// the owners are checked against the new config before it is used, so no request ever sees a config without them
+	if s.refreshConfig.OwnershipConfig != nil {
+		if err := testidentification.ValidateOwnershipConfig(*s.refreshConfig.OwnershipConfig, config); err != nil {
+			klog.Errorf("Keeping the test identification config in use, the test ownership config does not match it: %v", err)
+			return fmt.Errorf("test ownership config: %v", err)
+		}
+	}
+	return testidentification.SetIdentificationConfig(config)
+}
+
+func (s *Server) printIdentificationConfig(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(testidentification.CurrentIdentificationConfig()); err != nil {
+		klog.Errorf("unable to write test identification config: %v", err)
+	}
+}
+
+// testReports returns the current reports.  Handlers should call it once per request so that every report they use comes
+// from the same refresh.  The returned map must not be modified.
+func (s *Server) testReports() map[string]StandardReport {
+	return s.currTestReports.Load().(map[string]StandardReport)
+}
+
+// testReportsForRequest returns the current reports, or if ?snapshot= is set, the stored snapshot of the ?release= report.
+// The snapshot may be named by ID or by a YYYY-MM-DD date.  If ?team= is set, the reports only list the tests owned by
+// the team.
+func (s *Server) testReportsForRequest(req *http.Request) (map[string]StandardReport, error) {
+	reports, err := s.unfilteredTestReportsForRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// ?team= limits every table to the tests owned by the team
+	team := req.URL.Query().Get("team")
+	if len(team) == 0 {
+		return reports, nil
+	}
+	filtered := map[string]StandardReport{}
+	for reportName, report := range reports {
+		filtered[reportName] = filterStandardReportByTeam(report, team)
+	}
+	return filtered, nil
+}
+
+// filterStandardReportByTeam limits every period of the report to the tests owned by the team.
+func filterStandardReportByTeam(report StandardReport, team string) StandardReport {
+	filterFn := testreportconversion.FilterTestResultsByTeam(team)
+	return StandardReport{
+		CurrentPeriodReport: testreportconversion.FilterTestReportTests(report.CurrentPeriodReport, filterFn),
+		CurrentTwoDayReport: testreportconversion.FilterTestReportTests(report.CurrentTwoDayReport, filterFn),
+		PreviousWeekReport:  testreportconversion.FilterTestReportTests(report.PreviousWeekReport, filterFn),
+		jobRuns:             report.jobRuns,
+	}
+}
+
+func (s *Server) unfilteredTestReportsForRequest(req *http.Request) (map[string]StandardReport, error) {
+	snapshotRef := req.URL.Query().Get("snapshot")
+	if len(snapshotRef) == 0 {
+		return s.testReports(), nil
+	}
+	if s.snapshotStore == nil {
+		return nil, fmt.Errorf("snapshots are not enabled on this server")
+	}
+
+	reportName := req.URL.Query().Get("release")
+	if _, found := s.reportNameToDashboardCoordinates(reportName); !found {
+		return nil, fmt.Errorf("no snapshots of unknown release %q", reportName)
+	}
+	snapshot, err := s.snapshotStore.Resolve(reportName, snapshotRef)
+	if err != nil {
+		return nil, err
+	}
+	report, err := s.snapshotStore.Load(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	warning := fmt.Sprintf("This is snapshot %s of the report as it was computed at %s, not the live report.",
+		snapshot.ID, snapshot.Timestamp.Format("Jan 2 15:04 2006 MST"))
+	report.CurrentPeriodReport.AnalysisWarnings = append([]string{warning}, report.CurrentPeriodReport.AnalysisWarnings...)
+
+	return map[string]StandardReport{reportName: report}, nil
+}
+
+func (s *Server) printSnapshots(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if s.snapshotStore == nil {
+		api.PrintError(w, http.StatusNotFound, "snapshots are not enabled on this server")
+		return
+	}
+
+	reportNames := []string{req.URL.Query().Get("release")}
+	if len(reportNames[0]) == 0 {
+		var err error
+		if reportNames, err = s.snapshotStore.Releases(); err != nil {
+			api.PrintError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	} else if _, found := s.reportNameToDashboardCoordinates(reportNames[0]); !found {
+		api.PrintError(w, http.StatusNotFound, fmt.Sprintf("No valid release specified, valid releases are: %v", s.reportNames()))
+		return
+	}
+
+	snapshots := map[string][]sippyv1.Snapshot{}
+	for _, reportName := range reportNames {
+		releaseSnapshots, err := s.snapshotStore.List(reportName)
+		if err != nil {
+			api.PrintError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		snapshots[reportName] = releaseSnapshots
+	}
+	if err := json.NewEncoder(w).Encode(snapshots); err != nil {
+		klog.Errorf("unable to write snapshots: %v", err)
 	}
-	klog.Infof("Refresh complete")
 }
 
 func (s *Server) printHtmlReport(w http.ResponseWriter, req *http.Request) {
+	currTestReports, err := s.testReportsForRequest(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
 	reportName := req.URL.Query().Get("release")
 	dashboard, found := s.reportNameToDashboardCoordinates(reportName)
 	if !found {
-		releasehtml.WriteLandingPage(w, s.reportNames())
+		releasehtml.WriteLandingPage(w, s.reportNames(), s.RefreshStatus())
 		return
 	}
-	if _, hasReport := s.currTestReports[dashboard.ReportName]; !hasReport {
-		releasehtml.WriteLandingPage(w, s.reportNames())
+	if _, hasReport := currTestReports[dashboard.ReportName]; !hasReport {
+		releasehtml.WriteLandingPage(w, s.reportNames(), s.RefreshStatus())
 		return
 	}
 
 	releasehtml.PrintHtmlReport(w, req,
-		s.currTestReports[dashboard.ReportName].CurrentPeriodReport,
-		s.currTestReports[dashboard.ReportName].CurrentTwoDayReport,
-		s.currTestReports[dashboard.ReportName].PreviousWeekReport,
+		currTestReports[dashboard.ReportName].CurrentPeriodReport,
+		currTestReports[dashboard.ReportName].CurrentTwoDayReport,
+		currTestReports[dashboard.ReportName].PreviousWeekReport,
 		s.testReportGeneratorConfig.RawJobResultsAnalysisConfig.NumDays,
 		15,
-		s.reportNames())
+		s.reportNames(),
+		s.testReportGeneratorConfig.DisplayDataConfig.CISearchURL)
 }
 
 func (s *Server) printCanaryReport(w http.ResponseWriter, req *http.Request) {
+	currTestReports, err := s.testReportsForRequest(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
 	reportName := req.URL.Query().Get("release")
 	dashboard, found := s.reportNameToDashboardCoordinates(reportName)
 	if !found {
-		releasehtml.WriteLandingPage(w, s.reportNames())
+		releasehtml.WriteLandingPage(w, s.reportNames(), s.RefreshStatus())
 		return
 	}
-	if _, hasReport := s.currTestReports[dashboard.ReportName]; !hasReport {
-		releasehtml.WriteLandingPage(w, s.reportNames())
+	if _, hasReport := currTestReports[dashboard.ReportName]; !hasReport {
+		releasehtml.WriteLandingPage(w, s.reportNames(), s.RefreshStatus())
 		return
 	}
 
 	w.Header().Set("Content-Type", "text/plain;charset=UTF-8")
-	testReport := s.currTestReports[dashboard.ReportName].CurrentPeriodReport
+	testReport := currTestReports[dashboard.ReportName].CurrentPeriodReport
 	for i := len(testReport.ByTest) - 1; i >= 0; i-- {
 		t := testReport.ByTest[i]
 		if t.TestResultAcrossAllJobs.PassPercentage > 99 {
@@ -133,6 +403,13 @@ func (s *Server) printCanaryReport(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+func (s *Server) printRefreshStatus(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(s.RefreshStatus()); err != nil {
+		klog.Errorf("unable to write refresh status: %v", err)
+	}
+}
+
 func (s *Server) reportNameToDashboardCoordinates(reportName string) (TestGridDashboardCoordinates, bool) {
 	for _, dashboard := range s.dashboardCoordinates {
 		if dashboard.ReportName == reportName {
@@ -150,7 +427,19 @@ func (s *Server) reportNames() []string {
 	return ret
 }
 
+// printJSONReport serves the summary of the ?release=, or of every release for ?release=all.  The filters of
+// api.ParseFilterOptions, like ?name= and ?minRuns=, limit the tests, jobs, and variants it summarizes.
 func (s *Server) printJSONReport(w http.ResponseWriter, req *http.Request) {
+	currTestReports, err := s.testReportsForRequest(req)
+	if err != nil {
+		api.PrintError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	filters, err := api.ParseFilterOptions(req.URL.Query())
+	if err != nil {
+		api.PrintError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	reportName := req.URL.Query().Get("release")
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	releaseReports := make(map[string][]sippyprocessingv1.TestReport)
@@ -158,28 +447,22 @@ func (s *Server) printJSONReport(w http.ResponseWriter, req *http.Request) {
 		// return all available json reports
 		// store [currentReport, prevReport] in a slice
 		for _, reportName := range s.reportNames() {
-			if _, ok := s.currTestReports[reportName]; ok {
-				releaseReports[reportName] = []sippyprocessingv1.TestReport{s.currTestReports[reportName].CurrentPeriodReport, s.currTestReports[reportName].PreviousWeekReport}
+			if _, ok := currTestReports[reportName]; ok {
+				releaseReports[reportName] = []sippyprocessingv1.TestReport{api.FilterTestReport(currTestReports[reportName].CurrentPeriodReport, filters), currTestReports[reportName].PreviousWeekReport}
 			} else {
 				klog.Errorf("unable to load test report for reportName version %s", reportName)
 				continue
 			}
 		}
-		api.PrintJSONReport(w, req, releaseReports, s.testReportGeneratorConfig.RawJobResultsAnalysisConfig.NumDays, 15)
+		api.PrintJSONReport(w, req, releaseReports, s.testReportGeneratorConfig.DisplayDataConfig.CISearchURL)
 		return
-	} else if _, ok := s.currTestReports[reportName]; !ok {
+	} else if _, ok := currTestReports[reportName]; !ok {
 		// return a 404 error along with the list of available openshiftReleases in the detail section
-		errMsg := map[string]interface{}{
-			"code":   "404",
-			"detail": fmt.Sprintf("No valid reportName specified, valid reportNames are: %v", s.reportNames()),
-		}
-		errMsgBytes, _ := json.Marshal(errMsg)
-		w.WriteHeader(http.StatusNotFound)
-		w.Write(errMsgBytes)
+		api.PrintError(w, http.StatusNotFound, fmt.Sprintf("No valid reportName specified, valid reportNames are: %v", s.reportNames()))
 		return
 	}
-	releaseReports[reportName] = []sippyprocessingv1.TestReport{s.currTestReports[reportName].CurrentPeriodReport, s.currTestReports[reportName].PreviousWeekReport}
-	api.PrintJSONReport(w, req, releaseReports, s.testReportGeneratorConfig.RawJobResultsAnalysisConfig.NumDays, 15)
+	releaseReports[reportName] = []sippyprocessingv1.TestReport{api.FilterTestReport(currTestReports[reportName].CurrentPeriodReport, filters), currTestReports[reportName].PreviousWeekReport}
+	api.PrintJSONReport(w, req, releaseReports, s.testReportGeneratorConfig.DisplayDataConfig.CISearchURL)
 }
 
 func (s *Server) detailed(w http.ResponseWriter, req *http.Request) {
@@ -250,8 +533,10 @@ func (s *Server) detailed(w http.ResponseWriter, req *http.Request) {
 
 	testReportConfig := TestReportGeneratorConfig{
 		TestGridLoadingConfig: TestGridLoadingConfig{
-			LocalData: s.testReportGeneratorConfig.TestGridLoadingConfig.LocalData,
-			JobFilter: jobFilter,
+			LocalData:        s.testReportGeneratorConfig.TestGridLoadingConfig.LocalData,
+			JobFilter:        jobFilter,
+			TestGridEndpoint: s.testReportGeneratorConfig.TestGridLoadingConfig.TestGridEndpoint,
+			ProwURL:          s.testReportGeneratorConfig.TestGridLoadingConfig.ProwURL,
 		},
 		RawJobResultsAnalysisConfig: RawJobResultsAnalysisConfig{
 			StartDay: startDay,
@@ -261,20 +546,25 @@ func (s *Server) detailed(w http.ResponseWriter, req *http.Request) {
 			MinTestRuns:             minTestRuns,
 			TestSuccessThreshold:    testSuccessThreshold,
 			FailureClusterThreshold: failureClusterThreshold,
+			TestOwnership:           s.testReportGeneratorConfig.DisplayDataConfig.TestOwnership,
+			CISearchURL:             s.testReportGeneratorConfig.DisplayDataConfig.CISearchURL,
 		},
 	}
 	dashboardCoordinates, found := s.reportNameToDashboardCoordinates(reportName)
 	if !found {
-		releasehtml.WriteLandingPage(w, reportNames)
+		releasehtml.WriteLandingPage(w, reportNames, s.RefreshStatus())
 		return
 	}
-	testReports := testReportConfig.PrepareStandardTestReports(dashboardCoordinates, s.syntheticTestManager, s.variantManager, s.bugCache)
+	testReports := testReportConfig.PrepareStandardTestReports(dashboardCoordinates, s.syntheticTestManager, s.variantManagerFor(dashboardCoordinates), s.bugCache)
+	if team := req.URL.Query().Get("team"); len(team) > 0 {
+		testReports = filterStandardReportByTeam(testReports, team)
+	}
 
 	releasehtml.PrintHtmlReport(w, req,
 		testReports.CurrentPeriodReport,
 		testReports.CurrentTwoDayReport,
 		testReports.PreviousWeekReport,
-		numDays, jobTestCount, reportNames)
+		numDays, jobTestCount, reportNames, s.testReportGeneratorConfig.DisplayDataConfig.CISearchURL)
 
 }
 
@@ -287,20 +577,58 @@ func (s *Server) jobs(w http.ResponseWriter, req *http.Request) {
 		var err error
 		jobFilter, err = regexp.Compile(jobFilterString)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("jobFilter: %s", err), http.StatusBadRequest)
+			api.PrintError(w, http.StatusBadRequest, fmt.Sprintf("jobFilter: %s", err))
 			return
 		}
 	}
 
 	dashboardCoordinates, found := s.reportNameToDashboardCoordinates(reportName)
 	if !found {
-		http.Error(w, fmt.Sprintf("release %s not found", reportName), http.StatusBadRequest)
+		api.PrintError(w, http.StatusBadRequest, fmt.Sprintf("release %s not found", reportName))
+		return
+	}
+
+	testGridJobDetails, lastUpdateTime := testgridhelpers.LoadTestGridDataFromDisk(s.testReportGeneratorConfig.TestGridLoadingConfig.LocalData, dashboardCoordinates.TestGridDashboardNames, jobFilter, s.testReportGeneratorConfig.TestGridLoadingConfig.TestGridEndpoint)
+
+	api.PrintJobsReport(w, s.syntheticTestManager, s.testReportGeneratorConfig.TestGridLoadingConfig.ProwURL, testGridJobDetails, lastUpdateTime)
+}
+
+// testHistory serves the day by day results of one or more ?test= over all the data on disk for the ?release=.
+// ?by=job, ?by=variant, or ?by=job,variant adds the same history broken down by job and by variant.
+func (s *Server) testHistory(w http.ResponseWriter, req *http.Request) {
+	reportName := req.URL.Query().Get("release")
+	dashboardCoordinates, found := s.reportNameToDashboardCoordinates(reportName)
+	if !found {
+		api.PrintError(w, http.StatusBadRequest, fmt.Sprintf("release %s not found", reportName))
+		return
+	}
+
+	testNames := sets.NewString(req.URL.Query()["test"]...).List()
+	if len(testNames) == 0 {
+		api.PrintError(w, http.StatusBadRequest, "at least one test is required")
 		return
 	}
+	options := testgridconversion.TestHistoryOptions{
+		TestNames:      testNames,
+		VariantManager: s.variantManagerFor(dashboardCoordinates),
+	}
+	for _, by := range req.URL.Query()["by"] {
+		for _, breakdown := range strings.Split(by, ",") {
+			switch breakdown {
+			case "job":
+				options.ByJob = true
+			case "variant":
+				options.ByVariant = true
+			default:
+				api.PrintError(w, http.StatusBadRequest, fmt.Sprintf("by: %q is not one of job or variant", breakdown))
+				return
+			}
+		}
+	}
 
-	testGridJobDetails, lastUpdateTime := testgridhelpers.LoadTestGridDataFromDisk(s.testReportGeneratorConfig.TestGridLoadingConfig.LocalData, dashboardCoordinates.TestGridDashboardNames, jobFilter)
+	testGridJobDetails, lastUpdateTime := testgridhelpers.LoadTestGridDataFromDisk(s.testReportGeneratorConfig.TestGridLoadingConfig.LocalData, dashboardCoordinates.TestGridDashboardNames, s.testReportGeneratorConfig.TestGridLoadingConfig.JobFilter, s.testReportGeneratorConfig.TestGridLoadingConfig.TestGridEndpoint)
 
-	api.PrintJobsReport(w, s.syntheticTestManager, testGridJobDetails, lastUpdateTime)
+	api.PrintTestHistoryReport(w, reportName, options, testGridJobDetails, lastUpdateTime)
 }
 
 func (s *Server) jobsReport(w http.ResponseWriter, req *http.Request) {
@@ -317,13 +645,32 @@ func (s *Server) Serve() {
 	http.DefaultServeMux.HandleFunc("/json", s.printJSONReport)
 	http.DefaultServeMux.HandleFunc("/detailed", s.detailed)
 	http.DefaultServeMux.HandleFunc("/refresh", s.refresh)
+	http.DefaultServeMux.HandleFunc("/api/refresh", s.refreshJobStatus)
+	http.DefaultServeMux.HandleFunc("/api/status", s.printRefreshStatus)
+	http.DefaultServeMux.HandleFunc("/api/config", s.printIdentificationConfig)
+	http.DefaultServeMux.HandleFunc("/api/snapshots", s.printSnapshots)
+	http.DefaultServeMux.HandleFunc("/api/alerts", s.printAlerts)
+	http.DefaultServeMux.HandleFunc("/metrics", s.printMetrics)
 	http.DefaultServeMux.HandleFunc("/canary", s.printCanaryReport)
 	http.DefaultServeMux.HandleFunc("/api/jobs", s.jobs)
+	http.DefaultServeMux.HandleFunc("/api/tests/history", s.testHistory)
 	http.DefaultServeMux.HandleFunc("/jobs", s.jobsReport)
+	http.DefaultServeMux.HandleFunc("/variants/matrix", s.printVariantMatrixHtmlReport)
+	http.DefaultServeMux.HandleFunc("/api/variants/matrix", s.printVariantMatrix)
+	http.DefaultServeMux.HandleFunc("/component", s.printComponentHtmlReport)
+	http.DefaultServeMux.HandleFunc("/api/component", s.printComponentReport)
+	http.DefaultServeMux.HandleFunc("/jobrun", s.printJobRunHtmlReport)
+	http.DefaultServeMux.HandleFunc("/api/jobrun", s.printJobRunReport)
+	s.registerAPIV1(http.DefaultServeMux)
 	http.DefaultServeMux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("./static"))))
+	go s.refreshQueue.runWorker(nil)
+	go s.runAlertEvaluations(nil)
+	if s.refreshConfig.Interval > 0 {
+		go s.runPeriodicRefresh(nil)
+	}
 	//go func() {
 	klog.Infof("Serving reports on %s ", s.listenAddr)
-	if err := http.ListenAndServe(s.listenAddr, nil); err != nil {
+	if err := http.ListenAndServe(s.listenAddr, s.instrument(http.DefaultServeMux)); err != nil {
 		klog.Exitf("Server exited: %v", err)
 	}
 	//}()
@@ -0,0 +1,127 @@
+package sippyserver
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openshift/sippy/pkg/alerting"
+	"github.com/openshift/sippy/pkg/api"
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	testgridv1 "github.com/openshift/sippy/pkg/apis/testgrid/v1"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testgridconversion"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testidentification"
+)
+
+func TestReloadIdentificationConfigKeepsOwnersValid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "identification")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer testidentification.SetIdentificationConfig(testidentification.DefaultIdentificationConfig())
+
+	writeConfig := func(config testidentification.IdentificationConfig) string {
+		filename := filepath.Join(dir, "identification.json")
+		content, err := json.Marshal(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filename, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+
+	withStorage := testidentification.DefaultIdentificationConfig()
+	withStorage.BugzillaComponents = []string{"Unknown", "Storage"}
+	withStorage.OperatorComponents = map[string]string{}
+	withStorage.SigComponents = map[string]string{}
+	if err := testidentification.SetIdentificationConfig(withStorage); err != nil {
+		t.Fatal(err)
+	}
+
+	withoutStorage := withStorage
+	withoutStorage.BugzillaComponents = []string{"Unknown"}
+	s := &Server{
+		refreshConfig: RefreshConfig{
+			IdentificationConfigFile: writeConfig(withoutStorage),
+			OwnershipConfig: &testidentification.OwnershipConfig{
+				Tests: map[string]testidentification.Ownership{"test": {Component: "Storage"}},
+			},
+		},
+	}
+	if err := s.reloadIdentificationConfig(); err == nil {
+		t.Errorf("expected the reload to fail when an owner is no longer a component")
+	}
+	if !testidentification.IsValidBugzillaComponent("Storage") {
+		t.Errorf("expected the identification config in use to be kept")
+	}
+
+	s.refreshConfig.OwnershipConfig = nil
+	if err := s.reloadIdentificationConfig(); err != nil {
+		t.Errorf("expected the reload to succeed without an ownership config: %v", err)
+	}
+	if testidentification.IsValidBugzillaComponent("Storage") {
+		t.Errorf("expected the reloaded identification config to be in use")
+	}
+}
+
+func TestQueueAlertEvaluationKeepsTheLatest(t *testing.T) {
+	alertManager, err := alerting.NewManager(alerting.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{alertManager: alertManager, alertEvaluations: make(chan time.Time, 1)}
+
+	first := time.Now()
+	s.queueAlertEvaluation(first)
+	s.queueAlertEvaluation(first.Add(time.Minute))
+	if queued := <-s.alertEvaluations; !queued.Equal(first.Add(time.Minute)) {
+		t.Errorf("expected the evaluation of the latest refresh to replace the queued one, got %v", queued)
+	}
+	select {
+	case queued := <-s.alertEvaluations:
+		t.Errorf("expected a single queued evaluation, got another at %v", queued)
+	default:
+	}
+}
+
+func TestAPIV1JobRuns(t *testing.T) {
+	// the runs must be recent enough for sippy to look at them
+	now := int(time.Now().Unix() * 1000)
+	success := testgridv1.TestResult{Count: 3, Value: testgridv1.TestStatusSuccess}
+	jobs := []testgridv1.JobDetails{{
+		Name:        "job-aws",
+		Query:       "origin-ci-test/logs/job-aws",
+		ChangeLists: []string{"3", "2", "1"},
+		Timestamps:  []int{now - 3600000, now - 2*3600000, now - 3*3600000},
+		Tests:       []testgridv1.Test{{Name: "Overall", Statuses: []testgridv1.TestResult{success}}},
+	}}
+	jobRuns := api.NewJobRunResults(testgridconversion.NewEmptySythenticTestManager(), "", jobs)
+	s := &Server{dashboardCoordinates: []TestGridDashboardCoordinates{{ReportName: "4.8"}}}
+	s.currTestReports.Store(map[string]StandardReport{"4.8": {jobRuns: &jobRuns}})
+
+	get := func(query string) (interface{}, int, error) {
+		return s.apiV1JobRuns(httptest.NewRequest(http.MethodGet, "/api/v1/jobruns?"+query, nil))
+	}
+	response, _, err := get("release=4.8&job=job-aws&limit=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := response.(sippyv1.JobRunList)
+	if list.Total != 3 || len(list.Items) != 2 || list.Items[0].URL != jobRuns.Runs[0].URL || len(list.NextCursor) == 0 {
+		t.Errorf("expected the first page of the runs, got %+v", list)
+	}
+	if _, status, _ := get("release=4.8&snapshot=latest"); status != http.StatusBadRequest {
+		t.Errorf("expected snapshots to be rejected, got %d", status)
+	}
+	if _, status, _ := get("release=4.8&job=job-gcp"); status != http.StatusNotFound {
+		t.Errorf("expected an unknown job not to be found, got %d", status)
+	}
+}
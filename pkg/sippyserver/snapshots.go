@@ -0,0 +1,316 @@
+package sippyserver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/util"
+	"k8s.io/klog"
+)
+
+const (
+	// snapshotIDFormat is used to name snapshots.  It is safe to use in file names and urls.  Snapshots taken in the same
+	// second as an earlier one get a -1, -2, ... suffix.
+	snapshotIDFormat = "20060102T150405Z"
+	snapshotSuffix   = ".json.gz"
+	// snapshotDateFormat is accepted in place of a snapshot ID and selects the last snapshot taken on or before that day.
+	snapshotDateFormat = "2006-01-02"
+)
+
+// SnapshotStore persists every StandardReport the server computes, so past reports can be listed and viewed later.
+// Snapshots are stored as <dir>/<release>/<id>.json.gz.
+type SnapshotStore struct {
+	dir       string
+	retention SnapshotRetention
+}
+
+// SnapshotRetention limits how many snapshots of each release are kept.  The oldest snapshots are deleted whenever a
+// new one is saved.
+type SnapshotRetention struct {
+	// MaxCount is the number of snapshots kept for each release.  Zero keeps any number.
+	MaxCount int
+	// MaxAge is how long a snapshot is kept, measured from the newest one.  Zero keeps snapshots forever.
+	MaxAge time.Duration
+}
+
+// storedTestReport is how a TestReport is stored in a snapshot.  The job lists are not part of the json of a
+// TestReport, so they are stored next to it.
+type storedTestReport struct {
+	sippyprocessingv1.TestReport
+	ByJob              []sippyprocessingv1.JobResult `json:"byJob"`
+	FrequentJobResults []sippyprocessingv1.JobResult `json:"frequentJobResults"`
+}
+
+func toStoredTestReport(report sippyprocessingv1.TestReport) storedTestReport {
+	return storedTestReport{
+		TestReport:         report,
+		ByJob:              report.ByJob,
+		FrequentJobResults: report.FrequentJobResults,
+	}
+}
+
+func (r storedTestReport) toTestReport() sippyprocessingv1.TestReport {
+	report := r.TestReport
+	report.ByJob = r.ByJob
+	report.FrequentJobResults = r.FrequentJobResults
+	return report
+}
+
+// storedReport is how a StandardReport is stored in a snapshot.
+type storedReport struct {
+	CurrentPeriodReport storedTestReport `json:"currentPeriodReport"`
+	CurrentTwoDayReport storedTestReport `json:"currentTwoDayReport"`
+	PreviousWeekReport  storedTestReport `json:"previousWeekReport"`
+}
+
+func NewSnapshotStore(dir string, retention SnapshotRetention) (*SnapshotStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("could not create snapshot directory %s: %v", dir, err)
+	}
+	return &SnapshotStore{dir: dir, retention: retention}, nil
+}
+
+// releaseDir returns the directory of the snapshots of the release.  The release name comes from requests, so it must
+// name a single directory inside the store.
+func (s *SnapshotStore) releaseDir(release string) (string, error) {
+	name := url.PathEscape(release)
+	if len(name) == 0 || name == "." || name == ".." || filepath.Base(name) != name {
+		return "", fmt.Errorf("invalid release %q", release)
+	}
+	return filepath.Join(s.dir, name), nil
+}
+
+// Save stores the report for the release as it was computed at timestamp and returns the snapshot that was written.
+func (s *SnapshotStore) Save(release string, timestamp time.Time, report StandardReport) (sippyv1.Snapshot, error) {
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	stored := storedReport{
+		CurrentPeriodReport: toStoredTestReport(report.CurrentPeriodReport),
+		CurrentTwoDayReport: toStoredTestReport(report.CurrentTwoDayReport),
+		PreviousWeekReport:  toStoredTestReport(report.PreviousWeekReport),
+	}
+	if err := json.NewEncoder(zw).Encode(stored); err != nil {
+		return sippyv1.Snapshot{}, err
+	}
+	if err := zw.Close(); err != nil {
+		return sippyv1.Snapshot{}, err
+	}
+
+	dir, err := s.releaseDir(release)
+	if err != nil {
+		return sippyv1.Snapshot{}, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return sippyv1.Snapshot{}, err
+	}
+	timestamp = timestamp.UTC().Truncate(time.Second)
+	id := ""
+	for seq := 0; ; seq++ {
+		id = formatSnapshotID(timestamp, seq)
+		err := util.CreateFileAtomically(filepath.Join(dir, id+snapshotSuffix), buf.Bytes())
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return sippyv1.Snapshot{}, fmt.Errorf("could not write snapshot %s for %s: %v", id, release, err)
+		}
+	}
+	if err := s.prune(release, timestamp); err != nil {
+		// the snapshot was saved, the old ones will be pruned next time
+		klog.Warningf("Could not prune the snapshots of %s: %v", release, err)
+	}
+
+	return sippyv1.Snapshot{
+		Release:   release,
+		ID:        id,
+		Timestamp: timestamp,
+		Size:      int64(buf.Len()),
+	}, nil
+}
+
+// formatSnapshotID returns the ID of the seq'th snapshot taken in the second of the timestamp.
+func formatSnapshotID(timestamp time.Time, seq int) string {
+	id := timestamp.Format(snapshotIDFormat)
+	if seq > 0 {
+		id += "-" + strconv.Itoa(seq)
+	}
+	return id
+}
+
+// parseSnapshotID returns the timestamp and the sequence number within its second of the snapshot ID.
+func parseSnapshotID(id string) (time.Time, int, error) {
+	parts := strings.SplitN(id, "-", 2)
+	timestamp, err := time.Parse(snapshotIDFormat, parts[0])
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	seq := 0
+	if len(parts) == 2 {
+		if seq, err = strconv.Atoi(parts[1]); err != nil || seq < 1 {
+			return time.Time{}, 0, fmt.Errorf("invalid snapshot ID %q", id)
+		}
+	}
+	return timestamp, seq, nil
+}
+
+// prune deletes the snapshots of the release that are older than MaxAge before newest, then the oldest ones beyond
+// MaxCount.
+func (s *SnapshotStore) prune(release string, newest time.Time) error {
+	snapshots, err := s.List(release)
+	if err != nil {
+		return err
+	}
+
+	expired := 0
+	if s.retention.MaxAge > 0 {
+		for expired < len(snapshots) && snapshots[expired].Timestamp.Before(newest.Add(-s.retention.MaxAge)) {
+			expired++
+		}
+	}
+	if s.retention.MaxCount > 0 && len(snapshots)-expired > s.retention.MaxCount {
+		expired = len(snapshots) - s.retention.MaxCount
+	}
+
+	dir, err := s.releaseDir(release)
+	if err != nil {
+		return err
+	}
+	for _, snapshot := range snapshots[:expired] {
+		if err := os.Remove(filepath.Join(dir, snapshot.ID+snapshotSuffix)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		klog.V(2).Infof("Pruned snapshot %s of %s", snapshot.ID, release)
+	}
+	return nil
+}
+
+// Releases lists every release that has at least one snapshot, sorted by name.
+func (s *SnapshotStore) Releases() ([]string, error) {
+	entries, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	releases := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		release, err := url.PathUnescape(entry.Name())
+		if err != nil {
+			continue
+		}
+		releases = append(releases, release)
+	}
+	sort.Strings(releases)
+	return releases, nil
+}
+
+// List returns the snapshots for the release, oldest first.
+func (s *SnapshotStore) List(release string) ([]sippyv1.Snapshot, error) {
+	dir, err := s.releaseDir(release)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []sippyv1.Snapshot{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []sippyv1.Snapshot{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), snapshotSuffix) {
+			continue
+		}
+		id := strings.TrimSuffix(entry.Name(), snapshotSuffix)
+		timestamp, _, err := parseSnapshotID(id)
+		if err != nil {
+			// not something we wrote, for instance a temporary file
+			continue
+		}
+		snapshots = append(snapshots, sippyv1.Snapshot{
+			Release:   release,
+			ID:        id,
+			Timestamp: timestamp,
+			Size:      entry.Size(),
+		})
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		if !snapshots[i].Timestamp.Equal(snapshots[j].Timestamp) {
+			return snapshots[i].Timestamp.Before(snapshots[j].Timestamp)
+		}
+		_, seqI, _ := parseSnapshotID(snapshots[i].ID)
+		_, seqJ, _ := parseSnapshotID(snapshots[j].ID)
+		return seqI < seqJ
+	})
+	return snapshots, nil
+}
+
+// Resolve finds the snapshot for the release named by ref.  ref is either a snapshot ID, or a date in YYYY-MM-DD form
+// which selects the last snapshot taken on or before that day (UTC).
+func (s *SnapshotStore) Resolve(release, ref string) (sippyv1.Snapshot, error) {
+	snapshots, err := s.List(release)
+	if err != nil {
+		return sippyv1.Snapshot{}, err
+	}
+
+	if date, err := time.Parse(snapshotDateFormat, ref); err == nil {
+		endOfDay := date.AddDate(0, 0, 1)
+		for i := len(snapshots) - 1; i >= 0; i-- {
+			if snapshots[i].Timestamp.Before(endOfDay) {
+				return snapshots[i], nil
+			}
+		}
+		return sippyv1.Snapshot{}, fmt.Errorf("no snapshot of %s on or before %s", release, ref)
+	}
+
+	for _, snapshot := range snapshots {
+		if snapshot.ID == ref {
+			return snapshot, nil
+		}
+	}
+	return sippyv1.Snapshot{}, fmt.Errorf("no snapshot %q of %s", ref, release)
+}
+
+// Load reads the report stored in the snapshot.
+func (s *SnapshotStore) Load(snapshot sippyv1.Snapshot) (StandardReport, error) {
+	dir, err := s.releaseDir(snapshot.Release)
+	if err != nil {
+		return StandardReport{}, err
+	}
+	filename := filepath.Join(dir, snapshot.ID+snapshotSuffix)
+	f, err := os.Open(filename)
+	if err != nil {
+		return StandardReport{}, fmt.Errorf("could not read snapshot %s: %v", filename, err)
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return StandardReport{}, fmt.Errorf("could not read snapshot %s: %v", filename, err)
+	}
+	defer zr.Close()
+	stored := storedReport{}
+	if err := json.NewDecoder(zr).Decode(&stored); err != nil {
+		return StandardReport{}, fmt.Errorf("could not parse snapshot %s: %v", filename, err)
+	}
+	return StandardReport{
+		CurrentPeriodReport: stored.CurrentPeriodReport.toTestReport(),
+		CurrentTwoDayReport: stored.CurrentTwoDayReport.toTestReport(),
+		PreviousWeekReport:  stored.PreviousWeekReport.toTestReport(),
+	}, nil
+}
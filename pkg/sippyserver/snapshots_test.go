@@ -0,0 +1,202 @@
+package sippyserver
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+)
+
+func TestSnapshotStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := NewSnapshotStore(dir, SnapshotRetention{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reportAt := func(timestamp time.Time) StandardReport {
+		return StandardReport{
+			CurrentPeriodReport: sippyprocessingv1.TestReport{
+				Release:   "4.7",
+				Timestamp: timestamp,
+				ByJob:     []sippyprocessingv1.JobResult{{Name: "job-1", Successes: 3, Failures: 1, PassPercentage: 75}},
+			},
+			PreviousWeekReport: sippyprocessingv1.TestReport{Release: "4.7", Timestamp: timestamp},
+		}
+	}
+	jan10 := time.Date(2021, 1, 10, 12, 0, 0, 0, time.UTC)
+	jan12 := time.Date(2021, 1, 12, 8, 30, 0, 0, time.UTC)
+	for _, timestamp := range []time.Time{jan12, jan10} {
+		if _, err := store.Save("4.7", timestamp, reportAt(timestamp)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	releases, err := store.Releases()
+	if err != nil || !reflect.DeepEqual(releases, []string{"4.7"}) {
+		t.Fatalf("unexpected releases %v: %v", releases, err)
+	}
+	snapshots, err := store.List("4.7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 2 || !snapshots[0].Timestamp.Equal(jan10) || !snapshots[1].Timestamp.Equal(jan12) {
+		t.Fatalf("expected two snapshots oldest first, got %#v", snapshots)
+	}
+
+	tests := []struct {
+		ref     string
+		want    time.Time
+		wantErr bool
+	}{
+		{ref: snapshots[1].ID, want: jan12},
+		{ref: "2021-01-10", want: jan10},
+		{ref: "2021-01-11", want: jan10},
+		{ref: "2021-01-12", want: jan12},
+		{ref: "2021-01-09", wantErr: true},
+		{ref: "20210101T000000Z", wantErr: true},
+	}
+	for _, tc := range tests {
+		snapshot, err := store.Resolve("4.7", tc.ref)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %#v", tc.ref, snapshot)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.ref, err)
+			continue
+		}
+		if !snapshot.Timestamp.Equal(tc.want) {
+			t.Errorf("%s: expected snapshot at %v, got %v", tc.ref, tc.want, snapshot.Timestamp)
+		}
+	}
+
+	snapshot, _ := store.Resolve("4.7", "2021-01-12")
+	report, err := store.Load(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := reportAt(jan12); !reflect.DeepEqual(report.CurrentPeriodReport.ByJob, want.CurrentPeriodReport.ByJob) ||
+		!report.CurrentPeriodReport.Timestamp.Equal(jan12) {
+		t.Errorf("snapshot did not round trip: %#v", report.CurrentPeriodReport)
+	}
+
+	if snapshots, err := store.List("4.8"); err != nil || len(snapshots) != 0 {
+		t.Errorf("expected no snapshots for an unknown release, got %v: %v", snapshots, err)
+	}
+	for _, release := range []string{"..", ".", ""} {
+		if snapshots, err := store.List(release); err == nil {
+			t.Errorf("expected an error for release %q, got %v", release, snapshots)
+		}
+		if _, err := store.Save(release, jan12, reportAt(jan12)); err == nil {
+			t.Errorf("expected saving release %q to fail", release)
+		}
+	}
+}
+
+func TestSnapshotRetention(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	save := func(store *SnapshotStore, days ...int) {
+		for _, day := range days {
+			timestamp := time.Date(2021, 1, day, 12, 0, 0, 0, time.UTC)
+			if _, err := store.Save("4.7", timestamp, StandardReport{}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	days := func(store *SnapshotStore) []int {
+		snapshots, err := store.List("4.7")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ret := []int{}
+		for _, snapshot := range snapshots {
+			ret = append(ret, snapshot.Timestamp.Day())
+		}
+		return ret
+	}
+
+	byAge, err := NewSnapshotStore(dir, SnapshotRetention{MaxAge: 3 * 24 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	save(byAge, 1, 2, 3, 4, 5)
+	if actual := days(byAge); !reflect.DeepEqual(actual, []int{2, 3, 4, 5}) {
+		t.Errorf("expected the snapshots of the last three days to be kept, got %v", actual)
+	}
+
+	byCount, err := NewSnapshotStore(dir, SnapshotRetention{MaxCount: 2, MaxAge: 3 * 24 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	save(byCount, 6)
+	if actual := days(byCount); !reflect.DeepEqual(actual, []int{5, 6}) {
+		t.Errorf("expected the two newest snapshots to be kept, got %v", actual)
+	}
+}
+
+func TestSnapshotStoreSameSecond(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := NewSnapshotStore(dir, SnapshotRetention{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	timestamp := time.Date(2021, 1, 12, 8, 30, 0, 0, time.UTC)
+	ids := []string{}
+	for i := 0; i < 11; i++ {
+		report := StandardReport{CurrentPeriodReport: sippyprocessingv1.TestReport{Release: "4.7", Timestamp: timestamp.Add(time.Duration(i) * time.Millisecond)}}
+		snapshot, err := store.Save("4.7", report.CurrentPeriodReport.Timestamp, report)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, snapshot.ID)
+	}
+	if ids[0] != "20210112T083000Z" || ids[1] != "20210112T083000Z-1" || ids[10] != "20210112T083000Z-10" {
+		t.Errorf("expected the snapshots of the same second to get suffixes, got %v", ids)
+	}
+
+	snapshots, err := store.List("4.7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listed := []string{}
+	for _, snapshot := range snapshots {
+		listed = append(listed, snapshot.ID)
+	}
+	if !reflect.DeepEqual(listed, ids) {
+		t.Errorf("expected every snapshot to be kept in the order they were taken, got %v", listed)
+	}
+
+	snapshot, err := store.Resolve("4.7", "2021-01-12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := store.Load(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.CurrentPeriodReport.Timestamp.Equal(timestamp.Add(10 * time.Millisecond)) {
+		t.Errorf("expected the date to select the last snapshot, got the one at %v", report.CurrentPeriodReport.Timestamp)
+	}
+}
@@ -0,0 +1,77 @@
+package sippyserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/openshift/sippy/pkg/api"
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/html/releasehtml"
+	"github.com/openshift/sippy/pkg/variantanalysis"
+	"k8s.io/klog"
+)
+
+// variantMatrixForRequest computes the variant matrix of the current period of the ?release=, pivoted by the
+// comma-separated dimensions in ?rows= and ?columns=.
+func (s *Server) variantMatrixForRequest(req *http.Request) (sippyv1.VariantMatrix, sippyprocessingv1.TestReport, int, error) {
+	currTestReports, err := s.testReportsForRequest(req)
+	if err != nil {
+		return sippyv1.VariantMatrix{}, sippyprocessingv1.TestReport{}, http.StatusNotFound, err
+	}
+	reportName := req.URL.Query().Get("release")
+	dashboard, found := s.reportNameToDashboardCoordinates(reportName)
+	if !found {
+		return sippyv1.VariantMatrix{}, sippyprocessingv1.TestReport{}, http.StatusNotFound, fmt.Errorf("release %s not found", reportName)
+	}
+	report, ok := currTestReports[reportName]
+	if !ok {
+		return sippyv1.VariantMatrix{}, sippyprocessingv1.TestReport{}, http.StatusNotFound, fmt.Errorf("release %s has no report yet", reportName)
+	}
+
+	options := variantanalysis.DefaultMatrixOptions()
+	options.Rows = splitQueryList(req.URL.Query()["rows"])
+	options.Columns = splitQueryList(req.URL.Query()["columns"])
+	matrix, err := options.Matrix(report.CurrentPeriodReport, s.variantManagerFor(dashboard))
+	if err != nil {
+		return sippyv1.VariantMatrix{}, sippyprocessingv1.TestReport{}, http.StatusBadRequest, err
+	}
+	return matrix, report.CurrentPeriodReport, http.StatusOK, nil
+}
+
+// splitQueryList returns the values of a query parameter that may be repeated, comma-separated, or both.
+func splitQueryList(values []string) []string {
+	ret := []string{}
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if len(item) > 0 {
+				ret = append(ret, item)
+			}
+		}
+	}
+	return ret
+}
+
+func (s *Server) printVariantMatrix(w http.ResponseWriter, req *http.Request) {
+	matrix, _, status, err := s.variantMatrixForRequest(req)
+	if err != nil {
+		api.PrintError(w, status, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(matrix); err != nil {
+		klog.Errorf("unable to write variant matrix: %v", err)
+	}
+}
+
+func (s *Server) printVariantMatrixHtmlReport(w http.ResponseWriter, req *http.Request) {
+	matrix, report, status, err := s.variantMatrixForRequest(req)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	releasehtml.PrintVariantMatrixHtmlReport(w, matrix, report.Release, report.Timestamp)
+}
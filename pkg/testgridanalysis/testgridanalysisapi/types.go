@@ -34,13 +34,42 @@ type RawTestResult struct {
 	Successes int
 	Failures  int
 	Flakes    int
+	// RunPattern describes the order of the successes and failures in the job.
+	RunPattern RawTestRunPattern
+	// Synthetic is true for tests created by the synthetic test manager from the results of other tests.
+	Synthetic bool
+}
+
+// RawTestRunPattern is the order of the successes and failures of a test in a single job.  It becomes a
+// sippyprocessingv1.TestRunPattern once the test results are processed.
+type RawTestRunPattern struct {
+	Transitions          int
+	CurrentFailureStreak int
+	LongestFailureStreak int
+}
+
+// CombineTestRunPatterns merges the run patterns of a test in different jobs.  The runs of different jobs are separate,
+// so transitions are summed and each streak is the longest of either job.  Rows of the same job cover the same runs, so
+// they must be merged run by run instead.
+func CombineTestRunPatterns(lhs, rhs RawTestRunPattern) RawTestRunPattern {
+	combined := lhs
+	combined.Transitions += rhs.Transitions
+	if rhs.CurrentFailureStreak > combined.CurrentFailureStreak {
+		combined.CurrentFailureStreak = rhs.CurrentFailureStreak
+	}
+	if rhs.LongestFailureStreak > combined.LongestFailureStreak {
+		combined.LongestFailureStreak = rhs.LongestFailureStreak
+	}
+	return combined
 }
 
 // RawJobRunResult is an intermediate datatype that may not have complete or consistent data when interrogated.
 // It holds data for an individual run of a given job.
 type RawJobRunResult struct {
-	Job             string
-	JobRunURL       string
+	Job       string
+	JobRunURL string
+	// Timestamp is when the run started, in milliseconds since the epoch like the testgrid timestamps
+	Timestamp       int
 	TestFailures    int
 	FailedTestNames []string
 	Failed          bool
@@ -60,6 +89,10 @@ type RawJobRunResult struct {
 
 	// OpenShiftTestsStatus can be "", "Success", "Failure"
 	OpenShiftTestsStatus string
+
+	// TestStatuses holds the status, "Success" or "Failure", of the raw tests watched by the synthetic test manager.  A
+	// test that failed in any of its attempts is a "Failure".
+	TestStatuses map[string]string
 }
 
 type OperatorState struct {
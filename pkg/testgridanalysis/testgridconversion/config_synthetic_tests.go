@@ -0,0 +1,452 @@
+package testgridconversion
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/openshift/sippy/pkg/testgridanalysis/testgridanalysisapi"
+	"github.com/openshift/sippy/pkg/util"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+// The markers are the values a job run has before synthetic tests are created.  They are "", "Success", or "Failure",
+// except for MarkerUpgradeStarted, which is "true" or "false".
+const (
+	// MarkerOverall is the status of the job run as a whole.
+	MarkerOverall = "overall"
+	// MarkerSetup is the status of the setup container, or of its equivalent.
+	MarkerSetup = "setup"
+	// MarkerFinalOperators is "Failure" if any operator was unhealthy at the end of the run, and "" if there are no
+	// final operator states.
+	MarkerFinalOperators = "finalOperators"
+	// MarkerUpgradeStarted is "true" if the run attempted an upgrade.
+	MarkerUpgradeStarted = "upgradeStarted"
+	// MarkerUpgradeOperators is the status of upgrading the operators.
+	MarkerUpgradeOperators = "upgradeOperators"
+	// MarkerUpgradeMachineConfigPools is the status of upgrading the machine config pools.
+	MarkerUpgradeMachineConfigPools = "upgradeMachineConfigPools"
+	// MarkerOpenShiftTests is the status of the openshift-tests run.
+	MarkerOpenShiftTests = "openshiftTests"
+	// MarkerOperator is the final state of the operator that a per-operator synthetic test is for.
+	MarkerOperator = "operator"
+)
+
+var syntheticTestMarkers = sets.NewString(
+	MarkerOverall,
+	MarkerSetup,
+	MarkerFinalOperators,
+	MarkerUpgradeStarted,
+	MarkerUpgradeOperators,
+	MarkerUpgradeMachineConfigPools,
+	MarkerOpenShiftTests,
+	MarkerOperator,
+)
+
+// SyntheticTestConfig declares synthetic tests, so that new synthetic signals can be added without changing sippy.
+type SyntheticTestConfig struct {
+	// JobsWithoutSetup are regexes of jobs that do not have a setup container, either because they do not install the
+	// product or because they never had a passing install.  Their runs get an "Unknown" setup status instead of a
+	// warning about the missing setup container.
+	JobsWithoutSetup []string `json:"jobsWithoutSetup,omitempty"`
+	// Conditions are named conditions that the synthetic tests can refer to.
+	Conditions map[string]SyntheticTestCondition `json:"conditions,omitempty"`
+	// SyntheticTests are evaluated for every job run.
+	SyntheticTests []SyntheticTestDefinition `json:"syntheticTests"`
+}
+
+// SyntheticTestDefinition describes how the result of a synthetic test is derived from a job run.  The test fails if
+// Fail matches, otherwise it passes if Pass matches, and otherwise the run has no result for the test.  A missing Pass
+// matches every run.
+type SyntheticTestDefinition struct {
+	Name string `json:"name"`
+	// PerOperator creates one test for every operator in the final operator states of the run, named Name followed by
+	// the name of the operator.  The operator marker is the state of that operator.
+	PerOperator bool `json:"perOperator,omitempty"`
+	// When, if set, must match for the run to have the test at all.
+	When *SyntheticTestCondition `json:"when,omitempty"`
+	// ExcludeJobs are regexes of jobs whose runs have the test, but never a result for it.
+	ExcludeJobs []string                `json:"excludeJobs,omitempty"`
+	Fail        *SyntheticTestCondition `json:"fail,omitempty"`
+	Pass        *SyntheticTestCondition `json:"pass,omitempty"`
+}
+
+// SyntheticTestCondition is a condition on a job run.  Exactly one of its fields other than Values is set.
+type SyntheticTestCondition struct {
+	All []SyntheticTestCondition `json:"all,omitempty"`
+	Any []SyntheticTestCondition `json:"any,omitempty"`
+	Not *SyntheticTestCondition  `json:"not,omitempty"`
+	// Condition is the name of a condition in SyntheticTestConfig.Conditions.
+	Condition string `json:"condition,omitempty"`
+	// Marker matches if the value of the marker is one of Values.
+	Marker string `json:"marker,omitempty"`
+	// Test is a regex of raw test names.  It matches if their status is one of Values: "Failure" if any of them failed,
+	// "Success" if they all passed, or "" if none of them ran.
+	Test string `json:"test,omitempty"`
+
+	Values []string `json:"values,omitempty"`
+}
+
+// LoadSyntheticTestConfig reads a SyntheticTestConfig from a JSON file.
+func LoadSyntheticTestConfig(filename string) (SyntheticTestConfig, error) {
+	config := SyntheticTestConfig{}
+	err := util.LoadStrictJSON(filename, &config)
+	return config, err
+}
+
+// OpenshiftSyntheticTestConfig returns the config of the synthetic tests of OpenShift jobs.  It is a starting point for
+// configs that add synthetic tests of their own.
+func OpenshiftSyntheticTestConfig() SyntheticTestConfig {
+	config := SyntheticTestConfig{}
+	if err := util.ParseStrictJSON([]byte(openshiftSyntheticTestConfig), &config); err != nil {
+		// launch error, the config is checked by the unit tests
+		panic(fmt.Sprintf("invalid openshift synthetic test config: %v", err))
+	}
+	return config
+}
+
+// syntheticTestRun is the job run a condition is evaluated against.
+type syntheticTestRun struct {
+	jrr            testgridanalysisapi.RawJobRunResult
+	finalOperators string
+	// operator is the state of the operator of a per-operator test
+	operator string
+}
+
+func newSyntheticTestRun(jrr testgridanalysisapi.RawJobRunResult) syntheticTestRun {
+	run := syntheticTestRun{jrr: jrr}
+	for _, operator := range jrr.FinalOperatorStates {
+		run.finalOperators = testgridanalysisapi.Success
+		if operator.State == testgridanalysisapi.Failure {
+			run.finalOperators = testgridanalysisapi.Failure
+			break
+		}
+	}
+	return run
+}
+
+func (r syntheticTestRun) marker(name string) string {
+	switch name {
+	case MarkerOverall:
+		switch {
+		case r.jrr.Succeeded:
+			return testgridanalysisapi.Success
+		case r.jrr.Failed:
+			return testgridanalysisapi.Failure
+		}
+		return ""
+	case MarkerSetup:
+		return r.jrr.SetupStatus
+	case MarkerFinalOperators:
+		return r.finalOperators
+	case MarkerUpgradeStarted:
+		return fmt.Sprintf("%t", r.jrr.UpgradeStarted)
+	case MarkerUpgradeOperators:
+		return r.jrr.UpgradeForOperatorsStatus
+	case MarkerUpgradeMachineConfigPools:
+		return r.jrr.UpgradeForMachineConfigPoolsStatus
+	case MarkerOpenShiftTests:
+		return r.jrr.OpenShiftTestsStatus
+	case MarkerOperator:
+		return r.operator
+	}
+	return ""
+}
+
+func (r syntheticTestRun) testStatus(regex *regexp.Regexp) string {
+	status := ""
+	for testName, testStatus := range r.jrr.TestStatuses {
+		if !regex.MatchString(testName) {
+			continue
+		}
+		if testStatus == testgridanalysisapi.Failure {
+			return testgridanalysisapi.Failure
+		}
+		status = testStatus
+	}
+	return status
+}
+
+type conditionFunc func(run syntheticTestRun) bool
+
+type syntheticTest struct {
+	name        string
+	perOperator bool
+	when        conditionFunc
+	excludeJobs []*regexp.Regexp
+	fail        conditionFunc
+	pass        conditionFunc
+}
+
+// result returns the passes and failures of the test in the run, and whether the run has the test at all.
+func (t syntheticTest) result(run syntheticTestRun, excluded bool) (pass, fail int, ok bool) {
+	if t.when != nil && !t.when(run) {
+		return 0, 0, false
+	}
+	switch {
+	case excluded:
+		return 0, 0, true
+	case t.fail != nil && t.fail(run):
+		return 0, 1, true
+	case t.pass == nil || t.pass(run):
+		return 1, 0, true
+	}
+	return 0, 0, true
+}
+
+type configSyntheticManager struct {
+	tests            []syntheticTest
+	jobsWithoutSetup []*regexp.Regexp
+	watchedTests     []*regexp.Regexp
+}
+
+// NewConfigSyntheticTestManager returns a SythenticTestManager for the synthetic tests declared by the config, or an
+// error if the config is invalid.
+func NewConfigSyntheticTestManager(config SyntheticTestConfig) (SythenticTestManager, error) {
+	ret := &configSyntheticManager{}
+	var err error
+	if ret.jobsWithoutSetup, err = compileRegexes(config.JobsWithoutSetup); err != nil {
+		return nil, fmt.Errorf("invalid jobsWithoutSetup: %v", err)
+	}
+
+	compiler := conditionCompiler{
+		conditions: config.Conditions,
+		compiled:   map[string]conditionFunc{},
+		compiling:  sets.NewString(),
+		manager:    ret,
+	}
+	for name := range config.Conditions {
+		if _, err := compiler.named(name); err != nil {
+			return nil, err
+		}
+	}
+
+	names := sets.NewString()
+	for i, definition := range config.SyntheticTests {
+		if len(definition.Name) == 0 {
+			return nil, fmt.Errorf("synthetic test %d must have a name", i)
+		}
+		if names.Has(definition.Name) {
+			return nil, fmt.Errorf("synthetic test %q is defined more than once", definition.Name)
+		}
+		names.Insert(definition.Name)
+
+		test := syntheticTest{
+			name:        definition.Name,
+			perOperator: definition.PerOperator,
+		}
+		if test.excludeJobs, err = compileRegexes(definition.ExcludeJobs); err != nil {
+			return nil, fmt.Errorf("synthetic test %q has an invalid excludeJobs: %v", definition.Name, err)
+		}
+		for _, condition := range []struct {
+			name       string
+			definition *SyntheticTestCondition
+			compiled   *conditionFunc
+		}{
+			{name: "when", definition: definition.When, compiled: &test.when},
+			{name: "fail", definition: definition.Fail, compiled: &test.fail},
+			{name: "pass", definition: definition.Pass, compiled: &test.pass},
+		} {
+			if condition.definition == nil {
+				continue
+			}
+			if *condition.compiled, err = compiler.compile(*condition.definition); err != nil {
+				return nil, fmt.Errorf("synthetic test %q has an invalid %s condition: %v", definition.Name, condition.name, err)
+			}
+		}
+		ret.tests = append(ret.tests, test)
+	}
+
+	return ret, nil
+}
+
+func compileRegexes(expressions []string) ([]*regexp.Regexp, error) {
+	ret := []*regexp.Regexp{}
+	for _, expression := range expressions {
+		regex, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, regex)
+	}
+	return ret, nil
+}
+
+func matchesAny(regexes []*regexp.Regexp, name string) bool {
+	for _, regex := range regexes {
+		if regex.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+type conditionCompiler struct {
+	conditions map[string]SyntheticTestCondition
+	compiled   map[string]conditionFunc
+	// compiling are the named conditions being compiled, to detect conditions that refer to themselves
+	compiling sets.String
+	manager   *configSyntheticManager
+}
+
+func (c conditionCompiler) named(name string) (conditionFunc, error) {
+	if compiled, ok := c.compiled[name]; ok {
+		return compiled, nil
+	}
+	condition, ok := c.conditions[name]
+	if !ok {
+		return nil, fmt.Errorf("undefined condition %q", name)
+	}
+	if c.compiling.Has(name) {
+		return nil, fmt.Errorf("condition %q refers to itself", name)
+	}
+	c.compiling.Insert(name)
+	defer c.compiling.Delete(name)
+
+	compiled, err := c.compile(condition)
+	if err != nil {
+		return nil, fmt.Errorf("condition %q: %v", name, err)
+	}
+	c.compiled[name] = compiled
+	return compiled, nil
+}
+
+func (c conditionCompiler) compile(condition SyntheticTestCondition) (conditionFunc, error) {
+	set := 0
+	for _, isSet := range []bool{
+		len(condition.All) > 0,
+		len(condition.Any) > 0,
+		condition.Not != nil,
+		len(condition.Condition) > 0,
+		len(condition.Marker) > 0,
+		len(condition.Test) > 0,
+	} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, fmt.Errorf("exactly one of all, any, not, condition, marker, or test must be set")
+	}
+	if (len(condition.Marker) > 0 || len(condition.Test) > 0) != (len(condition.Values) > 0) {
+		return nil, fmt.Errorf("values must be set for marker and test conditions, and only for them")
+	}
+	values := sets.NewString(condition.Values...)
+
+	switch {
+	case len(condition.All) > 0, len(condition.Any) > 0:
+		children := []conditionFunc{}
+		for _, child := range append(condition.All, condition.Any...) {
+			compiled, err := c.compile(child)
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, compiled)
+		}
+		// a match in an "any" ends the evaluation the same way a mismatch in an "all" does
+		all := len(condition.All) > 0
+		return func(run syntheticTestRun) bool {
+			for _, child := range children {
+				if child(run) != all {
+					return !all
+				}
+			}
+			return all
+		}, nil
+
+	case condition.Not != nil:
+		child, err := c.compile(*condition.Not)
+		if err != nil {
+			return nil, err
+		}
+		return func(run syntheticTestRun) bool {
+			return !child(run)
+		}, nil
+
+	case len(condition.Condition) > 0:
+		return c.named(condition.Condition)
+
+	case len(condition.Marker) > 0:
+		if !syntheticTestMarkers.Has(condition.Marker) {
+			return nil, fmt.Errorf("unknown marker %q", condition.Marker)
+		}
+		marker := condition.Marker
+		return func(run syntheticTestRun) bool {
+			return values.Has(run.marker(marker))
+		}, nil
+
+	default:
+		regex, err := regexp.Compile(condition.Test)
+		if err != nil {
+			return nil, fmt.Errorf("invalid test regex: %v", err)
+		}
+		c.manager.watchedTests = append(c.manager.watchedTests, regex)
+		return func(run syntheticTestRun) bool {
+			return values.Has(run.testStatus(regex))
+		}, nil
+	}
+}
+
+func (m *configSyntheticManager) IsWatchedTest(testName string) bool {
+	return matchesAny(m.watchedTests, testName)
+}
+
+func (m *configSyntheticManager) CreateSyntheticTests(rawJobResults testgridanalysisapi.RawData) []string {
+	warnings := []string{}
+
+	type synthenticTestResult struct {
+		pass int
+		fail int
+	}
+
+	for jobName, jobResults := range rawJobResults.JobResults {
+		withoutSetup := matchesAny(m.jobsWithoutSetup, jobName)
+		excluded := make([]bool, len(m.tests))
+		for i, test := range m.tests {
+			excluded[i] = matchesAny(test.excludeJobs, jobName)
+		}
+
+		numRunsWithoutSetup := 0
+		for jrrKey, jrr := range jobResults.JobRunResults {
+			if jrr.SetupStatus == "" {
+				numRunsWithoutSetup++
+			}
+
+			run := newSyntheticTestRun(jrr)
+			syntheticTests := map[string]synthenticTestResult{}
+			for i, test := range m.tests {
+				if !test.perOperator {
+					if pass, fail, ok := test.result(run, excluded[i]); ok {
+						syntheticTests[test.name] = synthenticTestResult{pass: pass, fail: fail}
+					}
+					continue
+				}
+				for _, operatorState := range jrr.FinalOperatorStates {
+					run.operator = operatorState.State
+					if pass, fail, ok := test.result(run, excluded[i]); ok {
+						syntheticTests[test.name+operatorState.Name] = synthenticTestResult{pass: pass, fail: fail}
+					}
+				}
+				run.operator = ""
+			}
+
+			for testName, result := range syntheticTests {
+				if result.fail > 0 {
+					jrr.TestFailures += result.fail
+					jrr.FailedTestNames = append(jrr.FailedTestNames, testName)
+				}
+				addSyntheticTestResult(jobResults.TestResults, testName, result.pass, result.fail)
+			}
+
+			if len(jrr.SetupStatus) == 0 && withoutSetup {
+				jrr.SetupStatus = testgridanalysisapi.Unknown
+			}
+			jobResults.JobRunResults[jrrKey] = jrr
+		}
+		if numRunsWithoutSetup > 0 && numRunsWithoutSetup == len(jobResults.JobRunResults) && !withoutSetup {
+			warnings = append(warnings, fmt.Sprintf("%q is missing a test setup job to indicate successful installs", jobName))
+		}
+
+		rawJobResults.JobResults[jobName] = jobResults
+	}
+	return warnings
+}
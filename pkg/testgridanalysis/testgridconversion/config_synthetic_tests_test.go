@@ -0,0 +1,213 @@
+package testgridconversion
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/openshift/sippy/pkg/testgridanalysis/testgridanalysisapi"
+)
+
+func TestOpenshiftSyntheticTestConfig(t *testing.T) {
+	manager, err := NewConfigSyntheticTestManager(OpenshiftSyntheticTestConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	healthy := []testgridanalysisapi.OperatorState{{Name: "etcd", State: testgridanalysisapi.Success}}
+	failed := []testgridanalysisapi.OperatorState{{Name: "etcd", State: testgridanalysisapi.Failure}}
+
+	tests := []struct {
+		name             string
+		jobName          string
+		jrr              testgridanalysisapi.RawJobRunResult
+		expectedFailures []string
+		expectedPasses   []string
+		expectedSetup    string
+	}{
+		{
+			name:    "successful upgrade",
+			jobName: "release-openshift-origin-installer-e2e-gcp-upgrade-4.6",
+			jrr: testgridanalysisapi.RawJobRunResult{
+				Succeeded:                          true,
+				SetupStatus:                        testgridanalysisapi.Success,
+				FinalOperatorStates:                healthy,
+				UpgradeStarted:                     true,
+				UpgradeForOperatorsStatus:          testgridanalysisapi.Success,
+				UpgradeForMachineConfigPoolsStatus: testgridanalysisapi.Success,
+				OpenShiftTestsStatus:               testgridanalysisapi.Success,
+			},
+			expectedPasses: []string{
+				testgridanalysisapi.FinalOperatorHealthTestName,
+				testgridanalysisapi.InfrastructureTestName,
+				testgridanalysisapi.InstallTestName,
+				testgridanalysisapi.InstallTimeoutTestName,
+				testgridanalysisapi.OpenShiftTestsName,
+				testgridanalysisapi.UpgradeTestName,
+				testgridanalysisapi.OperatorUpgradePrefix + "etcd",
+				testgridanalysisapi.OperatorInstallPrefix + "etcd",
+			},
+			expectedSetup: testgridanalysisapi.Success,
+		},
+		{
+			name:             "infrastructure failure",
+			jobName:          "release-openshift-ocp-installer-e2e-aws-4.6",
+			jrr:              testgridanalysisapi.RawJobRunResult{Failed: true, SetupStatus: testgridanalysisapi.Failure},
+			expectedFailures: []string{testgridanalysisapi.InfrastructureTestName},
+			expectedSetup:    testgridanalysisapi.Failure,
+		},
+		{
+			name:           "job without setup",
+			jobName:        "release-openshift-ocp-osd-aws-nightly-4.6",
+			jrr:            testgridanalysisapi.RawJobRunResult{Failed: true},
+			expectedPasses: []string{testgridanalysisapi.InstallTimeoutTestName},
+			expectedSetup:  testgridanalysisapi.Unknown,
+		},
+		{
+			name:    "install timeout",
+			jobName: "release-openshift-ocp-installer-e2e-aws-4.6",
+			jrr: testgridanalysisapi.RawJobRunResult{
+				Failed:              true,
+				SetupStatus:         testgridanalysisapi.Failure,
+				FinalOperatorStates: healthy,
+			},
+			expectedFailures: []string{testgridanalysisapi.InstallTestName, testgridanalysisapi.InstallTimeoutTestName},
+			expectedSetup:    testgridanalysisapi.Failure,
+		},
+		{
+			name:    "operator install failure",
+			jobName: "release-openshift-ocp-installer-e2e-aws-4.6",
+			jrr: testgridanalysisapi.RawJobRunResult{
+				Failed:              true,
+				SetupStatus:         testgridanalysisapi.Failure,
+				FinalOperatorStates: failed,
+			},
+			expectedFailures: []string{
+				testgridanalysisapi.InstallTestName,
+				testgridanalysisapi.FinalOperatorHealthTestName,
+				testgridanalysisapi.OperatorInstallPrefix + "etcd",
+			},
+			expectedSetup: testgridanalysisapi.Failure,
+		},
+		{
+			name:    "openshift-tests failure",
+			jobName: "release-openshift-ocp-installer-e2e-aws-4.6",
+			jrr: testgridanalysisapi.RawJobRunResult{
+				Failed:               true,
+				SetupStatus:          testgridanalysisapi.Success,
+				FinalOperatorStates:  healthy,
+				OpenShiftTestsStatus: testgridanalysisapi.Failure,
+			},
+			expectedFailures: []string{testgridanalysisapi.OpenShiftTestsName},
+			expectedSetup:    testgridanalysisapi.Success,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.jrr.Job, tc.jrr.JobRunURL = tc.jobName, "https://prow/"+tc.jobName+"/1"
+			rawData := testgridanalysisapi.RawData{JobResults: map[string]testgridanalysisapi.RawJobResult{
+				tc.jobName: {
+					JobName:       tc.jobName,
+					JobRunResults: map[string]testgridanalysisapi.RawJobRunResult{tc.jrr.JobRunURL: tc.jrr},
+					TestResults:   map[string]testgridanalysisapi.RawTestResult{},
+				},
+			}}
+			if warnings := manager.CreateSyntheticTests(rawData); len(warnings) != 0 {
+				t.Errorf("expected no warnings, got %v", warnings)
+			}
+
+			jobResult := rawData.JobResults[tc.jobName]
+			jrr := jobResult.JobRunResults[tc.jrr.JobRunURL]
+			sort.Strings(jrr.FailedTestNames)
+			sort.Strings(tc.expectedFailures)
+			if len(jrr.FailedTestNames) != 0 || len(tc.expectedFailures) != 0 {
+				if !reflect.DeepEqual(jrr.FailedTestNames, tc.expectedFailures) {
+					t.Errorf("expected failures %v, got %v", tc.expectedFailures, jrr.FailedTestNames)
+				}
+			}
+			for _, testName := range tc.expectedPasses {
+				if result := jobResult.TestResults[testName]; result.Successes != 1 || result.Failures != 0 {
+					t.Errorf("expected %q to pass, got %#v", testName, result)
+				}
+			}
+			if jrr.SetupStatus != tc.expectedSetup {
+				t.Errorf("expected setup status %q, got %q", tc.expectedSetup, jrr.SetupStatus)
+			}
+		})
+	}
+}
+
+func TestConfigSyntheticTestFromRawTests(t *testing.T) {
+	config := SyntheticTestConfig{
+		SyntheticTests: []SyntheticTestDefinition{
+			{
+				Name: "[sig-sippy] etcd should be healthy at the end",
+				Fail: &SyntheticTestCondition{Test: `etcd.*healthy`, Values: []string{testgridanalysisapi.Failure}},
+				Pass: &SyntheticTestCondition{Test: `etcd.*healthy`, Values: []string{testgridanalysisapi.Success}},
+			},
+		},
+	}
+	manager, err := NewConfigSyntheticTestManager(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	watcher, ok := manager.(RawTestWatcher)
+	if !ok {
+		t.Fatal("expected the manager to watch raw tests")
+	}
+	if !watcher.IsWatchedTest("[sig-etcd] etcd members are healthy") || watcher.IsWatchedTest("[sig-network] dns works") {
+		t.Error("expected only the etcd test to be watched")
+	}
+
+	rawData := testgridanalysisapi.RawData{JobResults: map[string]testgridanalysisapi.RawJobResult{
+		"job": {
+			JobName: "job",
+			JobRunResults: map[string]testgridanalysisapi.RawJobRunResult{
+				"1": {SetupStatus: testgridanalysisapi.Success, TestStatuses: map[string]string{"[sig-etcd] etcd members are healthy": testgridanalysisapi.Success}},
+				"2": {SetupStatus: testgridanalysisapi.Success, TestStatuses: map[string]string{"[sig-etcd] etcd members are healthy": testgridanalysisapi.Failure}},
+				"3": {SetupStatus: testgridanalysisapi.Success},
+			},
+			TestResults: map[string]testgridanalysisapi.RawTestResult{},
+		},
+	}}
+	if warnings := manager.CreateSyntheticTests(rawData); len(warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", warnings)
+	}
+
+	result := rawData.JobResults["job"].TestResults["[sig-sippy] etcd should be healthy at the end"]
+	if result.Successes != 1 || result.Failures != 1 || !result.Synthetic {
+		t.Errorf("expected one pass and one failure of a synthetic test, got %#v", result)
+	}
+	if failed := rawData.JobResults["job"].JobRunResults["2"].FailedTestNames; !reflect.DeepEqual(failed, []string{"[sig-sippy] etcd should be healthy at the end"}) {
+		t.Errorf("expected the synthetic test to fail in run 2, got %v", failed)
+	}
+}
+
+func TestNewConfigSyntheticTestManagerValidation(t *testing.T) {
+	marker := &SyntheticTestCondition{Marker: MarkerSetup, Values: []string{testgridanalysisapi.Success}}
+	tests := []struct {
+		name   string
+		config SyntheticTestConfig
+	}{
+		{name: "missing name", config: SyntheticTestConfig{SyntheticTests: []SyntheticTestDefinition{{Pass: marker}}}},
+		{name: "duplicate", config: SyntheticTestConfig{SyntheticTests: []SyntheticTestDefinition{{Name: "a"}, {Name: "a"}}}},
+		{name: "bad exclude", config: SyntheticTestConfig{SyntheticTests: []SyntheticTestDefinition{{Name: "a", ExcludeJobs: []string{"("}}}}},
+		{name: "bad jobs without setup", config: SyntheticTestConfig{JobsWithoutSetup: []string{"("}}},
+		{name: "empty condition", config: SyntheticTestConfig{SyntheticTests: []SyntheticTestDefinition{{Name: "a", Fail: &SyntheticTestCondition{}}}}},
+		{name: "two conditions", config: SyntheticTestConfig{SyntheticTests: []SyntheticTestDefinition{{Name: "a", Fail: &SyntheticTestCondition{Marker: MarkerSetup, Not: marker, Values: []string{""}}}}}},
+		{name: "missing values", config: SyntheticTestConfig{SyntheticTests: []SyntheticTestDefinition{{Name: "a", Fail: &SyntheticTestCondition{Marker: MarkerSetup}}}}},
+		{name: "unknown marker", config: SyntheticTestConfig{SyntheticTests: []SyntheticTestDefinition{{Name: "a", Fail: &SyntheticTestCondition{Marker: "bogus", Values: []string{""}}}}}},
+		{name: "bad test regex", config: SyntheticTestConfig{SyntheticTests: []SyntheticTestDefinition{{Name: "a", Fail: &SyntheticTestCondition{Test: "(", Values: []string{""}}}}}},
+		{name: "undefined condition", config: SyntheticTestConfig{SyntheticTests: []SyntheticTestDefinition{{Name: "a", Fail: &SyntheticTestCondition{Condition: "bogus"}}}}},
+		{name: "recursive condition", config: SyntheticTestConfig{Conditions: map[string]SyntheticTestCondition{
+			"a": {Not: &SyntheticTestCondition{Condition: "b"}},
+			"b": {Condition: "a"},
+		}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewConfigSyntheticTestManager(tc.config); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package testgridconversion
+
+// openshiftSyntheticTestConfig declares the synthetic tests of OpenShift jobs.  It is used when sippy is run against
+// OpenShift dashboards without a --synthetic-test-config.
+const openshiftSyntheticTestConfig = `{
+  "jobsWithoutSetup": [
+    "promote-release-openshift-machine-os-content-e2e-aws-4\\.[0-9].*",
+    "periodic-ci-openshift-origin-release-3.11-e2e-gcp",
+    "release-openshift-ocp-osd"
+  ],
+  "conditions": {
+    "setupSucceeded": {"any": [
+      {"marker": "overall", "values": ["Success"]},
+      {"marker": "setup", "values": ["Success"]}
+    ]},
+    "setupFailed": {"all": [
+      {"marker": "overall", "values": ["Failure"]},
+      {"not": {"marker": "setup", "values": ["Success"]}}
+    ]},
+    "upgradeSucceeded": {"all": [
+      {"marker": "upgradeOperators", "values": ["Success"]},
+      {"marker": "upgradeMachineConfigPools", "values": ["Success"]}
+    ]}
+  },
+  "syntheticTests": [
+    {
+      "name": "[sig-sippy] tests should finish with healthy operators",
+      "fail": {"marker": "finalOperators", "values": ["Failure"]},
+      "pass": {"marker": "finalOperators", "values": ["Success"]}
+    },
+    {
+      "name": "[sig-sippy] install should work",
+      "fail": {"all": [
+        {"not": {"condition": "setupSucceeded"}},
+        {"marker": "finalOperators", "values": ["Success", "Failure"]}
+      ]},
+      "pass": {"condition": "setupSucceeded"}
+    },
+    {
+      "name": "operator install ",
+      "perOperator": true,
+      "fail": {"all": [
+        {"not": {"condition": "setupSucceeded"}},
+        {"not": {"marker": "operator", "values": ["Success"]}}
+      ]}
+    },
+    {
+      "name": "[sig-sippy] install should not timeout",
+      "fail": {"all": [
+        {"not": {"condition": "setupSucceeded"}},
+        {"marker": "finalOperators", "values": ["Success"]}
+      ]}
+    },
+    {
+      "name": "[sig-sippy] infrastructure should work",
+      "excludeJobs": [
+        "promote-release-openshift-machine-os-content-e2e-aws-4\\.[0-9].*",
+        "periodic-ci-openshift-origin-release-3.11-e2e-gcp",
+        "release-openshift-ocp-osd"
+      ],
+      "fail": {"all": [
+        {"condition": "setupFailed"},
+        {"marker": "finalOperators", "values": [""]}
+      ]}
+    },
+    {
+      "name": "[sig-sippy] upgrade should work",
+      "when": {"marker": "upgradeStarted", "values": ["true"]},
+      "fail": {"all": [
+        {"not": {"condition": "setupFailed"}},
+        {"not": {"condition": "upgradeSucceeded"}}
+      ]},
+      "pass": {"all": [
+        {"not": {"condition": "setupFailed"}},
+        {"condition": "upgradeSucceeded"}
+      ]}
+    },
+    {
+      "name": "Operator upgrade ",
+      "perOperator": true,
+      "when": {"all": [
+        {"marker": "upgradeStarted", "values": ["true"]},
+        {"not": {"condition": "setupFailed"}}
+      ]},
+      "fail": {"all": [
+        {"not": {"condition": "upgradeSucceeded"}},
+        {"not": {"marker": "operator", "values": ["Success"]}}
+      ]}
+    },
+    {
+      "name": "[sig-sippy] openshift-tests should work",
+      "fail": {"all": [
+        {"marker": "overall", "values": ["Failure"]},
+        {"marker": "openshiftTests", "values": ["Failure"]}
+      ]},
+      "pass": {"marker": "openshiftTests", "values": ["Success"]}
+    }
+  ]
+}`
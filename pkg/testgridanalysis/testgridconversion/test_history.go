@@ -0,0 +1,145 @@
+package testgridconversion
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	testgridv1 "github.com/openshift/sippy/pkg/apis/testgrid/v1"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testidentification"
+)
+
+// TestHistoryOptions control which tests a history is computed for and how it is broken down.
+type TestHistoryOptions struct {
+	// TestNames are the tests to compute history for, after the suite prefixes are removed
+	TestNames []string
+	ByJob     bool
+	// ByVariant requires a VariantManager
+	ByVariant      bool
+	VariantManager testidentification.VariantManager
+}
+
+// dailyCounts accumulates results keyed by date
+type dailyCounts map[string]*sippyv1.TestDayResult
+
+func (d dailyCounts) add(date string, status testgridv1.TestStatus) {
+	counts, ok := d[date]
+	if !ok {
+		counts = &sippyv1.TestDayResult{Date: date}
+		d[date] = counts
+	}
+	switch status {
+	case testgridv1.TestStatusSuccess:
+		counts.Successes++
+	case testgridv1.TestStatusFlake:
+		counts.Successes++
+		counts.Flakes++
+	case testgridv1.TestStatusFailure:
+		counts.Failures++
+	}
+}
+
+func (d dailyCounts) sorted() []sippyv1.TestDayResult {
+	ret := []sippyv1.TestDayResult{}
+	for _, counts := range d {
+		result := *counts
+		if result.Successes+result.Failures > 0 {
+			result.PassPercentage = float64(result.Successes) / float64(result.Successes+result.Failures) * 100.0
+		}
+		ret = append(ret, result)
+	}
+	// dates are YYYY-MM-DD, so they sort lexically
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Date < ret[j].Date
+	})
+	return ret
+}
+
+type testHistoryCounts struct {
+	all       dailyCounts
+	byJob     map[string]dailyCounts
+	byVariant map[string]dailyCounts
+}
+
+// ComputeTestHistory walks every column of every job, not just the ones in the analysis window, and counts the results
+// of the requested tests by day.  Tests that never ran have an empty history.
+func (o TestHistoryOptions) ComputeTestHistory(testGridJobInfo []testgridv1.JobDetails) []sippyv1.TestHistory {
+	countsByTest := map[string]*testHistoryCounts{}
+	for _, testName := range o.TestNames {
+		countsByTest[testName] = &testHistoryCounts{
+			all:       dailyCounts{},
+			byJob:     map[string]dailyCounts{},
+			byVariant: map[string]dailyCounts{},
+		}
+	}
+
+	for _, job := range testGridJobInfo {
+		variants := []string{}
+		if o.ByVariant && o.VariantManager != nil {
+			variants = o.VariantManager.IdentifyVariants(job.Name)
+		}
+
+		for _, test := range job.Tests {
+			testName := test.Name
+			for _, prefix := range testSuitePrefixes {
+				testName = strings.TrimPrefix(testName, prefix)
+			}
+			counts, ok := countsByTest[testName]
+			if !ok {
+				continue
+			}
+
+			col := 0
+			for _, result := range test.Statuses {
+				for i := 0; i < result.Count; i, col = i+1, col+1 {
+					if col >= len(job.Timestamps) {
+						break
+					}
+					if result.Value != testgridv1.TestStatusSuccess && result.Value != testgridv1.TestStatusFlake && result.Value != testgridv1.TestStatusFailure {
+						continue
+					}
+					// testgrid timestamps are in milliseconds
+					date := time.Unix(0, int64(job.Timestamps[col])*int64(time.Millisecond)).UTC().Format("2006-01-02")
+
+					counts.all.add(date, result.Value)
+					if o.ByJob {
+						if _, ok := counts.byJob[job.Name]; !ok {
+							counts.byJob[job.Name] = dailyCounts{}
+						}
+						counts.byJob[job.Name].add(date, result.Value)
+					}
+					for _, variant := range variants {
+						if _, ok := counts.byVariant[variant]; !ok {
+							counts.byVariant[variant] = dailyCounts{}
+						}
+						counts.byVariant[variant].add(date, result.Value)
+					}
+				}
+			}
+		}
+	}
+
+	ret := []sippyv1.TestHistory{}
+	for _, testName := range o.TestNames {
+		counts := countsByTest[testName]
+		history := sippyv1.TestHistory{
+			TestName: testName,
+			Days:     counts.all.sorted(),
+		}
+		if o.ByJob {
+			history.ByJob = map[string][]sippyv1.TestDayResult{}
+			for jobName, jobCounts := range counts.byJob {
+				history.ByJob[jobName] = jobCounts.sorted()
+			}
+		}
+		if o.ByVariant {
+			history.ByVariant = map[string][]sippyv1.TestDayResult{}
+			for variant, variantCounts := range counts.byVariant {
+				history.ByVariant[variant] = variantCounts.sorted()
+			}
+		}
+		ret = append(ret, history)
+	}
+	return ret
+}
@@ -0,0 +1,91 @@
+package testgridconversion
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	testgridv1 "github.com/openshift/sippy/pkg/apis/testgrid/v1"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testidentification"
+)
+
+func TestComputeTestHistory(t *testing.T) {
+	millis := func(year int, month time.Month, day, hour int) int {
+		return int(time.Date(year, month, day, hour, 0, 0, 0, time.UTC).UnixNano() / int64(time.Millisecond))
+	}
+	// testgrid columns are newest first
+	jobs := []testgridv1.JobDetails{
+		{
+			Name:       "job-aws",
+			Timestamps: []int{millis(2021, 1, 2, 10), millis(2021, 1, 2, 1), millis(2021, 1, 1, 20), millis(2021, 1, 1, 8)},
+			Tests: []testgridv1.Test{
+				{
+					Name: "openshift-tests.test-a",
+					Statuses: []testgridv1.TestResult{
+						{Count: 1, Value: testgridv1.TestStatusFailure},
+						{Count: 1, Value: testgridv1.TestStatusFlake},
+						{Count: 1, Value: testgridv1.TestStatusAbsent},
+						{Count: 1, Value: testgridv1.TestStatusSuccess},
+					},
+				},
+				{
+					Name:     "test-b",
+					Statuses: []testgridv1.TestResult{{Count: 4, Value: testgridv1.TestStatusFailure}},
+				},
+			},
+		},
+		{
+			Name:       "job-gcp",
+			Timestamps: []int{millis(2021, 1, 2, 5)},
+			Tests: []testgridv1.Test{
+				{
+					Name:     "test-a",
+					Statuses: []testgridv1.TestResult{{Count: 1, Value: testgridv1.TestStatusSuccess}},
+				},
+			},
+		},
+	}
+
+	variantManager, err := testidentification.NewConfigVariantManager(testidentification.VariantConfig{
+		Variants: []testidentification.VariantDefinition{
+			{Name: "aws", Jobs: []string{"job-aws"}},
+			{Name: "gcp", Jobs: []string{"job-gcp"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := TestHistoryOptions{
+		TestNames:      []string{"test-a", "test-missing"},
+		ByJob:          true,
+		ByVariant:      true,
+		VariantManager: variantManager,
+	}
+	histories := options.ComputeTestHistory(jobs)
+	if len(histories) != 2 {
+		t.Fatalf("expected two histories, got %#v", histories)
+	}
+
+	expectedDays := []sippyv1.TestDayResult{
+		{Date: "2021-01-01", Successes: 1, PassPercentage: 100},
+		{Date: "2021-01-02", Successes: 2, Failures: 1, Flakes: 1, PassPercentage: float64(2) / float64(3) * 100.0},
+	}
+	if !reflect.DeepEqual(histories[0].Days, expectedDays) {
+		t.Errorf("expected %#v, got %#v", expectedDays, histories[0].Days)
+	}
+	expectedGCP := []sippyv1.TestDayResult{{Date: "2021-01-02", Successes: 1, PassPercentage: 100}}
+	if !reflect.DeepEqual(histories[0].ByJob["job-gcp"], expectedGCP) {
+		t.Errorf("expected %#v, got %#v", expectedGCP, histories[0].ByJob["job-gcp"])
+	}
+	if !reflect.DeepEqual(histories[0].ByVariant["gcp"], expectedGCP) {
+		t.Errorf("expected %#v, got %#v", expectedGCP, histories[0].ByVariant["gcp"])
+	}
+	if len(histories[0].ByVariant["aws"]) != 2 {
+		t.Errorf("expected two days for aws, got %#v", histories[0].ByVariant["aws"])
+	}
+
+	if histories[1].TestName != "test-missing" || len(histories[1].Days) != 0 {
+		t.Errorf("expected an empty history for a test that never ran, got %#v", histories[1])
+	}
+}
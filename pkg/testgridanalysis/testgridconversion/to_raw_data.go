@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -23,20 +24,45 @@ type SythenticTestManager interface {
 	CreateSyntheticTests(rawJobResults testgridanalysisapi.RawData) []string
 }
 
+// RawTestWatcher is a SythenticTestManager that derives synthetic tests from the results of raw tests.  The status of
+// every watched test is recorded in the TestStatuses of each job run.
+type RawTestWatcher interface {
+	SythenticTestManager
+
+	IsWatchedTest(testName string) bool
+}
+
+// DefaultProwURL is the prow instance that job runs link to unless another one is configured.
+const DefaultProwURL = "https://prow.svc.ci.openshift.org"
+
 type ProcessingOptions struct {
 	SythenticTestManager SythenticTestManager
 	StartDay             int
 	NumDays              int
+	// ProwURL is the base url used to build job run urls.  If empty, DefaultProwURL is used.
+	ProwURL string
+}
+
+// JobRunURL returns the url of the prow page for a single run of a job.  If prowURL is empty, DefaultProwURL is used.
+func JobRunURL(prowURL string, job testgridv1.JobDetails, col int) string {
+	if len(prowURL) == 0 {
+		prowURL = DefaultProwURL
+	}
+	return fmt.Sprintf("%s/view/gcs/%s/%s", strings.TrimSuffix(prowURL, "/"), job.Query, job.ChangeLists[col])
 }
 
 // returns the raw data and a list of warnings encountered processing the data.
 func (o ProcessingOptions) ProcessTestGridDataIntoRawJobResults(testGridJobInfo []testgridv1.JobDetails) (testgridanalysisapi.RawData, []string) {
 	rawJobResults := testgridanalysisapi.RawData{JobResults: map[string]testgridanalysisapi.RawJobResult{}}
+	isWatchedTest := func(string) bool { return false }
+	if watcher, ok := o.SythenticTestManager.(RawTestWatcher); ok {
+		isWatchedTest = watcher.IsWatchedTest
+	}
 
 	for _, jobDetails := range testGridJobInfo {
 		klog.V(2).Infof("processing test details for job %s\n", jobDetails.Name)
 		startCol, endCol := computeLookback(o.StartDay, o.NumDays, jobDetails.Timestamps)
-		processJobDetails(rawJobResults, jobDetails, startCol, endCol)
+		processJobDetails(rawJobResults, jobDetails, startCol, endCol, o.ProwURL, isWatchedTest)
 	}
 
 	// now that we have all the JobRunResults, use them to create synthetic tests for install, upgrade, and infra
@@ -45,7 +71,10 @@ func (o ProcessingOptions) ProcessTestGridDataIntoRawJobResults(testGridJobInfo
 	return rawJobResults, warnings
 }
 
-func processJobDetails(rawJobResults testgridanalysisapi.RawData, job testgridv1.JobDetails, startCol, endCol int) {
+func processJobDetails(rawJobResults testgridanalysisapi.RawData, job testgridv1.JobDetails, startCol, endCol int, prowURL string, isWatchedTest func(string) bool) {
+	// a test may have several rows in the job, which cover the same runs, so its run pattern is built once every row
+	// has been merged into runOutcomes
+	runOutcomes := map[string]testRunOutcomes{}
 	for i, test := range job.Tests {
 		klog.V(4).Infof("Analyzing results from %d to %d from job %s for test %s\n", startCol, endCol, job.Name, test.Name)
 		//test.Name = strings.TrimSpace(tagStripRegex.ReplaceAllString(test.Name, ""))
@@ -53,8 +82,44 @@ func processJobDetails(rawJobResults testgridanalysisapi.RawData, job testgridv1
 			test.Name = strings.TrimPrefix(test.Name, prefix)
 		}
 		job.Tests[i] = test
-		processTest(rawJobResults, job, test, startCol, endCol)
+		processTest(rawJobResults, job, test, startCol, endCol, prowURL, isWatchedTest(test.Name), runOutcomes)
+	}
+
+	jobResult, ok := rawJobResults.JobResults[job.Name]
+	if !ok {
+		return
+	}
+	for testName, outcomes := range runOutcomes {
+		result := jobResult.TestResults[testName]
+		result.RunPattern = outcomes.runPattern()
+		jobResult.TestResults[testName] = result
+	}
+}
+
+// testRunOutcomes holds whether a test passed in each run of a job, by testgrid column.
+type testRunOutcomes map[int]bool
+
+// add records the outcome of the test in a run.  A test that failed in any of its rows failed the run.
+func (o testRunOutcomes) add(col int, passed bool) {
+	if previous, ok := o[col]; ok {
+		passed = passed && previous
+	}
+	o[col] = passed
+}
+
+// runPattern returns the run pattern of the outcomes.
+func (o testRunOutcomes) runPattern() testgridanalysisapi.RawTestRunPattern {
+	cols := []int{}
+	for col := range o {
+		cols = append(cols, col)
+	}
+	sort.Ints(cols)
+
+	pattern := runPatternBuilder{}
+	for _, col := range cols {
+		pattern.add(o[col])
 	}
+	return pattern.pattern
 }
 
 func computeLookback(startDay, numDays int, timestamps []int) (int, int) {
@@ -102,8 +167,10 @@ var testSuitePrefixes = []string{
 // ignoreTestRegex is used to strip o ut tests that don't have predictive or diagnostic value.  We don't want to show these in our data.
 var ignoreTestRegex = regexp.MustCompile(`Run multi-stage test|operator.Import the release payload|operator.Import a release payload|operator.Run template|operator.Build image|Monitor cluster while tests execute|Overall|job.initialize|\[sig-arch\]\[Feature:ClusterUpgrade\] Cluster should remain functional during upgrade`)
 
-// processTestToJobRunResults adds the tests to the provided jobresult to the provided JobResult and returns the passed, failed, flaked for the test
-func processTestToJobRunResults(jobResult testgridanalysisapi.RawJobResult, job testgridv1.JobDetails, test testgridv1.Test, startCol, endCol int) (passed int, failed int, flaked int) {
+// processTestToJobRunResults adds the tests to the provided jobresult to the provided JobResult and returns the passed, failed, flaked for the test.
+// The outcome of the test in each run is added to runOutcomes.
+func processTestToJobRunResults(jobResult testgridanalysisapi.RawJobResult, job testgridv1.JobDetails, test testgridv1.Test, startCol, endCol int, prowURL string, watched bool, runOutcomes map[string]testRunOutcomes) (passed int, failed int, flaked int) {
+	outcomes := testRunOutcomes{}
 	col := 0
 	for _, result := range test.Statuses {
 		if col > endCol {
@@ -131,15 +198,17 @@ func processTestToJobRunResults(jobResult testgridanalysisapi.RawJobResult, job
 		case testgridv1.TestStatusSuccess, testgridv1.TestStatusFlake: // success, flake(failed one or more times but ultimately succeeded)
 			for i := col; i < col+remaining && i < endCol; i++ {
 				passed++
+				outcomes.add(i, true)
 				if result.Value == testgridv1.TestStatusFlake {
 					flaked++
 				}
-				joburl := fmt.Sprintf("https://prow.svc.ci.openshift.org/view/gcs/%s/%s", job.Query, job.ChangeLists[i])
+				joburl := JobRunURL(prowURL, job, i)
 				jrr, ok := jobResult.JobRunResults[joburl]
 				if !ok {
 					jrr = testgridanalysisapi.RawJobRunResult{
 						Job:       job.Name,
 						JobRunURL: joburl,
+						Timestamp: job.Timestamps[i],
 					}
 				}
 				switch {
@@ -167,17 +236,22 @@ func processTestToJobRunResults(jobResult testgridanalysisapi.RawJobResult, job
 						jrr.OpenShiftTestsStatus = testgridanalysisapi.Success
 					}
 				}
+				if watched {
+					recordTestStatus(&jrr, test.Name, testgridanalysisapi.Success)
+				}
 				jobResult.JobRunResults[joburl] = jrr
 			}
 		case testgridv1.TestStatusFailure:
 			for i := col; i < col+remaining && i < endCol; i++ {
 				failed++
-				joburl := fmt.Sprintf("https://prow.svc.ci.openshift.org/view/gcs/%s/%s", job.Query, job.ChangeLists[i])
+				outcomes.add(i, false)
+				joburl := JobRunURL(prowURL, job, i)
 				jrr, ok := jobResult.JobRunResults[joburl]
 				if !ok {
 					jrr = testgridanalysisapi.RawJobRunResult{
 						Job:       job.Name,
 						JobRunURL: joburl,
+						Timestamp: job.Timestamps[i],
 					}
 				}
 				// only add the failing test and name if it has predictive value.  We excluded all the non-predictive ones above except for these
@@ -206,6 +280,9 @@ func processTestToJobRunResults(jobResult testgridanalysisapi.RawJobResult, job
 				case testidentification.IsOpenShiftTest(test.Name):
 					jrr.OpenShiftTestsStatus = testgridanalysisapi.Failure
 				}
+				if watched {
+					recordTestStatus(&jrr, test.Name, testgridanalysisapi.Failure)
+				}
 				jobResult.JobRunResults[joburl] = jrr
 			}
 		}
@@ -228,11 +305,49 @@ func processTestToJobRunResults(jobResult testgridanalysisapi.RawJobResult, job
 	}
 
 	addTestResult(jobResult.TestResults, testName, passed, failed, flaked)
+	if _, ok := runOutcomes[testName]; !ok {
+		runOutcomes[testName] = testRunOutcomes{}
+	}
+	for col, passed := range outcomes {
+		runOutcomes[testName].add(col, passed)
+	}
 
 	return
 }
 
-func processTest(rawJobResults testgridanalysisapi.RawData, job testgridv1.JobDetails, test testgridv1.Test, startCol, endCol int) {
+// runPatternBuilder builds the RawTestRunPattern of a test from its runs, newest run first, which is the order of the
+// testgrid columns.
+type runPatternBuilder struct {
+	pattern    testgridanalysisapi.RawTestRunPattern
+	runs       int
+	lastPassed bool
+	sawPass    bool
+	streak     int
+}
+
+func (b *runPatternBuilder) add(passed bool) {
+	if b.runs > 0 && passed != b.lastPassed {
+		b.pattern.Transitions++
+	}
+	b.runs++
+	b.lastPassed = passed
+
+	if passed {
+		b.sawPass = true
+		b.streak = 0
+		return
+	}
+	b.streak++
+	// until we see a pass, the failures are the most recent runs
+	if !b.sawPass {
+		b.pattern.CurrentFailureStreak = b.streak
+	}
+	if b.streak > b.pattern.LongestFailureStreak {
+		b.pattern.LongestFailureStreak = b.streak
+	}
+}
+
+func processTest(rawJobResults testgridanalysisapi.RawData, job testgridv1.JobDetails, test testgridv1.Test, startCol, endCol int, prowURL string, watched bool, runOutcomes map[string]testRunOutcomes) {
 	// strip out tests that don't have predictive or diagnostic value
 	// we have to know about overall to be able to set the global success or failure.
 	// we have to know about container setup to be able to set infra failures
@@ -251,12 +366,23 @@ func processTest(rawJobResults testgridanalysisapi.RawData, job testgridv1.JobDe
 		}
 	}
 
-	processTestToJobRunResults(jobResult, job, test, startCol, endCol)
+	processTestToJobRunResults(jobResult, job, test, startCol, endCol, prowURL, watched, runOutcomes)
 
 	// we have mutated, so assign back to our intermediate value
 	rawJobResults.JobResults[job.Name] = jobResult
 }
 
+// recordTestStatus records the status of a watched test in a job run.  A failure is never overwritten, so that a test
+// that failed and then passed on retry is still a failure.
+func recordTestStatus(jrr *testgridanalysisapi.RawJobRunResult, testName, status string) {
+	if jrr.TestStatuses == nil {
+		jrr.TestStatuses = map[string]string{}
+	}
+	if jrr.TestStatuses[testName] != testgridanalysisapi.Failure {
+		jrr.TestStatuses[testName] = status
+	}
+}
+
 func addTestResult(testResults map[string]testgridanalysisapi.RawTestResult, testName string, passed, failed, flaked int) {
 	result, ok := testResults[testName]
 	if !ok {
@@ -269,3 +395,12 @@ func addTestResult(testResults map[string]testgridanalysisapi.RawTestResult, tes
 
 	testResults[testName] = result
 }
+
+// addSyntheticTestResult adds the result of a synthetic test.  Job runs are not visited in order, so synthetic tests
+// have no run pattern.
+func addSyntheticTestResult(testResults map[string]testgridanalysisapi.RawTestResult, testName string, passed, failed int) {
+	addTestResult(testResults, testName, passed, failed, 0)
+	result := testResults[testName]
+	result.Synthetic = true
+	testResults[testName] = result
+}
@@ -0,0 +1,67 @@
+package testgridconversion
+
+import (
+	"testing"
+
+	testgridv1 "github.com/openshift/sippy/pkg/apis/testgrid/v1"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testgridanalysisapi"
+)
+
+func TestRunPatternBuilder(t *testing.T) {
+	tests := []struct {
+		name     string
+		runs     []bool // newest first
+		expected testgridanalysisapi.RawTestRunPattern
+	}{
+		{name: "no runs", expected: testgridanalysisapi.RawTestRunPattern{}},
+		{name: "all passing", runs: []bool{true, true, true}, expected: testgridanalysisapi.RawTestRunPattern{}},
+		{
+			name:     "currently failing",
+			runs:     []bool{false, false, true, false, true, true},
+			expected: testgridanalysisapi.RawTestRunPattern{Transitions: 3, CurrentFailureStreak: 2, LongestFailureStreak: 2},
+		},
+		{
+			name:     "failed in the past",
+			runs:     []bool{true, false, false, false, true},
+			expected: testgridanalysisapi.RawTestRunPattern{Transitions: 2, LongestFailureStreak: 3},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := runPatternBuilder{}
+			for _, passed := range tc.runs {
+				builder.add(passed)
+			}
+			if builder.pattern != tc.expected {
+				t.Errorf("expected %#v, got %#v", tc.expected, builder.pattern)
+			}
+		})
+	}
+}
+
+func TestRunPatternOfRepeatedRows(t *testing.T) {
+	failure := testgridv1.TestResult{Count: 1, Value: testgridv1.TestStatusFailure}
+	success := testgridv1.TestResult{Count: 1, Value: testgridv1.TestStatusSuccess}
+	job := testgridv1.JobDetails{
+		Name:        "job",
+		Query:       "origin-ci-test/logs/job",
+		ChangeLists: []string{"3", "2", "1"},
+		Timestamps:  []int{3, 2, 1},
+		// both rows cover the same three runs, and every run failed in at least one of them
+		Tests: []testgridv1.Test{
+			{Name: "[sig-network] dns", Statuses: []testgridv1.TestResult{failure, success, failure}},
+			{Name: "[sig-network] dns", Statuses: []testgridv1.TestResult{success, failure, failure}},
+		},
+	}
+	rawData := testgridanalysisapi.RawData{JobResults: map[string]testgridanalysisapi.RawJobResult{}}
+	processJobDetails(rawData, job, 0, 3, "", func(string) bool { return false })
+
+	result := rawData.JobResults["job"].TestResults["[sig-network] dns"]
+	expected := testgridanalysisapi.RawTestRunPattern{CurrentFailureStreak: 3, LongestFailureStreak: 3}
+	if result.RunPattern != expected {
+		t.Errorf("expected the rows to be merged run by run into %#v, got %#v", expected, result.RunPattern)
+	}
+	if result.Successes != 2 || result.Failures != 4 {
+		t.Errorf("expected the results of both rows to be counted, got %d successes and %d failures", result.Successes, result.Failures)
+	}
+}
@@ -0,0 +1,328 @@
+package testgridhelpers
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/openshift/sippy/pkg/util"
+	"k8s.io/klog"
+)
+
+// FetchOptions controls how testgrid data is downloaded to disk.
+type FetchOptions struct {
+	// StoragePath is the directory where the testgrid data and the fetch manifest are written
+	StoragePath string
+	// JobFilter is a regex run against job names. Only matching jobs are fetched.
+	JobFilter *regexp.Regexp
+	// Workers is the maximum number of concurrent requests made to testgrid
+	Workers int
+	// MaxRetries is the number of times a failed request is retried before it is recorded as a failure
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry.  It doubles on every subsequent retry.
+	RetryBackoff time.Duration
+	// Resume skips dashboards and jobs that were already fetched successfully by a previous fetch that was interrupted
+	// or that failed to fetch some pages
+	Resume bool
+	// Client is used for all requests.  If nil, http.DefaultClient is used.
+	Client *http.Client
+	// TestGridEndpoint is the testgrid instance to fetch from.  The zero value fetches from DefaultTestGridURL.
+	TestGridEndpoint Endpoint
+}
+
+// Fetcher downloads testgrid dashboard summaries and job details with a bounded pool of workers.
+// Every file is written atomically, and progress is recorded in a FetchManifest in the storage directory.
+type Fetcher struct {
+	options FetchOptions
+	client  *http.Client
+
+	manifestLock sync.Mutex
+	manifest     *FetchManifest
+	// manifestWritten is when the manifest was last written, and manifestDirty whether it changed since
+	manifestWritten time.Time
+	manifestDirty   bool
+}
+
+// manifestWriteInterval is the least time between two writes of the manifest during a fetch, so that the workers do
+// not wait on each other to rewrite it after every page.  An interrupted fetch loses track of at most this much work,
+// which a resumed fetch downloads again.
+const manifestWriteInterval = time.Second
+
+func NewFetcher(options FetchOptions) *Fetcher {
+	if options.Workers < 1 {
+		options.Workers = 1
+	}
+	if options.MaxRetries < 0 {
+		options.MaxRetries = 0
+	}
+	client := options.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &Fetcher{
+		options: options,
+		client:  client,
+	}
+}
+
+type jobFetchTask struct {
+	dashboard string
+	jobName   string
+}
+
+// Fetch downloads the job summaries for every dashboard and the job details for every relevant job on those dashboards.
+// Failures to fetch individual pages do not stop the fetch. They are recorded in the returned manifest and summarized in
+// the returned error.
+func (f *Fetcher) Fetch(dashboards []string) (*FetchManifest, error) {
+	if err := os.MkdirAll(f.options.StoragePath, 0755); err != nil {
+		return nil, fmt.Errorf("could not create storage directory %s: %v", f.options.StoragePath, err)
+	}
+
+	if err := f.initManifest(); err != nil {
+		return nil, err
+	}
+
+	// fetch all the dashboard summaries first, they tell us which jobs exist.
+	dashboardsToFetch := []string{}
+	for _, dashboard := range dashboards {
+		if f.options.Resume && f.manifest.dashboardSucceeded(dashboard) {
+			klog.V(2).Infof("Skipping dashboard %s, it was fetched by the previous run", dashboard)
+			continue
+		}
+		dashboardsToFetch = append(dashboardsToFetch, dashboard)
+	}
+	util.RunWorkers(f.options.Workers, len(dashboardsToFetch), func(i int) {
+		f.fetchJobSummaries(dashboardsToFetch[i])
+	})
+	f.flushManifest()
+
+	jobsToFetch := []jobFetchTask{}
+	for _, dashboard := range dashboards {
+		if !f.manifest.dashboardSucceeded(dashboard) {
+			continue
+		}
+		jobs, _, err := loadJobSummaries(dashboard, f.options.StoragePath)
+		if err != nil {
+			klog.Errorf("Error loading dashboard page %s: %v\n", dashboard, err)
+			f.recordDashboard(dashboard, FetchRecord{
+				URL:       f.options.TestGridEndpoint.URLForJobSummary(dashboard).String(),
+				FetchTime: time.Now(),
+				Status:    FetchFailed,
+				Error:     err.Error(),
+			})
+			continue
+		}
+
+		jobNames := []string{}
+		for jobName, job := range jobs {
+			if !util.RelevantJob(jobName, job.OverallStatus, f.options.JobFilter) {
+				continue
+			}
+			if f.options.Resume && f.manifest.jobSucceeded(dashboard, jobName) {
+				klog.V(4).Infof("Skipping job %s, it was fetched by the previous run", jobName)
+				continue
+			}
+			jobNames = append(jobNames, jobName)
+		}
+		sort.Strings(jobNames)
+		for _, jobName := range jobNames {
+			jobsToFetch = append(jobsToFetch, jobFetchTask{dashboard: dashboard, jobName: jobName})
+		}
+	}
+	util.RunWorkers(f.options.Workers, len(jobsToFetch), func(i int) {
+		f.fetchJobDetails(jobsToFetch[i].dashboard, jobsToFetch[i].jobName)
+	})
+
+	f.manifestLock.Lock()
+	defer f.manifestLock.Unlock()
+	completionTime := time.Now()
+	f.manifest.CompletionTime = &completionTime
+	if err := writeFetchManifest(f.options.StoragePath, f.manifest); err != nil {
+		return f.manifest, fmt.Errorf("could not write fetch manifest: %v", err)
+	}
+
+	failed, total := 0, 0
+	for _, dashboardRecord := range f.manifest.Dashboards {
+		total++
+		if dashboardRecord.Status != FetchSucceeded {
+			failed++
+		}
+		for _, jobRecord := range dashboardRecord.Jobs {
+			total++
+			if jobRecord.Status != FetchSucceeded {
+				failed++
+			}
+		}
+	}
+	if failed > 0 {
+		return f.manifest, fmt.Errorf("failed to fetch %d of %d testgrid pages, see %s for details",
+			failed, total, filepath.Join(f.options.StoragePath, ManifestFilename))
+	}
+	return f.manifest, nil
+}
+
+// initManifest starts a new manifest, or continues the previous one if we are resuming a fetch that was interrupted or
+// failed to fetch some pages.
+func (f *Fetcher) initManifest() error {
+	f.manifestLock.Lock()
+	defer f.manifestLock.Unlock()
+
+	if f.options.Resume {
+		previous, err := LoadFetchManifest(f.options.StoragePath)
+		if err != nil {
+			return err
+		}
+		if previous != nil && (!previous.IsComplete() || previous.hasFailures()) {
+			klog.Infof("Resuming fetch started at %v", previous.StartTime)
+			previous.CompletionTime = nil
+			f.manifest = previous
+			return nil
+		}
+	}
+
+	f.manifest = newFetchManifest()
+	if err := writeFetchManifest(f.options.StoragePath, f.manifest); err != nil {
+		return err
+	}
+	f.manifestWritten = time.Now()
+	return nil
+}
+
+func (f *Fetcher) fetchJobSummaries(dashboard string) {
+	url := f.options.TestGridEndpoint.URLForJobSummary(dashboard).String()
+	record := f.download(url, URLForJobSummary(dashboard).String())
+	if record.Status != FetchSucceeded {
+		klog.Errorf("Error fetching dashboard page %s: %v\n", dashboard, record.Error)
+	}
+	f.recordDashboard(dashboard, record)
+}
+
+func (f *Fetcher) fetchJobDetails(dashboard, jobName string) {
+	url := f.options.TestGridEndpoint.URLForJobDetails(dashboard, jobName).String()
+	record := f.download(url, URLForJobDetails(dashboard, jobName).String())
+	if record.Status != FetchSucceeded {
+		klog.Errorf("Error fetching job details for %s: %v\n", jobName, record.Error)
+	}
+	f.recordJob(dashboard, jobName, record)
+}
+
+func (f *Fetcher) recordDashboard(dashboard string, record FetchRecord) {
+	f.manifestLock.Lock()
+	defer f.manifestLock.Unlock()
+
+	dashboardRecord, ok := f.manifest.Dashboards[dashboard]
+	if !ok {
+		dashboardRecord = &DashboardFetchRecord{}
+		f.manifest.Dashboards[dashboard] = dashboardRecord
+	}
+	dashboardRecord.FetchRecord = record
+	f.manifestChangedLocked()
+}
+
+func (f *Fetcher) recordJob(dashboard, jobName string, record FetchRecord) {
+	f.manifestLock.Lock()
+	defer f.manifestLock.Unlock()
+
+	dashboardRecord, ok := f.manifest.Dashboards[dashboard]
+	if !ok {
+		dashboardRecord = &DashboardFetchRecord{}
+		f.manifest.Dashboards[dashboard] = dashboardRecord
+	}
+	if dashboardRecord.Jobs == nil {
+		dashboardRecord.Jobs = map[string]*FetchRecord{}
+	}
+	dashboardRecord.Jobs[jobName] = &record
+	f.manifestChangedLocked()
+}
+
+// manifestChangedLocked persists the manifest so an interruption doesn't lose track of completed work, unless it was
+// written less than manifestWriteInterval ago.  The caller must hold the manifestLock.
+func (f *Fetcher) manifestChangedLocked() {
+	f.manifestDirty = true
+	if time.Since(f.manifestWritten) >= manifestWriteInterval {
+		f.writeManifestLocked()
+	}
+}
+
+// flushManifest persists the changes to the manifest that were not written yet.
+func (f *Fetcher) flushManifest() {
+	f.manifestLock.Lock()
+	defer f.manifestLock.Unlock()
+
+	if f.manifestDirty {
+		f.writeManifestLocked()
+	}
+}
+
+// writeManifestLocked persists the manifest.  The caller must hold the manifestLock.
+func (f *Fetcher) writeManifestLocked() {
+	if err := writeFetchManifest(f.options.StoragePath, f.manifest); err != nil {
+		klog.Errorf("Error writing fetch manifest: %v", err)
+		return
+	}
+	f.manifestWritten = time.Now()
+	f.manifestDirty = false
+}
+
+// download fetches the url with retries and atomically writes it to the storage path.  The file is named after the
+// canonicalURL, so the data on disk is loaded the same way regardless of which testgrid instance it was fetched from.
+func (f *Fetcher) download(url, canonicalURL string) FetchRecord {
+	record := FetchRecord{URL: url}
+
+	backoff := f.options.RetryBackoff
+	for {
+		record.Attempts++
+		content, retryable, err := f.get(url)
+		if err == nil {
+			filename := filepath.Join(f.options.StoragePath, normalizeURL(canonicalURL))
+			err = util.WriteFileAtomically(filename, content)
+			retryable = false
+		}
+		record.FetchTime = time.Now()
+		if err == nil {
+			record.Status = FetchSucceeded
+			record.Error = ""
+			return record
+		}
+
+		record.Status = FetchFailed
+		record.Error = err.Error()
+		if !retryable || record.Attempts > f.options.MaxRetries {
+			return record
+		}
+
+		klog.V(2).Infof("Retrying %s in %v after attempt %d failed: %v", url, backoff, record.Attempts, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// get returns the body of the url and, on failure, whether the request is worth retrying.
+func (f *Fetcher) get(url string) ([]byte, bool, error) {
+	resp, err := f.client.Get(url)
+	if err != nil {
+		return nil, true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// include the start of the body, testgrid errors are usually short and explain the problem
+		snippet, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		retryable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		return nil, retryable, fmt.Errorf("non-200 response code fetching %v: %v: %s", url, resp.Status, string(snippet))
+	}
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, true, fmt.Errorf("error reading response from %v: %v", url, err)
+	}
+	return content, false, nil
+}
@@ -0,0 +1,295 @@
+package testgridhelpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeTestGrid stands in for testgrid.k8s.io.  It serves a summary for every dashboard and job details for every job.
+type fakeTestGrid struct {
+	lock sync.Mutex
+	// jobs is keyed by dashboard
+	jobs map[string][]string
+	// failuresBeforeSuccess is keyed by the tab (job) name and holds how many 500s to serve before succeeding
+	failuresBeforeSuccess map[string]int
+	// alwaysFail is keyed by tab (job) name and causes a 404 on every request
+	alwaysFail map[string]bool
+	requests   map[string]int
+}
+
+func (s *fakeTestGrid) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.requests[req.URL.RequestURI()]++
+
+	switch {
+	case strings.HasSuffix(req.URL.Path, "/summary"):
+		dashboard := strings.TrimSuffix(strings.TrimPrefix(req.URL.Path, "/"), "/summary")
+		summary := map[string]map[string]string{}
+		for _, job := range s.jobs[dashboard] {
+			summary[job] = map[string]string{"overall_status": "FAILING"}
+		}
+		json.NewEncoder(w).Encode(summary)
+
+	case strings.HasSuffix(req.URL.Path, "/table"):
+		tab := req.URL.Query().Get("tab")
+		if s.alwaysFail[tab] {
+			http.Error(w, "no such tab", http.StatusNotFound)
+			return
+		}
+		if s.failuresBeforeSuccess[tab] > 0 {
+			s.failuresBeforeSuccess[tab]--
+			http.Error(w, "try again", http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(w, `{"tests":[{"name":"Overall","statuses":[{"count":1,"value":1}]}],"timestamps":[1],"changelists":["1"],"query":"origin-ci-test/logs/%s"}`, tab)
+
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func newFakeTestGridFetcher(t *testing.T, fake *fakeTestGrid, storagePath string, resume bool) *Fetcher {
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	endpoint, err := NewEndpoint(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewFetcher(FetchOptions{
+		StoragePath:      storagePath,
+		Workers:          3,
+		MaxRetries:       2,
+		RetryBackoff:     time.Millisecond,
+		Resume:           resume,
+		TestGridEndpoint: endpoint,
+	})
+}
+
+func TestFetch(t *testing.T) {
+	fake := &fakeTestGrid{
+		jobs: map[string][]string{
+			"dashboard-a": {"job-1", "job-2", "job-3"},
+			"dashboard-b": {"job-4"},
+		},
+		failuresBeforeSuccess: map[string]int{"job-2": 2},
+		requests:              map[string]int{},
+	}
+	storagePath, err := ioutil.TempDir("", "fetch-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(storagePath)
+
+	manifest, err := newFakeTestGridFetcher(t, fake, storagePath, false).Fetch([]string{"dashboard-a", "dashboard-b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !manifest.IsComplete() {
+		t.Errorf("expected manifest to be complete")
+	}
+	if got := manifest.Dashboards["dashboard-a"].Jobs["job-2"].Attempts; got != 3 {
+		t.Errorf("expected 3 attempts for job-2, got %d", got)
+	}
+
+	details, lastUpdateTime := LoadTestGridDataFromDisk(storagePath, []string{"dashboard-a", "dashboard-b"}, nil, Endpoint{})
+	if len(details) != 4 {
+		t.Errorf("expected 4 jobs loaded from disk, got %d", len(details))
+	}
+	if lastUpdateTime.IsZero() {
+		t.Errorf("expected a last update time")
+	}
+
+	onDisk, err := LoadFetchManifest(storagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !onDisk.IsComplete() || len(onDisk.Dashboards) != 2 {
+		t.Errorf("unexpected manifest on disk: %#v", onDisk)
+	}
+
+	// no temporary files may be left behind
+	files, _ := ioutil.ReadDir(storagePath)
+	for _, file := range files {
+		if strings.Contains(file.Name(), ".tmp-") {
+			t.Errorf("temporary file left behind: %s", file.Name())
+		}
+	}
+}
+
+func TestFetchRecordsFailures(t *testing.T) {
+	fake := &fakeTestGrid{
+		jobs: map[string][]string{
+			"dashboard-a": {"job-1", "job-2"},
+		},
+		alwaysFail: map[string]bool{"job-2": true},
+		requests:   map[string]int{},
+	}
+	storagePath, err := ioutil.TempDir("", "fetch-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(storagePath)
+
+	manifest, err := newFakeTestGridFetcher(t, fake, storagePath, false).Fetch([]string{"dashboard-a"})
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	record := manifest.Dashboards["dashboard-a"].Jobs["job-2"]
+	if record.Status != FetchFailed || len(record.Error) == 0 {
+		t.Errorf("expected failure to be recorded, got %#v", record)
+	}
+	// a 404 is not worth retrying
+	if record.Attempts != 1 {
+		t.Errorf("expected a single attempt, got %d", record.Attempts)
+	}
+	if _, err := os.Stat(filepath.Join(storagePath, normalizeURL(URLForJobDetails("dashboard-a", "job-2").String()))); !os.IsNotExist(err) {
+		t.Errorf("expected no file for the failed job, got %v", err)
+	}
+}
+
+func TestFetchResume(t *testing.T) {
+	storagePath, err := ioutil.TempDir("", "fetch-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(storagePath)
+
+	// simulate an interrupted run that fetched the dashboard and job-1, but never finished.
+	fake := &fakeTestGrid{
+		jobs: map[string][]string{
+			"dashboard-a": {"job-1", "job-2"},
+		},
+		requests: map[string]int{},
+	}
+	first := newFakeTestGridFetcher(t, fake, storagePath, false)
+	if err := first.initManifest(); err != nil {
+		t.Fatal(err)
+	}
+	first.fetchJobSummaries("dashboard-a")
+	first.fetchJobDetails("dashboard-a", "job-1")
+	first.flushManifest()
+
+	fake.requests = map[string]int{}
+	manifest, err := newFakeTestGridFetcher(t, fake, storagePath, true).Fetch([]string{"dashboard-a"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if manifest.Dashboards["dashboard-a"].Jobs["job-1"].Status != FetchSucceeded || manifest.Dashboards["dashboard-a"].Jobs["job-2"].Status != FetchSucceeded {
+		t.Errorf("expected both jobs to be recorded as fetched: %#v", manifest.Dashboards["dashboard-a"].Jobs)
+	}
+	if len(fake.requests) != 1 {
+		t.Errorf("expected only job-2 to be fetched on resume, got %v", fake.requests)
+	}
+	for uri := range fake.requests {
+		if !strings.Contains(uri, "tab=job-2") {
+			t.Errorf("unexpected request on resume: %s", uri)
+		}
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	tests := []struct {
+		name        string
+		baseURL     string
+		wantDetails string
+		wantSummary string
+		wantJob     string
+		wantErr     bool
+	}{
+		{
+			name:        "default",
+			wantDetails: "https://testgrid.k8s.io/redhat-openshift-ocp-release-4.6-informing/table?show-stale-tests=&tab=job-1",
+			wantSummary: "https://testgrid.k8s.io/redhat-openshift-ocp-release-4.6-informing/summary",
+			wantJob:     "https://testgrid.k8s.io/redhat-openshift-ocp-release-4.6-informing#job-1",
+		},
+		{
+			name:        "mirror with path prefix",
+			baseURL:     "http://localhost:8080/testgrid/",
+			wantDetails: "http://localhost:8080/testgrid/redhat-openshift-ocp-release-4.6-informing/table?show-stale-tests=&tab=job-1",
+			wantSummary: "http://localhost:8080/testgrid/redhat-openshift-ocp-release-4.6-informing/summary",
+			wantJob:     "http://localhost:8080/testgrid/redhat-openshift-ocp-release-4.6-informing#job-1",
+		},
+		{
+			name:    "missing scheme",
+			baseURL: "testgrid.example.com",
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoint := Endpoint{}
+			if len(tc.baseURL) > 0 {
+				var err error
+				endpoint, err = NewEndpoint(tc.baseURL)
+				if tc.wantErr {
+					if err == nil {
+						t.Fatalf("expected an error")
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			dashboard := "redhat-openshift-ocp-release-4.6-informing"
+			if got := endpoint.URLForJobDetails(dashboard, "job-1").String(); got != tc.wantDetails {
+				t.Errorf("expected %s, got %s", tc.wantDetails, got)
+			}
+			if got := endpoint.URLForJobSummary(dashboard).String(); got != tc.wantSummary {
+				t.Errorf("expected %s, got %s", tc.wantSummary, got)
+			}
+			if got := endpoint.URLForJob(dashboard, "job-1").String(); got != tc.wantJob {
+				t.Errorf("expected %s, got %s", tc.wantJob, got)
+			}
+		})
+	}
+}
+
+func TestFetchResumeRetriesFailures(t *testing.T) {
+	storagePath, err := ioutil.TempDir("", "fetch-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(storagePath)
+
+	fake := &fakeTestGrid{
+		jobs: map[string][]string{
+			"dashboard-a": {"job-1", "job-2"},
+		},
+		alwaysFail: map[string]bool{"job-2": true},
+		requests:   map[string]int{},
+	}
+	if _, err := newFakeTestGridFetcher(t, fake, storagePath, false).Fetch([]string{"dashboard-a"}); err == nil {
+		t.Fatalf("expected job-2 to fail")
+	}
+
+	// the previous fetch completed, but only the job that failed is fetched again
+	fake.alwaysFail = nil
+	fake.requests = map[string]int{}
+	manifest, err := newFakeTestGridFetcher(t, fake, storagePath, true).Fetch([]string{"dashboard-a"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !manifest.IsComplete() || manifest.Dashboards["dashboard-a"].Jobs["job-2"].Status != FetchSucceeded {
+		t.Errorf("expected the failed job to be fetched: %#v", manifest.Dashboards["dashboard-a"].Jobs)
+	}
+	if len(fake.requests) != 1 {
+		t.Errorf("expected only job-2 to be fetched on resume, got %v", fake.requests)
+	}
+	for uri := range fake.requests {
+		if !strings.Contains(uri, "tab=job-2") {
+			t.Errorf("unexpected request on resume: %s", uri)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package testgridhelpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/openshift/sippy/pkg/util"
+)
+
+// ManifestFilename is the name of the file written into the --fetch-data directory that records what was fetched.
+const ManifestFilename = "fetch-manifest.json"
+
+type FetchStatus string
+
+const (
+	FetchSucceeded FetchStatus = "Succeeded"
+	FetchFailed    FetchStatus = "Failed"
+)
+
+// FetchManifest records which dashboards and jobs were fetched, when, and with what status.  It is rewritten as the
+// requests complete, so an interrupted fetch leaves behind a record of what it managed to download.
+type FetchManifest struct {
+	StartTime time.Time `json:"startTime"`
+	// CompletionTime is nil while a fetch is in progress or if the fetch was interrupted.
+	CompletionTime *time.Time `json:"completionTime,omitempty"`
+
+	// Dashboards is keyed by testgrid dashboard name
+	Dashboards map[string]*DashboardFetchRecord `json:"dashboards"`
+}
+
+type DashboardFetchRecord struct {
+	FetchRecord
+	// Jobs is keyed by job name
+	Jobs map[string]*FetchRecord `json:"jobs,omitempty"`
+}
+
+// FetchRecord describes the outcome of fetching a single testgrid page.
+type FetchRecord struct {
+	URL       string      `json:"url"`
+	FetchTime time.Time   `json:"fetchTime"`
+	Status    FetchStatus `json:"status"`
+	Attempts  int         `json:"attempts"`
+	Error     string      `json:"error,omitempty"`
+}
+
+func newFetchManifest() *FetchManifest {
+	return &FetchManifest{
+		StartTime:  time.Now(),
+		Dashboards: map[string]*DashboardFetchRecord{},
+	}
+}
+
+// IsComplete returns true if the fetch that wrote this manifest ran to completion, whether or not every request succeeded.
+func (m *FetchManifest) IsComplete() bool {
+	return m.CompletionTime != nil
+}
+
+// hasFailures returns true if any dashboard or job could not be fetched.
+func (m *FetchManifest) hasFailures() bool {
+	for _, dashboardRecord := range m.Dashboards {
+		if dashboardRecord.Status != FetchSucceeded {
+			return true
+		}
+		for _, jobRecord := range dashboardRecord.Jobs {
+			if jobRecord.Status != FetchSucceeded {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m *FetchManifest) dashboardSucceeded(dashboard string) bool {
+	record, ok := m.Dashboards[dashboard]
+	return ok && record.Status == FetchSucceeded
+}
+
+func (m *FetchManifest) jobSucceeded(dashboard, jobName string) bool {
+	dashboardRecord, ok := m.Dashboards[dashboard]
+	if !ok {
+		return false
+	}
+	record, ok := dashboardRecord.Jobs[jobName]
+	return ok && record.Status == FetchSucceeded
+}
+
+// LoadFetchManifest reads the manifest from the storagePath.  If no manifest exists, nil is returned without an error.
+func LoadFetchManifest(storagePath string) (*FetchManifest, error) {
+	filename := filepath.Join(storagePath, ManifestFilename)
+	b, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read fetch manifest %s: %v", filename, err)
+	}
+
+	manifest := &FetchManifest{}
+	if err := json.Unmarshal(b, manifest); err != nil {
+		return nil, fmt.Errorf("could not parse fetch manifest %s: %v", filename, err)
+	}
+	if manifest.Dashboards == nil {
+		manifest.Dashboards = map[string]*DashboardFetchRecord{}
+	}
+	return manifest, nil
+}
+
+func writeFetchManifest(storagePath string, manifest *FetchManifest) error {
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return util.WriteFileAtomically(filepath.Join(storagePath, ManifestFilename), b)
+}
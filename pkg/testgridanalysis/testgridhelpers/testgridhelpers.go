@@ -4,13 +4,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
 	"io/ioutil"
-	"net/http"
-	"net/http/httputil"
 	gourl "net/url"
 	"os"
 	"regexp"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	testgridv1 "github.com/openshift/sippy/pkg/apis/testgrid/v1"
@@ -22,45 +21,33 @@ var (
 	openshiftDashboardTemplate = "redhat-openshift-ocp-release-%s-%s"
 )
 
-func DownloadData(dashboards []string, filter string, storagePath string) {
-	var jobFilter *regexp.Regexp
-	if len(filter) > 0 {
-		jobFilter = regexp.MustCompile(filter)
-	}
+// filesLoaded and fileLoadErrors count the testgrid files read from disk since sippy started.  They are updated
+// atomically.
+var filesLoaded, fileLoadErrors int64
 
-	for _, dashboard := range dashboards {
-		err := downloadJobSummaries(dashboard, storagePath)
-		if err != nil {
-			klog.Errorf("Error fetching dashboard page %s: %v\n", dashboard, err)
-			continue
-		}
-		jobs, _, err := loadJobSummaries(dashboard, storagePath)
-		if err != nil {
-			klog.Errorf("Error loading dashboard page %s: %v\n", dashboard, err)
-			continue
-		}
+// LoadedFileCounts returns how many testgrid files were read from disk since sippy started, and how many of those could
+// not be read or parsed.
+func LoadedFileCounts() (loaded, failed int64) {
+	return atomic.LoadInt64(&filesLoaded), atomic.LoadInt64(&fileLoadErrors)
+}
 
-		for jobName, job := range jobs {
-			if util.RelevantJob(jobName, job.OverallStatus, jobFilter) {
-				klog.V(4).Infof("Job %s has bad status %s\n", jobName, job.OverallStatus)
-				err := downloadJobDetails(dashboard, jobName, storagePath)
-				if err != nil {
-					klog.Errorf("Error fetching job details for %s: %v\n", jobName, err)
-				}
-			}
-		}
+func countFileLoad(err error) {
+	atomic.AddInt64(&filesLoaded, 1)
+	if err != nil {
+		atomic.AddInt64(&fileLoadErrors, 1)
 	}
 }
 
 // LoadTestGridDataFromDisk reads the requested testgrid data from disk and returns the details and the timestamp of the last
-// modification on disk
-func LoadTestGridDataFromDisk(storagePath string, dashboards []string, jobFilter *regexp.Regexp) ([]testgridv1.JobDetails, time.Time) {
+// modification on disk.  The endpoint is used to link each job back to testgrid.
+func LoadTestGridDataFromDisk(storagePath string, dashboards []string, jobFilter *regexp.Regexp, endpoint Endpoint) ([]testgridv1.JobDetails, time.Time) {
 	testGridJobDetails := []testgridv1.JobDetails{}
 
 	lastUpdateTime := time.Time{}
 
 	for _, dashboard := range dashboards {
 		jobs, ts, err := loadJobSummaries(dashboard, storagePath)
+		countFileLoad(err)
 		if err != nil {
 			klog.Errorf("Error loading dashboard page %s: %v\n", dashboard, err)
 			continue
@@ -72,7 +59,8 @@ func LoadTestGridDataFromDisk(storagePath string, dashboards []string, jobFilter
 		for jobName, job := range jobs {
 			if util.RelevantJob(jobName, job.OverallStatus, jobFilter) {
 				klog.V(4).Infof("Job %s has bad status %s\n", jobName, job.OverallStatus)
-				details, err := loadJobDetails(dashboard, jobName, storagePath)
+				details, err := loadJobDetails(dashboard, jobName, storagePath, endpoint)
+				countFileLoad(err)
 				if err != nil {
 					klog.Errorf("Error loading job details for %s: %v\n", jobName, err)
 				} else {
@@ -85,7 +73,7 @@ func LoadTestGridDataFromDisk(storagePath string, dashboards []string, jobFilter
 	return testGridJobDetails, lastUpdateTime
 }
 
-func loadJobDetails(dashboard, jobName, storagePath string) (testgridv1.JobDetails, error) {
+func loadJobDetails(dashboard, jobName, storagePath string, endpoint Endpoint) (testgridv1.JobDetails, error) {
 	details := testgridv1.JobDetails{
 		Name: jobName,
 	}
@@ -104,7 +92,7 @@ func loadJobDetails(dashboard, jobName, storagePath string) (testgridv1.JobDetai
 	if err != nil {
 		return details, err
 	}
-	details.TestGridUrl = URLForJob(dashboard, jobName).String()
+	details.TestGridUrl = endpoint.URLForJob(dashboard, jobName).String()
 	return details, nil
 }
 
@@ -149,39 +137,59 @@ NextChar:
 	return string(out)
 }
 
-func downloadJobSummaries(dashboard string, storagePath string) error {
-	url := URLForJobSummary(dashboard)
+// https://testgrid.k8s.io/redhat-openshift-ocp-release-4.4-informing/table?&show-stale-tests=&tab=release-openshift-origin-installer-e2e-azure-compact-4.4
 
-	resp, err := http.Get(url.String())
-	if err != nil {
-		return err
-	}
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("non-200 response code fetching job details from %v: %v", url, resp)
-	}
-	filename := storagePath + "/" + normalizeURL(url.String())
-	f, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
+// https://testgrid.k8s.io/redhat-openshift-ocp-release-4.4-informing#release-openshift-origin-installer-e2e-azure-compact-4.4&show-stale-tests=&sort-by-failures=
 
-	buf := bytes.NewBuffer([]byte{})
-	io.Copy(buf, resp.Body)
+// DefaultTestGridURL is the testgrid instance used unless another one is configured.
+const DefaultTestGridURL = "https://testgrid.k8s.io"
 
-	_, err = f.Write(buf.Bytes())
-	return err
+// Endpoint builds urls for a testgrid instance: testgrid.k8s.io, a mirror of it, or a local stand-in.
+// The zero value refers to DefaultTestGridURL.
+type Endpoint struct {
+	baseURL *gourl.URL
 }
 
-// https://testgrid.k8s.io/redhat-openshift-ocp-release-4.4-informing/table?&show-stale-tests=&tab=release-openshift-origin-installer-e2e-azure-compact-4.4
+// NewEndpoint parses the base url of a testgrid instance.  The base url may include a path prefix.
+func NewEndpoint(baseURL string) (Endpoint, error) {
+	parsed, err := gourl.Parse(baseURL)
+	if err != nil {
+		return Endpoint{}, fmt.Errorf("invalid testgrid url %q: %v", baseURL, err)
+	}
+	if len(parsed.Scheme) == 0 || len(parsed.Host) == 0 {
+		return Endpoint{}, fmt.Errorf("invalid testgrid url %q: must include a scheme and a host", baseURL)
+	}
+	parsed.Path = strings.TrimSuffix(parsed.Path, "/")
+	parsed.RawQuery = ""
+	parsed.Fragment = ""
+	return Endpoint{baseURL: parsed}, nil
+}
 
-// https://testgrid.k8s.io/redhat-openshift-ocp-release-4.4-informing#release-openshift-origin-installer-e2e-azure-compact-4.4&show-stale-tests=&sort-by-failures=
+func (e Endpoint) String() string {
+	if e.baseURL == nil {
+		return DefaultTestGridURL
+	}
+	return e.baseURL.String()
+}
 
-func URLForJobDetails(dashboard, jobName string) *gourl.URL {
-	url := &gourl.URL{
-		Scheme: "https",
-		Host:   "testgrid.k8s.io",
-		Path:   fmt.Sprintf("/%s/table", gourl.PathEscape(dashboard)),
+func (e Endpoint) urlForPath(path string) *gourl.URL {
+	if e.baseURL == nil {
+		return &gourl.URL{
+			Scheme: "https",
+			Host:   "testgrid.k8s.io",
+			Path:   path,
+		}
+	}
+	return &gourl.URL{
+		Scheme: e.baseURL.Scheme,
+		User:   e.baseURL.User,
+		Host:   e.baseURL.Host,
+		Path:   e.baseURL.Path + path,
 	}
+}
+
+func (e Endpoint) URLForJobDetails(dashboard, jobName string) *gourl.URL {
+	url := e.urlForPath(fmt.Sprintf("/%s/table", gourl.PathEscape(dashboard)))
 	query := url.Query()
 	query.Set("show-stale-tests", "")
 	query.Set("tab", jobName)
@@ -190,51 +198,32 @@ func URLForJobDetails(dashboard, jobName string) *gourl.URL {
 
 	return url
 }
-func URLForJobSummary(dashboard string) *gourl.URL {
-	url := &gourl.URL{
-		Scheme: "https",
-		Host:   "testgrid.k8s.io",
-		Path:   fmt.Sprintf("/%s/summary", gourl.PathEscape(dashboard)),
-	}
 
-	return url
+func (e Endpoint) URLForJobSummary(dashboard string) *gourl.URL {
+	return e.urlForPath(fmt.Sprintf("/%s/summary", gourl.PathEscape(dashboard)))
 }
 
-func URLForJob(dashboard, jobName string) *gourl.URL {
-	url := &gourl.URL{
-		Scheme: "https",
-		Host:   "testgrid.k8s.io",
-		Path:   fmt.Sprintf("/%s", gourl.PathEscape(dashboard)),
-	}
+func (e Endpoint) URLForJob(dashboard, jobName string) *gourl.URL {
+	url := e.urlForPath(fmt.Sprintf("/%s", gourl.PathEscape(dashboard)))
 	// this is a non-standard fragment honored by test-grid
 	url.Fragment = gourl.PathEscape(jobName)
 
 	return url
 }
 
-func downloadJobDetails(dashboard, jobName, storagePath string) error {
-	url := URLForJobDetails(dashboard, jobName)
-
-	resp, err := http.Get(url.String())
-	if err != nil {
-		return err
-	}
-	if resp.StatusCode != 200 {
-		responseDump, _ := httputil.DumpResponse(resp, true)
-		fmt.Fprintf(os.Stderr, "response dump\n%v\n", string(responseDump))
-		return fmt.Errorf("non-200 response code fetching job details from %v: %v", url, resp)
-	}
-
-	filename := storagePath + "/" + normalizeURL(url.String())
-	f, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-
-	buf := bytes.NewBuffer([]byte{})
-	io.Copy(buf, resp.Body)
+// URLForJobDetails builds the job details url against DefaultTestGridURL.  It is also used to name the file on disk, so
+// a data directory is the same no matter which instance it came from.
+func URLForJobDetails(dashboard, jobName string) *gourl.URL {
+	return Endpoint{}.URLForJobDetails(dashboard, jobName)
+}
 
-	_, err = f.Write(buf.Bytes())
-	return err
+// URLForJobSummary builds the dashboard summary url against DefaultTestGridURL.  Like URLForJobDetails, it is also
+// used to name the file on disk.
+func URLForJobSummary(dashboard string) *gourl.URL {
+	return Endpoint{}.URLForJobSummary(dashboard)
+}
 
+// URLForJob builds the url of the job on DefaultTestGridURL.
+func URLForJob(dashboard, jobName string) *gourl.URL {
+	return Endpoint{}.URLForJob(dashboard, jobName)
 }
@@ -1,14 +1,12 @@
 package testidentification
 
 import (
-	"fmt"
 	"regexp"
-
-	"github.com/openshift/sippy/pkg/util/sets"
 )
 
 var (
-	ValidBugzillaComponents = sets.NewString(
+	// defaultBugzillaComponents are the components of the default IdentificationConfig
+	defaultBugzillaComponents = []string{
 		"apiserver-auth",
 		"assisted-installer",
 		"Bare Metal Hardware Provisioning",
@@ -82,74 +80,67 @@ var (
 		"Test Infrastructure",
 		"Unknown",
 		"Windows Containers",
-	)
-
-	operatorToBugzillaComponent = map[string]string{}
-
-	// sigToBugzillaComponent holds `sig-foo` (from '[sig-foo]' label in a test) as keys and maps them to the "most correct" BZ component
-	sigToBugzillaComponent = map[string]string{}
+	}
 
 	sigRegex *regexp.Regexp = regexp.MustCompile(`\[(sig-.*?)\]`)
 )
 
-func init() {
-	must(addOperatorMapping("authentication", "apiserver-auth"))
-	must(addOperatorMapping("cloud-credential", "Cloud Credential Operator"))
-	must(addOperatorMapping("cluster-autoscaler", "Cloud Compute"))
-	must(addOperatorMapping("config-operator", "config-operator"))
-	must(addOperatorMapping("console", "Management Console"))
-	must(addOperatorMapping("csi-snapshot-controller", "Storage"))
-	must(addOperatorMapping("dns", "DNS"))
-	must(addOperatorMapping("etcd", "Etcd"))
-	must(addOperatorMapping("ingress", "Routing"))
-	must(addOperatorMapping("image-registry", "Image Registry"))
-	must(addOperatorMapping("insights", "Insights Operator"))
-	must(addOperatorMapping("kube-apiserver", "kube-apiserver"))
-	must(addOperatorMapping("kube-controller-manager", "kube-controller-manager"))
-	must(addOperatorMapping("kube-scheduler", "kube-scheduler"))
-	must(addOperatorMapping("kube-storage-version-migrator", "kube-storage-version-migrator"))
-	must(addOperatorMapping("machine-api", "Cloud Compute"))
-	must(addOperatorMapping("machine-approver", "Cloud Compute"))
-	must(addOperatorMapping("machine-config", "Machine Config Operator"))
-	must(addOperatorMapping("marketplace", "OLM"))
-	must(addOperatorMapping("monitoring", "Monitoring"))
-	must(addOperatorMapping("network", "Networking"))
-	must(addOperatorMapping("node-tuning", "Node Tuning Operator"))
-	must(addOperatorMapping("openshift-apiserver", "openshift-apiserver"))
-	must(addOperatorMapping("openshift-controller-manager", "openshift-controller-manager"))
-	must(addOperatorMapping("openshift-samples", "Samples"))
-	must(addOperatorMapping("operator-lifecycle-manager", "OLM"))
-	must(addOperatorMapping("operator-lifecycle-manager-catalog", "OLM"))
-	must(addOperatorMapping("operator-lifecycle-manager-packageserver", "OLM"))
-	must(addOperatorMapping("service-ca", "service-ca"))
-	must(addOperatorMapping("storage", "Storage"))
-
-	must(addSigMapping("sig-cli", "oc"))
-	must(addSigMapping("sig-api-machinery", "kube-apiserver"))
-	must(addSigMapping("sig-apps", "kube-controller-manager"))
-	must(addSigMapping("sig-arch", "Unknown"))
-	must(addSigMapping("sig-auth", "apiserver-auth"))
-	must(addSigMapping("sig-builds", "Build"))
-	must(addSigMapping("sig-cli", "oc"))
-	must(addSigMapping("sig-cluster-lifecycle", "Unknown"))
-	must(addSigMapping("sig-devex", "Build"))
-	must(addSigMapping("sig-imageregistry", "Image Registry"))
-	must(addSigMapping("sig-network", "Networking"))
-	must(addSigMapping("sig-node", "Node"))
-	must(addSigMapping("sig-openshift-logging", "Logging"))
-	must(addSigMapping("sig-operator", "OLM"))
-	must(addSigMapping("sig-storage", "Storage"))
-	must(addSigMapping("sig-unknown", "Unknown"))
+// defaultOperatorComponents are the operator to bugzilla component mappings of the default IdentificationConfig
+var defaultOperatorComponents = map[string]string{
+	"authentication":                     "apiserver-auth",
+	"cloud-credential":                   "Cloud Credential Operator",
+	"cluster-autoscaler":                 "Cloud Compute",
+	"config-operator":                    "config-operator",
+	"console":                            "Management Console",
+	"csi-snapshot-controller":            "Storage",
+	"dns":                                "DNS",
+	"etcd":                               "Etcd",
+	"ingress":                            "Routing",
+	"image-registry":                     "Image Registry",
+	"insights":                           "Insights Operator",
+	"kube-apiserver":                     "kube-apiserver",
+	"kube-controller-manager":            "kube-controller-manager",
+	"kube-scheduler":                     "kube-scheduler",
+	"kube-storage-version-migrator":      "kube-storage-version-migrator",
+	"machine-api":                        "Cloud Compute",
+	"machine-approver":                   "Cloud Compute",
+	"machine-config":                     "Machine Config Operator",
+	"marketplace":                        "OLM",
+	"monitoring":                         "Monitoring",
+	"network":                            "Networking",
+	"node-tuning":                        "Node Tuning Operator",
+	"openshift-apiserver":                "openshift-apiserver",
+	"openshift-controller-manager":       "openshift-controller-manager",
+	"openshift-samples":                  "Samples",
+	"operator-lifecycle-manager":         "OLM",
+	"operator-lifecycle-manager-catalog": "OLM",
+	"operator-lifecycle-manager-packageserver": "OLM",
+	"service-ca": "service-ca",
+	"storage":    "Storage",
 }
 
-func must(err error) {
-	if err != nil {
-		panic(err)
-	}
+// defaultSigComponents hold `sig-foo` (from '[sig-foo]' label in a test) as keys and map them to the "most correct" BZ
+// component in the default IdentificationConfig
+var defaultSigComponents = map[string]string{
+	"sig-cli":               "oc",
+	"sig-api-machinery":     "kube-apiserver",
+	"sig-apps":              "kube-controller-manager",
+	"sig-arch":              "Unknown",
+	"sig-auth":              "apiserver-auth",
+	"sig-builds":            "Build",
+	"sig-cluster-lifecycle": "Unknown",
+	"sig-devex":             "Build",
+	"sig-imageregistry":     "Image Registry",
+	"sig-network":           "Networking",
+	"sig-node":              "Node",
+	"sig-openshift-logging": "Logging",
+	"sig-operator":          "OLM",
+	"sig-storage":           "Storage",
+	"sig-unknown":           "Unknown",
 }
 
 func GetBugzillaComponentForOperator(operator string) string {
-	ret, ok := operatorToBugzillaComponent[operator]
+	ret, ok := currentIdentification().operatorComponents[operator]
 	if !ok {
 		return "Unknown"
 	}
@@ -157,29 +148,13 @@ func GetBugzillaComponentForOperator(operator string) string {
 }
 
 func GetBugzillaComponentForSig(sig string) string {
-	ret, ok := operatorToBugzillaComponent[sig]
+	ret, ok := currentIdentification().sigComponents[sig]
 	if !ok {
 		return "Unknown"
 	}
 	return ret
 }
 
-func addOperatorMapping(operator, bugzillaComponent string) error {
-	if !ValidBugzillaComponents.Has(bugzillaComponent) {
-		return fmt.Errorf("%q is not a valid bugzilla component", bugzillaComponent)
-	}
-	operatorToBugzillaComponent[operator] = bugzillaComponent
-	return nil
-}
-
-func addSigMapping(sig, bugzillaComponent string) error {
-	if !ValidBugzillaComponents.Has(bugzillaComponent) {
-		return fmt.Errorf("%q is not a valid bugzilla component", bugzillaComponent)
-	}
-	sigToBugzillaComponent[sig] = bugzillaComponent
-	return nil
-}
-
 // find associated sig from test name
 func FindSig(name string) string {
 	match := sigRegex.FindStringSubmatch(name)
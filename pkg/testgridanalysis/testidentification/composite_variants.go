@@ -0,0 +1,83 @@
+package testidentification
+
+import (
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+// NamespacedVariantManager is a VariantManager whose variants are prefixed with "<Namespace>:" when it is part of a
+// composite, so that variants with the same name from different schemes stay separate.
+type NamespacedVariantManager struct {
+	Namespace      string
+	VariantManager VariantManager
+}
+
+type compositeVariants struct {
+	managers    []NamespacedVariantManager
+	allVariants sets.String
+}
+
+// NewCompositeVariantManager returns a VariantManager that puts a job in the variants of every one of the managers.  A
+// job is never stable if any of the managers says it is, and then it only belongs to the variants of the managers that
+// say so, which keeps it out of the "normal" variants of the other managers.
+func NewCompositeVariantManager(managers ...NamespacedVariantManager) VariantManager {
+	ret := compositeVariants{
+		managers:    managers,
+		allVariants: sets.NewString(),
+	}
+	for _, manager := range managers {
+		for _, variant := range manager.VariantManager.AllVariants().UnsortedList() {
+			ret.allVariants.Insert(namespacedVariant(manager.Namespace, variant))
+		}
+	}
+	return ret
+}
+
+func namespacedVariant(namespace, variant string) string {
+	return namespace + ":" + variant
+}
+
+func (v compositeVariants) AllVariants() sets.String {
+	return v.allVariants
+}
+
+func (v compositeVariants) IdentifyVariants(jobName string) []string {
+	neverStable := v.IsJobNeverStable(jobName)
+
+	variants := []string{}
+	for _, manager := range v.managers {
+		if neverStable && !manager.VariantManager.IsJobNeverStable(jobName) {
+			continue
+		}
+		for _, variant := range manager.VariantManager.IdentifyVariants(jobName) {
+			variants = append(variants, namespacedVariant(manager.Namespace, variant))
+		}
+	}
+	return variants
+}
+
+// Dimensions returns the dimensions of every manager that has them, with their names and variants namespaced.
+func (v compositeVariants) Dimensions() []VariantDimension {
+	dimensions := []VariantDimension{}
+	for _, manager := range v.managers {
+		for _, dimension := range VariantDimensions(manager.VariantManager) {
+			namespaced := VariantDimension{
+				Name:    namespacedVariant(manager.Namespace, dimension.Name),
+				Default: dimension.Default,
+			}
+			for _, variant := range dimension.Variants {
+				namespaced.Variants = append(namespaced.Variants, namespacedVariant(manager.Namespace, variant))
+			}
+			dimensions = append(dimensions, namespaced)
+		}
+	}
+	return dimensions
+}
+
+func (v compositeVariants) IsJobNeverStable(jobName string) bool {
+	for _, manager := range v.managers {
+		if manager.VariantManager.IsJobNeverStable(jobName) {
+			return true
+		}
+	}
+	return false
+}
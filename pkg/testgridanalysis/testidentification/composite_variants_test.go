@@ -0,0 +1,61 @@
+package testidentification
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompositeVariantManager(t *testing.T) {
+	custom, err := NewConfigVariantManager(VariantConfig{
+		Variants:        []VariantDefinition{{Name: "upgrade", Include: []string{"-upgrade"}}},
+		NeverStableJobs: []string{"custom-broken-upgrade"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	variantManager := NewCompositeVariantManager(
+		NamespacedVariantManager{Namespace: "ocp", VariantManager: NewOpenshiftVariantManager()},
+		NamespacedVariantManager{Namespace: "custom", VariantManager: custom},
+	)
+
+	for _, variant := range []string{"ocp:upgrade", "ocp:aws", "ocp:never-stable", "custom:upgrade", "custom:never-stable"} {
+		if !variantManager.AllVariants().Has(variant) {
+			t.Errorf("expected %q in %v", variant, variantManager.AllVariants().List())
+		}
+	}
+	if variantManager.AllVariants().Has("upgrade") {
+		t.Errorf("expected only namespaced variants, got %v", variantManager.AllVariants().List())
+	}
+
+	tests := []struct {
+		jobName     string
+		variants    []string
+		neverStable bool
+	}{
+		{
+			jobName:  "release-openshift-origin-installer-e2e-aws-upgrade-4.6",
+			variants: []string{"ocp:aws", "ocp:upgrade", "custom:upgrade"},
+		},
+		{
+			// never stable for ocp only, so it stays out of the custom variants
+			jobName:     "release-openshift-origin-installer-e2e-aws-upgrade-rollback-4.5-to-4.6",
+			variants:    []string{"ocp:never-stable"},
+			neverStable: true,
+		},
+		{
+			jobName:     "custom-broken-upgrade",
+			variants:    []string{"custom:never-stable"},
+			neverStable: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.jobName, func(t *testing.T) {
+			if actual := variantManager.IdentifyVariants(tc.jobName); !reflect.DeepEqual(tc.variants, actual) {
+				t.Errorf("expected %v, got %v", tc.variants, actual)
+			}
+			if actual := variantManager.IsJobNeverStable(tc.jobName); actual != tc.neverStable {
+				t.Errorf("expected never stable %v, got %v", tc.neverStable, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package testidentification
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/openshift/sippy/pkg/util"
+	"github.com/openshift/sippy/pkg/util/sets"
+	"k8s.io/klog"
+)
+
+// NeverStableVariant is the only variant of jobs that are listed as never stable.
+const NeverStableVariant = "never-stable"
+
+// VariantConfig defines a set of variants, so that variants can be changed without changing sippy.
+type VariantConfig struct {
+	// Variants are checked in order, and a job belongs to every variant it matches.
+	Variants []VariantDefinition `json:"variants"`
+	// NeverStableJobs are jobs that have never been stable (not were stable and broke).  They only belong to the
+	// never-stable variant.
+	NeverStableJobs []string `json:"neverStableJobs,omitempty"`
+	// UnknownVariant, if set, is the variant of jobs that do not match any variant.
+	UnknownVariant string `json:"unknownVariant,omitempty"`
+	// Dimensions group the variants so that combinations of them can be compared.
+	Dimensions []VariantDimension `json:"dimensions,omitempty"`
+}
+
+// VariantDefinition describes which jobs belong to a variant.
+type VariantDefinition struct {
+	Name string `json:"name"`
+	// Include are regexes of job names that belong to the variant.
+	Include []string `json:"include,omitempty"`
+	// Jobs are job names that belong to the variant even though they do not match any of the Include regexes.
+	Jobs []string `json:"jobs,omitempty"`
+	// Exclude are regexes of job names that do not belong to the variant, even though they match it.  The regexp
+	// package does not support negative lookahead, so this is how metal-ipi jobs are kept out of the metal-upi variant.
+	Exclude []string `json:"exclude,omitempty"`
+	// Exclusive variants are checked before all other variants, and jobs that match one do not belong to any other
+	// variant.
+	Exclusive bool `json:"exclusive,omitempty"`
+}
+
+type variantMatcher struct {
+	name      string
+	include   []*regexp.Regexp
+	jobs      sets.String
+	exclude   []*regexp.Regexp
+	exclusive bool
+}
+
+func (m variantMatcher) matches(jobName string) bool {
+	matched := m.jobs.Has(jobName)
+	for _, include := range m.include {
+		if matched {
+			break
+		}
+		if include.MatchString(jobName) {
+			matched = true
+		}
+	}
+	if !matched {
+		return false
+	}
+	for _, exclude := range m.exclude {
+		if exclude.MatchString(jobName) {
+			return false
+		}
+	}
+	return true
+}
+
+type configVariants struct {
+	matchers        []variantMatcher
+	allVariants     sets.String
+	neverStableJobs sets.String
+	unknownVariant  string
+	dimensions      []VariantDimension
+}
+
+// LoadVariantConfig reads a VariantConfig from a JSON file.
+func LoadVariantConfig(filename string) (VariantConfig, error) {
+	config := VariantConfig{}
+	err := util.LoadStrictJSON(filename, &config)
+	return config, err
+}
+
+// NewConfigVariantManager returns a VariantManager for the variants defined by the config, or an error if the config is
+// invalid.
+func NewConfigVariantManager(config VariantConfig) (VariantManager, error) {
+	ret := configVariants{
+		allVariants:     sets.NewString(),
+		neverStableJobs: sets.NewString(config.NeverStableJobs...),
+		unknownVariant:  config.UnknownVariant,
+	}
+	if ret.neverStableJobs.Len() > 0 {
+		ret.allVariants.Insert(NeverStableVariant)
+	}
+
+	for i, definition := range config.Variants {
+		if len(definition.Name) == 0 {
+			return nil, fmt.Errorf("variant %d must have a name", i)
+		}
+		if ret.allVariants.Has(definition.Name) {
+			return nil, fmt.Errorf("variant %q is defined more than once", definition.Name)
+		}
+		if len(definition.Include) == 0 && len(definition.Jobs) == 0 {
+			return nil, fmt.Errorf("variant %q must include jobs or regexes of jobs", definition.Name)
+		}
+
+		matcher := variantMatcher{
+			name:      definition.Name,
+			jobs:      sets.NewString(definition.Jobs...),
+			exclusive: definition.Exclusive,
+		}
+		for _, include := range definition.Include {
+			regex, err := regexp.Compile(include)
+			if err != nil {
+				return nil, fmt.Errorf("variant %q has an invalid include regex: %v", definition.Name, err)
+			}
+			matcher.include = append(matcher.include, regex)
+		}
+		for _, exclude := range definition.Exclude {
+			regex, err := regexp.Compile(exclude)
+			if err != nil {
+				return nil, fmt.Errorf("variant %q has an invalid exclude regex: %v", definition.Name, err)
+			}
+			matcher.exclude = append(matcher.exclude, regex)
+		}
+
+		ret.matchers = append(ret.matchers, matcher)
+		ret.allVariants.Insert(definition.Name)
+	}
+
+	if len(config.UnknownVariant) > 0 {
+		if ret.allVariants.Has(config.UnknownVariant) {
+			return nil, fmt.Errorf("unknown variant %q is also defined as a variant", config.UnknownVariant)
+		}
+		ret.allVariants.Insert(config.UnknownVariant)
+	}
+
+	dimensionNames := sets.NewString()
+	for i, dimension := range config.Dimensions {
+		if len(dimension.Name) == 0 {
+			return nil, fmt.Errorf("dimension %d must have a name", i)
+		}
+		if dimensionNames.Has(dimension.Name) {
+			return nil, fmt.Errorf("dimension %q is defined more than once", dimension.Name)
+		}
+		dimensionNames.Insert(dimension.Name)
+		if len(dimension.Variants) == 0 {
+			return nil, fmt.Errorf("dimension %q must have variants", dimension.Name)
+		}
+		if len(dimension.Default) == 0 {
+			return nil, fmt.Errorf("dimension %q must have a default", dimension.Name)
+		}
+		for _, variant := range dimension.Variants {
+			if !ret.allVariants.Has(variant) {
+				return nil, fmt.Errorf("dimension %q has undefined variant %q", dimension.Name, variant)
+			}
+		}
+	}
+	ret.dimensions = config.Dimensions
+
+	return ret, nil
+}
+
+func (v configVariants) AllVariants() sets.String {
+	return v.allVariants
+}
+
+func (v configVariants) IdentifyVariants(jobName string) []string {
+	if v.IsJobNeverStable(jobName) {
+		return []string{NeverStableVariant}
+	}
+
+	for _, matcher := range v.matchers {
+		if matcher.exclusive && matcher.matches(jobName) {
+			return []string{matcher.name}
+		}
+	}
+
+	variants := []string{}
+	for _, matcher := range v.matchers {
+		if !matcher.exclusive && matcher.matches(jobName) {
+			variants = append(variants, matcher.name)
+		}
+	}
+
+	if len(variants) == 0 && len(v.unknownVariant) > 0 {
+		klog.V(2).Infof("unknown variant for job: %s\n", jobName)
+		return []string{v.unknownVariant}
+	}
+
+	return variants
+}
+
+func (v configVariants) Dimensions() []VariantDimension {
+	return v.dimensions
+}
+
+func (v configVariants) IsJobNeverStable(jobName string) bool {
+	return v.neverStableJobs.Has(jobName)
+}
@@ -0,0 +1,123 @@
+package testidentification
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+// openshiftVariantConfig defines the same variants as the openshift variant manager.
+const openshiftVariantConfig = `{
+	"variants": [
+		{"name": "promote", "include": ["(?i)^promote-"], "exclusive": true},
+		{"name": "aws", "include": ["(?i)-aws"]},
+		{"name": "azure", "include": ["(?i)-azure"]},
+		{"name": "gcp", "include": ["(?i)-gcp"]},
+		{"name": "openstack", "include": ["(?i)-openstack"]},
+		{"name": "osd", "include": ["(?i)-osd"]},
+		{"name": "metal-assisted", "include": ["(?i)-metal-assisted"]},
+		{"name": "metal-ipi", "include": ["(?i)-metal-ipi"], "exclude": ["(?i)-metal-assisted"]},
+		{"name": "metal-upi", "include": ["(?i)-metal"], "exclude": ["(?i)-metal-assisted", "(?i)-metal-ipi"]},
+		{"name": "ovirt", "include": ["(?i)-ovirt"]},
+		{"name": "vsphere-upi", "include": ["(?i)-vsphere-upi"]},
+		{"name": "vsphere-ipi", "include": ["(?i)-vsphere"], "exclude": ["(?i)-vsphere-upi"]},
+		{"name": "upgrade", "include": ["(?i)-upgrade"]},
+		{"name": "serial", "include": ["(?i)-serial"]},
+		{"name": "ovn", "include": ["(?i)-ovn"]},
+		{"name": "fips", "include": ["(?i)-fips"]},
+		{"name": "ppc64le", "include": ["(?i)-ppc64le"]},
+		{"name": "s390x", "include": ["(?i)-s390x"]},
+		{"name": "realtime", "include": ["(?i)-rt"]},
+		{"name": "proxy", "include": ["(?i)-proxy"]}
+	],
+	"neverStableJobs": [
+		"release-openshift-ocp-installer-e2e-ovirt-upgrade-4.5-stable-to-4.6-ci",
+		"release-openshift-origin-installer-e2e-aws-upgrade-rollback-4.5-to-4.6",
+		"release-openshift-origin-installer-e2e-aws-disruptive-4.6"
+	],
+	"unknownVariant": "unknown variant"
+}`
+
+func writeVariantConfig(t *testing.T, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "variant-config-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, "variants.json")
+	if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return filename, func() { os.RemoveAll(dir) }
+}
+
+func TestConfigVariantManagerMatchesOpenshift(t *testing.T) {
+	filename, cleanup := writeVariantConfig(t, openshiftVariantConfig)
+	defer cleanup()
+	config, err := LoadVariantConfig(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configVariantManager, err := NewConfigVariantManager(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	openshiftVariantManager := NewOpenshiftVariantManager()
+
+	for _, jobName := range []string{
+		"promote-release-openshift-machine-os-content-e2e-aws-4.6",
+		"release-openshift-ocp-installer-e2e-aws-serial-4.6",
+		"release-openshift-ocp-installer-e2e-metal-assisted-4.6",
+		"release-openshift-ocp-installer-e2e-metal-ipi-4.6",
+		"release-openshift-ocp-installer-e2e-metal-4.6",
+		"release-openshift-ocp-installer-e2e-vsphere-upi-4.6",
+		"release-openshift-ocp-installer-e2e-vsphere-4.6",
+		"release-openshift-ocp-installer-e2e-gcp-rt-4.6",
+		"release-openshift-origin-installer-e2e-azure-upgrade-4.5-stable-to-4.6-ci",
+		"release-openshift-origin-installer-e2e-aws-disruptive-4.6",
+		"periodic-ci-something-else",
+	} {
+		expected := openshiftVariantManager.IdentifyVariants(jobName)
+		if actual := configVariantManager.IdentifyVariants(jobName); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%s: expected %v, got %v", jobName, expected, actual)
+		}
+		if expected, actual := openshiftVariantManager.IsJobNeverStable(jobName), configVariantManager.IsJobNeverStable(jobName); expected != actual {
+			t.Errorf("%s: expected never stable %v, got %v", jobName, expected, actual)
+		}
+	}
+
+	expectedVariants := sets.NewString(openshiftVariantManager.AllVariants().List()...).Insert("unknown variant")
+	if !expectedVariants.Equal(configVariantManager.AllVariants()) {
+		t.Errorf("expected variants %v, got %v", expectedVariants.List(), configVariantManager.AllVariants().List())
+	}
+}
+
+func TestNewConfigVariantManagerValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		config VariantConfig
+	}{
+		{name: "missing name", config: VariantConfig{Variants: []VariantDefinition{{Include: []string{"aws"}}}}},
+		{name: "duplicate", config: VariantConfig{Variants: []VariantDefinition{{Name: "aws", Include: []string{"aws"}}, {Name: "aws", Jobs: []string{"job"}}}}},
+		{name: "matches nothing", config: VariantConfig{Variants: []VariantDefinition{{Name: "aws"}}}},
+		{name: "bad include", config: VariantConfig{Variants: []VariantDefinition{{Name: "aws", Include: []string{"("}}}}},
+		{name: "bad exclude", config: VariantConfig{Variants: []VariantDefinition{{Name: "aws", Include: []string{"aws"}, Exclude: []string{"("}}}}},
+		{name: "never-stable conflict", config: VariantConfig{Variants: []VariantDefinition{{Name: NeverStableVariant, Include: []string{"aws"}}}, NeverStableJobs: []string{"job"}}},
+		{name: "unknown conflict", config: VariantConfig{Variants: []VariantDefinition{{Name: "aws", Include: []string{"aws"}}}, UnknownVariant: "aws"}},
+		{name: "unnamed dimension", config: VariantConfig{Variants: []VariantDefinition{{Name: "aws", Include: []string{"aws"}}}, Dimensions: []VariantDimension{{Variants: []string{"aws"}, Default: "other"}}}},
+		{name: "duplicate dimension", config: VariantConfig{Variants: []VariantDefinition{{Name: "aws", Include: []string{"aws"}}}, Dimensions: []VariantDimension{{Name: "platform", Variants: []string{"aws"}, Default: "other"}, {Name: "platform", Variants: []string{"aws"}, Default: "other"}}}},
+		{name: "undefined dimension variant", config: VariantConfig{Variants: []VariantDefinition{{Name: "aws", Include: []string{"aws"}}}, Dimensions: []VariantDimension{{Name: "platform", Variants: []string{"gcp"}, Default: "other"}}}},
+		{name: "dimension without default", config: VariantConfig{Variants: []VariantDefinition{{Name: "aws", Include: []string{"aws"}}}, Dimensions: []VariantDimension{{Name: "platform", Variants: []string{"aws"}}}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewConfigVariantManager(tc.config); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
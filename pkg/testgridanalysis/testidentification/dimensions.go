@@ -0,0 +1,40 @@
+package testidentification
+
+// VariantDimension groups variants that are alternatives to each other, like the platform a job runs on.  A job has one
+// value for every dimension: the first of the Variants it belongs to, or Default if it belongs to none of them.
+type VariantDimension struct {
+	Name     string   `json:"name"`
+	Variants []string `json:"variants"`
+	Default  string   `json:"default"`
+}
+
+// Value returns the value of the dimension for a job with the variants, and whether the job belongs to any of the
+// variants of the dimension.
+func (d VariantDimension) Value(jobVariants []string) (string, bool) {
+	for _, variant := range d.Variants {
+		for _, jobVariant := range jobVariants {
+			if variant == jobVariant {
+				return variant, true
+			}
+		}
+	}
+	return d.Default, false
+}
+
+// DimensionedVariantManager is a VariantManager whose variants can be grouped into dimensions, so that combinations of
+// variants can be compared.
+type DimensionedVariantManager interface {
+	VariantManager
+
+	// Dimensions returns the dimensions in the order they should be displayed.
+	Dimensions() []VariantDimension
+}
+
+// VariantDimensions returns the dimensions of the variant manager, or nil if its variants have no dimensions.
+func VariantDimensions(variantManager VariantManager) []VariantDimension {
+	dimensioned, ok := variantManager.(DimensionedVariantManager)
+	if !ok {
+		return nil
+	}
+	return dimensioned.Dimensions()
+}
@@ -0,0 +1,169 @@
+package testidentification
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/openshift/sippy/pkg/util"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+// IdentificationConfigVersion is the version of the IdentificationConfig format that this sippy reads.
+const IdentificationConfigVersion = "v1"
+
+// IdentificationConfig holds how tests are identified and which bugzilla components they belong to, so that TRT can
+// curate them per release without shipping a new binary.
+type IdentificationConfig struct {
+	Version string `json:"version"`
+	// JobSetupContainers are substrings of the names of tests that play the part of the setup container in jobs
+	// whose setup container is named differently.
+	JobSetupContainers []string `json:"jobSetupContainers"`
+	// CuratedTests are substrings of the names of tests that are important enough to watch individually, keyed by
+	// bugzilla release.
+	CuratedTests map[string][]string `json:"curatedTests"`
+	// BugzillaComponents are the valid bugzilla components.  It must include "Unknown".
+	BugzillaComponents []string `json:"bugzillaComponents"`
+	// OperatorComponents maps operator names to the bugzilla component of their bugs.
+	OperatorComponents map[string]string `json:"operatorComponents"`
+	// SigComponents maps `sig-foo` (from a '[sig-foo]' label in a test name) to the "most correct" bugzilla component.
+	SigComponents map[string]string `json:"sigComponents"`
+}
+
+// DefaultIdentificationConfig returns the config that is used unless another one is loaded.
+func DefaultIdentificationConfig() IdentificationConfig {
+	config := IdentificationConfig{
+		Version:            IdentificationConfigVersion,
+		JobSetupContainers: append([]string{}, defaultJobSetupContainers...),
+		CuratedTests:       map[string][]string{},
+		BugzillaComponents: append([]string{}, defaultBugzillaComponents...),
+		OperatorComponents: map[string]string{},
+		SigComponents:      map[string]string{},
+	}
+	for release, substrings := range defaultCuratedTests {
+		config.CuratedTests[release] = append([]string{}, substrings...)
+	}
+	for operator, component := range defaultOperatorComponents {
+		config.OperatorComponents[operator] = component
+	}
+	for sig, component := range defaultSigComponents {
+		config.SigComponents[sig] = component
+	}
+	return config
+}
+
+// LoadIdentificationConfig reads an IdentificationConfig from a JSON file and validates it.  Fields that are missing
+// from the file keep their default values.
+func LoadIdentificationConfig(filename string) (IdentificationConfig, error) {
+	config := IdentificationConfig{}
+	if err := util.LoadStrictJSON(filename, &config); err != nil {
+		return config, err
+	}
+
+	defaults := DefaultIdentificationConfig()
+	if config.JobSetupContainers == nil {
+		config.JobSetupContainers = defaults.JobSetupContainers
+	}
+	if config.CuratedTests == nil {
+		config.CuratedTests = defaults.CuratedTests
+	}
+	if config.BugzillaComponents == nil {
+		config.BugzillaComponents = defaults.BugzillaComponents
+	}
+	if config.OperatorComponents == nil {
+		config.OperatorComponents = defaults.OperatorComponents
+	}
+	if config.SigComponents == nil {
+		config.SigComponents = defaults.SigComponents
+	}
+
+	if err := config.Validate(); err != nil {
+		return config, fmt.Errorf("invalid %s: %v", filename, err)
+	}
+	return config, nil
+}
+
+// Validate returns an error if the config has an unknown version or is inconsistent.
+func (c IdentificationConfig) Validate() error {
+	if c.Version != IdentificationConfigVersion {
+		return fmt.Errorf("unsupported version %q, only %q is supported", c.Version, IdentificationConfigVersion)
+	}
+	// an empty substring would match every test
+	for _, setup := range c.JobSetupContainers {
+		if len(setup) == 0 {
+			return fmt.Errorf("jobSetupContainers must not be empty strings")
+		}
+	}
+	for release, substrings := range c.CuratedTests {
+		for _, substring := range substrings {
+			if len(substring) == 0 {
+				return fmt.Errorf("curatedTests for %q must not be empty strings", release)
+			}
+		}
+	}
+
+	components := sets.NewString(c.BugzillaComponents...)
+	if !components.Has("Unknown") {
+		return fmt.Errorf("bugzillaComponents must include \"Unknown\"")
+	}
+	for operator, component := range c.OperatorComponents {
+		if !components.Has(component) {
+			return fmt.Errorf("operator %q maps to %q, which is not a valid bugzilla component", operator, component)
+		}
+	}
+	for sig, component := range c.SigComponents {
+		if !components.Has(component) {
+			return fmt.Errorf("sig %q maps to %q, which is not a valid bugzilla component", sig, component)
+		}
+	}
+	return nil
+}
+
+// identification is the config in use, with lookups that are faster than the config itself.
+type identification struct {
+	config             IdentificationConfig
+	bugzillaComponents sets.String
+	operatorComponents map[string]string
+	sigComponents      map[string]string
+}
+
+var (
+	defaultIdentification = newIdentification(DefaultIdentificationConfig())
+	// identificationInUse holds the *identification in use, if it is not the default
+	identificationInUse atomic.Value
+)
+
+func newIdentification(config IdentificationConfig) *identification {
+	return &identification{
+		config:             config,
+		bugzillaComponents: sets.NewString(config.BugzillaComponents...),
+		operatorComponents: config.OperatorComponents,
+		sigComponents:      config.SigComponents,
+	}
+}
+
+func currentIdentification() *identification {
+	if curr, ok := identificationInUse.Load().(*identification); ok {
+		return curr
+	}
+	return defaultIdentification
+}
+
+// SetIdentificationConfig validates the config and uses it from then on.  It is safe to call while reports are built,
+// every lookup sees either the old or the new config.
+func SetIdentificationConfig(config IdentificationConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	identificationInUse.Store(newIdentification(config))
+	return nil
+}
+
+// CurrentIdentificationConfig returns the config in use.  It must not be modified.
+func CurrentIdentificationConfig() IdentificationConfig {
+	return currentIdentification().config
+}
+
+// IsValidBugzillaComponent returns true if the component is one of the bugzilla components of the config in use.
+func IsValidBugzillaComponent(component string) bool {
+	return currentIdentification().bugzillaComponents.Has(component)
+}
@@ -0,0 +1,94 @@
+package testidentification
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeIdentificationConfig(t *testing.T, dir, content string) string {
+	filename := filepath.Join(dir, "identification.json")
+	if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestDefaultIdentificationConfigIsValid(t *testing.T) {
+	if err := DefaultIdentificationConfig().Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadIdentificationConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "identification-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := writeIdentificationConfig(t, dir, `{
+		"version": "v1",
+		"jobSetupContainers": ["e2e-foo-install"],
+		"curatedTests": {"4.7": ["pods should successfully create sandboxes"]},
+		"operatorComponents": {"etcd": "Etcd"}
+	}`)
+	config, err := LoadIdentificationConfig(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(config.JobSetupContainers, []string{"e2e-foo-install"}) {
+		t.Errorf("expected the setup containers of the file, got %v", config.JobSetupContainers)
+	}
+	if !reflect.DeepEqual(config.BugzillaComponents, DefaultIdentificationConfig().BugzillaComponents) {
+		t.Errorf("expected the default bugzilla components when the file has none")
+	}
+
+	if err := SetIdentificationConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	defer SetIdentificationConfig(DefaultIdentificationConfig())
+
+	if !IsSetupContainerEquivalent("e2e-foo-install container test") {
+		t.Error("expected the setup container of the config to be recognized")
+	}
+	if IsSetupContainerEquivalent("e2e-vsphere-ipi-install-vsphere") {
+		t.Error("expected the default setup containers to be replaced")
+	}
+	if !IsCuratedTest("4.7", "[sig-network] pods should successfully create sandboxes by other") || IsCuratedTest("4.6", "install should work") {
+		t.Error("expected only the curated tests of the config")
+	}
+	if GetBugzillaComponentForOperator("etcd") != "Etcd" || GetBugzillaComponentForOperator("dns") != "Unknown" {
+		t.Error("expected only the operator components of the config")
+	}
+}
+
+func TestLoadIdentificationConfigErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "identification-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "missing version", content: `{}`},
+		{name: "future version", content: `{"version": "v2"}`},
+		{name: "empty setup container", content: `{"version": "v1", "jobSetupContainers": [""]}`},
+		{name: "empty curated test", content: `{"version": "v1", "curatedTests": {"4.7": [""]}}`},
+		{name: "no unknown component", content: `{"version": "v1", "bugzillaComponents": ["Etcd"], "operatorComponents": {}, "sigComponents": {}}`},
+		{name: "invalid operator component", content: `{"version": "v1", "operatorComponents": {"etcd": "etcd"}}`},
+		{name: "invalid sig component", content: `{"version": "v1", "sigComponents": {"sig-etcd": "etcd"}}`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := LoadIdentificationConfig(writeIdentificationConfig(t, dir, tc.content)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
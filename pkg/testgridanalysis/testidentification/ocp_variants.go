@@ -68,6 +68,18 @@ var (
 		"release-openshift-origin-installer-e2e-aws-upgrade-rollback-4.5-to-4.6", // this is manual for a networking change
 		"release-openshift-origin-installer-e2e-aws-disruptive-4.6",              // doesn't recover cleanly.  There is a bug.
 	)
+
+	// openshiftVariantDimensions are the ways openshift jobs differ from one another
+	openshiftVariantDimensions = []VariantDimension{
+		{
+			Name:     "platform",
+			Variants: []string{"aws", "azure", "gcp", "metal-assisted", "metal-ipi", "metal-upi", "openstack", "ovirt", "vsphere-ipi", "vsphere-upi"},
+			Default:  "other",
+		},
+		{Name: "network", Variants: []string{"ovn"}, Default: "sdn"},
+		{Name: "upgrade", Variants: []string{"upgrade"}, Default: "no-upgrade"},
+		{Name: "architecture", Variants: []string{"ppc64le", "s390x"}, Default: "amd64"},
+	}
 )
 
 type openshiftVariants struct{}
@@ -171,6 +183,11 @@ func (v openshiftVariants) IdentifyVariants(jobName string) []string {
 
 	return variants
 }
+
+func (openshiftVariants) Dimensions() []VariantDimension {
+	return openshiftVariantDimensions
+}
+
 func (openshiftVariants) IsJobNeverStable(jobName string) bool {
 	return openshiftJobsNeverStableForVariants.Has(jobName)
 }
@@ -0,0 +1,218 @@
+package testidentification
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/util"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+const (
+	OwnershipSourceOverride = "override"
+	OwnershipSourceRule     = "rule"
+	OwnershipSourceSig      = "sig"
+	OwnershipSourceOperator = "operator"
+	// OwnershipSourceDefault is the source of tests that no ownership source matched.
+	OwnershipSourceDefault = "default"
+)
+
+// TestOwnership resolves the owner of every test.
+type TestOwnership interface {
+	Owner(testName string) sippyprocessingv1.TestOwner
+}
+
+// OwnershipSource resolves the owners of some tests.
+type OwnershipSource interface {
+	// Name identifies the source in TestOwner.Source.
+	Name() string
+	// Owner returns the owner of the test, or false if the source does not know who owns it.
+	Owner(testName string) (sippyprocessingv1.TestOwner, bool)
+}
+
+// OwnershipConfig holds the owners of tests that cannot be found from the test names alone.
+type OwnershipConfig struct {
+	// Tests are the owners of individual tests, by test name.  They take precedence over everything else.
+	Tests map[string]Ownership `json:"tests,omitempty"`
+	// Rules are checked in order, after Tests and before the sig and operator of the test.
+	Rules []OwnershipRule `json:"rules,omitempty"`
+	// ComponentTeams are the teams of owners that have a component but no team.
+	ComponentTeams map[string]string `json:"componentTeams,omitempty"`
+}
+
+// Ownership is the component, and optionally the team, that owns a test.
+type Ownership struct {
+	Component string `json:"component"`
+	Team      string `json:"team,omitempty"`
+}
+
+// OwnershipRule owns the tests whose names match Regex.
+type OwnershipRule struct {
+	Regex string `json:"regex"`
+	Ownership
+}
+
+// LoadOwnershipConfig reads an OwnershipConfig from a JSON file.
+func LoadOwnershipConfig(filename string) (OwnershipConfig, error) {
+	config := OwnershipConfig{}
+	err := util.LoadStrictJSON(filename, &config)
+	return config, err
+}
+
+// NewTestOwnership returns the TestOwnership that resolves owners from the per-test overrides and the rules of the
+// config, then from the sig of the test, then from the operator of the test.  It returns an error if the config is
+// invalid, or has owners that are not bugzilla components of the identification config in use.
+func NewTestOwnership(config OwnershipConfig) (TestOwnership, error) {
+	return newTestOwnership(config, currentIdentification().bugzillaComponents)
+}
+
+// ValidateOwnershipConfig returns an error if the ownership config is invalid, or has owners that are not bugzilla
+// components of the identification config.  It lets a new identification config be checked before it is used.
+func ValidateOwnershipConfig(config OwnershipConfig, identification IdentificationConfig) error {
+	_, err := newTestOwnership(config, sets.NewString(identification.BugzillaComponents...))
+	return err
+}
+
+func newTestOwnership(config OwnershipConfig, bugzillaComponents sets.String) (TestOwnership, error) {
+	overrides := overrideOwnershipSource{}
+	for testName, ownership := range config.Tests {
+		if err := ownership.validate(bugzillaComponents); err != nil {
+			return nil, fmt.Errorf("test %q: %v", testName, err)
+		}
+		overrides[testName] = ownership
+	}
+
+	rules := ruleOwnershipSource{}
+	for i, rule := range config.Rules {
+		if err := rule.validate(bugzillaComponents); err != nil {
+			return nil, fmt.Errorf("rule %d: %v", i, err)
+		}
+		regex, err := regexp.Compile(rule.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d has an invalid regex: %v", i, err)
+		}
+		rules = append(rules, compiledOwnershipRule{regex: regex, ownership: rule.Ownership})
+	}
+
+	return NewOwnershipChain(config.ComponentTeams, overrides, rules, sigOwnershipSource{}, operatorOwnershipSource{}), nil
+}
+
+// DefaultTestOwnership resolves owners from the sig and operator of the tests only.
+func DefaultTestOwnership() TestOwnership {
+	return NewOwnershipChain(nil, sigOwnershipSource{}, operatorOwnershipSource{})
+}
+
+func (o Ownership) validate(bugzillaComponents sets.String) error {
+	if len(o.Component) == 0 {
+		return fmt.Errorf("must have a component")
+	}
+	if !bugzillaComponents.Has(o.Component) {
+		return fmt.Errorf("%q is not a valid bugzilla component", o.Component)
+	}
+	return nil
+}
+
+func (o Ownership) owner(source string) sippyprocessingv1.TestOwner {
+	return sippyprocessingv1.TestOwner{Component: o.Component, Team: o.Team, Source: source}
+}
+
+type ownershipChain struct {
+	sources        []OwnershipSource
+	componentTeams map[string]string
+}
+
+// NewOwnershipChain returns a TestOwnership that asks each source in order and uses the first owner found.  Owners
+// without a team get the team of their component from componentTeams.
+func NewOwnershipChain(componentTeams map[string]string, sources ...OwnershipSource) TestOwnership {
+	return ownershipChain{sources: sources, componentTeams: componentTeams}
+}
+
+func (c ownershipChain) Owner(testName string) sippyprocessingv1.TestOwner {
+	owner := sippyprocessingv1.TestOwner{Component: "Unknown", Source: OwnershipSourceDefault}
+	for _, source := range c.sources {
+		if found, ok := source.Owner(testName); ok {
+			owner = found
+			owner.Source = source.Name()
+			break
+		}
+	}
+	if len(owner.Team) == 0 {
+		owner.Team = c.componentTeams[owner.Component]
+	}
+	return owner
+}
+
+type overrideOwnershipSource map[string]Ownership
+
+func (overrideOwnershipSource) Name() string {
+	return OwnershipSourceOverride
+}
+
+func (s overrideOwnershipSource) Owner(testName string) (sippyprocessingv1.TestOwner, bool) {
+	ownership, ok := s[testName]
+	if !ok {
+		return sippyprocessingv1.TestOwner{}, false
+	}
+	return ownership.owner(OwnershipSourceOverride), true
+}
+
+type compiledOwnershipRule struct {
+	regex     *regexp.Regexp
+	ownership Ownership
+}
+
+type ruleOwnershipSource []compiledOwnershipRule
+
+func (ruleOwnershipSource) Name() string {
+	return OwnershipSourceRule
+}
+
+func (s ruleOwnershipSource) Owner(testName string) (sippyprocessingv1.TestOwner, bool) {
+	for _, rule := range s {
+		if rule.regex.MatchString(testName) {
+			return rule.ownership.owner(OwnershipSourceRule), true
+		}
+	}
+	return sippyprocessingv1.TestOwner{}, false
+}
+
+// sigOwnershipSource owns tests with a [sig-foo] tag that maps to a component in the identification config.
+type sigOwnershipSource struct{}
+
+func (sigOwnershipSource) Name() string {
+	return OwnershipSourceSig
+}
+
+func (sigOwnershipSource) Owner(testName string) (sippyprocessingv1.TestOwner, bool) {
+	match := sigRegex.FindStringSubmatch(testName)
+	if len(match) < 2 {
+		return sippyprocessingv1.TestOwner{}, false
+	}
+	component, ok := currentIdentification().sigComponents[match[1]]
+	if !ok {
+		return sippyprocessingv1.TestOwner{}, false
+	}
+	return sippyprocessingv1.TestOwner{Component: component, Source: OwnershipSourceSig}, true
+}
+
+// operatorOwnershipSource owns the install, upgrade, and health tests of operators that map to a component in the
+// identification config.
+type operatorOwnershipSource struct{}
+
+func (operatorOwnershipSource) Name() string {
+	return OwnershipSourceOperator
+}
+
+func (operatorOwnershipSource) Owner(testName string) (sippyprocessingv1.TestOwner, bool) {
+	operator := strings.TrimSpace(GetOperatorNameFromTest(testName))
+	if len(operator) == 0 {
+		return sippyprocessingv1.TestOwner{}, false
+	}
+	component, ok := currentIdentification().operatorComponents[operator]
+	if !ok {
+		return sippyprocessingv1.TestOwner{}, false
+	}
+	return sippyprocessingv1.TestOwner{Component: component, Source: OwnershipSourceOperator}, true
+}
@@ -0,0 +1,132 @@
+package testidentification
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+)
+
+func TestGetBugzillaComponentForSig(t *testing.T) {
+	if component := GetBugzillaComponentForSig("sig-network"); component != "Networking" {
+		t.Errorf("expected Networking, got %q", component)
+	}
+	if component := GetBugzillaComponentForSig("sig-bogus"); component != "Unknown" {
+		t.Errorf("expected Unknown, got %q", component)
+	}
+}
+
+func TestTestOwnership(t *testing.T) {
+	testOwnership, err := NewTestOwnership(OwnershipConfig{
+		Tests: map[string]Ownership{
+			"[sig-network] dns should resolve": {Component: "DNS", Team: "dns-team"},
+		},
+		Rules: []OwnershipRule{
+			{Regex: `\[sig-network\].*ingress`, Ownership: Ownership{Component: "Routing"}},
+			{Regex: `ingress`, Ownership: Ownership{Component: "Networking"}},
+		},
+		ComponentTeams: map[string]string{
+			"Networking": "sdn-team",
+			"Routing":    "routing-team",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		testName string
+		expected sippyprocessingv1.TestOwner
+	}{
+		{
+			testName: "[sig-network] dns should resolve",
+			expected: sippyprocessingv1.TestOwner{Component: "DNS", Team: "dns-team", Source: OwnershipSourceOverride},
+		},
+		{
+			testName: "[sig-network] ingress should route",
+			expected: sippyprocessingv1.TestOwner{Component: "Routing", Team: "routing-team", Source: OwnershipSourceRule},
+		},
+		{
+			testName: "[sig-storage] ingress should not matter",
+			expected: sippyprocessingv1.TestOwner{Component: "Networking", Team: "sdn-team", Source: OwnershipSourceRule},
+		},
+		{
+			testName: "[sig-network] pods should talk",
+			expected: sippyprocessingv1.TestOwner{Component: "Networking", Team: "sdn-team", Source: OwnershipSourceSig},
+		},
+		{
+			testName: "operator conditions etcd",
+			expected: sippyprocessingv1.TestOwner{Component: "Etcd", Source: OwnershipSourceOperator},
+		},
+		{
+			testName: "[sig-bogus] nobody owns this",
+			expected: sippyprocessingv1.TestOwner{Component: "Unknown", Source: OwnershipSourceDefault},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.testName, func(t *testing.T) {
+			if actual := testOwnership.Owner(tc.testName); actual != tc.expected {
+				t.Errorf("expected %#v, got %#v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNewTestOwnershipErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config OwnershipConfig
+	}{
+		{name: "test without component", config: OwnershipConfig{Tests: map[string]Ownership{"a": {Team: "team"}}}},
+		{name: "test with invalid component", config: OwnershipConfig{Tests: map[string]Ownership{"a": {Component: "bogus"}}}},
+		{name: "rule with invalid component", config: OwnershipConfig{Rules: []OwnershipRule{{Regex: "a", Ownership: Ownership{Component: "bogus"}}}}},
+		{name: "rule with invalid regex", config: OwnershipConfig{Rules: []OwnershipRule{{Regex: "(", Ownership: Ownership{Component: "Etcd"}}}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewTestOwnership(tc.config); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestLoadOwnershipConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ownership-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "ownership.json")
+	if err := ioutil.WriteFile(filename, []byte(`{"rules": [{"regex": "etcd", "component": "Etcd", "team": "etcd-team"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadOwnershipConfig(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Rules) != 1 || config.Rules[0].Component != "Etcd" || config.Rules[0].Team != "etcd-team" {
+		t.Errorf("expected the rule of the file, got %#v", config.Rules)
+	}
+}
+
+func TestValidateOwnershipConfig(t *testing.T) {
+	config := OwnershipConfig{
+		Rules: []OwnershipRule{{Regex: "csi", Ownership: Ownership{Component: "Storage"}}},
+	}
+	identification := DefaultIdentificationConfig()
+	identification.BugzillaComponents = []string{"Storage"}
+	if err := ValidateOwnershipConfig(config, identification); err != nil {
+		t.Errorf("expected the owners to be components of the identification config: %v", err)
+	}
+	identification.BugzillaComponents = []string{"Networking"}
+	if err := ValidateOwnershipConfig(config, identification); err == nil {
+		t.Errorf("expected an error for an owner that is not a component of the identification config")
+	}
+	if !IsValidBugzillaComponent("Storage") {
+		t.Errorf("expected the identification config in use to be left alone")
+	}
+}
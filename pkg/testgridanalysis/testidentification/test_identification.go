@@ -5,10 +5,10 @@ import (
 	"strings"
 
 	"github.com/openshift/sippy/pkg/testgridanalysis/testgridanalysisapi"
-	"github.com/openshift/sippy/pkg/util/sets"
 )
 
-var customJobSetupContainers = sets.NewString(
+// defaultJobSetupContainers are the setup container equivalents of the default IdentificationConfig
+var defaultJobSetupContainers = []string{
 	"e2e-44-stable-to-45-ci-ipi-install-install-stableinitial",
 	"e2e-aws-proxy-ipi-install-install",
 	"e2e-aws-upgrade-ipi-install-install-stableinitial",
@@ -35,14 +35,13 @@ var customJobSetupContainers = sets.NewString(
 	"install-install container test",
 	"install-stableinitial container test",
 	"hypershift-launch-wait-for-nodes",
-
-)
+}
 
 // TODO We should instead try to detect whether we fail in a pre-step to determine whether setup succeeded
 // not all setup containers are called setup.  This is heavily dependent on the actual UPI jobs, but they turn out to be different.
 // When this needs updating,  it shows up as installs timing out in weird numbers
 func IsSetupContainerEquivalent(testName string) bool {
-	for setup := range customJobSetupContainers {
+	for _, setup := range currentIdentification().config.JobSetupContainers {
 		if strings.Contains(testName, setup) {
 			return true
 		}
@@ -63,10 +62,10 @@ func IsSetupContainerEquivalent(testName string) bool {
 	return false
 }
 
-// curatedTestSubstrings is keyed by release.  This is a list of tests that are important enough to individually watch.
+// defaultCuratedTests is keyed by release.  This is a list of tests that are important enough to individually watch.
 // Whoever is running or working on TRT gets freedom to choose 10-20 of these for whatever reason they need.  At the moment,
 // we're chasing problems where pods are not running reliably and we have to track it down.
-var curatedTestSubstrings = map[string][]string{
+var defaultCuratedTests = map[string][]string{
 	"4.6": []string{
 		"[Feature:SCC][Early] should not have pod creation failures during install",
 		"infrastructure should work",
@@ -90,7 +89,7 @@ var (
 )
 
 func IsCuratedTest(bugzillaRelease, testName string) bool {
-	for _, substring := range curatedTestSubstrings[bugzillaRelease] {
+	for _, substring := range currentIdentification().config.CuratedTests[bugzillaRelease] {
 		if strings.Contains(testName, substring) {
 			return true
 		}
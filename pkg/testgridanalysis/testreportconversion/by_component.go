@@ -3,8 +3,6 @@ package testreportconversion
 import (
 	"sort"
 
-	"github.com/openshift/sippy/pkg/testgridanalysis/testidentification"
-
 	bugsv1 "github.com/openshift/sippy/pkg/apis/bugs/v1"
 	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
 	"github.com/openshift/sippy/pkg/testgridanalysis/testgridanalysisapi"
@@ -132,20 +130,9 @@ func getBugzillaComponentsFromTestResult(testResult sippyprocessingv1.TestResult
 		return bzComponents.List()
 	}
 
-	// If we didn't have a bug, use the test name itself to identify a likely victim/blame
-	switch {
-	case testidentification.IsOldInstallOperatorTest(testResult.Name):
-		operatorName := testidentification.GetOperatorFromInstallTest(testResult.Name)
-		return []string{testidentification.GetBugzillaComponentForOperator(operatorName)}
-
-	case testidentification.IsOldUpgradeOperatorTest(testResult.Name):
-		operatorName := testidentification.GetOperatorFromUpgradeTest(testResult.Name)
-		return []string{testidentification.GetBugzillaComponentForOperator(operatorName)}
-
-	default:
-		return []string{testidentification.GetBugzillaComponentForSig(testidentification.FindSig(testResult.Name))}
-	}
-
+	// If we didn't have a bug, blame the owner of the test.  The owner is resolved from the sig and operator of the
+	// test, and is "Unknown" if neither identifies it.
+	return []string{testResult.Owner.Component}
 }
 
 func getTestResultForJob(jobTestResults []sippyprocessingv1.TestResult, testName string) (sippyprocessingv1.TestResult, bool) {
@@ -79,12 +79,15 @@ func getTestResultsByName(jobResults []sippyprocessingv1.JobResult) testResultsB
 					TestSuccesses:  testResult.Successes,
 					PassPercentage: testResult.PassPercentage,
 					TestGridUrl:    jobResult.TestGridUrl,
+					RunPattern:     testResult.RunPattern,
+					Classification: classifyTestResult(testResult),
 				})
 				break
 			}
 		}
 
 		sort.Stable(failingTestJobResultByJobPassPercentage(failingTestResult.JobResults))
+		failingTestResult.Classification = classifyJobResults(failingTestResult.JobResults)
 
 		testsByName[testName] = failingTestResult
 	}
@@ -150,6 +153,7 @@ func getTopFailingTests(
 			TestName:                testResult.TestName,
 			TestResultAcrossAllJobs: testResult.TestResultAcrossAllJobs,
 			JobResults:              nil,
+			Classification:          testResult.Classification,
 		}
 		for _, jobResult := range testResult.JobResults {
 			// if the job hasn't run at least 7 times, don't add it to the list
@@ -5,6 +5,7 @@ import (
 
 	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
 	"github.com/openshift/sippy/pkg/testgridanalysis/testidentification"
+	"github.com/openshift/sippy/pkg/util"
 	"github.com/openshift/sippy/pkg/util/sets"
 )
 
@@ -55,9 +56,9 @@ func convertRawDataToByVariant(
 			JobRunFailures:                        failedJobRuns,
 			JobRunKnownFailures:                   knownFailureJobRuns,
 			JobRunInfrastructureFailures:          infraFailureJobRuns,
-			JobRunPassPercentage:                  percent(successfulJobRuns, failedJobRuns),
-			JobRunPassPercentageWithKnownFailures: percent(successfulJobRuns+knownFailureJobRuns, failedJobRuns-knownFailureJobRuns),
-			JobRunPassPercentageWithoutInfrastructureFailures: percent(successfulJobRuns, failedJobRuns-infraFailureJobRuns),
+			JobRunPassPercentage:                  util.Percent(successfulJobRuns, failedJobRuns),
+			JobRunPassPercentageWithKnownFailures: util.Percent(successfulJobRuns+knownFailureJobRuns, failedJobRuns-knownFailureJobRuns),
+			JobRunPassPercentageWithoutInfrastructureFailures: util.Percent(successfulJobRuns, failedJobRuns-infraFailureJobRuns),
 			JobResults:     jobResults,
 			AllTestResults: filteredVariantTestResults,
 		}
@@ -0,0 +1,206 @@
+package testreportconversion
+
+import (
+	"sort"
+
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testgridanalysisapi"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testidentification"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+const (
+	// minFailureClusterSupport is the number of job runs a set of tests has to fail together in to be a cluster.
+	minFailureClusterSupport = 3
+	// minFailureClusterConfidence is the fraction of the failures of every test in a cluster that have to happen together
+	// with the rest of the cluster.  Without it, a test that fails all the time would join every cluster.
+	minFailureClusterConfidence = 0.6
+	// maxFailuresPerClusteredJobRun excludes job runs with more failures than this.  Those runs are mass failures,
+	// which are reported as failure groups, and they would tie together tests that are otherwise unrelated.
+	maxFailuresPerClusteredJobRun = 25
+	// maxFailureClusterExamples is how many example job runs are listed for a cluster.
+	maxFailureClusterExamples = 5
+)
+
+// findFailureClusters finds sets of tests that repeatedly fail in the same job runs.  Each cluster starts from the pair
+// of tests that failed together most often and greedily adds the test that keeps the most runs in common, as long as
+// every test in the cluster keeps failing together with the rest in at least minFailureClusterSupport runs and
+// minFailureClusterConfidence of its failures.  A test belongs to at most one cluster.
+func findFailureClusters(
+	rawJobResults map[string]testgridanalysisapi.RawJobResult,
+	variantManager testidentification.VariantManager,
+) []sippyprocessingv1.FailureCluster {
+	// failedRuns maps test names to the URLs of the job runs they failed in
+	failedRuns := map[string]sets.String{}
+	// jobRuns maps the URLs of the clustered job runs to the runs
+	jobRuns := map[string]testgridanalysisapi.RawJobRunResult{}
+	for _, jobResult := range rawJobResults {
+		for _, rawJRR := range jobResult.JobRunResults {
+			if len(rawJRR.FailedTestNames) > maxFailuresPerClusteredJobRun {
+				continue
+			}
+			jobRuns[rawJRR.JobRunURL] = rawJRR
+			for _, testName := range rawJRR.FailedTestNames {
+				// synthetic tests summarize other failures, so they would cluster with whatever caused them
+				if jobResult.TestResults[testName].Synthetic {
+					continue
+				}
+				if _, ok := failedRuns[testName]; !ok {
+					failedRuns[testName] = sets.NewString()
+				}
+				failedRuns[testName].Insert(rawJRR.JobRunURL)
+			}
+		}
+	}
+
+	frequentTests := sets.NewString()
+	for testName, runs := range failedRuns {
+		if runs.Len() >= minFailureClusterSupport {
+			frequentTests.Insert(testName)
+		}
+	}
+
+	// only pairs of tests that failed in the same run at least once can be clustered, so start from those instead of
+	// comparing every pair of tests.
+	type pair struct {
+		lhs, rhs string
+		support  int
+	}
+	candidatePairs := map[pair]bool{}
+	for _, jobResult := range rawJobResults {
+		for _, rawJRR := range jobResult.JobRunResults {
+			if len(rawJRR.FailedTestNames) > maxFailuresPerClusteredJobRun {
+				continue
+			}
+			testNames := sets.NewString(rawJRR.FailedTestNames...).Intersection(frequentTests).List()
+			for i := range testNames {
+				for j := i + 1; j < len(testNames); j++ {
+					candidatePairs[pair{lhs: testNames[i], rhs: testNames[j]}] = true
+				}
+			}
+		}
+	}
+	pairs := []pair{}
+	// neighbors are the tests each test forms a cluster with.  Every test in a cluster forms a cluster with every other
+	// member on its own, so they are the only tests worth trying to add to a cluster.
+	neighbors := map[string]sets.String{}
+	for candidate := range candidatePairs {
+		runs := failedRuns[candidate.lhs].Intersection(failedRuns[candidate.rhs])
+		if !isFailureCluster(failedRuns, runs, candidate.lhs, candidate.rhs) {
+			continue
+		}
+		candidate.support = runs.Len()
+		pairs = append(pairs, candidate)
+		for _, testName := range []string{candidate.lhs, candidate.rhs} {
+			if _, ok := neighbors[testName]; !ok {
+				neighbors[testName] = sets.NewString()
+			}
+		}
+		neighbors[candidate.lhs].Insert(candidate.rhs)
+		neighbors[candidate.rhs].Insert(candidate.lhs)
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if pairs[i].support != pairs[j].support {
+			return pairs[i].support > pairs[j].support
+		}
+		if pairs[i].lhs != pairs[j].lhs {
+			return pairs[i].lhs < pairs[j].lhs
+		}
+		return pairs[i].rhs < pairs[j].rhs
+	})
+
+	clustered := sets.NewString()
+	clusters := []sippyprocessingv1.FailureCluster{}
+	for _, seed := range pairs {
+		if clustered.HasAny(seed.lhs, seed.rhs) {
+			continue
+		}
+		members := sets.NewString(seed.lhs, seed.rhs)
+		runs := failedRuns[seed.lhs].Intersection(failedRuns[seed.rhs])
+		for {
+			bestTest, bestRuns := "", sets.NewString()
+			for _, candidate := range neighbors[seed.lhs].List() {
+				if clustered.Has(candidate) || members.Has(candidate) {
+					continue
+				}
+				candidateRuns := runs.Intersection(failedRuns[candidate])
+				if candidateRuns.Len() <= bestRuns.Len() {
+					continue
+				}
+				if isFailureCluster(failedRuns, candidateRuns, append(members.List(), candidate)...) {
+					bestTest, bestRuns = candidate, candidateRuns
+				}
+			}
+			if len(bestTest) == 0 {
+				break
+			}
+			members.Insert(bestTest)
+			runs = bestRuns
+		}
+
+		clustered = clustered.Union(members)
+		clusters = append(clusters, newFailureCluster(members.List(), runs, failedRuns, jobRuns, variantManager))
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if clusters[i].Support != clusters[j].Support {
+			return clusters[i].Support > clusters[j].Support
+		}
+		return len(clusters[i].TestNames) > len(clusters[j].TestNames)
+	})
+	return clusters
+}
+
+// isFailureCluster returns true if the tests failed together in enough runs, and enough of the failures of each test
+// happened together with the others.
+func isFailureCluster(failedRuns map[string]sets.String, runsTogether sets.String, testNames ...string) bool {
+	if runsTogether.Len() < minFailureClusterSupport {
+		return false
+	}
+	return failureClusterConfidence(failedRuns, runsTogether, testNames) >= minFailureClusterConfidence
+}
+
+func failureClusterConfidence(failedRuns map[string]sets.String, runsTogether sets.String, testNames []string) float64 {
+	confidence := 1.0
+	for _, testName := range testNames {
+		if curr := float64(runsTogether.Len()) / float64(failedRuns[testName].Len()); curr < confidence {
+			confidence = curr
+		}
+	}
+	return confidence
+}
+
+// newFailureCluster describes the cluster of the sorted testNames that all failed in the runs.
+func newFailureCluster(
+	testNames []string,
+	runs sets.String,
+	failedRuns map[string]sets.String,
+	jobRuns map[string]testgridanalysisapi.RawJobRunResult,
+	variantManager testidentification.VariantManager,
+) sippyprocessingv1.FailureCluster {
+	jobs := sets.NewString()
+	variants := sets.NewString()
+	for _, run := range runs.UnsortedList() {
+		jobName := jobRuns[run].Job
+		jobs.Insert(jobName)
+		variants.Insert(variantManager.IdentifyVariants(jobName)...)
+	}
+
+	// the examples are the most recent runs
+	examples := runs.List()
+	sort.SliceStable(examples, func(i, j int) bool {
+		return jobRuns[examples[i]].Timestamp > jobRuns[examples[j]].Timestamp
+	})
+	if len(examples) > maxFailureClusterExamples {
+		examples = examples[:maxFailureClusterExamples]
+	}
+
+	return sippyprocessingv1.FailureCluster{
+		TestNames:         testNames,
+		Support:           runs.Len(),
+		Confidence:        failureClusterConfidence(failedRuns, runs, testNames),
+		Jobs:              jobs.List(),
+		Variants:          variants.List(),
+		ExampleJobRunURLs: examples,
+	}
+}
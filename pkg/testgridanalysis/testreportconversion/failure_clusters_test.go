@@ -0,0 +1,86 @@
+package testreportconversion
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/sippy/pkg/testgridanalysis/testgridanalysisapi"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testidentification"
+)
+
+func TestFindFailureClusters(t *testing.T) {
+	rawJobResults := map[string]testgridanalysisapi.RawJobResult{}
+	timestamp := 1000
+	addRun := func(jobName string, failedTestNames ...string) {
+		jobResult, ok := rawJobResults[jobName]
+		if !ok {
+			jobResult = testgridanalysisapi.RawJobResult{
+				JobName:       jobName,
+				JobRunResults: map[string]testgridanalysisapi.RawJobRunResult{},
+				TestResults: map[string]testgridanalysisapi.RawTestResult{
+					"synthetic": {Name: "synthetic", Synthetic: true},
+				},
+			}
+		}
+		url := fmt.Sprintf("https://prow/%s/%d", jobName, len(jobResult.JobRunResults)+100)
+		jobResult.JobRunResults[url] = testgridanalysisapi.RawJobRunResult{Job: jobName, JobRunURL: url, Timestamp: timestamp, FailedTestNames: failedTestNames}
+		rawJobResults[jobName] = jobResult
+		timestamp++
+	}
+
+	// storage tests fail together on both platforms
+	for i := 0; i < 3; i++ {
+		addRun("job-aws", "storage-a", "storage-b", "storage-c", "synthetic")
+	}
+	addRun("job-gcp", "storage-a", "storage-b", "storage-c")
+	addRun("job-gcp", "storage-a", "storage-b", "network")
+	// network fails all the time, so it doesn't belong to the storage cluster even though it fails with it
+	for i := 0; i < 6; i++ {
+		addRun("job-gcp", "network")
+	}
+	addRun("job-gcp", "network", "lonely")
+	// mass failures are ignored
+	massFailure := []string{}
+	for i := 0; i < maxFailuresPerClusteredJobRun+1; i++ {
+		massFailure = append(massFailure, fmt.Sprintf("mass-%d", i))
+	}
+	for i := 0; i < 3; i++ {
+		addRun("job-aws", massFailure...)
+	}
+
+	variantManager, err := testidentification.NewConfigVariantManager(testidentification.VariantConfig{
+		Variants: []testidentification.VariantDefinition{
+			{Name: "aws", Jobs: []string{"job-aws"}},
+			{Name: "gcp", Jobs: []string{"job-gcp"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clusters := findFailureClusters(rawJobResults, variantManager)
+	if len(clusters) != 1 {
+		t.Fatalf("expected one cluster, got %#v", clusters)
+	}
+	cluster := clusters[0]
+	if expected := []string{"storage-a", "storage-b", "storage-c"}; !reflect.DeepEqual(cluster.TestNames, expected) {
+		t.Errorf("expected %v, got %v", expected, cluster.TestNames)
+	}
+	if cluster.Support != 4 {
+		t.Errorf("expected support of 4, got %d", cluster.Support)
+	}
+	if cluster.Confidence != 0.8 {
+		t.Errorf("expected confidence of 0.8, got %v", cluster.Confidence)
+	}
+	if expected := []string{"job-aws", "job-gcp"}; !reflect.DeepEqual(cluster.Jobs, expected) {
+		t.Errorf("expected %v, got %v", expected, cluster.Jobs)
+	}
+	if expected := []string{"aws", "gcp"}; !reflect.DeepEqual(cluster.Variants, expected) {
+		t.Errorf("expected %v, got %v", expected, cluster.Variants)
+	}
+	// the gcp run is the most recent, even though its URL sorts first
+	expectedExamples := []string{"https://prow/job-gcp/100", "https://prow/job-aws/102", "https://prow/job-aws/101", "https://prow/job-aws/100"}
+	if !reflect.DeepEqual(cluster.ExampleJobRunURLs, expectedExamples) {
+		t.Errorf("expected the most recent runs first, got %v", cluster.ExampleJobRunURLs)
+	}
+}
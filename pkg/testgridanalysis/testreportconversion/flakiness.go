@@ -0,0 +1,87 @@
+package testreportconversion
+
+import (
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testgridanalysisapi"
+	"github.com/openshift/sippy/pkg/util"
+)
+
+const (
+	// maxBrokenPassPercentage is the pass percentage at or below which a test is considered permanently broken.
+	maxBrokenPassPercentage = 5.0
+	// minBrokenStreak is the number of consecutive failures required to consider a test newly broken, or to consider a
+	// past run of failures a fixed breakage instead of flakiness.
+	minBrokenStreak = 3
+	// minPassPercentageBeforeBreak is how reliably a test has to pass before its current failure streak for it to be
+	// considered newly broken instead of flaky.
+	minPassPercentageBeforeBreak = 90.0
+)
+
+// classificationSeverity orders the classifications, so a test that is broken in one job is not hidden by the jobs it
+// passes in.
+var classificationSeverity = map[sippyprocessingv1.TestClassification]int{
+	sippyprocessingv1.TestStable:            1,
+	sippyprocessingv1.TestFlaky:             2,
+	sippyprocessingv1.TestPermanentlyBroken: 3,
+	sippyprocessingv1.TestNewlyBroken:       4,
+}
+
+// combineTestClassifications returns the more severe of two classifications.
+func combineTestClassifications(lhs, rhs sippyprocessingv1.TestClassification) sippyprocessingv1.TestClassification {
+	if classificationSeverity[rhs] > classificationSeverity[lhs] {
+		return rhs
+	}
+	return lhs
+}
+
+// classifyJobResults classifies a test from the classifications of its jobs.  Each job has to be classified on its own
+// runs, because a streak in one job says nothing about the runs of the other jobs.
+func classifyJobResults(jobResults []sippyprocessingv1.FailingTestJobResult) sippyprocessingv1.TestClassification {
+	var classification sippyprocessingv1.TestClassification
+	for _, jobResult := range jobResults {
+		classification = combineTestClassifications(classification, jobResult.Classification)
+	}
+	return classification
+}
+
+// combineTestRunPatterns is testgridanalysisapi.CombineTestRunPatterns for the run patterns of processed results.
+func combineTestRunPatterns(lhs, rhs sippyprocessingv1.TestRunPattern) sippyprocessingv1.TestRunPattern {
+	return sippyprocessingv1.TestRunPattern(testgridanalysisapi.CombineTestRunPatterns(
+		testgridanalysisapi.RawTestRunPattern(lhs), testgridanalysisapi.RawTestRunPattern(rhs)))
+}
+
+// classifyTestResult decides whether a test is stable, flaky, permanently broken, or newly broken from the counts and
+// the run pattern of its results in a single job.  Tests without a run pattern, like synthetic tests, are classified
+// from their counts.
+func classifyTestResult(testResult sippyprocessingv1.TestResult) sippyprocessingv1.TestClassification {
+	runs := testResult.Successes + testResult.Failures
+	if runs == 0 {
+		return ""
+	}
+	pattern := testResult.RunPattern
+
+	if testResult.Failures == 0 {
+		// passing only after a retry is still flaky
+		if testResult.Flakes > 0 {
+			return sippyprocessingv1.TestFlaky
+		}
+		return sippyprocessingv1.TestStable
+	}
+
+	if testResult.PassPercentage <= maxBrokenPassPercentage {
+		return sippyprocessingv1.TestPermanentlyBroken
+	}
+
+	if streak := pattern.CurrentFailureStreak; streak >= minBrokenStreak {
+		if util.Percent(testResult.Successes, testResult.Failures-streak) >= minPassPercentageBeforeBreak {
+			return sippyprocessingv1.TestNewlyBroken
+		}
+	}
+
+	// every failure happened in a single streak that has since been fixed
+	if pattern.CurrentFailureStreak == 0 && pattern.LongestFailureStreak >= minBrokenStreak && pattern.LongestFailureStreak == testResult.Failures {
+		return sippyprocessingv1.TestStable
+	}
+
+	return sippyprocessingv1.TestFlaky
+}
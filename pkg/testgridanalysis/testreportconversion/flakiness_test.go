@@ -0,0 +1,116 @@
+package testreportconversion
+
+import (
+	"testing"
+
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/util"
+)
+
+func TestClassifyTestResult(t *testing.T) {
+	testResult := func(successes, failures, flakes int, pattern sippyprocessingv1.TestRunPattern) sippyprocessingv1.TestResult {
+		return sippyprocessingv1.TestResult{
+			Successes:      successes,
+			Failures:       failures,
+			Flakes:         flakes,
+			PassPercentage: util.Percent(successes, failures),
+			RunPattern:     pattern,
+		}
+	}
+	tests := []struct {
+		name       string
+		testResult sippyprocessingv1.TestResult
+		expected   sippyprocessingv1.TestClassification
+	}{
+		{name: "no runs", testResult: testResult(0, 0, 0, sippyprocessingv1.TestRunPattern{}), expected: ""},
+		{name: "always passes", testResult: testResult(20, 0, 0, sippyprocessingv1.TestRunPattern{}), expected: sippyprocessingv1.TestStable},
+		{name: "passes on retry", testResult: testResult(20, 0, 3, sippyprocessingv1.TestRunPattern{}), expected: sippyprocessingv1.TestFlaky},
+		{name: "never passes", testResult: testResult(0, 20, 0, sippyprocessingv1.TestRunPattern{CurrentFailureStreak: 20, LongestFailureStreak: 20}), expected: sippyprocessingv1.TestPermanentlyBroken},
+		{name: "started failing", testResult: testResult(20, 5, 0, sippyprocessingv1.TestRunPattern{Transitions: 1, CurrentFailureStreak: 5, LongestFailureStreak: 5}), expected: sippyprocessingv1.TestNewlyBroken},
+		{name: "fixed", testResult: testResult(20, 5, 0, sippyprocessingv1.TestRunPattern{Transitions: 2, LongestFailureStreak: 5}), expected: sippyprocessingv1.TestStable},
+		{name: "alternating", testResult: testResult(20, 5, 0, sippyprocessingv1.TestRunPattern{Transitions: 10, LongestFailureStreak: 1}), expected: sippyprocessingv1.TestFlaky},
+		{name: "failing after alternating", testResult: testResult(10, 10, 0, sippyprocessingv1.TestRunPattern{Transitions: 12, CurrentFailureStreak: 3, LongestFailureStreak: 3}), expected: sippyprocessingv1.TestFlaky},
+		{name: "synthetic without pattern", testResult: testResult(15, 5, 0, sippyprocessingv1.TestRunPattern{}), expected: sippyprocessingv1.TestFlaky},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := classifyTestResult(tc.testResult); actual != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestClassifyAcrossJobs(t *testing.T) {
+	jobResult := func(name string, successes, failures int, pattern sippyprocessingv1.TestRunPattern) sippyprocessingv1.JobResult {
+		return sippyprocessingv1.JobResult{
+			Name: name,
+			TestResults: []sippyprocessingv1.TestResult{{
+				Name:           "test",
+				Successes:      successes,
+				Failures:       failures,
+				PassPercentage: util.Percent(successes, failures),
+				RunPattern:     pattern,
+			}},
+		}
+	}
+	tests := []struct {
+		name       string
+		jobResults []sippyprocessingv1.JobResult
+		expected   sippyprocessingv1.TestClassification
+		jobs       map[string]sippyprocessingv1.TestClassification
+	}{
+		{
+			name: "broken in one job",
+			jobResults: []sippyprocessingv1.JobResult{
+				jobResult("broken", 0, 10, sippyprocessingv1.TestRunPattern{CurrentFailureStreak: 10, LongestFailureStreak: 10}),
+				jobResult("passing-1", 100, 0, sippyprocessingv1.TestRunPattern{}),
+				jobResult("passing-2", 100, 0, sippyprocessingv1.TestRunPattern{}),
+			},
+			expected: sippyprocessingv1.TestPermanentlyBroken,
+			jobs: map[string]sippyprocessingv1.TestClassification{
+				"broken":    sippyprocessingv1.TestPermanentlyBroken,
+				"passing-1": sippyprocessingv1.TestStable,
+				"passing-2": sippyprocessingv1.TestStable,
+			},
+		},
+		{
+			name: "newly broken in one job",
+			jobResults: []sippyprocessingv1.JobResult{
+				jobResult("newly-broken", 20, 4, sippyprocessingv1.TestRunPattern{Transitions: 1, CurrentFailureStreak: 4, LongestFailureStreak: 4}),
+				jobResult("flaky", 20, 5, sippyprocessingv1.TestRunPattern{Transitions: 10, LongestFailureStreak: 1}),
+			},
+			expected: sippyprocessingv1.TestNewlyBroken,
+			jobs: map[string]sippyprocessingv1.TestClassification{
+				"newly-broken": sippyprocessingv1.TestNewlyBroken,
+				"flaky":        sippyprocessingv1.TestFlaky,
+			},
+		},
+		{
+			name: "flaky in every job",
+			jobResults: []sippyprocessingv1.JobResult{
+				jobResult("flaky-1", 20, 5, sippyprocessingv1.TestRunPattern{Transitions: 10, LongestFailureStreak: 1}),
+				// the longest streak of the other job must not make this one look newly broken
+				jobResult("flaky-2", 20, 5, sippyprocessingv1.TestRunPattern{Transitions: 9, CurrentFailureStreak: 1, LongestFailureStreak: 2}),
+			},
+			expected: sippyprocessingv1.TestFlaky,
+			jobs: map[string]sippyprocessingv1.TestClassification{
+				"flaky-1": sippyprocessingv1.TestFlaky,
+				"flaky-2": sippyprocessingv1.TestFlaky,
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := getTestResultsByName(tc.jobResults)["test"]
+			if actual.Classification != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, actual.Classification)
+			}
+			for _, jobResult := range actual.JobResults {
+				if jobResult.Classification != tc.jobs[jobResult.Name] {
+					t.Errorf("expected job %s to be %q, got %q", jobResult.Name, tc.jobs[jobResult.Name], jobResult.Classification)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package testreportconversion
+
+import (
+	"fmt"
+	"strings"
+
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testgridanalysisapi"
+)
+
+// ClassifyJobRun returns the outcome of the run and the reason for it, by the precedence documented on
+// sippyprocessingv1.JobRunOutcome.
+func ClassifyJobRun(jrr testgridanalysisapi.RawJobRunResult) (sippyprocessingv1.JobRunOutcome, string) {
+	if jrr.Succeeded {
+		return sippyprocessingv1.JobRunSucceeded, "the job succeeded"
+	}
+	if !jrr.Failed {
+		return sippyprocessingv1.JobRunRunning, "the job has not finished"
+	}
+
+	failedOperators := []string{}
+	for _, operator := range jrr.FinalOperatorStates {
+		if operator.State == testgridanalysisapi.Failure {
+			failedOperators = append(failedOperators, operator.Name)
+		}
+	}
+
+	switch {
+	case len(jrr.FinalOperatorStates) == 0 && jrr.SetupStatus == testgridanalysisapi.Failure:
+		return sippyprocessingv1.JobRunInfrastructureFailure, "setup failed before any operator reported its state"
+	case len(jrr.FinalOperatorStates) == 0 && len(jrr.SetupStatus) == 0:
+		return sippyprocessingv1.JobRunInfrastructureFailure, "the job failed without a setup result before any operator reported its state"
+	case jrr.SetupStatus == testgridanalysisapi.Failure && len(failedOperators) == 0:
+		return sippyprocessingv1.JobRunInstallFailure, "setup failed although every operator was healthy at the end, probably a timeout"
+	case jrr.SetupStatus == testgridanalysisapi.Failure:
+		return sippyprocessingv1.JobRunInstallFailure, fmt.Sprintf("setup failed with %d of %d operators failing: %s",
+			len(failedOperators), len(jrr.FinalOperatorStates), strings.Join(failedOperators, ", "))
+	}
+
+	if jrr.UpgradeStarted {
+		failedUpgrades := []string{}
+		if jrr.UpgradeForOperatorsStatus == testgridanalysisapi.Failure {
+			failedUpgrades = append(failedUpgrades, "operators")
+		}
+		if jrr.UpgradeForMachineConfigPoolsStatus == testgridanalysisapi.Failure {
+			failedUpgrades = append(failedUpgrades, "machine config pools")
+		}
+		if len(failedUpgrades) > 0 {
+			return sippyprocessingv1.JobRunUpgradeFailure, fmt.Sprintf("the upgrade of the %s failed", strings.Join(failedUpgrades, " and "))
+		}
+	}
+
+	if jrr.OpenShiftTestsStatus == testgridanalysisapi.Failure {
+		return sippyprocessingv1.JobRunTestFailure, fmt.Sprintf("openshift-tests failed with %d failing tests", jrr.TestFailures)
+	}
+	if len(jrr.SetupStatus) == 0 {
+		return sippyprocessingv1.JobRunNoSetupResults, "the job failed without a setup result, and no later step failed"
+	}
+	return sippyprocessingv1.JobRunUnknownFailure, "the job failed, but no step that sippy knows of failed"
+}
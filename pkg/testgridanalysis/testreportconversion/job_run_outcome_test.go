@@ -0,0 +1,97 @@
+package testreportconversion
+
+import (
+	"testing"
+
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testgridanalysisapi"
+)
+
+func TestClassifyJobRun(t *testing.T) {
+	failedOperator := []testgridanalysisapi.OperatorState{
+		{Name: "etcd", State: testgridanalysisapi.Failure},
+		{Name: "dns", State: testgridanalysisapi.Success},
+	}
+	tests := []struct {
+		name           string
+		jrr            testgridanalysisapi.RawJobRunResult
+		expected       sippyprocessingv1.JobRunOutcome
+		expectedReason string
+	}{
+		{
+			name:     "succeeded",
+			jrr:      testgridanalysisapi.RawJobRunResult{Succeeded: true, SetupStatus: testgridanalysisapi.Success},
+			expected: sippyprocessingv1.JobRunSucceeded,
+		},
+		{
+			name:     "running",
+			jrr:      testgridanalysisapi.RawJobRunResult{},
+			expected: sippyprocessingv1.JobRunRunning,
+		},
+		{
+			name:     "setup failed without operators",
+			jrr:      testgridanalysisapi.RawJobRunResult{Failed: true, SetupStatus: testgridanalysisapi.Failure, OpenShiftTestsStatus: testgridanalysisapi.Failure},
+			expected: sippyprocessingv1.JobRunInfrastructureFailure,
+		},
+		{
+			name:     "no setup result and no operators",
+			jrr:      testgridanalysisapi.RawJobRunResult{Failed: true},
+			expected: sippyprocessingv1.JobRunInfrastructureFailure,
+		},
+		{
+			name:     "job without a setup step",
+			jrr:      testgridanalysisapi.RawJobRunResult{Failed: true, SetupStatus: testgridanalysisapi.Unknown},
+			expected: sippyprocessingv1.JobRunUnknownFailure,
+		},
+		{
+			name:           "setup failed with operators",
+			jrr:            testgridanalysisapi.RawJobRunResult{Failed: true, SetupStatus: testgridanalysisapi.Failure, FinalOperatorStates: failedOperator},
+			expected:       sippyprocessingv1.JobRunInstallFailure,
+			expectedReason: "setup failed with 1 of 2 operators failing: etcd",
+		},
+		{
+			name: "upgrade failed before tests",
+			jrr: testgridanalysisapi.RawJobRunResult{
+				Failed:                             true,
+				SetupStatus:                        testgridanalysisapi.Success,
+				FinalOperatorStates:                failedOperator,
+				UpgradeStarted:                     true,
+				UpgradeForOperatorsStatus:          testgridanalysisapi.Failure,
+				UpgradeForMachineConfigPoolsStatus: testgridanalysisapi.Failure,
+				OpenShiftTestsStatus:               testgridanalysisapi.Failure,
+			},
+			expected:       sippyprocessingv1.JobRunUpgradeFailure,
+			expectedReason: "the upgrade of the operators and machine config pools failed",
+		},
+		{
+			name:           "tests failed",
+			jrr:            testgridanalysisapi.RawJobRunResult{Failed: true, SetupStatus: testgridanalysisapi.Success, OpenShiftTestsStatus: testgridanalysisapi.Failure, TestFailures: 3},
+			expected:       sippyprocessingv1.JobRunTestFailure,
+			expectedReason: "openshift-tests failed with 3 failing tests",
+		},
+		{
+			name:     "no setup result after operators",
+			jrr:      testgridanalysisapi.RawJobRunResult{Failed: true, FinalOperatorStates: failedOperator},
+			expected: sippyprocessingv1.JobRunNoSetupResults,
+		},
+		{
+			name:     "nothing failed",
+			jrr:      testgridanalysisapi.RawJobRunResult{Failed: true, SetupStatus: testgridanalysisapi.Success},
+			expected: sippyprocessingv1.JobRunUnknownFailure,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, reason := ClassifyJobRun(tc.jrr)
+			if actual != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, actual)
+			}
+			if len(reason) == 0 {
+				t.Errorf("expected a reason")
+			}
+			if len(tc.expectedReason) > 0 && reason != tc.expectedReason {
+				t.Errorf("expected reason %q, got %q", tc.expectedReason, reason)
+			}
+		})
+	}
+}
@@ -6,6 +6,8 @@ import (
 	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
 	"github.com/openshift/sippy/pkg/buganalysis"
 	"github.com/openshift/sippy/pkg/testgridanalysis/testgridanalysisapi"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testidentification"
+	"github.com/openshift/sippy/pkg/util"
 )
 
 func FilterJobResultTests(jobResult *sippyprocessingv1.JobResult, testFilterFn TestResultFilterFunc) *sippyprocessingv1.JobResult {
@@ -62,13 +64,17 @@ func filterPertinentInfrequentJobResults(
 // convertRawJobResultsToProcessedJobResults performs no filtering
 func convertRawJobResultsToProcessedJobResults(
 	rawJobResults map[string]testgridanalysisapi.RawJobResult,
+	variantManager testidentification.VariantManager,
 	bugCache buganalysis.BugCache, // required to associate tests with bug
 	bugzillaRelease string, // required to limit bugs to those that apply to the release in question,
+	testOwnership testidentification.TestOwnership,
 ) []sippyprocessingv1.JobResult {
 	jobs := []sippyprocessingv1.JobResult{}
+	owners := newOwnerCache(testOwnership)
 
 	for _, rawJobResult := range rawJobResults {
-		job := convertRawJobResultToProcessedJobResult(rawJobResult, bugCache, bugzillaRelease)
+		job := convertRawJobResultToProcessedJobResult(rawJobResult, bugCache, bugzillaRelease, owners)
+		job.Variants = variantManager.IdentifyVariants(rawJobResult.JobName)
 		jobs = append(jobs, job)
 	}
 
@@ -81,12 +87,13 @@ func convertRawJobResultToProcessedJobResult(
 	rawJobResult testgridanalysisapi.RawJobResult,
 	bugCache buganalysis.BugCache, // required to associate tests with bug
 	bugzillaRelease string, // required to limit bugs to those that apply to the release in question,
+	owners ownerCache,
 ) sippyprocessingv1.JobResult {
 
 	job := sippyprocessingv1.JobResult{
 		Name:              rawJobResult.JobName,
 		TestGridUrl:       rawJobResult.TestGridJobUrl,
-		TestResults:       convertRawTestResultsToProcessedTestResults(rawJobResult.JobName, rawJobResult.TestResults, bugCache, bugzillaRelease),
+		TestResults:       convertRawTestResultsToProcessedTestResults(rawJobResult.JobName, rawJobResult.TestResults, bugCache, bugzillaRelease, owners),
 		BugList:           bugCache.ListBugs(bugzillaRelease, rawJobResult.JobName, ""),
 		AssociatedBugList: bugCache.ListAssociatedBugs(bugzillaRelease, rawJobResult.JobName, ""),
 	}
@@ -100,19 +107,14 @@ func convertRawJobResultToProcessedJobResult(
 		if rawJRR.Failed && areAllFailuresKnown(rawJRR, job.TestResults) {
 			job.KnownFailures++
 		}
-		// success - we saw the setup/infra test result, it succeeded (or the whole job succeeeded)
-		// failure - we saw the test result, it failed
-		// unknown - we know this job doesn't have a setup test, and the job didn't succeed, so we don't know if it
-		//           failed due to infra issues or not.  probably not infra.
-		// emptystring - we expected to see a test result for a setup test but we didn't and the overall job failed, probably infra
-		if rawJRR.SetupStatus != testgridanalysisapi.Success && rawJRR.SetupStatus != testgridanalysisapi.Unknown {
+		if outcome, _ := ClassifyJobRun(rawJRR); outcome == sippyprocessingv1.JobRunInfrastructureFailure {
 			job.InfrastructureFailures++
 		}
 	}
 
-	job.PassPercentage = percent(job.Successes, job.Failures)
-	job.PassPercentageWithKnownFailures = percent(job.Successes+job.KnownFailures, job.Failures-job.KnownFailures)
-	job.PassPercentageWithoutInfrastructureFailures = percent(job.Successes, job.Failures-job.InfrastructureFailures)
+	job.PassPercentage = util.Percent(job.Successes, job.Failures)
+	job.PassPercentageWithKnownFailures = util.Percent(job.Successes+job.KnownFailures, job.Failures-job.KnownFailures)
+	job.PassPercentageWithoutInfrastructureFailures = util.Percent(job.Successes, job.Failures-job.InfrastructureFailures)
 
 	// if there are more infrastructure failures than overall failures, then something is wrong with our accounting and
 	// we should make it clear this is an invalid value.
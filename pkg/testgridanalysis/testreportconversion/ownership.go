@@ -0,0 +1,91 @@
+package testreportconversion
+
+import (
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testidentification"
+)
+
+// ownerCache resolves the owner of each test once per report, because most tests run in many jobs.  It is not safe for
+// concurrent use.
+type ownerCache struct {
+	testOwnership testidentification.TestOwnership
+	owners        map[string]sippyprocessingv1.TestOwner
+}
+
+func newOwnerCache(testOwnership testidentification.TestOwnership) ownerCache {
+	if testOwnership == nil {
+		testOwnership = testidentification.DefaultTestOwnership()
+	}
+	return ownerCache{testOwnership: testOwnership, owners: map[string]sippyprocessingv1.TestOwner{}}
+}
+
+func (c ownerCache) owner(testName string) sippyprocessingv1.TestOwner {
+	owner, ok := c.owners[testName]
+	if !ok {
+		owner = c.testOwnership.Owner(testName)
+		c.owners[testName] = owner
+	}
+	return owner
+}
+
+// FilterTestResultsByTeam keeps the test results owned by the team.
+func FilterTestResultsByTeam(team string) TestResultFilterFunc {
+	return func(testResult sippyprocessingv1.TestResult) bool {
+		return testResult.Owner.Team == team
+	}
+}
+
+// FilterTestReportTests keeps only the test results that pass the filter in every table of the report.  Jobs, variants,
+// and job runs are kept with their pass rates, only the tests listed under them are filtered.
+func FilterTestReportTests(report sippyprocessingv1.TestReport, filterFn TestResultFilterFunc) sippyprocessingv1.TestReport {
+	filterFailingTests := func(in []sippyprocessingv1.FailingTestResult) []sippyprocessingv1.FailingTestResult {
+		ret := []sippyprocessingv1.FailingTestResult{}
+		for _, failingTest := range in {
+			if filterFn(failingTest.TestResultAcrossAllJobs) {
+				ret = append(ret, failingTest)
+			}
+		}
+		return ret
+	}
+	filterJobs := func(in []sippyprocessingv1.JobResult) []sippyprocessingv1.JobResult {
+		ret := []sippyprocessingv1.JobResult{}
+		for _, jobResult := range in {
+			jobResult.TestResults = filterFn.FilterTestResults(jobResult.TestResults)
+			ret = append(ret, jobResult)
+		}
+		return ret
+	}
+
+	ret := report
+	ret.ByTest = filterFailingTests(report.ByTest)
+	ret.TopFailingTestsWithBug = filterFailingTests(report.TopFailingTestsWithBug)
+	ret.TopFailingTestsWithoutBug = filterFailingTests(report.TopFailingTestsWithoutBug)
+	ret.CuratedTests = filterFailingTests(report.CuratedTests)
+	ret.ByJob = filterJobs(report.ByJob)
+	ret.FrequentJobResults = filterJobs(report.FrequentJobResults)
+	ret.InfrequentJobResults = filterJobs(report.InfrequentJobResults)
+
+	ret.ByVariant = []sippyprocessingv1.VariantResults{}
+	for _, variantResults := range report.ByVariant {
+		variantResults.JobResults = filterJobs(variantResults.JobResults)
+		variantResults.AllTestResults = filterFn.FilterTestResults(variantResults.AllTestResults)
+		ret.ByVariant = append(ret.ByVariant, variantResults)
+	}
+
+	ret.JobFailuresByBugzillaComponent = map[string]sippyprocessingv1.SortedBugzillaComponentResult{}
+	for component, componentResult := range report.JobFailuresByBugzillaComponent {
+		jobsFailed := []sippyprocessingv1.BugzillaJobResult{}
+		for _, jobResult := range componentResult.JobsFailed {
+			jobResult.Failures = filterFn.FilterTestResults(jobResult.Failures)
+			if len(jobResult.Failures) > 0 {
+				jobsFailed = append(jobsFailed, jobResult)
+			}
+		}
+		if len(jobsFailed) > 0 {
+			componentResult.JobsFailed = jobsFailed
+			ret.JobFailuresByBugzillaComponent[component] = componentResult
+		}
+	}
+
+	return ret
+}
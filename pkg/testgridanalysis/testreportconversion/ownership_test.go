@@ -0,0 +1,52 @@
+package testreportconversion
+
+import (
+	"testing"
+
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+)
+
+func TestFilterTestReportTests(t *testing.T) {
+	owned := sippyprocessingv1.TestResult{Name: "owned", Owner: sippyprocessingv1.TestOwner{Component: "Etcd", Team: "etcd-team"}}
+	other := sippyprocessingv1.TestResult{Name: "other", Owner: sippyprocessingv1.TestOwner{Component: "Networking", Team: "sdn-team"}}
+	failingTests := []sippyprocessingv1.FailingTestResult{
+		{TestName: owned.Name, TestResultAcrossAllJobs: owned},
+		{TestName: other.Name, TestResultAcrossAllJobs: other},
+	}
+	jobResults := []sippyprocessingv1.JobResult{{Name: "job", TestResults: []sippyprocessingv1.TestResult{owned, other}}}
+
+	report := sippyprocessingv1.TestReport{
+		ByTest:                 failingTests,
+		TopFailingTestsWithBug: failingTests,
+		ByJob:                  jobResults,
+		ByVariant: []sippyprocessingv1.VariantResults{
+			{VariantName: "aws", JobResults: jobResults, AllTestResults: []sippyprocessingv1.TestResult{owned, other}},
+		},
+		JobFailuresByBugzillaComponent: map[string]sippyprocessingv1.SortedBugzillaComponentResult{
+			"Etcd":       {Name: "Etcd", JobsFailed: []sippyprocessingv1.BugzillaJobResult{{JobName: "job", Failures: []sippyprocessingv1.TestResult{owned}}}},
+			"Networking": {Name: "Networking", JobsFailed: []sippyprocessingv1.BugzillaJobResult{{JobName: "job", Failures: []sippyprocessingv1.TestResult{other}}}},
+		},
+	}
+
+	filtered := FilterTestReportTests(report, FilterTestResultsByTeam("etcd-team"))
+	if len(filtered.ByTest) != 1 || filtered.ByTest[0].TestName != "owned" {
+		t.Errorf("expected only the owned test, got %v", filtered.ByTest)
+	}
+	if len(filtered.TopFailingTestsWithBug) != 1 {
+		t.Errorf("expected only the owned failing test, got %v", filtered.TopFailingTestsWithBug)
+	}
+	if len(filtered.ByJob) != 1 || len(filtered.ByJob[0].TestResults) != 1 {
+		t.Errorf("expected the job with only the owned test, got %v", filtered.ByJob)
+	}
+	if len(filtered.ByVariant[0].AllTestResults) != 1 || len(filtered.ByVariant[0].JobResults[0].TestResults) != 1 {
+		t.Errorf("expected the variant with only the owned test, got %v", filtered.ByVariant)
+	}
+	if _, ok := filtered.JobFailuresByBugzillaComponent["Networking"]; ok || len(filtered.JobFailuresByBugzillaComponent) != 1 {
+		t.Errorf("expected only the component of the owned test, got %v", filtered.JobFailuresByBugzillaComponent)
+	}
+
+	// the unfiltered report must not change
+	if len(report.ByTest) != 2 || len(report.ByJob[0].TestResults) != 2 || len(report.ByVariant[0].AllTestResults) != 2 {
+		t.Error("expected the unfiltered report to be unchanged")
+	}
+}
@@ -15,6 +15,7 @@ func PrepareTestReport(
 	reportName string,
 	rawData testgridanalysisapi.RawData,
 	variantManager testidentification.VariantManager,
+	testOwnership testidentification.TestOwnership, // resolves the owner of every test
 	bugCache buganalysis.BugCache, // required to associate tests with bug
 	bugzillaRelease string, // required to limit bugs to those that apply to the release in question
 	// TODO refactor into a test run filter
@@ -28,7 +29,7 @@ func PrepareTestReport(
 ) sippyprocessingv1.TestReport {
 
 	// allJobResults holds all the job results with all the test results.  It contains complete frequency information and
-	allJobResults := convertRawJobResultsToProcessedJobResults(rawData.JobResults, bugCache, bugzillaRelease)
+	allJobResults := convertRawJobResultsToProcessedJobResults(rawData.JobResults, variantManager, bugCache, bugzillaRelease, testOwnership)
 	allTestResultsByName := getTestResultsByName(allJobResults)
 
 	standardTestResultFilterFn := StandardTestResultFilter(minRuns, successThreshold)
@@ -37,6 +38,7 @@ func PrepareTestReport(
 	byVariant := convertRawDataToByVariant(allJobResults, standardTestResultFilterFn, variantManager)
 
 	filteredFailureGroups := filterFailureGroups(rawData.JobResults, allTestResultsByName, failureClusterThreshold)
+	failureClusters := findFailureClusters(rawData.JobResults, variantManager)
 	frequentJobResults := filterPertinentFrequentJobResults(allJobResults, numDays, standardTestResultFilterFn)
 	infrequentJobResults := filterPertinentInfrequentJobResults(allJobResults, numDays, infrequentJobsTestResultFilterFn)
 
@@ -63,9 +65,10 @@ func PrepareTestReport(
 			FinalOperatorHealth: finalOperatorHealth,
 		},
 
-		ByTest:        allTestResultsByName.toOrderedList(),
-		ByVariant:     byVariant,
-		FailureGroups: filteredFailureGroups,
+		ByTest:          allTestResultsByName.toOrderedList(),
+		ByVariant:       byVariant,
+		FailureGroups:   filteredFailureGroups,
+		FailureClusters: failureClusters,
 
 		ByJob:                allJobResults,
 		FrequentJobResults:   frequentJobResults,
@@ -102,6 +105,7 @@ func filterFailureGroups(
 
 			allFailuresKnown := areAllFailuresKnownFromProcessedResults(rawJRR, allTestResultsByName)
 			hasUnknownFailure := rawJRR.Failed && !allFailuresKnown
+			outcome, outcomeReason := ClassifyJobRun(rawJRR)
 
 			filteredJrr = append(filteredJrr, sippyprocessingv1.JobRunResult{
 				Job:                jobResult.JobName,
@@ -111,6 +115,8 @@ func filterFailureGroups(
 				Failed:             rawJRR.Failed,
 				HasUnknownFailures: hasUnknownFailure,
 				Succeeded:          rawJRR.Succeeded,
+				Outcome:            outcome,
+				OutcomeReason:      outcomeReason,
 			})
 		}
 	}
@@ -153,11 +159,3 @@ func generateSortedBugFailureCounts(allTestResultsByName testResultsByName) []bu
 	})
 	return sortedBugs
 }
-
-func percent(success, failure int) float64 {
-	if success+failure == 0 {
-		//return math.NaN()
-		return 0.0
-	}
-	return float64(success) / float64(success+failure) * 100.0
-}
@@ -11,6 +11,7 @@ import (
 	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
 	"github.com/openshift/sippy/pkg/buganalysis"
 	"github.com/openshift/sippy/pkg/testgridanalysis/testgridanalysisapi"
+	"github.com/openshift/sippy/pkg/util"
 )
 
 // testResultsByPassPercentage sorts from lowest to highest pass percentage
@@ -41,7 +42,8 @@ func combineTestResults(lhs, rhs []sippyprocessingv1.TestResult) []sippyprocessi
 		existing.Failures += currTestResult.Failures
 		existing.Successes += currTestResult.Successes
 		existing.Flakes += currTestResult.Flakes
-		existing.PassPercentage = percent(existing.Successes, existing.Failures)
+		existing.RunPattern = combineTestRunPatterns(existing.RunPattern, currTestResult.RunPattern)
+		existing.PassPercentage = util.Percent(existing.Successes, existing.Failures)
 		// bugs should be the same for now.
 		byTestName[currTestResult.Name] = existing
 	}
@@ -66,9 +68,11 @@ func combineTestResult(lhs, rhs sippyprocessingv1.TestResult) sippyprocessingv1.
 	combined.Failures += rhs.Failures
 	combined.Successes += rhs.Successes
 	combined.Flakes += rhs.Flakes
-	combined.PassPercentage = percent(combined.Successes, combined.Failures)
+	combined.RunPattern = combineTestRunPatterns(lhs.RunPattern, rhs.RunPattern)
+	combined.PassPercentage = util.Percent(combined.Successes, combined.Failures)
 	combined.BugList = combineBugLists(lhs.BugList, rhs.BugList)
 	combined.AssociatedBugList = combineBugLists(lhs.AssociatedBugList, rhs.AssociatedBugList)
+	combined.BugLookupFailed = lhs.BugLookupFailed || rhs.BugLookupFailed
 
 	return combined
 }
@@ -79,7 +83,7 @@ func combineBugLists(lhs, rhs []bugsv1.Bug) []bugsv1.Bug {
 		combined = append(combined, curr)
 	}
 	for _, curr := range rhs {
-		if existing := findBug(curr.ID, combined); existing != nil {
+		if existing := findBug(curr, combined); existing != nil {
 			continue
 		}
 		combined = append(combined, curr)
@@ -88,9 +92,9 @@ func combineBugLists(lhs, rhs []bugsv1.Bug) []bugsv1.Bug {
 	return combined
 }
 
-func findBug(id int64, haystack []bugsv1.Bug) *bugsv1.Bug {
+func findBug(bug bugsv1.Bug, haystack []bugsv1.Bug) *bugsv1.Bug {
 	for _, curr := range haystack {
-		if curr.ID == id {
+		if curr.Tracker == bug.Tracker && curr.Name() == bug.Name() {
 			return &curr
 		}
 	}
@@ -102,15 +106,19 @@ func convertRawTestResultToProcessedTestResult(
 	rawTestResult testgridanalysisapi.RawTestResult,
 	bugCache buganalysis.BugCache, // required to associate tests with bug
 	bugzillaRelease string, // required to limit bugs to those that apply to the release in question
+	owners ownerCache,
 ) sippyprocessingv1.TestResult {
 	return sippyprocessingv1.TestResult{
 		Name:              rawTestResult.Name,
 		Successes:         rawTestResult.Successes,
 		Failures:          rawTestResult.Failures,
 		Flakes:            rawTestResult.Flakes,
-		PassPercentage:    percent(rawTestResult.Successes, rawTestResult.Failures),
+		PassPercentage:    util.Percent(rawTestResult.Successes, rawTestResult.Failures),
+		RunPattern:        sippyprocessingv1.TestRunPattern(rawTestResult.RunPattern),
+		Owner:             owners.owner(rawTestResult.Name),
 		BugList:           bugCache.ListBugs(bugzillaRelease, jobName, rawTestResult.Name),
 		AssociatedBugList: bugCache.ListAssociatedBugs(bugzillaRelease, jobName, rawTestResult.Name),
+		BugLookupFailed:   bugCache.BugLookupFailed(rawTestResult.Name),
 	}
 }
 
@@ -119,12 +127,13 @@ func convertRawTestResultsToProcessedTestResults(
 	rawTestResults map[string]testgridanalysisapi.RawTestResult,
 	bugCache buganalysis.BugCache, // required to associate tests with bug
 	bugzillaRelease string, // required to limit bugs to those that apply to the release in question
+	owners ownerCache,
 ) []sippyprocessingv1.TestResult {
 
 	ret := []sippyprocessingv1.TestResult{}
 
 	for _, rawTestResult := range rawTestResults {
-		ret = append(ret, convertRawTestResultToProcessedTestResult(jobName, rawTestResult, bugCache, bugzillaRelease))
+		ret = append(ret, convertRawTestResultToProcessedTestResult(jobName, rawTestResult, bugCache, bugzillaRelease, owners))
 	}
 
 	sort.Stable(testResultsByPassPercentage(ret))
@@ -239,7 +248,7 @@ func (filterFn TestResultFilterFunc) FilterTestResults(testResults []sippyproces
 func excludeNeverStableJobs(in sippyprocessingv1.FailingTestResult, variantManager testidentification.VariantManager) sippyprocessingv1.FailingTestResult {
 	filteredFailingTestResult := sippyprocessingv1.FailingTestResult{
 		TestName:                in.TestName,
-		TestResultAcrossAllJobs: sippyprocessingv1.TestResult{Name: in.TestName},
+		TestResultAcrossAllJobs: sippyprocessingv1.TestResult{Name: in.TestName, Owner: in.TestResultAcrossAllJobs.Owner},
 		JobResults:              nil,
 	}
 
@@ -254,10 +263,13 @@ func excludeNeverStableJobs(in sippyprocessingv1.FailingTestResult, variantManag
 	for _, jobResult := range filteredFailingTestResult.JobResults {
 		filteredFailingTestResult.TestResultAcrossAllJobs.BugList = in.TestResultAcrossAllJobs.BugList
 		filteredFailingTestResult.TestResultAcrossAllJobs.AssociatedBugList = in.TestResultAcrossAllJobs.AssociatedBugList
+		filteredFailingTestResult.TestResultAcrossAllJobs.BugLookupFailed = in.TestResultAcrossAllJobs.BugLookupFailed
 		filteredFailingTestResult.TestResultAcrossAllJobs.Successes += jobResult.TestSuccesses
 		filteredFailingTestResult.TestResultAcrossAllJobs.Failures += jobResult.TestFailures
+		filteredFailingTestResult.TestResultAcrossAllJobs.RunPattern = combineTestRunPatterns(filteredFailingTestResult.TestResultAcrossAllJobs.RunPattern, jobResult.RunPattern)
 	}
-	filteredFailingTestResult.TestResultAcrossAllJobs.PassPercentage = percent(filteredFailingTestResult.TestResultAcrossAllJobs.Successes, filteredFailingTestResult.TestResultAcrossAllJobs.Failures)
+	filteredFailingTestResult.TestResultAcrossAllJobs.PassPercentage = util.Percent(filteredFailingTestResult.TestResultAcrossAllJobs.Successes, filteredFailingTestResult.TestResultAcrossAllJobs.Failures)
+	filteredFailingTestResult.Classification = classifyJobResults(filteredFailingTestResult.JobResults)
 
 	return filteredFailingTestResult
 }
@@ -0,0 +1,40 @@
+package testreportconversion
+
+import (
+	"testing"
+
+	"github.com/openshift/sippy/pkg/buganalysis"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testgridanalysisapi"
+)
+
+// failedLookupBugCache has no bugs, and could not look up the bugs of the tests in failed.
+type failedLookupBugCache struct {
+	buganalysis.BugCache
+	failed map[string]bool
+}
+
+func (c failedLookupBugCache) BugLookupFailed(testName string) bool {
+	return c.failed[testName]
+}
+
+func TestBugLookupFailed(t *testing.T) {
+	bugCache := failedLookupBugCache{BugCache: buganalysis.NewNoOpBugCache(), failed: map[string]bool{"b": true}}
+	rawTestResults := map[string]testgridanalysisapi.RawTestResult{
+		"a": {Name: "a", Failures: 1},
+		"b": {Name: "b", Failures: 1},
+	}
+
+	byName := map[string]bool{}
+	for _, result := range convertRawTestResultsToProcessedTestResults("job", rawTestResults, bugCache, "4.8", newOwnerCache(nil)) {
+		byName[result.Name] = result.BugLookupFailed
+	}
+	if byName["a"] || !byName["b"] {
+		t.Errorf("expected only b to be marked as failed, got %v", byName)
+	}
+
+	lhs := convertRawTestResultToProcessedTestResult("job-1", rawTestResults["b"], buganalysis.NewNoOpBugCache(), "4.8", newOwnerCache(nil))
+	rhs := convertRawTestResultToProcessedTestResult("job-2", rawTestResults["b"], bugCache, "4.8", newOwnerCache(nil))
+	if combined := combineTestResult(lhs, rhs); !combined.BugLookupFailed {
+		t.Errorf("expected a failed lookup in any job to mark the combined result")
+	}
+}
@@ -0,0 +1,81 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomically writes the content to a temporary file in the same directory and renames it into place, so readers
+// never observe a partially written file.
+func WriteFileAtomically(filename string, content []byte) error {
+	tmpName, err := writeTempFile(filename, content)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, filename); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return nil
+}
+
+// CreateFileAtomically is WriteFileAtomically for a file that must not exist yet.  If it does, it is left alone and an
+// error for which os.IsExist returns true is returned.
+func CreateFileAtomically(filename string, content []byte) error {
+	tmpName, err := writeTempFile(filename, content)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpName)
+	// unlike a rename, a link fails if the file exists
+	return os.Link(tmpName, filename)
+}
+
+// writeTempFile writes the content to a temporary file next to filename and returns its name.
+func writeTempFile(filename string, content []byte) (string, error) {
+	f, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp-")
+	if err != nil {
+		return "", err
+	}
+	tmpName := f.Name()
+
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		os.Remove(tmpName)
+		return "", err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmpName)
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmpName)
+		return "", err
+	}
+	return tmpName, nil
+}
+
+// LoadStrictJSON reads the JSON file into v.  Unknown fields are an error, because a misspelled field in a config would
+// silently keep its default.
+func LoadStrictJSON(filename string, v interface{}) error {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	if err := ParseStrictJSON(content, v); err != nil {
+		return fmt.Errorf("could not parse %s: %v", filename, err)
+	}
+	return nil
+}
+
+// ParseStrictJSON is LoadStrictJSON for content that is already in memory.
+func ParseStrictJSON(content []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
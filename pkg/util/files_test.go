@@ -0,0 +1,78 @@
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadStrictJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "strict-json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	type config struct {
+		Name string `json:"name"`
+	}
+	tests := []struct {
+		name     string
+		content  string
+		expected config
+		wantErr  bool
+	}{
+		{name: "known fields", content: `{"name": "a"}`, expected: config{Name: "a"}},
+		{name: "misspelled field", content: `{"nmae": "a"}`, wantErr: true},
+		{name: "invalid json", content: `{"name": `, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			filename := filepath.Join(dir, "config.json")
+			if err := ioutil.WriteFile(filename, []byte(tc.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			actual := config{}
+			err := LoadStrictJSON(filename, &actual)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %#v", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != tc.expected {
+				t.Errorf("expected %#v, got %#v", tc.expected, actual)
+			}
+		})
+	}
+
+	if err := LoadStrictJSON(filepath.Join(dir, "missing.json"), &config{}); !os.IsNotExist(err) {
+		t.Errorf("expected the error of the missing file, got %v", err)
+	}
+}
+
+func TestCreateFileAtomically(t *testing.T) {
+	dir, err := ioutil.TempDir("", "create-file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "file")
+	if err := CreateFileAtomically(filename, []byte("first")); err != nil {
+		t.Fatal(err)
+	}
+	if err := CreateFileAtomically(filename, []byte("second")); !os.IsExist(err) {
+		t.Errorf("expected the existing file to be refused, got %v", err)
+	}
+	if content, _ := ioutil.ReadFile(filename); string(content) != "first" {
+		t.Errorf("expected the existing file to be left alone, got %q", content)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 1 {
+		t.Errorf("expected no temporary files to be left behind, got %d files", len(files))
+	}
+}
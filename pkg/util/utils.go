@@ -2,6 +2,7 @@ package util
 
 import (
 	"regexp"
+	"strings"
 
 	bugsv1 "github.com/openshift/sippy/pkg/apis/bugs/v1"
 	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
@@ -87,11 +88,21 @@ func RelevantJob(jobName, status string, filter *regexp.Regexp) bool {
 	*/
 }
 
+// IsActiveBug returns whether the bug is still being worked on.  Statuses are compared like bugzilla statuses, so the jira
+// status "Release Pending" is RELEASE_PENDING.
 func IsActiveBug(bug bugsv1.Bug) bool {
-	switch bug.Status {
+	switch strings.ToUpper(strings.Replace(bug.Status, " ", "_", -1)) {
 	case "VERIFIED", "RELEASE_PENDING", "CLOSED":
 		return false
 	default:
 		return true
 	}
 }
+
+// Percent returns the percentage of successes, or 0 if there were no runs.
+func Percent(success, failure int) float64 {
+	if success+failure == 0 {
+		return 0.0
+	}
+	return float64(success) / float64(success+failure) * 100.0
+}
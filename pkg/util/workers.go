@@ -0,0 +1,24 @@
+package util
+
+import "sync"
+
+// RunWorkers calls work for every index in [0, numTasks) using at most numWorkers goroutines and waits for them to finish.
+func RunWorkers(numWorkers, numTasks int, work func(i int)) {
+	tasks := make(chan int)
+	wg := sync.WaitGroup{}
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range tasks {
+				work(task)
+			}
+		}()
+	}
+
+	for i := 0; i < numTasks; i++ {
+		tasks <- i
+	}
+	close(tasks)
+	wg.Wait()
+}
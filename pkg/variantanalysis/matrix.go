@@ -0,0 +1,238 @@
+package variantanalysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	sippyv1 "github.com/openshift/sippy/pkg/apis/sippy/v1"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testidentification"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testreportconversion"
+	"github.com/openshift/sippy/pkg/util"
+)
+
+const (
+	// DefaultMinTestRuns is the minimum number of runs of a test in a cell for it to be listed as failing.
+	DefaultMinTestRuns = 5
+	// DefaultMaxFailingTests is the number of failing tests listed for each cell.
+	DefaultMaxFailingTests = 5
+)
+
+type MatrixOptions struct {
+	// Rows are the names of the dimensions that identify the rows.  If Rows and Columns are both empty, the first
+	// dimension is used for the rows and the rest for the columns.
+	Rows []string
+	// Columns are the names of the dimensions that identify the columns.
+	Columns []string
+	// MinTestRuns is the minimum number of runs of a test in a cell for it to be listed as failing.
+	MinTestRuns int
+	// MaxFailingTests is the number of failing tests listed for each cell.
+	MaxFailingTests int
+}
+
+func DefaultMatrixOptions() MatrixOptions {
+	return MatrixOptions{
+		MinTestRuns:     DefaultMinTestRuns,
+		MaxFailingTests: DefaultMaxFailingTests,
+	}
+}
+
+type cell struct {
+	row, column []string
+	successes   int
+	failures    int
+	jobs        []string
+	testResults map[string]sippyprocessingv1.TestResult
+}
+
+// Matrix aggregates the job results of the report by the values of the row and column dimensions of the variant
+// manager.  Jobs that are never stable, or that do not belong to a variant of any dimension, are left out because they
+// would make up a meaningless cell of default values.
+func (o MatrixOptions) Matrix(report sippyprocessingv1.TestReport, variantManager testidentification.VariantManager) (sippyv1.VariantMatrix, error) {
+	dimensions := testidentification.VariantDimensions(variantManager)
+	if len(dimensions) == 0 {
+		return sippyv1.VariantMatrix{}, fmt.Errorf("the variants of %s have no dimensions", report.Release)
+	}
+
+	rows, columns := o.Rows, o.Columns
+	if len(rows) == 0 && len(columns) == 0 {
+		rows = []string{dimensions[0].Name}
+		for _, dimension := range dimensions[1:] {
+			columns = append(columns, dimension.Name)
+		}
+	}
+	for _, row := range rows {
+		for _, column := range columns {
+			if row == column {
+				return sippyv1.VariantMatrix{}, fmt.Errorf("variant dimension %q cannot be both a row and a column", row)
+			}
+		}
+	}
+	rowDimensions, err := findDimensions(dimensions, rows)
+	if err != nil {
+		return sippyv1.VariantMatrix{}, err
+	}
+	columnDimensions, err := findDimensions(dimensions, columns)
+	if err != nil {
+		return sippyv1.VariantMatrix{}, err
+	}
+
+	cells := map[string]*cell{}
+	for _, jobResult := range report.ByJob {
+		if variantManager.IsJobNeverStable(jobResult.Name) {
+			continue
+		}
+		jobVariants := variantManager.IdentifyVariants(jobResult.Name)
+		matchedAny := false
+		for _, dimension := range dimensions {
+			if _, matched := dimension.Value(jobVariants); matched {
+				matchedAny = true
+			}
+		}
+		if !matchedAny {
+			continue
+		}
+
+		row := values(rowDimensions, jobVariants)
+		column := values(columnDimensions, jobVariants)
+		key := MatrixKey(row, column)
+		curr, ok := cells[key]
+		if !ok {
+			curr = &cell{row: row, column: column, testResults: map[string]sippyprocessingv1.TestResult{}}
+			cells[key] = curr
+		}
+		curr.successes += jobResult.Successes
+		curr.failures += jobResult.Failures
+		curr.jobs = append(curr.jobs, jobResult.Name)
+		for _, testResult := range jobResult.TestResults {
+			existing := curr.testResults[testResult.Name]
+			existing.Name = testResult.Name
+			existing.Successes += testResult.Successes
+			existing.Failures += testResult.Failures
+			curr.testResults[testResult.Name] = existing
+		}
+	}
+
+	matrix := sippyv1.VariantMatrix{
+		Rows:         rows,
+		Columns:      columns,
+		RowValues:    [][]string{},
+		ColumnValues: [][]string{},
+		Cells:        []sippyv1.VariantMatrixCell{},
+	}
+	seenRows, seenColumns := map[string]bool{}, map[string]bool{}
+	for _, curr := range cells {
+		if rowKey := strings.Join(curr.row, "\x00"); !seenRows[rowKey] {
+			seenRows[rowKey] = true
+			matrix.RowValues = append(matrix.RowValues, curr.row)
+		}
+		if columnKey := strings.Join(curr.column, "\x00"); !seenColumns[columnKey] {
+			seenColumns[columnKey] = true
+			matrix.ColumnValues = append(matrix.ColumnValues, curr.column)
+		}
+		sort.Strings(curr.jobs)
+		matrix.Cells = append(matrix.Cells, sippyv1.VariantMatrixCell{
+			Row:                  curr.row,
+			Column:               curr.column,
+			JobRunSuccesses:      curr.successes,
+			JobRunFailures:       curr.failures,
+			JobRunPassPercentage: util.Percent(curr.successes, curr.failures),
+			Jobs:                 curr.jobs,
+			FailingTests:         o.failingTests(curr.testResults),
+		})
+	}
+	sortValues(matrix.RowValues, rowDimensions)
+	sortValues(matrix.ColumnValues, columnDimensions)
+	// lowest pass rate first, so the combinations dragging the release down are easy to find
+	sort.Slice(matrix.Cells, func(i, j int) bool {
+		if matrix.Cells[i].JobRunPassPercentage != matrix.Cells[j].JobRunPassPercentage {
+			return matrix.Cells[i].JobRunPassPercentage < matrix.Cells[j].JobRunPassPercentage
+		}
+		return strings.Join(matrix.Cells[i].Jobs, ",") < strings.Join(matrix.Cells[j].Jobs, ",")
+	})
+
+	return matrix, nil
+}
+
+func (o MatrixOptions) failingTests(testResults map[string]sippyprocessingv1.TestResult) []sippyv1.VariantMatrixTest {
+	filterFn := testreportconversion.TestResultFilterFuncs{
+		testreportconversion.FilterLowValueTestsByName,
+		testreportconversion.FilterTooFewTestRuns(o.MinTestRuns),
+	}.And
+
+	failing := []sippyv1.VariantMatrixTest{}
+	for _, testResult := range testResults {
+		if testResult.Failures == 0 || !filterFn(testResult) {
+			continue
+		}
+		failing = append(failing, sippyv1.VariantMatrixTest{
+			Name:           testResult.Name,
+			PassPercentage: util.Percent(testResult.Successes, testResult.Failures),
+			Runs:           testResult.Successes + testResult.Failures,
+		})
+	}
+	sort.Slice(failing, func(i, j int) bool {
+		if failing[i].PassPercentage != failing[j].PassPercentage {
+			return failing[i].PassPercentage < failing[j].PassPercentage
+		}
+		return failing[i].Name < failing[j].Name
+	})
+	if len(failing) > o.MaxFailingTests {
+		failing = failing[:o.MaxFailingTests]
+	}
+	return failing
+}
+
+func findDimensions(dimensions []testidentification.VariantDimension, names []string) ([]testidentification.VariantDimension, error) {
+	found := []testidentification.VariantDimension{}
+	for _, name := range names {
+		matched := false
+		for _, dimension := range dimensions {
+			if dimension.Name == name {
+				found = append(found, dimension)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("unknown variant dimension %q", name)
+		}
+	}
+	return found, nil
+}
+
+func values(dimensions []testidentification.VariantDimension, jobVariants []string) []string {
+	ret := []string{}
+	for _, dimension := range dimensions {
+		value, _ := dimension.Value(jobVariants)
+		ret = append(ret, value)
+	}
+	return ret
+}
+
+// sortValues orders the combinations of values by the order of the variants in each dimension, with the default last.
+func sortValues(combinations [][]string, dimensions []testidentification.VariantDimension) {
+	order := func(dimension testidentification.VariantDimension, value string) int {
+		for i, variant := range dimension.Variants {
+			if variant == value {
+				return i
+			}
+		}
+		return len(dimension.Variants)
+	}
+	sort.Slice(combinations, func(i, j int) bool {
+		for k, dimension := range dimensions {
+			lhs, rhs := order(dimension, combinations[i][k]), order(dimension, combinations[j][k])
+			if lhs != rhs {
+				return lhs < rhs
+			}
+		}
+		return false
+	})
+}
+
+// MatrixKey identifies the cell of a matrix by the values of its row and column.
+func MatrixKey(row, column []string) string {
+	return strings.Join(row, "\x00") + "\x01" + strings.Join(column, "\x00")
+}
@@ -0,0 +1,143 @@
+package variantanalysis
+
+import (
+	"reflect"
+	"testing"
+
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/testgridanalysis/testidentification"
+)
+
+func newTestVariantManager(t *testing.T) testidentification.VariantManager {
+	variantManager, err := testidentification.NewConfigVariantManager(testidentification.VariantConfig{
+		Variants: []testidentification.VariantDefinition{
+			{Name: "aws", Include: []string{"-aws"}},
+			{Name: "gcp", Include: []string{"-gcp"}},
+			{Name: "ovn", Include: []string{"-ovn"}},
+		},
+		NeverStableJobs: []string{"e2e-aws-broken"},
+		Dimensions: []testidentification.VariantDimension{
+			{Name: "platform", Variants: []string{"aws", "gcp"}, Default: "other"},
+			{Name: "network", Variants: []string{"ovn"}, Default: "sdn"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return variantManager
+}
+
+func newTestReport() sippyprocessingv1.TestReport {
+	return sippyprocessingv1.TestReport{
+		Release: "4.7",
+		ByJob: []sippyprocessingv1.JobResult{
+			{
+				Name: "e2e-aws", Successes: 9, Failures: 1,
+				TestResults: []sippyprocessingv1.TestResult{
+					{Name: "storage", Successes: 9, Failures: 1},
+				},
+			},
+			{
+				Name: "e2e-aws-ovn", Successes: 2, Failures: 8,
+				TestResults: []sippyprocessingv1.TestResult{
+					{Name: "storage", Successes: 8, Failures: 2},
+					{Name: "network", Successes: 2, Failures: 8},
+					{Name: "rare", Successes: 1, Failures: 1},
+				},
+			},
+			{
+				Name: "e2e-gcp", Successes: 5, Failures: 5,
+				TestResults: []sippyprocessingv1.TestResult{
+					{Name: "storage", Successes: 10},
+				},
+			},
+			{Name: "e2e-gcp-2", Successes: 5, Failures: 0},
+			// left out because it is never stable, and because it matches no dimension
+			{Name: "e2e-aws-broken", Successes: 0, Failures: 10},
+			{Name: "e2e-metal", Successes: 0, Failures: 10},
+		},
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	matrix, err := DefaultMatrixOptions().Matrix(newTestReport(), newTestVariantManager(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(matrix.Rows, []string{"platform"}) || !reflect.DeepEqual(matrix.Columns, []string{"network"}) {
+		t.Errorf("expected platform rows and network columns, got %v and %v", matrix.Rows, matrix.Columns)
+	}
+	if expected := [][]string{{"aws"}, {"gcp"}}; !reflect.DeepEqual(matrix.RowValues, expected) {
+		t.Errorf("expected row values %v, got %v", expected, matrix.RowValues)
+	}
+	if expected := [][]string{{"ovn"}, {"sdn"}}; !reflect.DeepEqual(matrix.ColumnValues, expected) {
+		t.Errorf("expected column values %v, got %v", expected, matrix.ColumnValues)
+	}
+	if len(matrix.Cells) != 3 {
+		t.Fatalf("expected 3 cells, got %#v", matrix.Cells)
+	}
+
+	worst := matrix.Cells[0]
+	if !reflect.DeepEqual(worst.Row, []string{"aws"}) || !reflect.DeepEqual(worst.Column, []string{"ovn"}) || worst.JobRunPassPercentage != 20 {
+		t.Errorf("expected aws/ovn to be the worst cell at 20%%, got %#v", worst)
+	}
+	if len(worst.FailingTests) != 2 || worst.FailingTests[0].Name != "network" || worst.FailingTests[1].Name != "storage" {
+		t.Errorf("expected network and storage to be failing in aws/ovn, got %#v", worst.FailingTests)
+	}
+
+	gcp := matrix.Cells[1]
+	if !reflect.DeepEqual(gcp.Jobs, []string{"e2e-gcp", "e2e-gcp-2"}) || gcp.JobRunSuccesses != 10 || gcp.JobRunFailures != 5 {
+		t.Errorf("expected both gcp jobs to be combined, got %#v", gcp)
+	}
+	if len(gcp.FailingTests) != 0 {
+		t.Errorf("expected no failing tests in gcp/sdn, got %#v", gcp.FailingTests)
+	}
+}
+
+func TestMatrixPivot(t *testing.T) {
+	options := DefaultMatrixOptions()
+	options.Rows = []string{"network", "platform"}
+	matrix, err := options.Matrix(newTestReport(), newTestVariantManager(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := [][]string{{"ovn", "aws"}, {"sdn", "aws"}, {"sdn", "gcp"}}; !reflect.DeepEqual(matrix.RowValues, expected) {
+		t.Errorf("expected row values %v, got %v", expected, matrix.RowValues)
+	}
+	if expected := [][]string{{}}; !reflect.DeepEqual(matrix.ColumnValues, expected) {
+		t.Errorf("expected a single column, got %v", matrix.ColumnValues)
+	}
+}
+
+func TestMatrixErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		options        MatrixOptions
+		variantManager testidentification.VariantManager
+	}{
+		{
+			name:           "no dimensions",
+			options:        DefaultMatrixOptions(),
+			variantManager: testidentification.NewEmptyVariantManager(),
+		},
+		{
+			name:           "unknown dimension",
+			options:        MatrixOptions{Rows: []string{"arch"}},
+			variantManager: newTestVariantManager(t),
+		},
+		{
+			name:           "row and column",
+			options:        MatrixOptions{Rows: []string{"platform"}, Columns: []string{"platform"}},
+			variantManager: newTestVariantManager(t),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := tc.options.Matrix(newTestReport(), tc.variantManager); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -93,7 +93,7 @@ export class JobsDashboard extends React.Component {
             while (ts >= timestampBegin) {
                 let results = [];
                 while (job.timestamps[i] >= ts) {
-                    results.push(<a key={"result-"+i} className={'result result-' + job.results[i]} href={'https://prow.ci.openshift.org/view/gs/origin-ci-test/logs/' + job.name + '/' + job.build_ids[i]} target="_blank">{job.results[i]}</a>);
+                    results.push(<a key={"result-"+i} className={'result result-' + job.results[i]} href={'/jobrun?release=' + encodeURIComponent(this.props.release) + '&url=' + encodeURIComponent(job.run_urls[i])} target="_blank">{job.results[i]}</a>);
                     i++;
                 }
                 row.push(<td key={"ts-"+ts} className="col-day"><div className="results">{results}</div></td>);